@@ -0,0 +1,124 @@
+// Package archivehash computes BLAKE2 digests of the files in tar and zip
+// archives as they are read, without extracting them, and of the files
+// of tar archives as they are written.
+//
+// Besides the digest of each regular file, it computes a digest of the
+// whole archive contents: the digest of the sequence, in archive order,
+// of
+//
+//	uvarint(len(name)) || name || uvarint(size) || digest
+//
+// for every regular file. It only depends on the names and contents of
+// the files, not on the archive format, timestamps or permissions.
+package archivehash
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/binary"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Entry is the digest of a file in an archive.
+type Entry struct {
+	Name   string
+	Size   int64
+	Digest []byte
+}
+
+// Result holds the digests of an archive.
+type Result struct {
+	Entries []Entry
+	// Digest is the digest of the archive contents.
+	Digest []byte
+}
+
+// digester accumulates the entries of an archive.
+type digester struct {
+	newHash func() hash.Hash
+	h       hash.Hash
+	all     hash.Hash
+	res     Result
+}
+
+func newDigester(newHash func() hash.Hash) *digester {
+	if newHash == nil {
+		newHash = func() hash.Hash { return blake2b.New(&blake2b.Config{Size: 32}) }
+	}
+	return &digester{newHash: newHash, h: newHash(), all: newHash()}
+}
+
+func (d *digester) add(name string, r io.Reader) error {
+	d.h.Reset()
+	size, err := io.Copy(d.h, r)
+	if err != nil {
+		return err
+	}
+	d.record(name, size)
+	return nil
+}
+
+// record adds the entry of the file name of size bytes, whose contents
+// have been written to d.h.
+func (d *digester) record(name string, size int64) {
+	e := Entry{Name: name, Size: size, Digest: d.h.Sum(nil)}
+	d.res.Entries = append(d.res.Entries, e)
+
+	var tmp [binary.MaxVarintLen64]byte
+	d.all.Write(tmp[:binary.PutUvarint(tmp[:], uint64(len(name)))])
+	io.WriteString(d.all, name)
+	d.all.Write(tmp[:binary.PutUvarint(tmp[:], uint64(size))])
+	d.all.Write(e.Digest)
+}
+
+func (d *digester) result() *Result {
+	d.res.Digest = d.all.Sum(nil)
+	return &d.res
+}
+
+// Tar reads tr to the end and returns the digests of its regular files,
+// computed with hashes from newHash, or 32-byte BLAKE2b if it is nil.
+func Tar(tr *tar.Reader, newHash func() hash.Hash) (*Result, error) {
+	d := newDigester(newHash)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return d.result(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if err := d.add(hdr.Name, tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Zip returns the digests of the files in zr, computed with hashes from
+// newHash, or 32-byte BLAKE2b if it is nil. The files are read in the
+// order of the central directory, and their CRC-32 is checked as well.
+func Zip(zr *zip.Reader, newHash func() hash.Hash) (*Result, error) {
+	d := newDigester(newHash)
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") || !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = d.add(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d.result(), nil
+}
@@ -0,0 +1,68 @@
+package archivehash
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+var files = []struct {
+	name, data string
+}{
+	{"README", "read me"},
+	{"src/main.go", "package main"},
+	{"empty", ""},
+}
+
+func TestTarAndZip(t *testing.T) {
+	var tbuf bytes.Buffer
+	tw := tar.NewWriter(&tbuf)
+	tw.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range files {
+		tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.data))})
+		tw.Write([]byte(f.data))
+	}
+	tw.Close()
+
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	zw.Create("src/")
+	for _, f := range files {
+		w, _ := zw.Create(f.name)
+		w.Write([]byte(f.data))
+	}
+	zw.Close()
+
+	tr, err := Tar(tar.NewReader(&tbuf), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zbuf.Bytes()), int64(zbuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zres, err := Zip(zr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, res := range []*Result{tr, zres} {
+		if len(res.Entries) != len(files) {
+			t.Fatalf("got %d entries, want %d", len(res.Entries), len(files))
+		}
+		for i, f := range files {
+			h := blake2b.New(&blake2b.Config{Size: 32})
+			h.Write([]byte(f.data))
+			e := res.Entries[i]
+			if e.Name != f.name || e.Size != int64(len(f.data)) || !bytes.Equal(e.Digest, h.Sum(nil)) {
+				t.Errorf("entry %d: got %+v", i, e)
+			}
+		}
+	}
+	if !bytes.Equal(tr.Digest, zres.Digest) {
+		t.Error("tar and zip archives of the same files have different digests")
+	}
+}
@@ -0,0 +1,116 @@
+package archivehash
+
+import (
+	"archive/tar"
+	"bytes"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/jadeydi/blake2/sumfile"
+)
+
+// TarWriter writes a tar archive like tar.Writer, and computes the digests
+// of its regular files as they are written, as Tar would compute them by
+// reading the archive back, so that build systems get an archive and its
+// checksums in a single pass over the data.
+type TarWriter struct {
+	tw *tar.Writer
+	d  *digester
+	// name and size are those of the regular file being written, if
+	// inFile is set.
+	name    string
+	size    int64
+	inFile  bool
+	modTime time.Time
+}
+
+// NewTarWriter returns a TarWriter writing to w, computing digests with
+// hashes from newHash, or 32-byte BLAKE2b if it is nil.
+func NewTarWriter(w io.Writer, newHash func() hash.Hash) *TarWriter {
+	return &TarWriter{tw: tar.NewWriter(w), d: newDigester(newHash)}
+}
+
+// WriteHeader writes hdr and begins a new file, as tar.Writer does.
+func (t *TarWriter) WriteHeader(hdr *tar.Header) error {
+	t.finish()
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.ModTime.After(t.modTime) {
+		t.modTime = hdr.ModTime
+	}
+	if hdr.FileInfo().Mode().IsRegular() {
+		t.d.h.Reset()
+		t.name, t.size, t.inFile = hdr.Name, 0, true
+	}
+	return nil
+}
+
+// Write writes to the current file, hashing the bytes written.
+func (t *TarWriter) Write(p []byte) (int, error) {
+	n, err := t.tw.Write(p)
+	if t.inFile {
+		t.d.h.Write(p[:n])
+		t.size += int64(n)
+	}
+	return n, err
+}
+
+// finish records the digest of the current file, if any.
+func (t *TarWriter) finish() {
+	if t.inFile {
+		t.d.record(t.name, t.size)
+		t.inFile = false
+	}
+}
+
+// Result returns the digests of the regular files written so far.
+func (t *TarWriter) Result() *Result {
+	t.finish()
+	r := *t.d.result()
+	r.Entries = append([]Entry(nil), r.Entries...)
+	return &r
+}
+
+// WriteManifest ends the current file and appends a file named name to
+// the archive, listing the digests of the regular files written so far
+// in the GNU format of package sumfile, so that the extracted files can
+// be checked with b2sum -c when digests are BLAKE2b. The manifest itself
+// is not part of Result, though Tar finds it when reading the archive.
+// It has mode 0644 and the latest modification time of the files.
+func (t *TarWriter) WriteManifest(name string) error {
+	t.finish()
+	var buf bytes.Buffer
+	w := sumfile.NewWriter(&buf, false)
+	for _, e := range t.d.res.Entries {
+		if err := w.Write(sumfile.Entry{Path: e.Name, Digest: e.Digest}); err != nil {
+			return err
+		}
+	}
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(buf.Len()),
+		ModTime:  t.modTime,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(buf.Bytes())
+	return err
+}
+
+// Flush finishes writing the current file, as tar.Writer does.
+func (t *TarWriter) Flush() error {
+	t.finish()
+	return t.tw.Flush()
+}
+
+// Close closes the archive, as tar.Writer does, without closing the
+// underlying writer.
+func (t *TarWriter) Close() error {
+	t.finish()
+	return t.tw.Close()
+}
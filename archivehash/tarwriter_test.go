@@ -0,0 +1,78 @@
+package archivehash
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jadeydi/blake2/sumfile"
+)
+
+func TestTarWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTarWriter(&buf, nil)
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tw.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "README"})
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.data)), ModTime: mtime}); err != nil {
+			t.Fatal(err)
+		}
+		// Write in two pieces.
+		half := len(f.data) / 2
+		tw.Write([]byte(f.data[:half]))
+		tw.Write([]byte(f.data[half:]))
+	}
+	got := tw.Result()
+	if err := tw.WriteManifest("B2SUMS"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Tar(tar.NewReader(bytes.NewReader(buf.Bytes())), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(want.Entries); n != len(files)+1 || want.Entries[n-1].Name != "B2SUMS" {
+		t.Fatalf("archive entries %+v", want.Entries)
+	}
+	if !reflect.DeepEqual(got.Entries, want.Entries[:len(files)]) {
+		t.Errorf("TarWriter entries %+v, want %+v", got.Entries, want.Entries[:len(files)])
+	}
+	if res := tw.Result(); !reflect.DeepEqual(res, got) {
+		t.Errorf("Result after the manifest = %+v, want %+v", res, got)
+	}
+
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name != "B2SUMS" {
+			continue
+		}
+		if !hdr.ModTime.Equal(mtime) {
+			t.Errorf("manifest time %v, want %v", hdr.ModTime, mtime)
+		}
+		r := sumfile.NewReader(tr)
+		for i, f := range files {
+			e, err := r.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Path != f.name || !bytes.Equal(e.Digest, got.Entries[i].Digest) {
+				t.Errorf("manifest line %d = %+v", i, e)
+			}
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("manifest end: %v", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,233 @@
+// Package argon2 implements the Argon2id and Argon2i memory-hard key
+// derivation functions of RFC 9106, version 1.3, on top of the blake2b
+// package: the initial hash H0 and the variable-length hash H' run on its
+// fastest backend. The block function is BlaMka, a variant of the BLAKE2b
+// round with extra multiplications, computed in Go.
+//
+// For password hashing, Argon2id is recommended, as
+//
+//	key := argon2.IDKey(password, salt, 1, 64*1024, 4, 32)
+//
+// with a random salt of at least 16 bytes. The parameters are the number
+// of passes over memory, the memory size in KiB, the number of lanes
+// hashed in parallel and the key length in bytes.
+package argon2
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Version is the implemented version of Argon2.
+const Version = 0x13
+
+const (
+	argon2d = iota
+	argon2i
+	argon2id
+)
+
+const (
+	blockLength = 128 // in 64-bit words
+	syncPoints  = 4   // slices per pass
+)
+
+type block [blockLength]uint64
+
+// Key derives a keyLen-byte key from password and salt with Argon2i,
+// which is resistant to side-channel attacks, making time passes over
+// memory KiB of memory with threads lanes. Prefer IDKey for password
+// hashing. It panics if time or threads is 0.
+func Key(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon2i, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+// IDKey derives a keyLen-byte key from password and salt with Argon2id,
+// which resists both side-channel and GPU cracking attacks, making time
+// passes over memory KiB of memory with threads lanes. RFC 9106
+// recommends time = 1 and memory = 2 GiB, or time = 3 and memory = 64 MiB
+// when memory is constrained. It panics if time or threads is 0.
+func IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon2id, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+func deriveKey(mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	if time < 1 {
+		panic("argon2: number of passes too small")
+	}
+	if threads < 1 {
+		panic("argon2: parallelism degree too low")
+	}
+	h0 := initHash(password, salt, secret, data, time, memory, uint32(threads), keyLen, mode)
+
+	// Memory is rounded down to a multiple of 4 blocks per lane, with at
+	// least 8 blocks per lane.
+	memory = memory / (syncPoints * uint32(threads)) * (syncPoints * uint32(threads))
+	if memory < 2*syncPoints*uint32(threads) {
+		memory = 2 * syncPoints * uint32(threads)
+	}
+	B := initBlocks(&h0, memory, uint32(threads))
+	processBlocks(B, time, memory, uint32(threads), mode)
+	return extractKey(B, memory, uint32(threads), keyLen)
+}
+
+// initHash returns H0 followed by room for the block and lane numbers
+// that the first blocks of each lane are derived with.
+func initHash(password, salt, secret, data []byte, time, memory, threads, keyLen uint32, mode int) [blake2b.MaxDigestSize + 8]byte {
+	var h0 [blake2b.MaxDigestSize + 8]byte
+	d := blake2b.New(nil)
+	writeUint32 := func(v uint32) {
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], v)
+		d.Write(buf[:])
+	}
+	writeUint32(threads)
+	writeUint32(keyLen)
+	writeUint32(memory)
+	writeUint32(time)
+	writeUint32(Version)
+	writeUint32(uint32(mode))
+	for _, b := range [][]byte{password, salt, secret, data} {
+		writeUint32(uint32(len(b)))
+		d.Write(b)
+	}
+	d.Sum(h0[:0])
+	return h0
+}
+
+// initBlocks allocates the memory and computes the first two blocks of
+// each lane.
+func initBlocks(h0 *[blake2b.MaxDigestSize + 8]byte, memory, threads uint32) []block {
+	B := make([]block, memory)
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * (memory / threads)
+		binary.LittleEndian.PutUint32(h0[blake2b.MaxDigestSize+4:], lane)
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.MaxDigestSize:], i)
+			b := blake2b.Long(8*blockLength, h0[:])
+			for k := range B[j+i] {
+				B[j+i][k] = binary.LittleEndian.Uint64(b[8*k:])
+			}
+		}
+	}
+	return B
+}
+
+// processBlocks fills the memory, making time passes. Each pass is split
+// into four slices; the segments of a slice, one per lane, are computed
+// concurrently.
+func processBlocks(B []block, time, memory, threads uint32, mode int) {
+	lanes := memory / threads
+	segments := lanes / syncPoints
+
+	processSegment := func(n, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+		var addresses, in, zero block
+		// Argon2i, and Argon2id in the first half of the first pass,
+		// take reference block indices from a counter-based sequence
+		// rather than from the memory contents.
+		independent := mode == argon2i || (mode == argon2id && n == 0 && slice < syncPoints/2)
+		if independent {
+			in[0] = uint64(n)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if n == 0 && slice == 0 {
+			index = 2 // the first two blocks are already computed
+			if independent {
+				in[6]++
+				processBlock(&addresses, &in, &zero)
+				processBlock(&addresses, &addresses, &zero)
+			}
+		}
+
+		offset := lane*lanes + slice*segments + index
+		for index < segments {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				prev += lanes // the last block of the lane
+			}
+			var random uint64
+			if independent {
+				if index%blockLength == 0 {
+					in[6]++
+					processBlock(&addresses, &in, &zero)
+					processBlock(&addresses, &addresses, &zero)
+				}
+				random = addresses[index%blockLength]
+			} else {
+				random = B[prev][0]
+			}
+			ref := indexAlpha(random, lanes, segments, threads, n, slice, lane, index)
+			processBlockXOR(&B[offset], &B[prev], &B[ref])
+			index, offset = index+1, offset+1
+		}
+	}
+
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(n, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// extractKey XORs the last blocks of every lane and hashes the result to
+// a keyLen-byte key.
+func extractKey(B []block, memory, threads, keyLen uint32) []byte {
+	lanes := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range B[lane*lanes+lanes-1] {
+			B[memory-1][i] ^= v
+		}
+	}
+	var b [8 * blockLength]byte
+	for i, v := range B[memory-1] {
+		binary.LittleEndian.PutUint64(b[8*i:], v)
+	}
+	return blake2b.Long(int(keyLen), b[:])
+}
+
+// indexAlpha maps the pseudo-random value rand to the index of the block
+// referenced by block index of the segment of lane (RFC 9106, section
+// 3.4.1.2).
+func indexAlpha(rand uint64, lanes, segments, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(rand>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+	m, s := 3*segments, ((slice+1)%syncPoints)*segments
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segments, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+	return phi(rand, uint64(m), uint64(s), refLane, lanes)
+}
+
+// phi picks a block among the m candidates starting at s in lane, with a
+// distribution biased towards recent blocks.
+func phi(rand, m, s uint64, lane, lanes uint32) uint32 {
+	p := rand & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * m) >> 32
+	return lane*lanes + uint32((s+m-(p+1))%uint64(lanes))
+}
@@ -0,0 +1,48 @@
+package argon2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestRFC9106 checks the test vectors of RFC 9106, section 5.
+func TestRFC9106(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+	for _, c := range []struct {
+		mode int
+		want string
+	}{
+		{argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{argon2i, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	} {
+		got := hex.EncodeToString(deriveKey(c.mode, password, salt, secret, data, 3, 32, 4, 32))
+		if got != c.want {
+			t.Errorf("mode %d: tag %s, want %s", c.mode, got, c.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+	a := IDKey(password, salt, 1, 64, 2, 32)
+	if len(a) != 32 {
+		t.Fatalf("IDKey returned %d bytes", len(a))
+	}
+	if !bytes.Equal(a, IDKey(password, salt, 1, 64, 2, 32)) {
+		t.Error("IDKey is not deterministic")
+	}
+	if bytes.Equal(a, Key(password, salt, 1, 64, 2, 32)) {
+		t.Error("Argon2i and Argon2id give the same key")
+	}
+	if bytes.Equal(a, IDKey(password, salt, 2, 64, 2, 32)) {
+		t.Error("number of passes does not change the key")
+	}
+	if k := IDKey(password, salt, 1, 64, 1, 100); len(k) != 100 {
+		t.Errorf("IDKey returned %d bytes, want 100", len(k))
+	}
+}
@@ -0,0 +1,87 @@
+package argon2
+
+import "math/bits"
+
+// processBlock sets out to the compression G(in1, in2).
+func processBlock(out, in1, in2 *block) {
+	processBlockGeneric(out, in1, in2, false)
+}
+
+// processBlockXOR XORs the compression G(in1, in2) into out, as passes
+// after the first do in version 1.3.
+func processBlockXOR(out, in1, in2 *block) {
+	processBlockGeneric(out, in1, in2, true)
+}
+
+// processBlockGeneric computes G: the block R = in1 XOR in2, seen as an
+// 8x8 matrix of 16-byte registers, goes through BlaMka rounds on each
+// row and then on each column, and the result is XORed with R.
+func processBlockGeneric(out, in1, in2 *block, xor bool) {
+	var t block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+	for i := 0; i < blockLength; i += 16 {
+		blamka(
+			&t[i+0], &t[i+1], &t[i+2], &t[i+3],
+			&t[i+4], &t[i+5], &t[i+6], &t[i+7],
+			&t[i+8], &t[i+9], &t[i+10], &t[i+11],
+			&t[i+12], &t[i+13], &t[i+14], &t[i+15],
+		)
+	}
+	for i := 0; i < blockLength/8; i += 2 {
+		blamka(
+			&t[i], &t[i+1], &t[16+i], &t[16+i+1],
+			&t[32+i], &t[32+i+1], &t[48+i], &t[48+i+1],
+			&t[64+i], &t[64+i+1], &t[80+i], &t[80+i+1],
+			&t[96+i], &t[96+i+1], &t[112+i], &t[112+i+1],
+		)
+	}
+	if xor {
+		for i := range t {
+			out[i] ^= in1[i] ^ in2[i] ^ t[i]
+		}
+	} else {
+		for i := range t {
+			out[i] = in1[i] ^ in2[i] ^ t[i]
+		}
+	}
+}
+
+// blamka is one round of BLAKE2b on the 4x4 matrix of words v00..v15,
+// with GB in place of G.
+func blamka(t00, t01, t02, t03, t04, t05, t06, t07, t08, t09, t10, t11, t12, t13, t14, t15 *uint64) {
+	v00, v01, v02, v03 := *t00, *t01, *t02, *t03
+	v04, v05, v06, v07 := *t04, *t05, *t06, *t07
+	v08, v09, v10, v11 := *t08, *t09, *t10, *t11
+	v12, v13, v14, v15 := *t12, *t13, *t14, *t15
+
+	v00, v04, v08, v12 = gb(v00, v04, v08, v12)
+	v01, v05, v09, v13 = gb(v01, v05, v09, v13)
+	v02, v06, v10, v14 = gb(v02, v06, v10, v14)
+	v03, v07, v11, v15 = gb(v03, v07, v11, v15)
+
+	v00, v05, v10, v15 = gb(v00, v05, v10, v15)
+	v01, v06, v11, v12 = gb(v01, v06, v11, v12)
+	v02, v07, v08, v13 = gb(v02, v07, v08, v13)
+	v03, v04, v09, v14 = gb(v03, v04, v09, v14)
+
+	*t00, *t01, *t02, *t03 = v00, v01, v02, v03
+	*t04, *t05, *t06, *t07 = v04, v05, v06, v07
+	*t08, *t09, *t10, *t11 = v08, v09, v10, v11
+	*t12, *t13, *t14, *t15 = v12, v13, v14, v15
+}
+
+// gb is the G function of BLAKE2b with the additions a + b replaced by
+// a + b + 2*lo(a)*lo(b), where lo takes the low 32 bits.
+func gb(a, b, c, d uint64) (uint64, uint64, uint64, uint64) {
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d = bits.RotateLeft64(d^a, -32)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b = bits.RotateLeft64(b^c, -24)
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d = bits.RotateLeft64(d^a, -16)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b = bits.RotateLeft64(b^c, -63)
+	return a, b, c, d
+}
@@ -0,0 +1,89 @@
+package blake2
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// AsyncHasher hashes readers submitted by a pipeline on a fixed pool of
+// workers, each reusing one digest and one copy buffer, so that memory
+// stays bounded however many objects flow through it.
+type AsyncHasher struct {
+	jobs chan asyncJob
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+type asyncJob struct {
+	r io.Reader
+	// max is the limit of SubmitLimited, or -1.
+	max    int64
+	result chan<- Result
+}
+
+// NewAsyncHasher returns an AsyncHasher hashing with cfg, of the variant
+// given by its Variant field, on workers goroutines, or runtime.NumCPU()
+// if workers is not positive. Up to queue jobs wait for a worker before
+// Submit blocks. Close must be called to stop the workers.
+func NewAsyncHasher(workers, queue int, cfg *Config) (*AsyncHasher, error) {
+	h, err := newConfigHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	a := &AsyncHasher{jobs: make(chan asyncJob, queue)}
+	a.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go a.work(h.Clone())
+	}
+	return a, nil
+}
+
+func (a *AsyncHasher) work(h Hasher) {
+	defer a.wg.Done()
+	buf := make([]byte, 32<<10)
+	limited := NewLimitedHasher(h, 0)
+	for job := range a.jobs {
+		w := h
+		if job.max >= 0 {
+			limited.max = job.max
+			w = limited
+		}
+		w.Reset()
+		var r Result
+		r.Size, r.Err = io.CopyBuffer(w, job.r, buf)
+		if r.Err == nil {
+			r.Digest = w.Sum(nil)
+		}
+		job.result <- r
+	}
+}
+
+// Submit queues r to be hashed and returns a channel receiving its
+// result, with an empty Path, once it is read to the end. The channel is
+// buffered, so results need not be received. Submit blocks while the
+// queue is full, holding back the pipeline, and panics after Close.
+func (a *AsyncHasher) Submit(r io.Reader) <-chan Result {
+	return a.SubmitLimited(r, -1)
+}
+
+// SubmitLimited is like Submit, but stops reading r once more than max
+// bytes have been read, if max is not negative, and reports ErrLimit;
+// the Size of the result is then max.
+func (a *AsyncHasher) SubmitLimited(r io.Reader, max int64) <-chan Result {
+	result := make(chan Result, 1)
+	a.jobs <- asyncJob{r, max, result}
+	return result
+}
+
+// Close waits for the jobs submitted to be hashed and stops the workers.
+func (a *AsyncHasher) Close() {
+	a.once.Do(func() { close(a.jobs) })
+	a.wg.Wait()
+}
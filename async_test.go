@@ -0,0 +1,67 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestAsyncHasher(t *testing.T) {
+	cfg := &Config{Variant: BLAKE2s, Size: 16}
+	a, err := NewAsyncHasher(3, 2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []<-chan Result
+	var inputs []string
+	for i := 0; i < 20; i++ {
+		in := strings.Repeat(fmt.Sprint(i), 1000*i)
+		inputs = append(inputs, in)
+		results = append(results, a.Submit(strings.NewReader(in)))
+	}
+	fail := errors.New("read failed")
+	failed := a.Submit(iotest.ErrReader(fail))
+	a.Close()
+
+	for i, c := range results {
+		r := <-c
+		h, _ := NewHasher(BLAKE2s, cfg)
+		h.Write([]byte(inputs[i]))
+		if r.Err != nil || r.Size != int64(len(inputs[i])) || !bytes.Equal(r.Digest, h.Sum(nil)) {
+			t.Errorf("job %d: result %+v", i, r)
+		}
+	}
+	if r := <-failed; r.Err != fail || r.Digest != nil {
+		t.Errorf("failing reader: result %+v", r)
+	}
+
+	if _, err := NewAsyncHasher(1, 0, &Config{Size: 65}); err == nil {
+		t.Error("NewAsyncHasher accepted an invalid config")
+	}
+}
+
+func TestAsyncHasherLimit(t *testing.T) {
+	a, err := NewAsyncHasher(1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	over := a.SubmitLimited(strings.NewReader(strings.Repeat("x", 100001)), 100000)
+	within := a.SubmitLimited(strings.NewReader("short"), 5)
+	unlimited := a.Submit(strings.NewReader(strings.Repeat("x", 100001)))
+	a.Close()
+
+	if r := <-over; r.Err != ErrLimit || r.Size != 100000 || r.Digest != nil {
+		t.Errorf("over the limit: result %+v", r)
+	}
+	h, _ := NewHasher(BLAKE2b, nil)
+	h.Write([]byte("short"))
+	if r := <-within; r.Err != nil || r.Size != 5 || !bytes.Equal(r.Digest, h.Sum(nil)) {
+		t.Errorf("within the limit: result %+v", r)
+	}
+	if r := <-unlimited; r.Err != nil || r.Size != 100001 {
+		t.Errorf("after a limited job: result %+v", r)
+	}
+}
@@ -0,0 +1,29 @@
+package blake2
+
+import (
+	"errors"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// Backends returns the names of the implementations available in this
+// build for both variants, sorted; see blake2b.Backends.
+func Backends() []string {
+	return blake2b.Backends()
+}
+
+// SetBackend selects the implementation used by hashes of both variants
+// created afterwards, by one of the names returned by Backends. Like
+// blake2b.SetBackend, it is meant for program initialization.
+func SetBackend(name string) error {
+	for _, b := range Backends() {
+		if b == name {
+			if err := blake2b.SetBackend(name); err != nil {
+				return err
+			}
+			return blake2s.SetBackend(name)
+		}
+	}
+	return errors.New("blake2: unknown backend " + name)
+}
@@ -0,0 +1,26 @@
+package blake2
+
+import (
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestSetBackend(t *testing.T) {
+	savedB, savedS := blake2b.Backend(), blake2s.Backend()
+	defer func() {
+		blake2b.SetBackend(savedB)
+		blake2s.SetBackend(savedS)
+	}()
+
+	if err := SetBackend("pure-go"); err != nil {
+		t.Fatal(err)
+	}
+	if blake2b.Backend() != "pure-go" || blake2s.Backend() != "pure-go" {
+		t.Errorf("backends are %s and %s, want pure-go", blake2b.Backend(), blake2s.Backend())
+	}
+	if err := SetBackend("no such backend"); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
@@ -0,0 +1,79 @@
+package blake2
+
+import (
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// BenchmarkSizes are the message sizes measured by default by
+// MeasureBackends: a short message, a packet, a page and a large buffer.
+var BenchmarkSizes = []int{64, 1500, 8 << 10, 1 << 20}
+
+// Throughput is the speed of a backend at hashing messages of a size.
+type Throughput struct {
+	Backend string
+	// Size is the message length in bytes.
+	Size int
+	// BytesPerSecond is the number of message bytes hashed per second,
+	// each message by a hash that is Reset, written and summed.
+	BytesPerSecond float64
+}
+
+// MeasureBackends measures the throughput of each backend of Backends on
+// this machine for hashing messages of each of sizes, or of
+// BenchmarkSizes if nil, with the variant v, each for about d. The
+// results are ordered by backend then size. Operators can compare them
+// to pick a backend with SetBackend.
+//
+// It selects each backend in turn with SetBackend, then restores the
+// backend in use, so like SetBackend it must not be called concurrently
+// with the creation of hashes.
+func MeasureBackends(v Variant, sizes []int, d time.Duration) ([]Throughput, error) {
+	if sizes == nil {
+		sizes = BenchmarkSizes
+	}
+	defer blake2b.SetBackend(blake2b.Backend())
+	defer blake2s.SetBackend(blake2s.Backend())
+
+	var results []Throughput
+	for _, backend := range Backends() {
+		if err := SetBackend(backend); err != nil {
+			return nil, err
+		}
+		for _, size := range sizes {
+			h, err := NewHasher(v, nil)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, Throughput{
+				Backend:        backend,
+				Size:           size,
+				BytesPerSecond: measure(h, make([]byte, size), d),
+			})
+		}
+	}
+	return results, nil
+}
+
+// measure returns the bytes of msg hashed per second by h over about d.
+func measure(h Hasher, msg []byte, d time.Duration) float64 {
+	// Check the time once per megabyte or so, not for every short
+	// message.
+	batch := 1 + (1<<20)/(len(msg)+1)
+	var sum []byte
+	var n int64
+	start := time.Now()
+	for {
+		for i := 0; i < batch; i++ {
+			h.Reset()
+			h.Write(msg)
+			sum = h.Sum(sum[:0])
+		}
+		n += int64(batch) * int64(len(msg))
+		if elapsed := time.Since(start); elapsed >= d {
+			return float64(n) / elapsed.Seconds()
+		}
+	}
+}
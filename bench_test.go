@@ -0,0 +1,33 @@
+package blake2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestMeasureBackends(t *testing.T) {
+	SetBackend("pure-go")
+	defer SetBackend(blake2b.Backend())
+	sizes := []int{0, 100}
+	results, err := MeasureBackends(BLAKE2s, sizes, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends := Backends()
+	if len(results) != len(backends)*len(sizes) {
+		t.Fatalf("%d results, want %d", len(results), len(backends)*len(sizes))
+	}
+	for i, r := range results {
+		if r.Backend != backends[i/len(sizes)] || r.Size != sizes[i%len(sizes)] {
+			t.Errorf("result %d = %+v", i, r)
+		}
+		if r.Size > 0 && r.BytesPerSecond <= 0 {
+			t.Errorf("result %d = %+v, want a positive throughput", i, r)
+		}
+	}
+	if b := blake2b.Backend(); b != "pure-go" {
+		t.Errorf("backend %s after MeasureBackends, want pure-go", b)
+	}
+}
@@ -0,0 +1,161 @@
+// Package blake2 gives access to both BLAKE2 variants through a common
+// interface, for libraries that accept any BLAKE2 flavor. The variants
+// themselves are implemented by the blake2b and blake2s packages.
+package blake2
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// Variant is a BLAKE2 variant.
+type Variant int
+
+const (
+	// BLAKE2b is optimized for 64-bit platforms, with digests of up to
+	// 64 bytes.
+	BLAKE2b Variant = iota + 1
+	// BLAKE2s is optimized for 8- to 32-bit platforms, with digests of
+	// up to 32 bytes.
+	BLAKE2s
+)
+
+func (v Variant) String() string {
+	switch v {
+	case BLAKE2b:
+		return "BLAKE2b"
+	case BLAKE2s:
+		return "BLAKE2s"
+	}
+	return fmt.Sprintf("Variant(%d)", int(v))
+}
+
+// Hasher is a BLAKE2 hash of either variant.
+type Hasher interface {
+	hash.Hash
+	// Clone returns an independent copy of the hash, including the
+	// data written so far.
+	Clone() Hasher
+}
+
+// Config contains the parameters of a hash, as in the blake2b and
+// blake2s packages. Limits depend on the variant.
+type Config struct {
+	// Variant is the variant hashes are created for by NewMultiHasher;
+	// if 0, BLAKE2b. NewHasher takes the variant as an argument instead.
+	Variant Variant
+	// Digest byte length. If 0, the largest size of the variant is used.
+	Size uint8
+	// Key for keyed hashing. Can be nil.
+	Key []byte
+	// Salt, used to randomize the hash, of up to 16 bytes for BLAKE2b
+	// and 8 bytes for BLAKE2s. Can be nil.
+	Salt []byte
+	// Personal makes the hash function unique for each application, of
+	// up to 16 bytes for BLAKE2b and 8 bytes for BLAKE2s. Can be nil.
+	Personal []byte
+	// HashLongParams hashes a Salt or Personal longer than the field of
+	// the variant down to its size instead of rejecting it.
+	HashLongParams bool
+}
+
+// SetBLAKE2b sets the Variant of c to BLAKE2b and its Salt and Personal
+// to salt and personal, which have the widths of the BLAKE2b fields, so
+// that parameters meant for BLAKE2s, which are narrower, do not compile
+// instead of being zero-padded into digests other implementations do not
+// match.
+func (c *Config) SetBLAKE2b(salt [blake2b.SaltSize]byte, personal blake2b.Personalization) {
+	c.Variant = BLAKE2b
+	c.Salt = append([]byte(nil), salt[:]...)
+	c.Personal = append([]byte(nil), personal[:]...)
+}
+
+// SetBLAKE2s sets the Variant of c to BLAKE2s and its Salt and Personal
+// to salt and personal, which have the widths of the BLAKE2s fields, like
+// SetBLAKE2b.
+func (c *Config) SetBLAKE2s(salt [blake2s.SaltSize]byte, personal blake2s.Personalization) {
+	c.Variant = BLAKE2s
+	c.Salt = append([]byte(nil), salt[:]...)
+	c.Personal = append([]byte(nil), personal[:]...)
+}
+
+// ErrVariant is returned by NewHasher for unknown variants, and for
+// configs set up for another variant.
+var ErrVariant = errors.New("blake2: unknown variant")
+
+// NewHasher returns a new hash of the given variant, configured by cfg,
+// which may be nil. It returns an error if a parameter exceeds the limits
+// of the variant, and an error wrapping ErrVariant if cfg.Variant is set
+// to another variant.
+func NewHasher(variant Variant, cfg *Config) (Hasher, error) {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Variant != 0 && c.Variant != variant {
+		return nil, fmt.Errorf("%w: config is for %v, not %v", ErrVariant, c.Variant, variant)
+	}
+
+	switch variant {
+	case BLAKE2b:
+		bc := &blake2b.Config{Size: c.Size, Key: c.Key, Salt: c.Salt, Personal: c.Personal, HashLongParams: c.HashLongParams}
+		if err := bc.Validate(); err != nil {
+			return nil, err
+		}
+		return hasher{blake2b.New(bc)}, nil
+	case BLAKE2s:
+		sc := &blake2s.Config{Size: c.Size, Key: c.Key, Salt: c.Salt, Personal: c.Personal, HashLongParams: c.HashLongParams}
+		if err := sc.Validate(); err != nil {
+			return nil, err
+		}
+		return hasher{blake2s.New(sc)}, nil
+	}
+	return nil, ErrVariant
+}
+
+// MaxSize returns the largest digest size of v, in bytes, or 0 for
+// unknown variants.
+func (v Variant) MaxSize() int {
+	switch v {
+	case BLAKE2b:
+		return blake2b.MaxDigestSize
+	case BLAKE2s:
+		return blake2s.MaxDigestSize
+	}
+	return 0
+}
+
+// NewSize returns a new hash of variant v with n-byte digests, keyed with
+// key if it is not empty. Unlike a Config, whose Size 0 means the largest
+// size, it accepts only sizes from 1 to v.MaxSize, and returns the
+// ErrDigestSize of the variant's package otherwise.
+func (v Variant) NewSize(n int, key []byte) (Hasher, error) {
+	switch {
+	case v != BLAKE2b && v != BLAKE2s:
+		return nil, ErrVariant
+	case n >= 1 && n <= v.MaxSize():
+		return NewHasher(v, &Config{Size: uint8(n), Key: key})
+	case v == BLAKE2s:
+		return nil, blake2s.ErrDigestSize
+	}
+	return nil, blake2b.ErrDigestSize
+}
+
+// cloner is implemented by the digests of both variants.
+type cloner interface {
+	hash.Hash
+	Clone() hash.Hash
+}
+
+// hasher adapts a digest of either variant to Hasher.
+type hasher struct {
+	cloner
+}
+
+func (h hasher) Clone() Hasher {
+	return hasher{h.cloner.Clone().(cloner)}
+}
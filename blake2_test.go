@@ -0,0 +1,113 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestNewHasher(t *testing.T) {
+	cfg := &Config{Size: 32, Key: []byte("key"), Personal: []byte("personal")}
+	for _, c := range []struct {
+		variant Variant
+		want    []byte
+	}{
+		{BLAKE2b, blake2b.New(&blake2b.Config{Size: 32, Key: cfg.Key, Personal: cfg.Personal}).Sum(nil)},
+		{BLAKE2s, blake2s.New(&blake2s.Config{Size: 32, Key: cfg.Key, Personal: cfg.Personal}).Sum(nil)},
+	} {
+		h, err := NewHasher(c.variant, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, c.want) {
+			t.Errorf("%v: got %x, want %x", c.variant, got, c.want)
+		}
+
+		clone := h.Clone()
+		clone.Write([]byte("data"))
+		if !bytes.Equal(h.Sum(nil), c.want) {
+			t.Errorf("%v: writing to the clone changed the original", c.variant)
+		}
+	}
+}
+
+func TestNewHasherErrors(t *testing.T) {
+	if _, err := NewHasher(BLAKE2s, &Config{Size: 64}); err == nil {
+		t.Error("BLAKE2s accepted a 64-byte digest")
+	}
+	if _, err := NewHasher(BLAKE2s, &Config{Personal: make([]byte, 16)}); err == nil {
+		t.Error("BLAKE2s accepted a 16-byte personalization")
+	}
+	if _, err := NewHasher(BLAKE2s, &Config{Personal: make([]byte, 16), HashLongParams: true}); err != nil {
+		t.Errorf("BLAKE2s with HashLongParams: %v", err)
+	}
+	if _, err := NewHasher(Variant(0), nil); err != ErrVariant {
+		t.Errorf("unknown variant: got %v, want ErrVariant", err)
+	}
+	if h, err := NewHasher(BLAKE2b, nil); err != nil || h.Size() != 64 {
+		t.Errorf("default BLAKE2b: %v", err)
+	}
+}
+
+func TestSetVariantParams(t *testing.T) {
+	c := &Config{Size: 16}
+	c.SetBLAKE2s([blake2s.SaltSize]byte{'s'}, blake2s.PersonalFromString("app"))
+	h, err := NewHasher(BLAKE2s, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := blake2s.PersonalFromString("app")
+	want := blake2s.New(&blake2s.Config{Size: 16, Salt: []byte("s"), Personal: p[:]})
+	if !bytes.Equal(h.Sum(nil), want.Sum(nil)) {
+		t.Error("SetBLAKE2s digest differs from blake2s")
+	}
+	if _, err := NewHasher(BLAKE2b, c); !errors.Is(err, ErrVariant) {
+		t.Errorf("BLAKE2b hash of a BLAKE2s config: %v, want ErrVariant", err)
+	}
+
+	c.SetBLAKE2b([blake2b.SaltSize]byte{'s'}, blake2b.PersonalFromString("app"))
+	if h, err := NewHasher(BLAKE2b, c); err != nil || h.Size() != 16 || c.Variant != BLAKE2b {
+		t.Errorf("SetBLAKE2b: %v", err)
+	}
+}
+
+func TestNewSize(t *testing.T) {
+	key := []byte("secret")
+	for _, v := range []Variant{BLAKE2b, BLAKE2s} {
+		for _, n := range []int{1, 20, v.MaxSize()} {
+			h, err := v.NewSize(n, key)
+			if err != nil {
+				t.Fatalf("%v.NewSize(%d): %v", v, n, err)
+			}
+			want, _ := NewHasher(v, &Config{Size: uint8(n), Key: key})
+			h.Write([]byte("message"))
+			want.Write([]byte("message"))
+			if !bytes.Equal(h.Sum(nil), want.Sum(nil)) || h.Size() != n {
+				t.Errorf("%v.NewSize(%d) does not match NewHasher", v, n)
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		v    Variant
+		n    int
+		want error
+	}{
+		{BLAKE2b, 0, blake2b.ErrDigestSize},
+		{BLAKE2b, 65, blake2b.ErrDigestSize},
+		{BLAKE2b, 256 + 32, blake2b.ErrDigestSize},
+		{BLAKE2s, 33, blake2s.ErrDigestSize},
+		{BLAKE2s, -1, blake2s.ErrDigestSize},
+		{Variant(3), 32, ErrVariant},
+	} {
+		if _, err := c.v.NewSize(c.n, nil); err != c.want {
+			t.Errorf("%v.NewSize(%d): error %v, want %v", c.v, c.n, err, c.want)
+		}
+	}
+	if _, err := BLAKE2s.NewSize(32, make([]byte, 33)); err != blake2s.ErrKeySize {
+		t.Errorf("long key: error %v, want ErrKeySize", err)
+	}
+}
@@ -0,0 +1,86 @@
+package blake2b
+
+import (
+	"crypto/subtle"
+	"runtime"
+	"sync"
+)
+
+// MessageTag is a message and its keyed BLAKE2b tag.
+type MessageTag struct {
+	Message []byte
+	Tag     []byte
+}
+
+// macPools hold unkeyed digests by size, for VerifyBatch.
+var macPools [MaxDigestSize + 1]sync.Pool
+
+// VerifyBatch reports, for each pair, whether its tag is the size-byte
+// MAC of its message under key, as NewMAC(size, key) computes it. Tags
+// of any other length are invalid, so that callers cannot be made to
+// accept short, guessable tags. Tags are compared in constant time.
+// Digests are reused across pairs and calls, which makes verifying many
+// short messages, such as webhooks or queue messages, much cheaper than
+// creating a digest for each. Every tag is invalid for keys and sizes
+// that NewMAC rejects.
+func VerifyBatch(key []byte, size int, pairs []MessageTag) []bool {
+	ok := make([]bool, len(pairs))
+	verifyBatch(key, size, pairs, ok)
+	return ok
+}
+
+// VerifyBatchParallel is like VerifyBatch, but splits the pairs among up
+// to workers goroutines, or runtime.GOMAXPROCS(0) if workers is not
+// positive.
+func VerifyBatchParallel(key []byte, size int, pairs []MessageTag, workers int) []bool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(pairs) {
+		workers = len(pairs)
+	}
+	ok := make([]bool, len(pairs))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		lo, hi := i*len(pairs)/workers, (i+1)*len(pairs)/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verifyBatch(key, size, pairs[lo:hi], ok[lo:hi])
+		}()
+	}
+	wg.Wait()
+	return ok
+}
+
+func verifyBatch(key []byte, size int, pairs []MessageTag, ok []bool) {
+	if len(key) == 0 || len(key) > MaxKeySize || size < 1 || size > MaxDigestSize {
+		return
+	}
+	var d *Digest
+	var sum [MaxDigestSize]byte
+	for i, p := range pairs {
+		if len(p.Tag) != size {
+			continue
+		}
+		if d == nil {
+			d, _ = macPools[size].Get().(*Digest)
+			if d == nil {
+				d = New(&Config{Size: uint8(size)})
+			}
+			d.ResetWithKey(key)
+		} else {
+			d.Reset()
+		}
+		d.Write(p.Message)
+		ok[i] = subtle.ConstantTimeCompare(d.Sum(sum[:0]), p.Tag) == 1
+	}
+	if d != nil {
+		// Do not keep the key in pooled digests.
+		for i := range d.key {
+			d.key[i] = 0
+		}
+		d.ResetWithKey(nil)
+		macPools[size].Put(d)
+	}
+}
@@ -0,0 +1,98 @@
+package blake2b
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	key := []byte("webhook secret")
+	var pairs []MessageTag
+	var want []bool
+	for i := 0; i < 100; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+		d := NewMAC(32, key)
+		d.Write(msg)
+		tag := d.Sum(nil)
+		valid := i%4 != 0
+		if !valid {
+			tag[i%len(tag)] ^= 1
+		}
+		pairs = append(pairs, MessageTag{Message: msg, Tag: tag})
+		want = append(want, valid)
+	}
+	// A valid tag of another size is not a valid 32-byte tag.
+	short := NewMAC(16, key)
+	short.Write([]byte("x"))
+	pairs = append(pairs, MessageTag{Message: []byte("x")}, MessageTag{Message: []byte("x"), Tag: short.Sum(nil)}, MessageTag{Message: []byte("x"), Tag: make([]byte, 65)})
+	want = append(want, false, false, false)
+
+	for name, got := range map[string][]bool{
+		"VerifyBatch":            VerifyBatch(key, 32, pairs),
+		"VerifyBatchParallel":    VerifyBatchParallel(key, 32, pairs, 0),
+		"VerifyBatchParallel(7)": VerifyBatchParallel(key, 32, pairs, 7),
+	} {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: pair %d: %v, want %v", name, i, got[i], want[i])
+			}
+		}
+	}
+
+	// Pooled digests must not keep the previous key.
+	for i, ok := range VerifyBatch([]byte("other key"), 32, pairs) {
+		if ok {
+			t.Errorf("pair %d verified under another key", i)
+		}
+	}
+	for i, ok := range VerifyBatch(make([]byte, MaxKeySize+1), 32, pairs[:3]) {
+		if ok {
+			t.Errorf("pair %d verified under an oversized key", i)
+		}
+	}
+	for _, size := range []int{0, MaxDigestSize + 1} {
+		if VerifyBatch(key, size, []MessageTag{{Message: []byte("x"), Tag: make([]byte, size)}})[0] {
+			t.Errorf("tag of size %d verified", size)
+		}
+	}
+}
+
+func TestVerifyBatchForgery(t *testing.T) {
+	key := []byte("webhook secret")
+	msg := []byte(`{"event":"push"}`)
+
+	// Trying every 1-byte tag must not find one.
+	pairs := make([]MessageTag, 256)
+	for i := range pairs {
+		pairs[i] = MessageTag{Message: msg, Tag: []byte{byte(i)}}
+	}
+	for i, ok := range VerifyBatch(key, 32, pairs) {
+		if ok {
+			t.Errorf("1-byte tag %#x verified", i)
+		}
+	}
+
+	// Without a key, the unkeyed digest that anyone can compute must not
+	// verify.
+	sum := Sum256(msg)
+	for _, key := range [][]byte{nil, {}} {
+		if VerifyBatch(key, 32, []MessageTag{{Message: msg, Tag: sum[:]}})[0] {
+			t.Errorf("unkeyed digest verified under key %q", key)
+		}
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	key := []byte("webhook secret")
+	pairs := make([]MessageTag, 1000)
+	for i := range pairs {
+		msg := []byte(fmt.Sprintf(`{"event":"push","id":%d}`, i))
+		d := NewMAC(32, key)
+		d.Write(msg)
+		pairs[i] = MessageTag{Message: msg, Tag: d.Sum(nil)}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyBatch(key, 32, pairs)
+	}
+}
@@ -40,3 +40,27 @@ func BenchmarkSHA256(b *testing.B) {
 func BenchmarkSHA512(b *testing.B) {
 	benchmarkHash(b, sha512.New)
 }
+
+func BenchmarkSum64(b *testing.B) {
+	data := make([]byte, 64)
+	out := make([]byte, 0, 32)
+	d := New(&Config{Size: 32})
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		d.Reset()
+		d.Write(data)
+		d.Sum(out[:0])
+	}
+}
+
+func BenchmarkFinalize64(b *testing.B) {
+	data := make([]byte, 64)
+	out := make([]byte, 0, 32)
+	d := New(&Config{Size: 32})
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		d.Reset()
+		d.Write(data)
+		d.Finalize(out[:0])
+	}
+}
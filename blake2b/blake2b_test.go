@@ -587,33 +587,14 @@ func ExampleNew_short() {
 func ExampleNew_personalized() {
 	h := New(&Config{
 		Key:      []byte("sekrit"),
-		Salt:     []byte("random but public"),
+		Salt:     []byte("random & public"),
 		Personal: []byte("myAppName"),
 	})
 	h.Write([]byte("one two three"))
 	d := h.Sum(nil)
 	fmt.Printf("%X", d)
 	// Output:
-	// FE995AB57D24D0A0DB081BB8C1E1EB69F46A3CE5AC97FD463837C5FCAA18080688C7389449692F32D6FD53C6B7A475E52AFF5C3FBDCD253715C4F8D1333068C5
-}
-
-func ExampleNew_treehash() {
-	h := New(&Config{
-		Tree: &Tree{
-			Fanout:        64,
-			MaxDepth:      8,
-			LeafSize:      65536,
-			InnerHashSize: 32,
-			NodeDepth:     3,
-			NodeOffset:    23,
-			IsLastNode:    true,
-		},
-	})
-	h.Write([]byte("one two three"))
-	d := h.Sum(nil)
-	fmt.Printf("%X", d)
-	// Output:
-	// E86CF85D23FF3E33CCBC37F37B3A8EAE0FAE26E763FB5253F3D740DF823D47AB1273D6FFC53AD8FB15F3153F3E9F92974510975AE08ED311C68D3E4C0A3B21A6
+	// EFCD9C1BB4C9AECFA941015D29CA4041E2B33021D93E875044B5262C4F23C77AB42CA4F0323C52DFFFAA50DEE9446A2C6F0982FDEE43978612B895E23A3BCCEF
 }
 
 func ExampleNewBlake2B() {
@@ -658,3 +639,196 @@ func ExampleNewKeyedBlake2B() {
 	// Output:
 	// FC182724DC024B95F62E606859AC806E4EDCA09A927F6BC8BCCD07DADE3E4F26FC9D041661407527AADEF517A173E19BAB5C389217C29A08BE9731AEC83C02C3
 }
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStateAccessors(t *testing.T) {
+	h := New(&Config{Size: 32, Personal: []byte("personal")})
+	p := h.ParamBlock()
+	if p[0] != 32 || p[2] != 1 || p[3] != 1 || string(p[48:56]) != "personal" {
+		t.Errorf("bad parameter block: %X", p)
+	}
+	if cv, ok := h.ChainValue(); !ok || cv[0] != 0x6a09e667f3bcc908^0x01010020 {
+		t.Errorf("bad chaining value: %X", cv)
+	}
+	h.Write(make([]byte, 129))
+	if c, ok := h.Counter(); !ok || c != [2]uint64{128, 0} {
+		t.Errorf("bad counter: %v", c)
+	}
+	h.Finalize(nil)
+	if _, ok := h.ChainValue(); ok {
+		t.Error("chaining value exposed after Finalize")
+	}
+}
+
+func TestKeyedReset(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("one two three"))
+	want := h.Sum(nil)
+
+	h.Reset()
+	h.Write([]byte("one two three"))
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("after Reset: got %X, want %X", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		config *Config
+		err    error
+	}{
+		{nil, nil},
+		{&Config{Size: MaxDigestSize, Key: make([]byte, MaxKeySize), Salt: make([]byte, SaltSize), Personal: make([]byte, PersonalSize)}, nil},
+		{&Config{Size: MaxDigestSize + 1}, ErrDigestSize},
+		{&Config{Key: make([]byte, MaxKeySize+1)}, ErrKeySize},
+		{&Config{Salt: make([]byte, SaltSize+1)}, ErrSaltSize},
+		{&Config{Personal: make([]byte, PersonalSize+1)}, ErrPersonalSize},
+	} {
+		if err := c.config.Validate(); err != c.err {
+			t.Errorf("%+v: got %v, want %v", c.config, err, c.err)
+		}
+	}
+
+	defer func() {
+		if err := recover(); err != ErrSaltSize {
+			t.Errorf("New with a long salt panicked with %v, want ErrSaltSize", err)
+		}
+	}()
+	New(&Config{Salt: make([]byte, SaltSize+1)})
+}
+
+func TestLen(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write(make([]byte, 1000))
+	h.Write([]byte("abc"))
+	if n := h.Len(); n != 1003 {
+		t.Errorf("Len() = %d, want 1003", n)
+	}
+	if n := h.Clone().(*Digest).Len(); n != 1003 {
+		t.Errorf("clone: Len() = %d, want 1003", n)
+	}
+	h.Reset()
+	if n := h.Len(); n != 0 {
+		t.Errorf("after Reset: Len() = %d, want 0", n)
+	}
+}
+
+func TestLargeWrite(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	want := New(nil)
+	want.Write(data)
+
+	defer func(n int) { maxUpdate = n }(maxUpdate)
+	maxUpdate = 100
+	d := New(nil)
+	if n, err := d.Write(data); n != len(data) || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if !bytes.Equal(d.Sum(nil), want.Sum(nil)) || d.Len() != uint64(len(data)) {
+		t.Errorf("split write: %x, want %x", d.Sum(nil), want.Sum(nil))
+	}
+}
+
+// TestCounterCarry checks that the low word of the byte counter carries
+// into the high one in every backend, as for streams longer than the low
+// word can count, restoring a state instead of hashing such a stream.
+func TestCounterCarry(t *testing.T) {
+	param := New(nil).param
+	data := make([]byte, 3*BlockSize)
+	var want []byte
+	for name, b := range backends {
+		s := b.newState()
+		if err := s.init(&param, false); err != nil {
+			t.Fatal(err)
+		}
+		saved, ok := s.(savableState)
+		if ok {
+			_, ok = saved.pending()
+		}
+		if !ok {
+			continue
+		}
+		h, _ := saved.chainValue()
+		saved.restore(h, [2]uint64{^uint64(0) - BlockSize + 1, 0}, nil)
+		saved.update(data)
+		if c, _ := saved.counter(); c != [2]uint64{BlockSize, 1} {
+			t.Errorf("%s: counter %d", name, c)
+		}
+		sum := make([]byte, MaxDigestSize)
+		if err := saved.final(sum); err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want = sum
+		} else if !bytes.Equal(sum, want) {
+			t.Errorf("%s: digest %x, want %x", name, sum, want)
+		}
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	for _, cfg := range []*Config{nil, {Size: 20, Key: []byte("key")}} {
+		d := New(cfg)
+		d.Write([]byte("hello, world"))
+		want := d.Sum([]byte("prefix"))
+		if got := d.Finalize([]byte("prefix")); !bytes.Equal(got, want) {
+			t.Errorf("Finalize = %x, want %x", got, want)
+		}
+		if n, err := d.Write([]byte("more")); n != 0 || err != ErrFinalized {
+			t.Errorf("Write after Finalize = %d, %v, want ErrFinalized", n, err)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Sum after Finalize did not panic")
+				}
+			}()
+			d.Sum(nil)
+		}()
+
+		d.Reset()
+		d.Write([]byte("hello, world"))
+		if got := d.Finalize(nil); !bytes.Equal(got, want[len("prefix"):]) {
+			t.Errorf("Finalize after Reset = %x, want %x", got, want[len("prefix"):])
+		}
+	}
+}
+
+func TestFinal(t *testing.T) {
+	d := New(nil)
+	d.Write([]byte("hello, world"))
+	want := d.Sum(nil)
+	got, err := d.Final()
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("Final = %x, %v, want %x", got, err, want)
+	}
+	if _, err := d.Final(); err != ErrFinalized {
+		t.Errorf("second Final: %v, want ErrFinalized", err)
+	}
+	for name, write := range map[string]func() (int, error){
+		"Write":     func() (int, error) { return d.Write([]byte("more")) },
+		"WriteCopy": func() (int, error) { return d.WriteCopy([]byte("more")) },
+		"WriteV":    func() (int, error) { return d.WriteV([]byte("mo"), []byte("re")) },
+	} {
+		if n, err := write(); n != 0 || err != ErrFinalized {
+			t.Errorf("%s after Final = %d, %v, want ErrFinalized", name, n, err)
+		}
+	}
+	if d.Len() != 12 {
+		t.Errorf("Len after rejected writes = %d, want 12", d.Len())
+	}
+
+	d.Reset()
+	d.Write([]byte("hello, world"))
+	if got, err := d.Final(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("Final after Reset = %x, %v, want %x", got, err, want)
+	}
+}
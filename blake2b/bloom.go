@@ -0,0 +1,28 @@
+package blake2b
+
+import "encoding/binary"
+
+// HashK returns k indices in [0, m) for data, as Bloom and cuckoo
+// filters need, from a single 128-bit BLAKE2b digest. The digest is split
+// into two 64-bit values h1 and h2, and the i-th index is
+// (h1 + i*h2) mod m (Kirsch and Mitzenmacher's double hashing), with h2
+// forced odd so that the indices do not collapse when h2 is 0. It panics
+// if m is 0 or k is negative.
+func HashK(data []byte, k int, m uint64) []uint64 {
+	if m == 0 || k < 0 {
+		panic("blake2b: invalid HashK arguments")
+	}
+	var sum [16]byte
+	h := New(&Config{Size: uint8(len(sum))})
+	h.Write(data)
+	h.Sum(sum[:0])
+	h1 := binary.LittleEndian.Uint64(sum[:8])
+	h2 := binary.LittleEndian.Uint64(sum[8:]) | 1
+
+	indices := make([]uint64, k)
+	for i := range indices {
+		indices[i] = h1 % m
+		h1 += h2
+	}
+	return indices
+}
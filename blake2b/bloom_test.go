@@ -0,0 +1,45 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestHashK(t *testing.T) {
+	data := []byte("element")
+	d := New(&Config{Size: 16})
+	d.Write(data)
+	s := d.Sum(nil)
+	h1 := binary.LittleEndian.Uint64(s[:8])
+	h2 := binary.LittleEndian.Uint64(s[8:]) | 1
+
+	const m = 1000003
+	got := HashK(data, 5, m)
+	if len(got) != 5 {
+		t.Fatalf("HashK returned %d indices, want 5", len(got))
+	}
+	for i, v := range got {
+		if want := (h1 + uint64(i)*h2) % m; v != want {
+			t.Errorf("index %d = %d, want %d", i, v, want)
+		}
+	}
+	if len(HashK(data, 0, m)) != 0 {
+		t.Error("HashK with k = 0 returned indices")
+	}
+}
+
+func TestHashKSpread(t *testing.T) {
+	const m, n, k = 64, 6400, 3
+	var counts [m]int
+	for i := 0; i < n; i++ {
+		for _, v := range HashK([]byte(fmt.Sprint(i)), k, m) {
+			counts[v]++
+		}
+	}
+	for i, c := range counts {
+		if c < n*k/m/2 || c > n*k/m*2 {
+			t.Errorf("bucket %d got %d of %d indices", i, c, n*k)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !cgo
+// +build !cgo
+
+package blake2b
+
+// cgoEnabled reports whether the build uses cgo, for Features.
+const cgoEnabled = false
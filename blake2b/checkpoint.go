@@ -0,0 +1,53 @@
+package blake2b
+
+// CheckpointWriter is an io.Writer that hashes everything written to it
+// and records an intermediate digest each time another Interval bytes
+// have passed through. The checkpoints allow a partially transferred or
+// partially corrupted stream to be validated up to the last good
+// boundary.
+type CheckpointWriter struct {
+	d           *Digest
+	interval    int64
+	n           int64
+	checkpoints [][]byte
+}
+
+// NewCheckpointWriter returns a CheckpointWriter hashing with the given
+// config and recording a checkpoint every interval bytes.
+func NewCheckpointWriter(config *Config, interval int64) *CheckpointWriter {
+	if interval <= 0 {
+		panic("blake2b: checkpoint interval must be positive")
+	}
+	return &CheckpointWriter{d: New(config), interval: interval}
+}
+
+func (w *CheckpointWriter) Write(buf []byte) (int, error) {
+	n := len(buf)
+	for len(buf) > 0 {
+		k := w.interval - w.n
+		if int64(len(buf)) < k {
+			k = int64(len(buf))
+		}
+		w.d.Write(buf[:k])
+		w.n += k
+		buf = buf[k:]
+		if w.n == w.interval {
+			// Sum works on a copy of the state, so hashing continues
+			// undisturbed.
+			w.checkpoints = append(w.checkpoints, w.d.Sum(nil))
+			w.n = 0
+		}
+	}
+	return n, nil
+}
+
+// Checkpoints returns the intermediate digests recorded so far. The i-th
+// digest covers the first (i+1)*interval bytes of the stream.
+func (w *CheckpointWriter) Checkpoints() [][]byte {
+	return w.checkpoints
+}
+
+// Sum appends the digest of everything written so far to buf.
+func (w *CheckpointWriter) Sum(buf []byte) []byte {
+	return w.d.Sum(buf)
+}
@@ -0,0 +1,22 @@
+package blake2b
+
+// F is the BLAKE2b compression function, for building custom modes, such
+// as precompiles verifying other chains, and for computing test vectors.
+// It compresses the message block m, as 16 little-endian words, into the
+// chaining value h, with the byte counter t, low word first; f sets the
+// final block flag. The last node flag is left clear.
+func F(h *[8]uint64, m *[16]uint64, t [2]uint64, f bool) {
+	FRounds(12, h, m, t, f)
+}
+
+// FRounds is F computing the given number of rounds instead of 12,
+// rounds past the tenth reusing the message schedule from the first, as
+// the BLAKE2 precompile of EIP-152 does. With fewer than 12 rounds it is
+// not secure.
+func FRounds(rounds uint32, h *[8]uint64, m *[16]uint64, t [2]uint64, f bool) {
+	var f0 uint64
+	if f {
+		f0 = ^uint64(0)
+	}
+	compressWords(h, m, t[0], t[1], f0, 0, rounds)
+}
@@ -0,0 +1,53 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// eip152Input returns the chaining value and message of the EIP-152 test
+// vectors: an unkeyed 64-byte hash of "abc".
+func eip152Input() (h [8]uint64, m [16]uint64) {
+	h = iv
+	h[0] ^= 0x01010040
+	m[0] = 0x636261
+	return h, m
+}
+
+func encodeWords(h *[8]uint64) []byte {
+	out := make([]byte, 64)
+	for i, w := range h {
+		binary.LittleEndian.PutUint64(out[8*i:], w)
+	}
+	return out
+}
+
+func TestF(t *testing.T) {
+	h, m := eip152Input()
+	F(&h, &m, [2]uint64{3, 0}, true)
+	want := Sum512([]byte("abc"))
+	if got := encodeWords(&h); !bytes.Equal(got, want[:]) {
+		t.Errorf("F = %x, want %x", got, want)
+	}
+}
+
+func TestFRoundsEIP152(t *testing.T) {
+	for _, c := range []struct {
+		rounds uint32
+		f      bool
+		want   string
+	}{
+		{0, true, "08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b"},
+		{12, true, "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{12, false, "75ab69d3190a562c51aef8d88f1c2775876944407270c42c9844252c26d2875298743e7f6d5ea2f2d3e8d226039cd31b4e426ac4f2d3d666a610c2116fde4735"},
+		{1, true, "b63a380cb2897d521994a85234ee2c181b5f844d2c624c002677e9703449d2fba551b3a8333bcdf5f2f7e08993d53923de3d64fcc68c034e717b9293fed7a421"},
+	} {
+		h, m := eip152Input()
+		FRounds(c.rounds, &h, &m, [2]uint64{3, 0}, c.f)
+		if got := hex.EncodeToString(encodeWords(&h)); got != c.want {
+			t.Errorf("FRounds(%d, f=%v) = %s, want %s", c.rounds, c.f, got, c.want)
+		}
+	}
+}
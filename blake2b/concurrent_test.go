@@ -0,0 +1,34 @@
+package blake2b
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestConcurrent(t *testing.T) {
+	c := NewConcurrent(nil)
+	block := bytes.Repeat([]byte("x"), 1000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				c.Write(block)
+				c.Sum(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// All writes are identical, so their order does not matter.
+	want := New(nil)
+	for i := 0; i < 80; i++ {
+		want.Write(block)
+	}
+	if !bytes.Equal(c.Sum(nil), want.Sum(nil)) {
+		t.Error("concurrent writes were lost or corrupted")
+	}
+}
@@ -0,0 +1,59 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+)
+
+func TestCPointer(t *testing.T) {
+	defer SetBackend(Backend())
+	// Go memory stands in for C memory, which test files cannot
+	// allocate.
+	data := make([]byte, 3*BlockSize+5)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		for _, config := range []*Config{nil, {Size: 20, Key: []byte("key")}} {
+			want := New(config)
+			want.Write(data)
+			d := New(config)
+			d.WriteCPointer(nil, 0)
+			if n, err := d.WriteCPointer(unsafe.Pointer(&data[0]), len(data)); n != len(data) || err != nil {
+				t.Fatalf("%s: WriteCPointer = %d, %v", backend, n, err)
+			}
+			// A byte past the buffer checks that nothing is written
+			// beyond it.
+			out := make([]byte, MaxDigestSize+1)
+			n, err := d.SumIntoCPointer(unsafe.Pointer(&out[0]), MaxDigestSize)
+			if err != nil || n != d.Size() {
+				t.Fatalf("%s: SumIntoCPointer = %d, %v", backend, n, err)
+			}
+			if !bytes.Equal(out[:n], want.Sum(nil)) || d.Len() != want.Len() {
+				t.Errorf("%s, config %+v: WriteCPointer differs from Write", backend, config)
+			}
+			if out[n] != 0 {
+				t.Errorf("%s: SumIntoCPointer wrote past the digest", backend)
+			}
+		}
+	}
+
+	d := New(nil)
+	var b [MaxDigestSize]byte
+	for _, tt := range []struct {
+		ptr unsafe.Pointer
+		n   int
+	}{{nil, 1}, {unsafe.Pointer(&b[0]), -1}} {
+		if _, err := d.WriteCPointer(tt.ptr, tt.n); err != ErrCPointer {
+			t.Errorf("WriteCPointer(%v, %d) error = %v, want ErrCPointer", tt.ptr, tt.n, err)
+		}
+	}
+	if _, err := d.SumIntoCPointer(unsafe.Pointer(&b[0]), MaxDigestSize-1); err != ErrCPointer {
+		t.Errorf("SumIntoCPointer into a short buffer: error = %v, want ErrCPointer", err)
+	}
+	if _, err := d.SumIntoCPointer(nil, MaxDigestSize); err != ErrCPointer {
+		t.Errorf("SumIntoCPointer into nil: error = %v, want ErrCPointer", err)
+	}
+}
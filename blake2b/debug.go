@@ -0,0 +1,68 @@
+//go:build blake2_debug
+// +build blake2_debug
+
+package blake2b
+
+import (
+	"bytes"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// debugState tracks the use of a digest, to report misuses.
+type debugState struct {
+	busy   int32
+	summed bool
+	// borrowed is a copy of the C buffer being written, to detect
+	// changes.
+	borrowed []byte
+}
+
+func (s *debugState) report(err error) {
+	reportMisuse(&Misuse{Err: err, Stack: debug.Stack()})
+}
+
+// enter and leave bracket the methods of a digest, to detect concurrent
+// calls.
+func (s *debugState) enter() {
+	if atomic.AddInt32(&s.busy, 1) != 1 {
+		s.report(ErrConcurrentUse)
+	}
+}
+
+func (s *debugState) leave() {
+	atomic.AddInt32(&s.busy, -1)
+}
+
+// wrote records a write of n bytes.
+func (s *debugState) wrote(n int) {
+	if s.summed && n > 0 {
+		s.summed = false
+		s.report(ErrWriteAfterSum)
+	}
+}
+
+// sum records a Sum of d.
+func (s *debugState) sum(d *Digest) {
+	if d.written == 0 && len(d.key) > 0 {
+		s.report(ErrEmptyMAC)
+	}
+	s.summed = true
+}
+
+// borrow and giveBack bracket the write of a C buffer, to detect changes
+// to it meanwhile.
+func (s *debugState) borrow(b []byte) {
+	s.borrowed = append(s.borrowed[:0], b...)
+}
+
+func (s *debugState) giveBack(b []byte) {
+	if !bytes.Equal(s.borrowed, b) {
+		s.report(ErrCBufferChanged)
+	}
+	s.borrowed = s.borrowed[:0]
+}
+
+func (s *debugState) reset() {
+	s.summed = false
+}
@@ -0,0 +1,30 @@
+package blake2b
+
+// DeriveNode derives the KeySize-byte key at path in a hierarchy of keys
+// rooted at master, such as per-tenant or per-purpose keys, or the
+// accounts of a wallet. Each level is the keyed BLAKE2b digest of its
+// label, keyed with the parent key and personalized with
+// PersonalFromString of the label:
+//
+//	DeriveNode(master, "tenant-42", "backup")
+//
+// derives the "tenant-42" key from master, then the "backup" key from
+// it. Knowing a key gives its descendants but neither its parent nor its
+// siblings. With an empty path, DeriveNode returns a copy of master.
+// It panics if master is empty or longer than MaxKeySize.
+func DeriveNode(master []byte, path ...string) []byte {
+	if len(master) == 0 {
+		panic("blake2b: empty master key")
+	}
+	if len(master) > MaxKeySize {
+		panic(ErrKeySize)
+	}
+	key := append([]byte(nil), master...)
+	for _, label := range path {
+		p := PersonalFromString(label)
+		h := New(&Config{Size: KeySize, Key: key, Personal: p[:]})
+		h.Write([]byte(label))
+		key = h.Sum(key[:0])
+	}
+	return key
+}
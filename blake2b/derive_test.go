@@ -0,0 +1,46 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveNode(t *testing.T) {
+	master := []byte("master key of the key hierarchy")
+	child := DeriveNode(master, "tenant-42")
+	grandchild := DeriveNode(master, "tenant-42", "backup")
+	if len(child) != KeySize || len(grandchild) != KeySize {
+		t.Fatalf("key sizes %d and %d, want %d", len(child), len(grandchild), KeySize)
+	}
+	if !bytes.Equal(DeriveNode(child, "backup"), grandchild) {
+		t.Error("deriving from the child does not give the grandchild")
+	}
+
+	p := PersonalFromString("tenant-42")
+	h := New(&Config{Size: KeySize, Key: master, Personal: p[:]})
+	h.Write([]byte("tenant-42"))
+	if !bytes.Equal(child, h.Sum(nil)) {
+		t.Error("child is not the keyed digest of its label")
+	}
+
+	for _, path := range [][]string{
+		{"tenant-43"},
+		{"tenant-42", "backup", ""},
+		{"tenant-42backup"},
+		{"backup", "tenant-42"},
+	} {
+		if bytes.Equal(DeriveNode(master, path...), grandchild) {
+			t.Errorf("path %q gives the same key as tenant-42/backup", path)
+		}
+	}
+	if !bytes.Equal(DeriveNode(master), master) {
+		t.Error("empty path does not return master")
+	}
+
+	defer func() {
+		if recover() != ErrKeySize {
+			t.Error("long master key did not panic with ErrKeySize")
+		}
+	}()
+	DeriveNode(make([]byte, MaxKeySize+1), "x")
+}
@@ -0,0 +1,47 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ZcashPoW is the personalization prefix of Equihash in Zcash.
+const ZcashPoW = "ZcashPoW"
+
+// EquihashPersonal returns the personalization of the Equihash (n, k)
+// instance of a proof-of-work scheme: prefix, such as ZcashPoW, padded to
+// 8 bytes, followed by n and k as 4-byte little-endian integers. It
+// panics if prefix is longer than 8 bytes.
+func EquihashPersonal(prefix string, n, k uint32) Personalization {
+	if len(prefix) > 8 {
+		panic("blake2b: Equihash prefix longer than 8 bytes")
+	}
+	var p Personalization
+	copy(p[:8], prefix)
+	binary.LittleEndian.PutUint32(p[8:], n)
+	binary.LittleEndian.PutUint32(p[12:], k)
+	return p
+}
+
+// EquihashSize returns the digest size of the Equihash instance (n, k),
+// which holds as many n-bit hash outputs as fit in 512 bits: 50 bytes for
+// Zcash's (200, 9). It returns an error unless n is a multiple of 8, k
+// is at least 1 and n/(k+1) is at most 32 bits, as Equihash requires.
+func EquihashSize(n, k uint32) (int, error) {
+	if n == 0 || n%8 != 0 || n > 512 || k < 1 || n%(k+1) != 0 || n/(k+1) > 32 {
+		return 0, errors.New("blake2b: invalid Equihash parameters")
+	}
+	return int(512 / n * n / 8), nil
+}
+
+// NewEquihash returns a new hash for the Equihash (n, k) instance with
+// the personalization prefix, as used to generate and verify
+// solutions: the block header is written first, then each 4-byte
+// little-endian index divided by 512/n.
+func NewEquihash(prefix string, n, k uint32) (*Digest, error) {
+	size, err := EquihashSize(n, k)
+	if err != nil {
+		return nil, err
+	}
+	return NewPersonalized(&Config{Size: uint8(size)}, EquihashPersonal(prefix, n, k)), nil
+}
@@ -0,0 +1,39 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEquihash(t *testing.T) {
+	p := EquihashPersonal(ZcashPoW, 200, 9)
+	want := []byte("ZcashPoW\xc8\x00\x00\x00\x09\x00\x00\x00")
+	if !bytes.Equal(p[:], want) {
+		t.Errorf("personalization %q, want %q", p[:], want)
+	}
+
+	h, err := NewEquihash(ZcashPoW, 200, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Size() != 50 {
+		t.Errorf("Zcash digest size %d, want 50", h.Size())
+	}
+	ref := New(&Config{Size: 50, Personal: want})
+	h.Write([]byte("header"))
+	ref.Write([]byte("header"))
+	if !bytes.Equal(h.Sum(nil), ref.Sum(nil)) {
+		t.Error("NewEquihash digest differs")
+	}
+
+	for _, c := range []struct{ n, k uint32 }{{144, 5}, {96, 5}, {48, 5}} {
+		if _, err := EquihashSize(c.n, c.k); err != nil {
+			t.Errorf("(%d, %d): %v", c.n, c.k, err)
+		}
+	}
+	for _, c := range []struct{ n, k uint32 }{{0, 1}, {201, 9}, {200, 0}, {200, 6}, {512, 3}} {
+		if _, err := NewEquihash(ZcashPoW, c.n, c.k); err == nil {
+			t.Errorf("(%d, %d) accepted", c.n, c.k)
+		}
+	}
+}
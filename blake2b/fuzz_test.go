@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	d := New(&Config{Key: []byte("key"), Salt: []byte("salt")})
+	d.Write(bytes.Repeat([]byte("x"), 200))
+	b, _ := d.MarshalBinary()
+	f.Add(b)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d := new(Digest)
+		if d.UnmarshalBinary(data) != nil {
+			return
+		}
+		d.Write([]byte("more"))
+		d.Sum(nil)
+	})
+}
+
+func FuzzResumeFromCheckpoint(f *testing.F) {
+	d := New(nil)
+	d.Write([]byte("checkpointed"))
+	var buf bytes.Buffer
+	d.SaveCheckpoint(&buf)
+	f.Add(buf.Bytes())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if d, err := ResumeFromCheckpoint(bytes.NewReader(data)); err == nil {
+			d.Sum(nil)
+		}
+	})
+}
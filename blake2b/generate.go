@@ -0,0 +1,3 @@
+package blake2b
+
+//go:generate go run gen_sums.go -pkg blake2b -bits 160,224,256,384,512
@@ -0,0 +1,49 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenericBackend(t *testing.T) {
+	saved := defaultBackend
+	defer func() { defaultBackend = saved }()
+	defaultBackend = genericBackend{}
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenericMatchesDefault(t *testing.T) {
+	configs := append([]*Config{
+		nil,
+		{Size: 20, Salt: []byte("salt"), Personal: []byte("personal")},
+	}, treeConfigs...)
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, config := range configs {
+		for _, n := range []int{0, 1, 127, 128, 129, 256, 1000} {
+			want := New(config)
+			got := New(config)
+			got.state = genericBackend{}.newState()
+			got.Reset()
+			want.Write(data[:n])
+			got.Write(data[:n])
+			if !bytes.Equal(got.Sum(nil), want.Sum(nil)) {
+				t.Errorf("config %+v, %d bytes: digests differ", config, n)
+			}
+			wantCounter, ok := want.Counter()
+			if gotCounter, _ := got.Counter(); ok && gotCounter != wantCounter {
+				t.Errorf("config %+v, %d bytes: counter %v, want %v", config, n, gotCounter, wantCounter)
+			}
+		}
+	}
+}
+
+// exposesState reports whether the backend of d exposes its state.
+func exposesState(d *Digest) bool {
+	_, ok := d.Counter()
+	return ok
+}
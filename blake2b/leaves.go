@@ -0,0 +1,128 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+)
+
+// leafHasher lets a digest configured with Tree.HashLeaves hash the
+// leaves of its two-level tree itself. The last leaf seen is kept pending
+// until more data arrives, since only Sum knows that it is the last node.
+type leafHasher struct {
+	param   [64]byte
+	key     []byte
+	backend string
+	size    int
+	pending []byte
+	offset  uint32
+}
+
+func newLeafHasher(param *[64]byte, key []byte, backend string) *leafHasher {
+	l := &leafHasher{param: *param, key: key, backend: backend}
+	l.param[0] = param[17] // leaves output inner hashes
+	l.param[16] = 0        // at depth 0
+	l.size = int(binary.LittleEndian.Uint32(param[4:8]))
+	l.pending = make([]byte, 0, l.size)
+	return l
+}
+
+func (l *leafHasher) clone() *leafHasher {
+	c := *l
+	c.pending = append(make([]byte, 0, l.size), l.pending...)
+	return &c
+}
+
+func (l *leafHasher) reset() {
+	l.pending = l.pending[:0]
+	l.offset = 0
+}
+
+// write splits buf into leaves and absorbs the digests of all but the
+// last one into root. Runs of many complete leaves are hashed in
+// parallel.
+func (l *leafHasher) write(root state, buf []byte) {
+	if len(l.pending) > 0 || len(buf) <= l.size {
+		n := copy(l.pending[len(l.pending):l.size], buf)
+		l.pending = l.pending[:len(l.pending)+n]
+		buf = buf[n:]
+		if len(buf) == 0 {
+			return
+		}
+		l.absorb(root, l.pending, false)
+		l.pending = l.pending[:0]
+	}
+
+	// Keep at least one byte back for the pending leaf.
+	full := (len(buf) - 1) / l.size
+	l.absorbAll(root, buf[:full*l.size])
+	l.pending = append(l.pending, buf[full*l.size:]...)
+}
+
+// absorbAll absorbs the digests of the complete leaves in buf into root.
+func (l *leafHasher) absorbAll(root state, buf []byte) {
+	n := len(buf) / l.size
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	if workers < 2 {
+		for len(buf) > 0 {
+			l.absorb(root, buf[:l.size], false)
+			buf = buf[l.size:]
+		}
+		return
+	}
+
+	inner := int(l.param[0])
+	sums := make([]byte, n*inner)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				l.hashLeaf(root, sums[i*inner:(i+1)*inner], l.offset+uint32(i), buf[i*l.size:(i+1)*l.size], false)
+			}
+		}(w)
+	}
+	wg.Wait()
+	root.update(sums)
+	l.offset += uint32(n)
+}
+
+// absorb absorbs the digest of the next leaf, holding data, into root.
+func (l *leafHasher) absorb(root state, data []byte, last bool) {
+	sum := make([]byte, l.param[0])
+	l.hashLeaf(root, sum, l.offset, data, last)
+	root.update(sum)
+	l.offset++
+}
+
+// finish absorbs the pending leaf into root as the last one, without
+// changing l.
+func (l *leafHasher) finish(root state) {
+	sum := make([]byte, l.param[0])
+	l.hashLeaf(root, sum, l.offset, l.pending, true)
+	root.update(sum)
+}
+
+// hashLeaf writes the digest of the leaf at offset holding data to out.
+// The leaf is hashed with a copy of root, reinitialized, so that it uses
+// the implementation of the digest, and its number of rounds for
+// NewReducedRounds, rather than those of new digests.
+func (l *leafHasher) hashLeaf(root state, out []byte, offset uint32, data []byte, last bool) {
+	param := l.param
+	binary.LittleEndian.PutUint32(param[8:12], offset)
+	// Leaves are hashed within Write, whose failures cannot reach the
+	// digest, so backend failures panic with their BackendError.
+	d := &Digest{state: root.clone(), backend: l.backend, key: l.key, param: param, isLastNode: last}
+	d.Reset()
+	if d.err != nil {
+		panic(d.err)
+	}
+	d.absorb(data)
+	if err := d.state.final(out); err != nil {
+		panic(d.backendError(ErrFinalFailed, err))
+	}
+}
@@ -0,0 +1,42 @@
+package blake2b
+
+import "encoding/binary"
+
+// Long returns the size-byte digest of in computed by H', the
+// variable-length hash function of Argon2 (RFC 9106, section 3.3). Up to
+// MaxDigestSize bytes, it is the BLAKE2b digest of size, as 4
+// little-endian bytes, followed by in; longer digests chain 64-byte
+// BLAKE2b digests, taking the first 32 bytes of each. It panics if size
+// is not positive.
+func Long(size int, in []byte) []byte {
+	if size <= 0 || uint64(size) > 1<<32-1 {
+		panic("blake2b: invalid Long digest size")
+	}
+	var prefix [4]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(size))
+
+	if size <= MaxDigestSize {
+		d := New(&Config{Size: uint8(size)})
+		d.Write(prefix[:])
+		d.Write(in)
+		return d.Sum(nil)
+	}
+
+	out := make([]byte, 0, size)
+	d := New(nil)
+	d.Write(prefix[:])
+	d.Write(in)
+	v := d.Sum(nil)
+	for {
+		out = append(out, v[:32]...)
+		if size-len(out) <= MaxDigestSize {
+			break
+		}
+		d.Reset()
+		d.Write(v)
+		v = d.Sum(v[:0])
+	}
+	last := New(&Config{Size: uint8(size - len(out))})
+	last.Write(v)
+	return last.Sum(out)
+}
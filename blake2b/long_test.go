@@ -0,0 +1,25 @@
+package blake2b
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestLong(t *testing.T) {
+	for _, c := range []struct {
+		size int
+		want string
+	}{
+		{1, "92"},
+		{32, "085f91e757ffd9000d6dbbb972bb70ca99a9c04692aeaf5adbd06be0b2ea55b1"},
+		{65, "09a645c1744ef298cb054ed632aad71d9b8a5ef09c5533305fc5e759a9e6a332bd35390f81c0b2c3269901ad3e102481519439cf2638d102cd6a1f26ea3d336d4b"},
+		{100, "c4f582cf240a8f0bcc8d882346efc9b2081dad5d1b83526c48af77ce2102382d144fffb5e9327ba564207284915aef7dd26521eae4c5f1afb9da79191009f37c4fba4532b6b0e07623a043c047520a88c8c097416042d2e2fa2385346b8d11adfbf83231"},
+	} {
+		if got := hex.EncodeToString(Long(c.size, []byte("input"))); got != c.want {
+			t.Errorf("Long(%d) = %s, want %s", c.size, got, c.want)
+		}
+	}
+	if out := Long(1024, []byte("input")); len(out) != 1024 {
+		t.Errorf("Long(1024) returned %d bytes", len(out))
+	}
+}
@@ -0,0 +1,51 @@
+package blake2b
+
+import (
+	"crypto/subtle"
+	"hash"
+)
+
+// NewMAC returns a new MAC with size-byte tags, using the keyed mode of
+// BLAKE2b, as the New(size, key) constructors of other BLAKE2 libraries
+// do. It panics with ErrDigestSize unless 1 <= size <= MaxDigestSize,
+// with ErrKeySize if key is longer than MaxKeySize, and if key is empty,
+// which would give an unkeyed hash. KeySize bytes give full security.
+func NewMAC(size int, key []byte) hash.Hash {
+	if size < 1 || size > MaxDigestSize {
+		panic(ErrDigestSize)
+	}
+	if len(key) > MaxKeySize {
+		panic(ErrKeySize)
+	}
+	if len(key) == 0 {
+		panic("blake2b: empty MAC key")
+	}
+	return New(&Config{Size: uint8(size), Key: key})
+}
+
+// MAC128 returns the 16-byte MAC of message under key, as NewMAC(16, key)
+// computes it, for authenticating packets and other short messages that
+// have no room for larger tags. With the cgo backends it is computed in
+// a single call, and it does not allocate for message and key buffers
+// already on the heap. It panics for keys that NewMAC rejects.
+func MAC128(key, message []byte) [16]byte {
+	if len(key) == 0 {
+		panic("blake2b: empty MAC key")
+	}
+	var tag [16]byte
+	if err := sum(tag[:], message, key, nil); err != nil {
+		panic(err)
+	}
+	return tag
+}
+
+// VerifyMAC128 reports whether tag is the MAC128 of message under key,
+// comparing in constant time. Tags are invalid for keys that MAC128
+// rejects.
+func VerifyMAC128(key, message, tag []byte) bool {
+	if len(key) == 0 || len(key) > MaxKeySize || len(tag) != 16 {
+		return false
+	}
+	want := MAC128(key, message)
+	return subtle.ConstantTimeCompare(want[:], tag) == 1
+}
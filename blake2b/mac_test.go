@@ -0,0 +1,91 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewMAC(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	m := NewMAC(16, key)
+	m.Write([]byte("message"))
+	want := New(&Config{Size: 16, Key: key})
+	want.Write([]byte("message"))
+	if got := m.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("got %x, want %x", got, want.Sum(nil))
+	}
+
+	for _, c := range []struct {
+		size int
+		key  []byte
+		want interface{}
+	}{
+		{0, key, ErrDigestSize},
+		{MaxDigestSize + 1, key, ErrDigestSize},
+		{16, nil, "blake2b: empty MAC key"},
+		{16, make([]byte, MaxKeySize+1), ErrKeySize},
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err != c.want {
+					t.Errorf("NewMAC(%d, %d-byte key) panicked with %v, want %v", c.size, len(c.key), err, c.want)
+				}
+			}()
+			NewMAC(c.size, c.key)
+		}()
+	}
+}
+
+func TestMAC128(t *testing.T) {
+	defer SetBackend(Backend())
+	key := []byte("0123456789abcdef0123456789abcdef")
+	message := []byte("packet payload")
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		m := NewMAC(16, key)
+		m.Write(message)
+		want := m.Sum(nil)
+		tag := MAC128(key, message)
+		if !bytes.Equal(tag[:], want) {
+			t.Errorf("%s: MAC128 = %x, want %x", backend, tag, want)
+		}
+		if !VerifyMAC128(key, message, tag[:]) {
+			t.Errorf("%s: VerifyMAC128 rejected a valid tag", backend)
+		}
+		if backend != "cgo-ref" {
+			continue
+		}
+		if allocs := testing.AllocsPerRun(100, func() { MAC128(key, message) }); allocs != 0 {
+			t.Errorf("%s: MAC128 allocates %v times", backend, allocs)
+		}
+	}
+
+	tag := MAC128(key, message)
+	bad := tag
+	bad[15] ^= 1
+	for _, c := range []struct {
+		name              string
+		key, message, tag []byte
+	}{
+		{"other message", key, []byte("other"), tag[:]},
+		{"other tag", key, message, bad[:]},
+		{"short tag", key, message, tag[:8]},
+		{"other key", key[1:], message, tag[:]},
+		{"empty key", nil, message, tag[:]},
+		{"long key", make([]byte, MaxKeySize+1), message, tag[:]},
+	} {
+		if VerifyMAC128(c.key, c.message, c.tag) {
+			t.Errorf("%s: VerifyMAC128 accepted an invalid tag", c.name)
+		}
+	}
+}
+
+func BenchmarkMAC128(b *testing.B) {
+	key := make([]byte, KeySize)
+	packet := make([]byte, 64)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(packet)))
+	for i := 0; i < b.N; i++ {
+		MAC128(key, packet)
+	}
+}
@@ -0,0 +1,162 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/jadeydi/blake2/internal/wire"
+)
+
+const (
+	marshalMagic = "b2b\x01"
+	// marshalHead and marshalTail are the encoded sizes before and after
+	// the key, not counting the pending bytes.
+	marshalHead = len(marshalMagic) + 64 + 1 + 1
+	marshalTail = 8 + 8*8 + 2*8 + 2
+)
+
+var (
+	errMarshalState = errors.New("blake2b: digest state cannot be saved")
+	// ErrInvalidState is returned by UnmarshalBinary for encodings that
+	// are truncated or corrupted, or whose parameters New cannot have
+	// set, so that states read from untrusted storage cannot make the
+	// digest misbehave.
+	ErrInvalidState = errors.New("blake2b: invalid digest state")
+	// ErrVersion is returned by UnmarshalBinary and ResumeFromCheckpoint
+	// for encodings of a newer version of the package.
+	ErrVersion = errors.New("blake2b: unsupported encoding version")
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding holds
+// the parameters, the key and the running state of the digest, so that
+// UnmarshalBinary can resume hashing where d left off, in this or
+// another process. It contains the key: protect it as such.
+//
+// Digests hashing with Tree.HashLeaves, with reduced rounds, or with the
+// OpenSSL backend cannot be marshaled.
+func (d *Digest) MarshalBinary() ([]byte, error) {
+	s, ok := d.state.(savableState)
+	if !ok || d.leaves != nil {
+		return nil, errMarshalState
+	}
+	pending, ok := s.pending()
+	if !ok {
+		return nil, errMarshalState
+	}
+	b := make([]byte, 0, marshalHead+len(d.key)+marshalTail+len(pending))
+	b = append(b, marshalMagic...)
+	b = append(b, d.param[:]...)
+	if d.isLastNode {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = append(b, byte(len(d.key)))
+	b = append(b, d.key...)
+	b = appendUint64(b, d.written)
+	// States that report pending expose their chaining value and counter.
+	h, _ := s.chainValue()
+	t, _ := s.counter()
+	for _, v := range h {
+		b = appendUint64(b, v)
+	}
+	for _, v := range t {
+		b = appendUint64(b, v)
+	}
+	b = append(b, byte(len(pending)), byte(len(pending)>>8))
+	b = append(b, pending...)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a
+// state encoded by MarshalBinary.
+func (d *Digest) UnmarshalBinary(b []byte) error {
+	rest, version, ok := wire.Header(b, marshalMagic)
+	switch {
+	case !ok:
+		return ErrInvalidState
+	case version != marshalMagic[len(marshalMagic)-1]:
+		return ErrVersion
+	case len(b) < marshalHead+marshalTail:
+		return ErrInvalidState
+	}
+	b = rest
+	var param [64]byte
+	copy(param[:], b)
+	b = b[64:]
+	if !validParam(&param) {
+		return ErrInvalidState
+	}
+	isLastNode := b[0] != 0
+	keyLen := int(b[1])
+	b = b[2:]
+	if keyLen > MaxKeySize || keyLen != int(param[1]) || len(b) < keyLen+marshalTail {
+		return ErrInvalidState
+	}
+	key := b[:keyLen]
+	b = b[keyLen:]
+	written := binary.LittleEndian.Uint64(b)
+	b = b[8:]
+	var h [8]uint64
+	for i := range h {
+		h[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	b = b[8*8:]
+	t := [2]uint64{binary.LittleEndian.Uint64(b), binary.LittleEndian.Uint64(b[8:])}
+	b = b[2*8:]
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n != len(b) || n > 2*BlockSize {
+		return ErrInvalidState
+	}
+
+	s := defaultBackend.newState()
+	if err := s.init(&param, isLastNode); err != nil {
+		return err
+	}
+	saved, ok := s.(savableState)
+	if ok {
+		_, ok = saved.pending()
+	}
+	if !ok {
+		// The pure Go implementation can always be restored.
+		saved = genericBackend{}.newState().(savableState)
+		if err := saved.init(&param, isLastNode); err != nil {
+			return err
+		}
+	}
+	saved.restore(h, t, b)
+
+	d.state = saved
+	d.backend = defaultBackend.name()
+	if !ok {
+		d.backend = genericBackend{}.name()
+	}
+	d.param = param
+	d.isLastNode = isLastNode
+	d.key = append(d.key[:0], key...)
+	d.leaves = nil
+	d.written = written
+	return nil
+}
+
+// validParam reports whether param is a parameter block New can have
+// encoded: digest and inner hash sizes and key length within limits, and
+// reserved bytes zero.
+func validParam(p *[64]byte) bool {
+	if p[0] == 0 || p[0] > MaxDigestSize || p[1] > MaxKeySize || p[17] > MaxDigestSize {
+		return false
+	}
+	for _, c := range p[18:32] {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
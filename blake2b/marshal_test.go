@@ -0,0 +1,103 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	configs := append([]*Config{
+		nil,
+		{Key: []byte("key")},
+		{Size: 20, Salt: []byte("salt"), Personal: []byte("personal")},
+	}, treeConfigs...)
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, config := range configs {
+		for _, n := range []int{0, 1, 128, 129, 1000} {
+			h := New(config)
+			h.Write(data[:n])
+			b, err := h.MarshalBinary()
+			if err == errMarshalState && !exposesState(h) {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("config %+v, %d bytes: %v", config, n, err)
+			}
+			r := new(Digest)
+			if err := r.UnmarshalBinary(b); err != nil {
+				t.Fatalf("config %+v, %d bytes: %v", config, n, err)
+			}
+			if r.Len() != h.Len() {
+				t.Errorf("config %+v, %d bytes: Len %d, want %d", config, n, r.Len(), h.Len())
+			}
+			h.Write(data)
+			r.Write(data)
+			if !bytes.Equal(r.Sum(nil), h.Sum(nil)) {
+				t.Errorf("config %+v, %d bytes: restored digest differs", config, n)
+			}
+			h.Reset()
+			r.Reset()
+			if !bytes.Equal(r.Sum(nil), h.Sum(nil)) {
+				t.Errorf("config %+v, %d bytes: restored digest differs after Reset", config, n)
+			}
+		}
+	}
+}
+
+func TestMarshalBinaryGeneric(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("hello"))
+	g := New(&Config{Key: []byte("key")})
+	g.state = genericBackend{}.newState()
+	g.Reset()
+	g.Write([]byte("hello"))
+
+	b, err := h.MarshalBinary()
+	if err == errMarshalState {
+		t.Skip("backend cannot save its state")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	gb, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, gb) {
+		t.Errorf("encoding %X, want %X", gb, b)
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("hello"))
+	b, err := h.MarshalBinary()
+	if err != nil {
+		t.Skip(err)
+	}
+	for i, bad := range [][]byte{
+		nil,
+		b[:len(b)-1],
+		append(append([]byte(nil), b...), 0),
+		append([]byte("b2s\x01"), b[4:]...),
+	} {
+		if err := new(Digest).UnmarshalBinary(bad); err != ErrInvalidState {
+			t.Errorf("%d: UnmarshalBinary returned %v, want %v", i, err, ErrInvalidState)
+		}
+	}
+
+	// A digest size of 0 would make Sum fail in the backend.
+	noSize := append([]byte(nil), b...)
+	noSize[4] = 0
+	if err := new(Digest).UnmarshalBinary(noSize); err != ErrInvalidState {
+		t.Errorf("digest size 0: UnmarshalBinary returned %v, want %v", err, ErrInvalidState)
+	}
+	newer := append([]byte(nil), b...)
+	newer[3] = 2
+	if err := new(Digest).UnmarshalBinary(newer); err != ErrVersion {
+		t.Errorf("newer version: UnmarshalBinary returned %v, want %v", err, ErrVersion)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build blake2_notree
+// +build blake2_notree
+
+package blake2b
+
+// The blake2_notree build tag leaves out tree hashing, for small targets,
+// such as TinyGo firmware, that only hash sequentially. Config.Validate
+// then rejects configs with tree parameters with ErrTreeDisabled.
+const treeHashing = false
+
+func (p *Params) setTree(t *Tree) {
+	panic(ErrTreeDisabled)
+}
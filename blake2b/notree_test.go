@@ -0,0 +1,24 @@
+//go:build blake2_notree
+// +build blake2_notree
+
+package blake2b
+
+import "testing"
+
+var treeConfigs []*Config
+
+func TestTreeDisabled(t *testing.T) {
+	config := &Config{Tree: &Tree{Fanout: 2, MaxDepth: 2}}
+	if err := config.Validate(); err != ErrTreeDisabled {
+		t.Errorf("Validate: got %v, want ErrTreeDisabled", err)
+	}
+	if _, err := NewParams(config); err != ErrTreeDisabled {
+		t.Errorf("NewParams: got %v, want ErrTreeDisabled", err)
+	}
+	defer func() {
+		if r := recover(); r != ErrTreeDisabled {
+			t.Errorf("New: panic %v, want ErrTreeDisabled", r)
+		}
+	}()
+	New(config)
+}
@@ -0,0 +1,28 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	d := New(nil)
+	if d.KeySize() != 0 || !bytes.Equal(d.Salt(), make([]byte, SaltSize)) ||
+		!bytes.Equal(d.Personal(), make([]byte, PersonalSize)) || d.Tree() != nil {
+		t.Errorf("default digest: key %d, salt %x, personal %x, tree %+v", d.KeySize(), d.Salt(), d.Personal(), d.Tree())
+	}
+
+	long := bytes.Repeat([]byte("p"), 40)
+	d = New(&Config{Size: 20, Key: []byte("key"), Salt: []byte("salt"), Personal: long, HashLongParams: true})
+	salt := append([]byte("salt"), make([]byte, SaltSize-4)...)
+	if d.Size() != 20 || d.KeySize() != 3 || !bytes.Equal(d.Salt(), salt) {
+		t.Errorf("size %d, key %d, salt %x", d.Size(), d.KeySize(), d.Salt())
+	}
+	if want := PersonalFromString(string(long)); !bytes.Equal(d.Personal(), want[:]) {
+		t.Errorf("personal %x, want the digest of the long personalization %x", d.Personal(), want)
+	}
+	d.Salt()[0] = 0
+	if d.Salt()[0] != 's' {
+		t.Error("Salt does not return a copy")
+	}
+}
@@ -0,0 +1,74 @@
+package blake2b
+
+// Personalization is a value for the personalization parameter, which
+// separates the hashes of different applications.
+type Personalization [PersonalSize]byte
+
+// SetSalt sets c.Salt to salt. Unlike assigning a slice, which is
+// zero-padded to SaltSize bytes, it takes exactly the 16 bytes of the BLAKE2b
+// salt field, so that a BLAKE2s salt, of 8 bytes, is a compile-time error
+// rather than a source of digests other implementations do not match.
+func (c *Config) SetSalt(salt [SaltSize]byte) {
+	c.Salt = append([]byte(nil), salt[:]...)
+}
+
+// SetPersonal sets c.Personal to p, of exactly PersonalSize bytes, like
+// SetSalt.
+func (c *Config) SetPersonal(p Personalization) {
+	c.Personal = append([]byte(nil), p[:]...)
+}
+
+// PersonalFromString derives a Personalization from an application label
+// of any length, as the BLAKE2b digest of the label truncated to PersonalSize
+// bytes, rather than truncating long labels themselves. Different labels
+// thus give different personalizations, with overwhelming probability.
+func PersonalFromString(s string) Personalization {
+	var p Personalization
+	h := New(&Config{Size: PersonalSize})
+	h.Write([]byte(s))
+	h.Sum(p[:0])
+	return p
+}
+
+// fitParam returns p if it fits in a parameter field of size bytes, and
+// otherwise its size-byte BLAKE2b digest, for Config.HashLongParams.
+func fitParam(p []byte, size int) []byte {
+	if len(p) <= size {
+		return p
+	}
+	h := New(&Config{Size: uint8(size)})
+	h.Write(p)
+	return h.Sum(nil)
+}
+
+// NewPersonalized returns a new hash configured by config, which may be
+// nil, personalized with p instead of config.Personal.
+func NewPersonalized(config *Config, p Personalization) *Digest {
+	c := new(Config)
+	if config != nil {
+		*c = *config
+	}
+	c.Personal = p[:]
+	return New(c)
+}
+
+// NewWithDomain returns a new hash configured by cfg, which may be nil,
+// made independent of the hashes of every other domain, like the context
+// strings of BLAKE3. The domain string, of any length, is hashed with
+// BLAKE2b into the salt and personalization parameters, replacing
+// cfg.Salt and cfg.Personal; use a fixed, descriptive string such as
+// "example.com 2024-01 session tokens".
+func NewWithDomain(domain string, cfg *Config) *Digest {
+	var sep [SaltSize + PersonalSize]byte
+	h := New(&Config{Size: uint8(len(sep)), Personal: []byte("domain")})
+	h.Write([]byte(domain))
+	h.Sum(sep[:0])
+
+	c := new(Config)
+	if cfg != nil {
+		*c = *cfg
+	}
+	c.Salt = sep[:SaltSize]
+	c.Personal = sep[SaltSize:]
+	return New(c)
+}
@@ -0,0 +1,90 @@
+package blake2b
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPersonalFromString(t *testing.T) {
+	long := strings.Repeat("application label ", 4)
+	a := PersonalFromString(long + "a")
+	b := PersonalFromString(long + "b")
+	if a == b {
+		t.Error("labels with a common prefix give the same personalization")
+	}
+	if PersonalFromString("label") != PersonalFromString("label") {
+		t.Error("PersonalFromString is not deterministic")
+	}
+
+	h := NewPersonalized(&Config{Size: 32}, a)
+	want := New(&Config{Size: 32, Personal: a[:]})
+	if !bytes.Equal(h.Sum(nil), want.Sum(nil)) {
+		t.Error("NewPersonalized does not use the personalization")
+	}
+}
+
+func TestNewWithDomain(t *testing.T) {
+	sum := func(domain string) []byte {
+		h := NewWithDomain(domain, &Config{Size: 32, Salt: []byte("ignored")})
+		h.Write([]byte("message"))
+		return h.Sum(nil)
+	}
+	a := sum("example.com session tokens")
+	if !bytes.Equal(a, sum("example.com session tokens")) {
+		t.Error("NewWithDomain is not deterministic")
+	}
+	if bytes.Equal(a, sum("example.com session tokens ")) {
+		t.Error("different domains give the same hash")
+	}
+	if bytes.Equal(sum(strings.Repeat("x", 100)+"a"), sum(strings.Repeat("x", 100)+"b")) {
+		t.Error("long domains with a common prefix give the same hash")
+	}
+	if n := len(NewWithDomain("", nil).Sum(nil)); n != MaxDigestSize {
+		t.Errorf("nil config gives %d-byte digests, want %d", n, MaxDigestSize)
+	}
+}
+
+func TestHashLongParams(t *testing.T) {
+	label := []byte("com.example.billing.invoices.v2 long application label")
+	long := &Config{Size: 32, Personal: label, Salt: label, HashLongParams: true}
+	if err := long.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := (&Config{Personal: label}).Validate(); err != ErrPersonalSize {
+		t.Errorf("Validate without HashLongParams: %v, want ErrPersonalSize", err)
+	}
+
+	p := PersonalFromString(string(label))
+	want := New(&Config{Size: 32, Personal: p[:], Salt: p[:SaltSize]})
+	if got := New(long); !bytes.Equal(got.Sum(nil), want.Sum(nil)) {
+		t.Error("long parameters are not hashed as PersonalFromString")
+	}
+
+	other := *long
+	other.Personal = append(label[:len(label):len(label)], '2')
+	if bytes.Equal(New(&other).Sum(nil), New(long).Sum(nil)) {
+		t.Error("labels with a common prefix give the same hash")
+	}
+
+	short := []byte("app")
+	a := New(&Config{Personal: short, HashLongParams: true})
+	b := New(&Config{Personal: short})
+	if !bytes.Equal(a.Sum(nil), b.Sum(nil)) {
+		t.Error("HashLongParams changes parameters that fit")
+	}
+}
+
+func TestSetSaltPersonal(t *testing.T) {
+	var salt [SaltSize]byte
+	copy(salt[:], "salt")
+	p := PersonalFromString("app")
+	c := new(Config)
+	c.SetSalt(salt)
+	c.SetPersonal(p)
+	salt[0] = 'X'
+	want := New(&Config{Salt: []byte("salt"), Personal: p[:]})
+	if !bytes.Equal(New(c).Sum(nil), want.Sum(nil)) || len(c.Salt) != SaltSize || len(c.Personal) != PersonalSize {
+		t.Errorf("config %+v", c)
+	}
+}
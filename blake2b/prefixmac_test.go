@@ -0,0 +1,36 @@
+package blake2b
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+)
+
+func TestPrefixMAC(t *testing.T) {
+	want := New(&Config{Size: 16})
+	want.Write([]byte("keymessage"))
+
+	m, err := NewPrefixMAC([]byte("key"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		m.Write([]byte("message"))
+		if got := m.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+			t.Errorf("run %d: got %x, want %x", i, got, want.Sum(nil))
+		}
+		m.Reset()
+	}
+
+	// Only the methods of hash.Hash are exposed, which keep the key.
+	if _, ok := m.(interface{ ResetWithKey([]byte) error }); ok {
+		t.Error("prefix MAC exposes ResetWithKey")
+	}
+	if _, ok := m.(interface{ Clone() hash.Hash }); ok {
+		t.Error("prefix MAC exposes Clone")
+	}
+
+	if _, err := NewPrefixMAC(nil, 65); err != ErrDigestSize {
+		t.Errorf("size 65: got %v, want ErrDigestSize", err)
+	}
+}
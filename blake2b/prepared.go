@@ -0,0 +1,71 @@
+package blake2b
+
+// Params is a validated Config with its parameter block encoded, for
+// servers creating many digests of the same configuration: Params.New
+// skips the validation and encoding New does on every call. A Params is
+// immutable, so it can be shared by any number of goroutines, each
+// creating digests of its own; the digests themselves are not safe for
+// concurrent use.
+type Params struct {
+	param      [64]byte
+	key        []byte
+	isLastNode bool
+	hashLeaves bool
+}
+
+// NewParams validates config and encodes its parameters. If config is
+// nil, uses a 64-byte digest size. It returns the error of
+// config.Validate if config is invalid. The key is copied, so that
+// config can be changed or reused afterwards.
+func NewParams(config *Config) (*Params, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	p := new(Params)
+	p.init(config)
+	p.key = append([]byte(nil), p.key...)
+	return p, nil
+}
+
+// init encodes config, which must be valid, into p. p.key refers to the
+// key of config.
+func (p *Params) init(config *Config) {
+	p.param[0] = 64 // digest length
+	p.param[2] = 1  // fanout
+	p.param[3] = 1  // depth
+	if config == nil {
+		return
+	}
+	if config.Size != 0 {
+		p.param[0] = config.Size
+	}
+	if len(config.Key) > 0 {
+		p.param[1] = uint8(len(config.Key))
+		p.key = config.Key
+	}
+	copy(p.param[32:48], fitParam(config.Salt, SaltSize))
+	copy(p.param[48:64], fitParam(config.Personal, PersonalSize))
+
+	if config.Tree != nil {
+		p.setTree(config.Tree)
+	}
+}
+
+// New returns a new digest with the parameters of p, like New with the
+// config p was made from.
+func (p *Params) New() *Digest {
+	d := &Digest{state: defaultBackend.newState(), backend: defaultBackend.name(), param: p.param, isLastNode: p.isLastNode}
+	if len(p.key) > 0 {
+		d.key = append([]byte(nil), p.key...)
+	}
+	if p.hashLeaves {
+		d.leaves = newLeafHasher(&d.param, d.key, d.backend)
+	}
+	d.Reset()
+	return d
+}
+
+// Size returns the digest size of the digests of p.
+func (p *Params) Size() int {
+	return int(p.param[0])
+}
@@ -0,0 +1,64 @@
+package blake2b
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestPreparedParams(t *testing.T) {
+	key := []byte("key")
+	config := &Config{Size: 32, Key: key, Salt: []byte("salt"), Personal: []byte("app")}
+	p, err := NewParams(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := New(config)
+	want.Write([]byte("message"))
+	key[0] = 'K'
+	if p.Size() != 32 {
+		t.Errorf("Size = %d", p.Size())
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				d := p.New()
+				d.Write([]byte("message"))
+				if !bytes.Equal(d.Sum(nil), want.Sum(nil)) {
+					t.Error("Params.New digest differs from New")
+					return
+				}
+				d.ResetWithKey([]byte("other"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := NewParams(&Config{Size: 65}); err != ErrDigestSize {
+		t.Errorf("NewParams of an invalid config: %v, want ErrDigestSize", err)
+	}
+	if p, err := NewParams(nil); err != nil || p.Size() != 64 {
+		t.Errorf("NewParams(nil) = %v, %v", p, err)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	config := &Config{Size: 32, Key: []byte("key"), Personal: []byte("app")}
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			New(config)
+		}
+	})
+	p, _ := NewParams(config)
+	b.Run("Params", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.New()
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSumRecords(t *testing.T) {
+	const stride, count = 24, 200
+	data := make([]byte, stride*count)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	defer SetBackend(Backend())
+	for _, config := range []*Config{
+		nil,
+		{Size: 16, Key: []byte("index key"), Salt: []byte("salt"), Personal: []byte("records")},
+	} {
+		for _, backend := range Backends() {
+			SetBackend(backend)
+			size := New(config).Size()
+			out := make([]byte, count*size)
+			if err := SumRecords(out, data, stride, config); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < count; i++ {
+				d := New(config)
+				d.Write(data[i*stride : (i+1)*stride])
+				if want := d.Sum(nil); !bytes.Equal(out[i*size:(i+1)*size], want) {
+					t.Fatalf("%s: record %d: %x, want %x", backend, i, out[i*size:(i+1)*size], want)
+				}
+			}
+		}
+	}
+
+	// Small maxUpdate values split the records into several calls.
+	defer func(n int) { maxUpdate = n }(maxUpdate)
+	maxUpdate = 100
+	out := make([]byte, count*MaxDigestSize)
+	if err := SumRecords(out, data, stride, nil); err != nil {
+		t.Fatal(err)
+	}
+	d := New(nil)
+	d.Write(data[len(data)-stride:])
+	if !bytes.Equal(out[len(out)-MaxDigestSize:], d.Sum(nil)) {
+		t.Error("last record of split calls differs")
+	}
+
+	if err := SumRecords(out, data[1:], stride, nil); err != ErrStride {
+		t.Errorf("partial record: %v, want ErrStride", err)
+	}
+	if err := SumRecords(out[:10], data, stride, nil); err != ErrStride {
+		t.Errorf("short output: %v, want ErrStride", err)
+	}
+	if err := SumRecords(nil, nil, stride, nil); err != nil {
+		t.Errorf("no records: %v", err)
+	}
+}
+
+func BenchmarkSumRecords(b *testing.B) {
+	const stride, count = 32, 1 << 12
+	data := make([]byte, stride*count)
+	out := make([]byte, count*32)
+	config := &Config{Size: 32}
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		SumRecords(out, data, stride, config)
+	}
+}
+
+func BenchmarkSumRecordsDigests(b *testing.B) {
+	const stride, count = 32, 1 << 12
+	data := make([]byte, stride*count)
+	out := make([]byte, 0, 32)
+	config := &Config{Size: 32}
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < count; j++ {
+			d := New(config)
+			d.Write(data[j*stride : (j+1)*stride])
+			d.Sum(out[:0])
+		}
+	}
+}
@@ -0,0 +1,187 @@
+//go:build cgo && !windows && !purego && !tinygo && (amd64 || 386)
+// +build cgo
+// +build !windows
+// +build !purego
+// +build !tinygo
+// +build amd64 386
+
+package blake2b
+
+import (
+	// #cgo CFLAGS: -O3
+	// #cgo system_libb2 CFLAGS: -DBLAKE2_SYSTEM_LIBB2
+	// #cgo system_libb2 pkg-config: libb2
+	// #include <stdlib.h>
+	// #include "blake2-go.h"
+	"C"
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// defaultBackend is the backend used by new digests.
+var defaultBackend backend = refBackend{}
+
+// refBackend is the backend calling the bundled C implementation, or the
+// system libb2 when built with the system_libb2 tag.
+type refBackend struct{}
+
+func init() {
+	backends[refBackend{}.name()] = refBackend{}
+	useEnvBackend()
+}
+
+func (refBackend) name() string { return "cgo-ref" }
+
+// simd returns the bit sets of the x86 extensions the C sources were
+// compiled for, -1 for the system libb2, and of those the CPU supports.
+func (refBackend) simd() (compiled, cpu int) {
+	return int(C.go_simd_compiled()), int(C.go_simd_cpu())
+}
+
+func (refBackend) newState() state {
+	return newRefState()
+}
+
+func (refBackend) sumOnce(out []byte, param *[64]byte, key, in []byte) error {
+	var k, b unsafe.Pointer
+	if len(key) > 0 {
+		k = unsafe.Pointer(&key[0])
+	}
+	if len(in) > 0 {
+		b = unsafe.Pointer(&in[0])
+	}
+	if C.go_blake2b_sum_param((*C.uint8_t)(&out[0]), C.size_t(len(out)), (*C.uint8_t)(&param[0]),
+		(*C.uint8_t)(k), C.size_t(len(key)), b, C.size_t(len(in))) < 0 {
+		return errors.New("blake2b: invalid parameters")
+	}
+	return nil
+}
+
+// refState keeps the C state in C memory, so that C code never holds a
+// pointer into the Go heap; it is freed when the refState is collected.
+// Every method using s keeps r alive until the C call returns.
+type refState struct {
+	s *C.blake2b_state
+}
+
+func newRefState() *refState {
+	r := &refState{s: (*C.blake2b_state)(C.calloc(1, C.sizeof_blake2b_state))}
+	if r.s == nil {
+		panic("blake2b: unable to allocate state")
+	}
+	runtime.SetFinalizer(r, (*refState).free)
+	return r
+}
+
+func (r *refState) free() {
+	C.free(unsafe.Pointer(r.s))
+	r.s = nil
+}
+
+func (r *refState) init(param *[64]byte, lastNode bool) error {
+	defer runtime.KeepAlive(r)
+	if C.go_blake2b_init_param(r.s, (*C.uint8_t)(unsafe.Pointer(&param[0]))) < 0 {
+		return errors.New("blake2b: invalid parameters")
+	}
+	if lastNode {
+		r.s.last_node = C.uint8_t(1)
+	}
+	return nil
+}
+
+func (r *refState) update(buf []byte) {
+	C.go_blake2b_update(r.s, unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+	runtime.KeepAlive(r)
+}
+
+func (r *refState) updateV(bufs [4][]byte) {
+	defer runtime.KeepAlive(r)
+	var in [4]unsafe.Pointer
+	for i, buf := range bufs {
+		if len(buf) > 0 {
+			in[i] = unsafe.Pointer(&buf[0])
+		}
+	}
+	C.go_blake2b_update4(r.s, in[0], C.size_t(len(bufs[0])), in[1], C.size_t(len(bufs[1])),
+		in[2], C.size_t(len(bufs[2])), in[3], C.size_t(len(bufs[3])))
+}
+
+func (r *refState) final(out []byte) error {
+	defer runtime.KeepAlive(r)
+	// go_blake2b_final_copy finalizes a copy of the state so that the
+	// caller can keep writing.
+	if C.go_blake2b_final_copy(r.s, unsafe.Pointer(&out[0]), C.size_t(len(out))) < 0 {
+		return errors.New("blake2b: invalid output length")
+	}
+	return nil
+}
+
+func (r *refState) finalize(out []byte) error {
+	defer runtime.KeepAlive(r)
+	if C.go_blake2b_final(r.s, unsafe.Pointer(&out[0]), C.size_t(len(out))) < 0 {
+		return errors.New("blake2b: invalid output length")
+	}
+	return nil
+}
+
+func (r *refState) sumRecords(out, data []byte, stride int) error {
+	defer runtime.KeepAlive(r)
+	count := len(data) / stride
+	if count == 0 {
+		return nil
+	}
+	size := len(out) / count
+	if C.go_blake2b_sum_records(r.s, (*C.uint8_t)(&out[0]), C.size_t(size), (*C.uint8_t)(&data[0]), C.size_t(stride), C.size_t(count)) < 0 {
+		return errors.New("blake2b: invalid output length")
+	}
+	return nil
+}
+
+func (r *refState) xofBlocks(out []byte, param *[64]byte, h0 []byte, first uint32) error {
+	if len(out) == 0 {
+		return nil
+	}
+	if C.go_blake2b_xof_blocks((*C.uint8_t)(&out[0]), C.size_t(len(out)), (*C.uint8_t)(&param[0]),
+		(*C.uint8_t)(&h0[0]), C.uint32_t(first)) < 0 {
+		return errors.New("blake2b: invalid parameters")
+	}
+	return nil
+}
+
+func (r *refState) clone() state {
+	defer runtime.KeepAlive(r)
+	c := newRefState()
+	*c.s = *r.s
+	return c
+}
+
+func (r *refState) chainValue() ([8]uint64, bool) {
+	defer runtime.KeepAlive(r)
+	var h [8]uint64
+	for i := range h {
+		h[i] = uint64(r.s.h[i])
+	}
+	return h, true
+}
+
+func (r *refState) counter() ([2]uint64, bool) {
+	defer runtime.KeepAlive(r)
+	return [2]uint64{uint64(r.s.t[0]), uint64(r.s.t[1])}, true
+}
+
+func (r *refState) pending() ([]byte, bool) {
+	defer runtime.KeepAlive(r)
+	return C.GoBytes(unsafe.Pointer(&r.s.buf[0]), C.int(r.s.buflen)), true
+}
+
+func (r *refState) restore(h [8]uint64, t [2]uint64, pending []byte) {
+	for i := range h {
+		r.s.h[i] = C.uint64_t(h[i])
+	}
+	r.s.t[0], r.s.t[1] = C.uint64_t(t[0]), C.uint64_t(t[1])
+	runtime.KeepAlive(r)
+	if len(pending) > 0 {
+		r.update(pending)
+	}
+}
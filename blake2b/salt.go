@@ -0,0 +1,63 @@
+package blake2b
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+)
+
+// NewRandomSalt returns a copy of cfg, which may be nil, with its Salt
+// replaced by SaltSize random bytes from crypto/rand, and the salt
+// itself, to be stored next to the digest so that it can be verified.
+func NewRandomSalt(cfg *Config) (*Config, []byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	c := new(Config)
+	if cfg != nil {
+		*c = *cfg
+	}
+	c.Salt = salt
+	return c, salt, nil
+}
+
+// SumSalted hashes data as a digest created with New(cfg) would, cfg
+// being nil or valid, but with a fresh random salt from NewRandomSalt in
+// place of cfg.Salt. It returns the digest and the salt, both to be
+// stored, for VerifySalted. Randomized hashing keeps equal inputs from
+// having equal digests, and makes precomputed attacks useless.
+func SumSalted(data []byte, cfg *Config) (digest, salt []byte, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	c, salt, err := NewRandomSalt(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := New(c)
+	d.Write(data)
+	return d.Sum(nil), salt, nil
+}
+
+// VerifySalted reports whether digest is the digest of data returned by
+// SumSalted with salt and the same cfg, which may be nil. The digests are
+// compared in constant time.
+func VerifySalted(data, digest, salt []byte, cfg *Config) bool {
+	if len(salt) != SaltSize {
+		return false
+	}
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	c.Salt = salt
+	if c.Validate() != nil {
+		return false
+	}
+	d := New(&c)
+	if d.Size() != len(digest) {
+		return false
+	}
+	d.Write(data)
+	return subtle.ConstantTimeCompare(d.Sum(nil), digest) == 1
+}
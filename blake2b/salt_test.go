@@ -0,0 +1,77 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewRandomSalt(t *testing.T) {
+	cfg := &Config{Size: 16, Personal: []byte("personal")}
+	c1, salt1, err := NewRandomSalt(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, salt2, err := NewRandomSalt(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salt1) != SaltSize || bytes.Equal(salt1, salt2) {
+		t.Errorf("bad salts %x and %x", salt1, salt2)
+	}
+	if cfg.Salt != nil || c1.Size != 16 || !bytes.Equal(c1.Salt, salt1) {
+		t.Errorf("bad config %+v from %+v", c1, cfg)
+	}
+
+	h1 := New(c1)
+	h2 := New(c2)
+	if bytes.Equal(h1.Sum(nil), h2.Sum(nil)) {
+		t.Error("different salts gave the same digest")
+	}
+}
+
+func TestSumSalted(t *testing.T) {
+	data := []byte("password reset token")
+	cfg := &Config{Size: 16, Key: []byte("key"), Personal: []byte("tokens")}
+	digest, salt, err := SumSalted(data, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digest) != 16 || len(salt) != SaltSize || cfg.Salt != nil {
+		t.Fatalf("digest %x, salt %x, config %+v", digest, salt, cfg)
+	}
+	if d2, s2, _ := SumSalted(data, cfg); bytes.Equal(d2, digest) || bytes.Equal(s2, salt) {
+		t.Error("equal digests or salts for two calls")
+	}
+
+	d := New(&Config{Size: 16, Key: []byte("key"), Salt: salt, Personal: []byte("tokens")})
+	d.Write(data)
+	if !bytes.Equal(d.Sum(nil), digest) {
+		t.Error("digest differs from New with the salt")
+	}
+
+	if !VerifySalted(data, digest, salt, cfg) {
+		t.Error("VerifySalted rejected a valid digest")
+	}
+	other := append([]byte(nil), salt...)
+	other[0] ^= 1
+	for _, tt := range []struct {
+		name               string
+		data, digest, salt []byte
+		cfg                *Config
+	}{
+		{"other data", []byte("other"), digest, salt, cfg},
+		{"other salt", data, digest, other, cfg},
+		{"short salt", data, digest, salt[:1], cfg},
+		{"truncated digest", data, digest[:8], salt, cfg},
+		{"other config", data, digest, salt, nil},
+		{"invalid config", data, digest, salt, &Config{Key: make([]byte, MaxKeySize+1)}},
+	} {
+		if VerifySalted(tt.data, tt.digest, tt.salt, tt.cfg) {
+			t.Errorf("%s: VerifySalted accepted an invalid digest", tt.name)
+		}
+	}
+
+	if _, _, err := SumSalted(data, &Config{Size: MaxDigestSize + 1}); err != ErrDigestSize {
+		t.Errorf("invalid config: %v, want ErrDigestSize", err)
+	}
+}
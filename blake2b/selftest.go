@@ -0,0 +1,63 @@
+package blake2b
+
+import (
+	"bytes"
+	"errors"
+)
+
+// selftestResult is the BLAKE2b-256 "grand hash" of all the digests
+// computed by SelfTest, as given in RFC 7693, Appendix E.
+var selftestResult = []byte{
+	0xC2, 0x3A, 0x78, 0x00, 0xD9, 0x81, 0x23, 0xBD,
+	0x10, 0xF5, 0x06, 0xC6, 0x1E, 0x29, 0xDA, 0x56,
+	0x03, 0xD7, 0x63, 0xB8, 0xBB, 0xAD, 0x2E, 0x73,
+	0x7F, 0x5E, 0x76, 0x5A, 0x7B, 0xCC, 0xD4, 0x75,
+}
+
+// SelfTest runs the self-test procedure of RFC 7693, Appendix E, which
+// hashes deterministic inputs of various lengths with various digest
+// sizes, both unkeyed and keyed, and checks the hash of all the results
+// against a known answer. It returns an error if the linked
+// implementation does not produce the expected output.
+func SelfTest() error {
+	var (
+		in  [1024]byte
+		key [64]byte
+		md  [64]byte
+	)
+
+	h := New(&Config{Size: 32})
+	for _, outlen := range []int{20, 32, 48, 64} {
+		for _, inlen := range []int{0, 3, 128, 129, 255, 1024} {
+			selftestSeq(in[:inlen], uint32(inlen))
+			if err := sum(md[:outlen], in[:inlen], nil, nil); err != nil {
+				return err
+			}
+			h.Write(md[:outlen])
+
+			selftestSeq(key[:outlen], uint32(outlen))
+			if err := sum(md[:outlen], in[:inlen], key[:outlen], nil); err != nil {
+				return err
+			}
+			h.Write(md[:outlen])
+		}
+	}
+
+	if !bytes.Equal(h.Sum(nil), selftestResult) {
+		return errors.New("blake2b: self-test failed")
+	}
+	return nil
+}
+
+// selftestSeq fills out with the deterministic Fibonacci sequence used by
+// the RFC 7693 self-test.
+func selftestSeq(out []byte, seed uint32) {
+	a := 0xDEAD4BAD * seed
+	b := uint32(1)
+	for i := range out {
+		t := a + b
+		a = b
+		b = t
+		out[i] = byte(t >> 24)
+	}
+}
@@ -0,0 +1,37 @@
+package blake2b
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats receives the activity of digests, for monitoring the throughput
+// of hashing-heavy services. Its methods are called by Write and Sum of
+// every digest, on their goroutines, so they must be fast and safe for
+// concurrent use.
+type Stats interface {
+	// Hashed reports n bytes written to a digest of the named backend,
+	// and the time taken to hash them.
+	Hashed(backend string, n int, elapsed time.Duration)
+	// Finalized reports a digest computed by the named backend.
+	Finalized(backend string)
+}
+
+// statsBox holds the Stats in use, as atomic.Value does not store nil.
+type statsBox struct {
+	s Stats
+}
+
+var stats atomic.Value
+
+// SetStats makes digests report their activity to s, or to nothing if s
+// is nil, which is the default. Reporting costs two clock readings per
+// Write.
+func SetStats(s Stats) {
+	stats.Store(statsBox{s})
+}
+
+func currentStats() Stats {
+	b, _ := stats.Load().(statsBox)
+	return b.s
+}
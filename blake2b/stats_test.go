@@ -0,0 +1,47 @@
+package blake2b
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testStats struct {
+	mu        sync.Mutex
+	bytes     map[string]int
+	finalized map[string]int
+}
+
+func (s *testStats) Hashed(backend string, n int, elapsed time.Duration) {
+	s.mu.Lock()
+	s.bytes[backend] += n
+	s.mu.Unlock()
+}
+
+func (s *testStats) Finalized(backend string) {
+	s.mu.Lock()
+	s.finalized[backend]++
+	s.mu.Unlock()
+}
+
+func TestStats(t *testing.T) {
+	s := &testStats{bytes: make(map[string]int), finalized: make(map[string]int)}
+	SetStats(s)
+	defer SetStats(nil)
+
+	d := New(nil)
+	d.Write(make([]byte, 1000))
+	d.Write(nil)
+	d.Sum(nil)
+	d.Clone().Sum(nil)
+	if s.bytes[Backend()] != 1000 || s.finalized[Backend()] != 2 {
+		t.Errorf("bytes %v, finalized %v", s.bytes, s.finalized)
+	}
+
+	SetStats(nil)
+	d.Write(make([]byte, 10))
+	d.Sum(nil)
+	if s.bytes[Backend()] != 1000 || s.finalized[Backend()] != 2 {
+		t.Error("activity reported after SetStats(nil)")
+	}
+}
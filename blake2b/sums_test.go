@@ -0,0 +1,99 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSums(t *testing.T) {
+	data := []byte("fixed-size digests")
+	sum := func(size uint8) []byte {
+		d := New(&Config{Size: size})
+		d.Write(data)
+		return d.Sum(nil)
+	}
+	s160, s224, s256, s384, s512 := Sum160(data), Sum224(data), Sum256(data), Sum384(data), Sum512(data)
+	for _, c := range []struct {
+		got  []byte
+		size uint8
+	}{
+		{s160[:], 20},
+		{s224[:], 28},
+		{s256[:], 32},
+		{s384[:], 48},
+		{s512[:], 64},
+	} {
+		if want := sum(c.size); !bytes.Equal(c.got, want) {
+			t.Errorf("Sum%d = %x, want %x", int(c.size)*8, c.got, want)
+		}
+	}
+}
+
+func TestSumOneShot(t *testing.T) {
+	defer SetBackend(Backend())
+	defer func(n int) { maxUpdate = n }(maxUpdate)
+	in := make([]byte, 1000)
+	for i := range in {
+		in[i] = byte(i)
+	}
+	key := in[:MaxKeySize]
+	for _, name := range Backends() {
+		SetBackend(name)
+		for _, limit := range []int{maxUpdate, 100} {
+			maxUpdate = limit
+			for _, size := range []int{1, 20, MaxDigestSize} {
+				for _, n := range []int{0, 1, BlockSize, BlockSize + 1, len(in)} {
+					for _, k := range [][]byte{nil, key[:1], key} {
+						for _, p := range [][]byte{nil, in[:PersonalSize]} {
+							got := make([]byte, size)
+							if err := sum(got, in[:n], k, p); err != nil {
+								t.Fatalf("%s: sum: %v", name, err)
+							}
+							d := New(&Config{Size: uint8(size), Key: k, Personal: p})
+							d.Write(in[:n])
+							if want := d.Sum(nil); !bytes.Equal(got, want) {
+								t.Errorf("%s, limit %d: sum of %d bytes, size %d, key %d, personal %d = %x, want %x",
+									name, limit, n, size, len(k), len(p), got, want)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSumPersonal(t *testing.T) {
+	key, personal := []byte("key"), []byte("app v1")
+	got, err := Sum256Personal([]byte("message"), key, personal)
+	d := New(&Config{Size: 32, Key: key, Personal: personal})
+	d.Write([]byte("message"))
+	if err != nil || !bytes.Equal(got[:], d.Sum(nil)) {
+		t.Errorf("Sum256Personal = %x, %v, want %x", got, err, d.Sum(nil))
+	}
+	if got, _ := Sum256Personal([]byte("message"), nil, nil); got != Sum256([]byte("message")) {
+		t.Error("Sum256Personal without key or personalization differs from Sum256")
+	}
+	if _, err := Sum256Personal(nil, make([]byte, MaxKeySize+1), nil); err != ErrKeySize {
+		t.Errorf("long key: %v, want ErrKeySize", err)
+	}
+	if _, err := Sum256Personal(nil, nil, make([]byte, PersonalSize+1)); err != ErrPersonalSize {
+		t.Errorf("long personalization: %v, want ErrPersonalSize", err)
+	}
+}
+
+func BenchmarkSum256Personal(b *testing.B) {
+	data, key, personal := make([]byte, 16), make([]byte, 32), []byte("app v1")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Sum256Personal(data, key, personal)
+	}
+}
+
+func BenchmarkSum256Short(b *testing.B) {
+	data := make([]byte, 16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Sum256(data)
+	}
+}
@@ -0,0 +1,49 @@
+package blake2b
+
+import "io"
+
+// HashingReader is an io.Reader that hashes all data read through it.
+type HashingReader struct {
+	r io.Reader
+	d *Digest
+}
+
+// NewHashingReader returns a HashingReader reading from r and hashing
+// with the given config.
+func NewHashingReader(r io.Reader, config *Config) *HashingReader {
+	return &HashingReader{r: r, d: New(config)}
+}
+
+func (r *HashingReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	r.d.Write(buf[:n])
+	return n, err
+}
+
+// Sum appends the digest of everything read so far to buf.
+func (r *HashingReader) Sum(buf []byte) []byte {
+	return r.d.Sum(buf)
+}
+
+// HashingWriter is an io.Writer that hashes all data written through it.
+type HashingWriter struct {
+	w io.Writer
+	d *Digest
+}
+
+// NewHashingWriter returns a HashingWriter writing to w and hashing with
+// the given config. Only the bytes accepted by w are hashed.
+func NewHashingWriter(w io.Writer, config *Config) *HashingWriter {
+	return &HashingWriter{w: w, d: New(config)}
+}
+
+func (w *HashingWriter) Write(buf []byte) (int, error) {
+	n, err := w.w.Write(buf)
+	w.d.Write(buf[:n])
+	return n, err
+}
+
+// Sum appends the digest of everything written so far to buf.
+func (w *HashingWriter) Sum(buf []byte) []byte {
+	return w.d.Sum(buf)
+}
@@ -0,0 +1,33 @@
+package blake2b
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHashingReaderWriter(t *testing.T) {
+	const data = "one two three"
+	h := New(nil)
+	h.Write([]byte(data))
+	want := h.Sum(nil)
+
+	r := NewHashingReader(strings.NewReader(data), nil)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r.Sum(nil), want) {
+		t.Error("reader sum mismatch")
+	}
+
+	var out bytes.Buffer
+	w := NewHashingWriter(&out, nil)
+	io.WriteString(w, data)
+	if !bytes.Equal(w.Sum(nil), want) {
+		t.Error("writer sum mismatch")
+	}
+	if out.String() != data {
+		t.Errorf("writer passed through %q", out.String())
+	}
+}
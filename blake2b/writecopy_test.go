@@ -0,0 +1,48 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteCopy(t *testing.T) {
+	defer SetBackend(Backend())
+	data := make([]byte, 3*scratchSize+5)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		for _, n := range []int{0, 1, BlockSize, scratchSize, len(data)} {
+			want := New(nil)
+			want.Write(data[:n])
+			d := New(nil)
+			d.WriteCopy(data[:n])
+			if !bytes.Equal(d.Sum(nil), want.Sum(nil)) || d.Len() != uint64(n) {
+				t.Errorf("%s: WriteCopy of %d bytes differs from Write", backend, n)
+			}
+		}
+
+		d := New(&Config{Size: 32})
+		allocs := testing.AllocsPerRun(100, func() {
+			var rec [16]byte
+			binary.LittleEndian.PutUint64(rec[:], d.Len())
+			d.WriteCopy(rec[:])
+		})
+		if allocs != 0 {
+			t.Errorf("%s: WriteCopy of a stack buffer allocates %v times", backend, allocs)
+		}
+	}
+}
+
+func BenchmarkWriteCopy16(b *testing.B) {
+	d := New(nil)
+	b.ReportAllocs()
+	b.SetBytes(16)
+	for i := 0; i < b.N; i++ {
+		var rec [16]byte
+		binary.LittleEndian.PutUint64(rec[:], uint64(i))
+		d.WriteCopy(rec[:])
+	}
+}
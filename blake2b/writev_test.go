@@ -0,0 +1,62 @@
+package blake2b
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteV(t *testing.T) {
+	defer SetBackend(Backend())
+	defer func(n int) { maxUpdate = n }(maxUpdate)
+	data := make([]byte, 40*BlockSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	// Parts of various lengths, with empty ones and more than a single
+	// call of updateV takes.
+	var parts [][]byte
+	for off, n := 0, 0; off+n <= len(data); off, n = off+n, (n+7)%(3*BlockSize) {
+		parts = append(parts, data[off:off+n])
+	}
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		for _, limit := range []int{maxUpdate, BlockSize} {
+			maxUpdate = limit
+			for _, config := range []*Config{nil, {Key: []byte("key")}} {
+				want := New(config)
+				d := New(config)
+				total := 0
+				for _, part := range parts {
+					want.Write(part)
+					total += len(part)
+				}
+				if n, err := d.WriteV(parts...); n != total || err != nil {
+					t.Fatalf("%s: WriteV = %d, %v, want %d", backend, n, err, total)
+				}
+				if !bytes.Equal(d.Sum(nil), want.Sum(nil)) || d.Len() != want.Len() {
+					t.Errorf("%s, limit %d, config %+v: WriteV differs from Write", backend, limit, config)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkWriteV(b *testing.B) {
+	header, meta, body := make([]byte, 8), make([]byte, 16), make([]byte, 40)
+	b.Run("Write", func(b *testing.B) {
+		d := New(nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.Write(header)
+			d.Write(meta)
+			d.Write(body)
+		}
+	})
+	b.Run("WriteV", func(b *testing.B) {
+		d := New(nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.WriteV(header, meta, body)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// UnknownOutputLength is the output length of an XOF whose length is not
+// known in advance. Its output is up to 2^32 blocks of 64 bytes long.
+const UnknownOutputLength = 1<<32 - 1
+
+var (
+	// ErrXOFLength is returned by NewXOF for an output length of 0.
+	ErrXOFLength = errors.New("blake2b: invalid XOF output length")
+	// ErrXOFWrite is returned by XOF.Write after output has been read.
+	ErrXOFWrite = errors.New("blake2b: write to XOF after read")
+	// ErrXOFSeek is returned by XOF.Seek for invalid offsets and for
+	// seeking relative to the end of an output of unknown length.
+	ErrXOFSeek = errors.New("blake2b: invalid XOF seek")
+)
+
+// XOF is the BLAKE2Xb extendable-output function: a BLAKE2b hash whose
+// digest, of up to 2^32-2 bytes or 256 GiB if its length is unknown, is
+// read like a stream. The input is
+// hashed into a root digest H0, and output block i is the BLAKE2b digest
+// of H0 as node i of a tree of 64-byte leaves. Each block can thus be
+// computed on its own, which Seek and ReadAt use to read the output at
+// any offset, for instance to derive the i-th subkey of a KDF without
+// computing the ones before it.
+//
+// An XOF is not safe for concurrent use, except for concurrent calls to
+// ReadAt once all input has been written.
+type XOF struct {
+	length uint32
+	root   *Digest
+	// node computes output blocks for Read.
+	node *Digest
+	h0   [64]byte
+	once *sync.Once
+	done bool
+	// off is the offset of Read; block holds the output block it is in,
+	// if cached.
+	off    uint64
+	block  []byte
+	cached bool
+	buf    [64]byte
+}
+
+// NewXOF returns a new XOF with an output of length bytes, configured by
+// config, which may be nil. Length is part of the parameters, so outputs
+// of different lengths are unrelated; use UnknownOutputLength if it is
+// not known in advance. The Size and Tree of config must be zero.
+func NewXOF(length uint32, config *Config) (*XOF, error) {
+	if length == 0 {
+		return nil, ErrXOFLength
+	}
+	c := Config{}
+	if config != nil {
+		c = *config
+	}
+	if c.Size != 0 || c.Tree != nil {
+		return nil, errors.New("blake2b: XOF config with a size or tree parameters")
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	root := New(&c)
+	binary.LittleEndian.PutUint32(root.param[12:16], length)
+	root.Reset()
+
+	// Output blocks are unkeyed nodes of depth 0 under a root of unlimited
+	// fanout and depth, with 64-byte leaves and inner hashes.
+	node := New(&Config{Salt: c.Salt, Personal: c.Personal})
+	node.param[2] = 0
+	node.param[3] = 0
+	binary.LittleEndian.PutUint32(node.param[4:8], MaxDigestSize)
+	binary.LittleEndian.PutUint32(node.param[12:16], length)
+	node.param[17] = MaxDigestSize
+
+	return &XOF{length: length, root: root, node: node, once: new(sync.Once)}, nil
+}
+
+// Write absorbs p into the input. It returns ErrXOFWrite once output has
+// been read.
+func (x *XOF) Write(p []byte) (int, error) {
+	if x.done {
+		return 0, ErrXOFWrite
+	}
+	return x.root.Write(p)
+}
+
+// limit returns the length of the output.
+func (x *XOF) limit() uint64 {
+	if x.length == UnknownOutputLength {
+		return MaxDigestSize << 32
+	}
+	return uint64(x.length)
+}
+
+// finish computes H0, ending the input.
+func (x *XOF) finish() {
+	x.root.Sum(x.h0[:0])
+	x.done = true
+}
+
+// outputBlock computes output block i into buf with node, returning it.
+func (x *XOF) outputBlock(node *Digest, i uint64, buf *[64]byte) []byte {
+	size := x.limit() - i*MaxDigestSize
+	if size > MaxDigestSize {
+		size = MaxDigestSize
+	}
+	node.param[0] = uint8(size)
+	binary.LittleEndian.PutUint32(node.param[8:12], uint32(i))
+	node.Reset()
+	node.Write(x.h0[:])
+	return node.Sum(buf[:0])
+}
+
+// Read reads the output from the current offset. It returns io.EOF at the
+// end of the output.
+func (x *XOF) Read(p []byte) (int, error) {
+	x.once.Do(x.finish)
+	if x.off >= x.limit() {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && x.off < x.limit() {
+		i := x.off / MaxDigestSize
+		if !x.cached {
+			x.block = x.outputBlock(x.node, i, &x.buf)
+			x.cached = true
+		}
+		k := copy(p[n:], x.block[x.off%MaxDigestSize:])
+		n += k
+		x.off += uint64(k)
+		if x.off%MaxDigestSize == 0 {
+			x.cached = false
+		}
+	}
+	return n, nil
+}
+
+// xofBatch is the number of bytes of output WriteTo computes at once.
+const xofBatch = 1024 * MaxDigestSize
+
+// WriteTo writes the output from the current offset to its end to w, as
+// io.WriterTo, which io.Copy uses to stream it. Output blocks are
+// computed in batches, in a single call with the cgo backends, rather
+// than a block per Read, which makes generating hundreds of megabytes of
+// keystream much cheaper. The offset advances past the bytes written.
+func (x *XOF) WriteTo(w io.Writer) (int64, error) {
+	x.once.Do(x.finish)
+	buf := make([]byte, xofBatch)
+	var total int64
+	// Finish the block Read is in, so that batches start on a block.
+	if r := x.off % MaxDigestSize; r != 0 && x.off < x.limit() {
+		n, _ := x.Read(buf[:MaxDigestSize-r])
+		m, err := w.Write(buf[:n])
+		total += int64(m)
+		if err == nil && m < n {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			x.off -= uint64(n - m)
+			x.cached = false
+			return total, err
+		}
+	}
+	for x.off < x.limit() {
+		n := x.limit() - x.off
+		if n > xofBatch {
+			n = xofBatch
+		}
+		x.outputBlocks(buf[:n], x.off/MaxDigestSize)
+		m, err := w.Write(buf[:n])
+		x.off += uint64(m)
+		total += int64(m)
+		if err == nil && uint64(m) < n {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			x.cached = false
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// outputBlocks computes the output blocks from block first on into out,
+// which ends on a block or at the end of the output.
+func (x *XOF) outputBlocks(out []byte, first uint64) {
+	if s, ok := x.node.state.(xofState); ok {
+		st := currentStats()
+		var start time.Time
+		if st != nil {
+			start = time.Now()
+		}
+		if s.xofBlocks(out, &x.node.param, x.h0[:], uint32(first)) != nil {
+			panic("blake2b: unable to compute XOF output")
+		}
+		if st != nil {
+			blocks := (len(out) + MaxDigestSize - 1) / MaxDigestSize
+			st.Hashed(x.node.backend, blocks*MaxDigestSize, time.Since(start))
+			for i := 0; i < blocks; i++ {
+				st.Finalized(x.node.backend)
+			}
+		}
+		return
+	}
+	var buf [64]byte
+	for i := first; len(out) > 0; i++ {
+		k := copy(out, x.outputBlock(x.node, i, &buf))
+		out = out[k:]
+	}
+}
+
+// ReadAt reads len(p) bytes of output at offset off, computing only the
+// blocks they lie in, without changing the offset of Read. It returns
+// io.EOF if the output ends before len(p) bytes.
+func (x *XOF) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, ErrXOFSeek
+	}
+	x.once.Do(x.finish)
+	node := x.node.Clone().(*Digest)
+	var buf [64]byte
+	pos := uint64(off)
+	n := 0
+	for n < len(p) && pos < x.limit() {
+		block := x.outputBlock(node, pos/MaxDigestSize, &buf)
+		k := copy(p[n:], block[pos%MaxDigestSize:])
+		n += k
+		pos += uint64(k)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Seek sets the offset of the next Read, as io.Seeker. Seeking relative
+// to io.SeekEnd requires a known output length. Offsets past the end are
+// allowed; reading there returns io.EOF.
+func (x *XOF) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(x.off)
+	case io.SeekEnd:
+		if x.length == UnknownOutputLength {
+			return 0, ErrXOFSeek
+		}
+		base = int64(x.length)
+	default:
+		return 0, ErrXOFSeek
+	}
+	if offset < -base {
+		return 0, ErrXOFSeek
+	}
+	x.once.Do(x.finish)
+	if off := uint64(base + offset); off/MaxDigestSize != x.off/MaxDigestSize {
+		x.cached = false
+	}
+	x.off = uint64(base + offset)
+	return int64(x.off), nil
+}
+
+// Reset discards the input and restores the initial state.
+func (x *XOF) Reset() {
+	x.root.Reset()
+	x.once = new(sync.Once)
+	x.done = false
+	x.off = 0
+	x.cached = false
+}
@@ -0,0 +1,213 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+var xofTests = []struct {
+	in     string
+	length uint32
+	config *Config
+	// out is the first bytes of output, computed with an independent
+	// implementation of BLAKE2Xb.
+	out string
+}{
+	{"abc", 100, nil, "e0f82b71c07860b65be612d2633becc46596a6c12a8772b561adec35721b7a5c44a7e075e8a3bc8c4fc8390a197be2085b4aa4385c207f24e46415defc659afd73bacb288080b10849aeea386c60cd3fa04c9bcbfeebaed6e98634d696b9d5bdef0ad2c5"},
+	{"abc", UnknownOutputLength, nil, "ae080c1efbcf7f60ed52a04161d02b7ee63bed362534f0661da02c6e40cd208946d066b86b3dff620e57acea9cd72d3056cf6cb0c18341452a17ce2cced67b702669bf0bed358c1b708e97de2533b294cdd5e9e229678be36399b5b28d6541c4bc4e3079fb8a0fbdf6023a65f36c654947ce7c114a243670dad347f03275b5c5bd383e8d53fd0fe8f387ea3d6445fc6510c8a3b9fc5cced503b824504f0471bd3ac19514bdaf7a3c021dc44ca8ff6d656a6007d43b552f07560e8b79217060c1387971e8e3ee97d9"},
+	{"message", 37, &Config{Key: []byte("key"), Salt: []byte("salt"), Personal: []byte("person")}, "27241f48424e6615acced99d6ef70e505430654b4c063da95c7e588ee6c7b4ac854a2215bc"},
+}
+
+func TestXOF(t *testing.T) {
+	defer SetBackend(Backend())
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		for i, tt := range xofTests {
+			want, _ := hex.DecodeString(tt.out)
+			x, err := NewXOF(tt.length, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x.Write([]byte(tt.in))
+			got := make([]byte, len(want))
+			// Read in pieces that do not line up with output blocks.
+			for n := 0; n < len(got); n += 7 {
+				end := n + 7
+				if end > len(got) {
+					end = len(got)
+				}
+				if _, err := io.ReadFull(x, got[n:end]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s, test %d: got %x, want %x", backend, i, got, want)
+			}
+			if tt.length != UnknownOutputLength {
+				if n, err := x.Read(got); n != 0 || err != io.EOF {
+					t.Errorf("%s, test %d: Read at end returned %d, %v", backend, i, n, err)
+				}
+			}
+		}
+	}
+}
+
+func TestXOFReadAt(t *testing.T) {
+	x, _ := NewXOF(1000, nil)
+	x.Write([]byte("input"))
+	all, err := ioutil.ReadAll(x)
+	if err != nil || len(all) != 1000 {
+		t.Fatalf("ReadAll: %d bytes, %v", len(all), err)
+	}
+
+	y, _ := NewXOF(1000, nil)
+	y.Write([]byte("input"))
+	for _, off := range []int{0, 1, 63, 64, 500, 990} {
+		buf := make([]byte, 10)
+		if _, err := y.ReadAt(buf, int64(off)); err != nil {
+			t.Fatalf("ReadAt(%d): %v", off, err)
+		}
+		if !bytes.Equal(buf, all[off:off+10]) {
+			t.Errorf("ReadAt(%d) = %x, want %x", off, buf, all[off:off+10])
+		}
+	}
+	buf := make([]byte, 20)
+	if n, err := y.ReadAt(buf, 990); n != 10 || err != io.EOF || !bytes.Equal(buf[:n], all[990:]) {
+		t.Errorf("ReadAt past the end returned %d, %v", n, err)
+	}
+	if _, err := y.Write([]byte("more")); err != ErrXOFWrite {
+		t.Errorf("Write after ReadAt: %v, want ErrXOFWrite", err)
+	}
+}
+
+func TestXOFSeek(t *testing.T) {
+	x, _ := NewXOF(300, nil)
+	all, _ := ioutil.ReadAll(x)
+	for _, c := range []struct {
+		offset int64
+		whence int
+		want   int64
+	}{
+		{130, io.SeekStart, 130},
+		{-5, io.SeekCurrent, 125},
+		{-64, io.SeekEnd, 236},
+		{10, io.SeekEnd, 310},
+	} {
+		pos, err := x.Seek(c.offset, c.whence)
+		if err != nil || pos != c.want {
+			t.Fatalf("Seek(%d, %d) = %d, %v; want %d", c.offset, c.whence, pos, err, c.want)
+		}
+		buf := make([]byte, 3)
+		n, _ := x.Read(buf)
+		if pos < 300 && !bytes.Equal(buf[:n], all[pos:pos+3]) {
+			t.Errorf("read at %d: %x, want %x", pos, buf[:n], all[pos:pos+3])
+		}
+		x.Seek(-int64(n), io.SeekCurrent)
+	}
+	if _, err := x.Seek(-1, io.SeekStart); err != ErrXOFSeek {
+		t.Errorf("negative offset: %v, want ErrXOFSeek", err)
+	}
+	u, _ := NewXOF(UnknownOutputLength, nil)
+	if _, err := u.Seek(0, io.SeekEnd); err != ErrXOFSeek {
+		t.Errorf("SeekEnd with unknown length: %v, want ErrXOFSeek", err)
+	}
+	// The last block of an output of unknown length is at 2^38 - 64.
+	buf := make([]byte, 64)
+	if n, err := u.ReadAt(buf, MaxDigestSize<<32-64); n != 64 || err != nil {
+		t.Errorf("ReadAt last block: %d, %v", n, err)
+	}
+}
+
+func TestXOFReset(t *testing.T) {
+	x, _ := NewXOF(64, nil)
+	x.Write([]byte("a"))
+	a, _ := ioutil.ReadAll(x)
+	x.Reset()
+	x.Write([]byte("a"))
+	b, _ := ioutil.ReadAll(x)
+	if !bytes.Equal(a, b) {
+		t.Error("output differs after Reset")
+	}
+	if _, err := NewXOF(0, nil); err != ErrXOFLength {
+		t.Errorf("length 0: %v, want ErrXOFLength", err)
+	}
+	if _, err := NewXOF(64, &Config{Size: 32}); err == nil {
+		t.Error("NewXOF accepted a config with a size")
+	}
+}
+
+// limitedWriter fails once n bytes have been written.
+type limitedWriter struct {
+	buf bytes.Buffer
+	n   int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n-w.buf.Len() {
+		p = p[:w.n-w.buf.Len()]
+		w.buf.Write(p)
+		return len(p), io.ErrClosedPipe
+	}
+	return w.buf.Write(p)
+}
+
+func TestXOFWriteTo(t *testing.T) {
+	defer SetBackend(Backend())
+	for _, backend := range Backends() {
+		SetBackend(backend)
+		for _, length := range []int{1, MaxDigestSize + 3, xofBatch + 5*MaxDigestSize + 1} {
+			want := make([]byte, length)
+			x, _ := NewXOF(uint32(length), &Config{Key: []byte("key")})
+			x.Write([]byte("input"))
+			x.ReadAt(want, 0)
+			for _, off := range []int{0, 5, MaxDigestSize, length} {
+				if off > length {
+					continue
+				}
+				x.Seek(int64(off), io.SeekStart)
+				var got bytes.Buffer
+				if n, err := x.WriteTo(&got); n != int64(length-off) || err != nil {
+					t.Fatalf("%s: WriteTo of %d from %d = %d, %v", backend, length, off, n, err)
+				}
+				if !bytes.Equal(got.Bytes(), want[off:]) {
+					t.Errorf("%s: WriteTo of %d from %d differs from ReadAt", backend, length, off)
+				}
+			}
+
+			if length < 8 {
+				continue
+			}
+			// After a failed write, reading resumes after the bytes written.
+			x.Seek(3, io.SeekStart)
+			w := &limitedWriter{n: length / 2}
+			if n, err := x.WriteTo(w); n != int64(w.n) || err != io.ErrClosedPipe {
+				t.Fatalf("%s: WriteTo to a failing writer = %d, %v", backend, n, err)
+			}
+			rest, _ := ioutil.ReadAll(x)
+			if got := append(w.buf.Bytes(), rest...); !bytes.Equal(got, want[3:]) {
+				t.Errorf("%s: output after a failed WriteTo of %d differs", backend, length)
+			}
+		}
+	}
+}
+
+func BenchmarkXOF(b *testing.B) {
+	const size = 1 << 20
+	b.Run("Read", func(b *testing.B) {
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			x, _ := NewXOF(UnknownOutputLength, nil)
+			io.Copy(ioutil.Discard, struct{ io.Reader }{io.LimitReader(x, size)})
+		}
+	})
+	b.Run("WriteTo", func(b *testing.B) {
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			x, _ := NewXOF(size, nil)
+			x.WriteTo(ioutil.Discard)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package blake2s
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// backend is an implementation of BLAKE2s that digests drive. The bundled
+// reference C code is the default; other implementations can be compiled in
+// with build tags and selected with SetBackend.
+//
+// Backends deal only with the standard parameter block and message
+// blocks: keyed hashing is done by the digest, which absorbs the padded
+// key as the first message block after initialization, as the
+// specification describes.
+type backend interface {
+	// newState returns a new state, to be initialized with init.
+	newState() state
+	// name returns the name the backend is registered under.
+	name() string
+}
+
+// state is the running state of a BLAKE2s computation.
+type state interface {
+	// init resets the state for the encoded parameter block param. If
+	// lastNode is set, the state hashes the last node of a tree level.
+	init(param *[32]byte, lastNode bool) error
+	// update absorbs buf.
+	update(buf []byte)
+	// final writes the digest of all data absorbed so far to out, which
+	// is as long as the configured digest size. It does not change the
+	// state, so absorbing can continue afterwards.
+	final(out []byte) error
+	// clone returns an independent copy of the state.
+	clone() state
+}
+
+// finalizer is implemented by states that can finalize in place, without
+// the copy final makes, for Finalize.
+type finalizer interface {
+	// finalize writes the digest to out like final, leaving the state
+	// unusable until init.
+	finalize(out []byte) error
+}
+
+// recordSummer is implemented by states that hash many records from
+// their current state at once, for SumRecords.
+type recordSummer interface {
+	// sumRecords writes to out the digests of the records of stride
+	// bytes of data, each absorbed into a copy of the state, without
+	// changing the state.
+	sumRecords(out, data []byte, stride int) error
+}
+
+// vectorUpdater is implemented by states that absorb several buffers in
+// a single call, for WriteV.
+type vectorUpdater interface {
+	// updateV absorbs the buffers of bufs in order, as successive calls
+	// to update would. Empty buffers are skipped. They are passed by
+	// value so that the slice holding them does not escape.
+	updateV(bufs [4][]byte)
+}
+
+// xofState is implemented by states that compute many BLAKE2X output
+// blocks in a single call, for XOF.WriteTo.
+type xofState interface {
+	// xofBlocks writes to out the output blocks from block first on,
+	// each the digest of the root digest h0 under param, with the node
+	// offset set to the block index and the digest length to a full
+	// block, or to what is left of out for the last one.
+	xofBlocks(out []byte, param *[32]byte, h0 []byte, first uint32) error
+}
+
+// oneShotBackend is implemented by backends that compute a digest from
+// scratch in a single call, for the one-shot sum functions, which then
+// avoid creating a state and crossing into C for each step.
+type oneShotBackend interface {
+	// sumOnce writes to out, which is as long as the digest size in
+	// param, the digest of in hashed with the encoded parameter block
+	// param and keyed with key, whose length param also gives.
+	sumOnce(out []byte, param *[32]byte, key, in []byte) error
+}
+
+// BackendError reports a backend failing to initialize a state or to
+// compute a digest, which the bundled implementations only do for
+// parameters they reject, such as a system libb2 older than the
+// parameters used. Digests return it from Write and Final after such a
+// failure, until a Reset succeeds, and Sum panics with it, so that
+// services can tell it apart and degrade instead of crashing.
+type BackendError struct {
+	// Err is ErrInitFailed or ErrFinalFailed.
+	Err error
+	// Backend is the name of the failing backend.
+	Backend string
+	// Params summarizes the parameter block, without its salt and
+	// personalization.
+	Params string
+	// Cause is the error returned by the backend.
+	Cause error
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("%v: %v (backend %s, %s)", e.Err, e.Cause, e.Backend, e.Params)
+}
+
+// Unwrap returns e.Err, so that errors.Is matches ErrInitFailed and
+// ErrFinalFailed.
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// backendError returns the BackendError of d for a failure err of its
+// backend.
+func (d *Digest) backendError(kind, err error) error {
+	p := &d.param
+	return &BackendError{
+		Err:     kind,
+		Backend: d.backend,
+		Params: fmt.Sprintf("size %d, key %d, fanout %d, depth %d, leaf %d, offset %d, node depth %d, inner size %d, last node %t",
+			p[0], p[1], p[2], p[3], binary.LittleEndian.Uint32(p[4:8]), binary.LittleEndian.Uint32(p[8:12]), p[14], p[15], d.isLastNode),
+		Cause: err,
+	}
+}
+
+// finalizedState replaces the state of a digest after Finalize, until
+// Reset restores s.
+type finalizedState struct {
+	s state
+}
+
+func (finalizedState) init(*[32]byte, bool) error {
+	panic("blake2s: digest used after Finalize")
+}
+
+func (finalizedState) update([]byte) {
+	panic("blake2s: digest used after Finalize")
+}
+
+func (finalizedState) final([]byte) error {
+	panic("blake2s: digest used after Finalize")
+}
+
+func (finalizedState) clone() state {
+	panic("blake2s: digest used after Finalize")
+}
+
+// rawState is implemented by states that expose their internals. The
+// methods report false if the state cannot be read, which states wrapping
+// another implementation may not allow.
+type rawState interface {
+	chainValue() ([8]uint32, bool)
+	counter() ([2]uint32, bool)
+}
+
+// savableState is implemented by states that can be saved and restored,
+// see MarshalBinary.
+type savableState interface {
+	state
+	rawState
+	// pending returns a copy of the bytes buffered but not compressed yet,
+	// or false if the state cannot be saved.
+	pending() ([]byte, bool)
+	// restore sets the chaining value and counter of a freshly
+	// initialized state, then buffers pending.
+	restore(h [8]uint32, t [2]uint32, pending []byte)
+}
+
+// backends are the backends available in this build, by name. Backends
+// compiled in with build tags register themselves at init time.
+var backends = map[string]backend{genericBackend{}.name(): genericBackend{}}
+
+// EnvBackend is the environment variable that selects, at init time, the
+// implementation used by new digests instead of the automatic choice, by
+// one of the names returned by Backends, so that operators can work
+// around a faulty implementation or compare them without rebuilding:
+//
+//	BLAKE2_BACKEND=pure-go ./server
+//
+// Names of implementations missing from the build are ignored.
+const EnvBackend = "BLAKE2_BACKEND"
+
+func init() {
+	useEnvBackend()
+}
+
+// useEnvBackend selects the backend named by EnvBackend, if registered.
+// The init functions registering backends call it too, so that the
+// choice holds whatever order they run in.
+func useEnvBackend() {
+	if b, ok := backends[os.Getenv(EnvBackend)]; ok {
+		defaultBackend = b
+	}
+}
+
+// Backends returns the names of the implementations available in this
+// build, sorted:
+//
+//	pure-go  the pure Go implementation, always available
+//	cgo-ref  the bundled C sources, or the system libb2
+//	openssl  OpenSSL, with the openssl build tag
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Backend returns the name of the implementation used by new digests.
+func Backend() string {
+	return defaultBackend.name()
+}
+
+// backendSet records a call to SetBackend, for Features.
+var backendSet bool
+
+// SetBackend selects the implementation used by digests created
+// afterwards, by one of the names returned by Backends, for benchmarking,
+// debugging or compliance. Digests already created keep theirs. It is
+// meant to be called during program initialization and must not be
+// called concurrently with the other functions of the package.
+func SetBackend(name string) error {
+	b, ok := backends[name]
+	if !ok {
+		return errors.New("blake2s: unknown backend " + name)
+	}
+	defaultBackend = b
+	backendSet = true
+	return nil
+}
@@ -0,0 +1,123 @@
+package blake2s
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSetBackend(t *testing.T) {
+	saved := defaultBackend
+	defer func() { defaultBackend = saved }()
+
+	names := Backends()
+	if len(names) == 0 || Backend() == "" {
+		t.Fatalf("Backends() = %v, Backend() = %q", names, Backend())
+	}
+	var want []byte
+	for _, name := range names {
+		if err := SetBackend(name); err != nil {
+			t.Fatal(err)
+		}
+		if Backend() != name {
+			t.Errorf("Backend() = %q after SetBackend(%q)", Backend(), name)
+		}
+		h := New(&Config{Key: []byte("key")})
+		h.Write([]byte("message"))
+		got := h.Sum(nil)
+		if want == nil {
+			want = got
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("backend %s: digest %X, want %X", name, got, want)
+		}
+	}
+	if err := SetBackend("no such backend"); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
+
+func TestEnvBackend(t *testing.T) {
+	saved := defaultBackend
+	defer func() { defaultBackend = saved }()
+	defer os.Setenv(EnvBackend, os.Getenv(EnvBackend))
+
+	for _, name := range Backends() {
+		os.Setenv(EnvBackend, name)
+		useEnvBackend()
+		if Backend() != name {
+			t.Errorf("Backend() = %q with %s=%s", Backend(), EnvBackend, name)
+		}
+	}
+	os.Setenv(EnvBackend, "no such backend")
+	want := Backend()
+	useEnvBackend()
+	if Backend() != want {
+		t.Errorf("Backend() = %q with an unknown backend, want %q", Backend(), want)
+	}
+}
+
+// failingState is a state whose init or final fails, as a backend
+// rejecting parameters would.
+type failingState struct {
+	state
+	initErr, finalErr error
+}
+
+func (f *failingState) init(param *[32]byte, lastNode bool) error {
+	if f.initErr != nil {
+		return f.initErr
+	}
+	return f.state.init(param, lastNode)
+}
+
+func (f *failingState) final(out []byte) error {
+	if f.finalErr != nil {
+		return f.finalErr
+	}
+	return f.state.final(out)
+}
+
+func TestBackendError(t *testing.T) {
+	cause := errors.New("parameters rejected")
+	d := New(&Config{Size: 32, Key: []byte("key")})
+	fs := &failingState{state: genericBackend{}.newState(), initErr: cause}
+	d.state = fs
+	d.Reset()
+	if _, err := d.Write([]byte("x")); !errors.Is(err, ErrInitFailed) {
+		t.Fatalf("Write after a failed init: %v, want ErrInitFailed", err)
+	}
+	_, err := d.Final()
+	var be *BackendError
+	if !errors.As(err, &be) || be.Cause != cause || be.Backend != d.backend || !strings.Contains(be.Params, "size 32, key 3") {
+		t.Errorf("Final after a failed init: %v", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !errors.Is(r.(error), ErrInitFailed) {
+				t.Errorf("Sum panicked with %v, want a BackendError", r)
+			}
+		}()
+		d.Sum(nil)
+	}()
+
+	// A successful Reset clears the error; a failing final is reported
+	// by Final.
+	fs.initErr, fs.finalErr = nil, cause
+	d.Reset()
+	if _, err := d.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Final(); !errors.Is(err, ErrFinalFailed) {
+		t.Errorf("Final with a failing final: %v, want ErrFinalFailed", err)
+	}
+	fs.finalErr = nil
+	d.Reset()
+	d.Write([]byte("x"))
+	want := New(&Config{Size: 32, Key: []byte("key")})
+	want.Write([]byte("x"))
+	if got, err := d.Final(); err != nil || !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("Final after recovery = %x, %v", got, err)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+   Glue between the Go wrapper and the BLAKE2 C library.
+
+   The Go side only calls the go_* functions below, which have the same
+   signatures whether the bundled sources or the system libb2 (build tag
+   system_libb2) provide the implementation; the libb2 prototypes differ
+   slightly between releases. Parameter blocks are passed as their
+   standard 32-byte encoding.
+*/
+#ifndef BLAKE2_GO_H
+#define BLAKE2_GO_H
+
+#include <string.h>
+
+#if defined(BLAKE2_SYSTEM_LIBB2)
+#include <blake2.h>
+#else
+#include "blake2.h"
+#endif
+
+static inline int go_blake2s_init_param( blake2s_state *S, const uint8_t *P )
+{
+  return blake2s_init_param( S, ( const blake2s_param * )P );
+}
+
+static inline int go_blake2s_update( blake2s_state *S, const void *in, size_t inlen )
+{
+  return blake2s_update( S, in, inlen );
+}
+
+/* Finalizes a copy of S, so that S can keep absorbing data. */
+static inline int go_blake2s_final_copy( const blake2s_state *S, void *out, size_t outlen )
+{
+  blake2s_state copy = *S;
+  return blake2s_final( &copy, out, outlen );
+}
+
+/* Finalizes S itself, which must be initialized again before reuse. */
+static inline int go_blake2s_final( blake2s_state *S, void *out, size_t outlen )
+{
+  return blake2s_final( S, out, outlen );
+}
+
+/* Hashes count records of stride bytes from in, each from a copy of S,
+   writing their outlen-byte digests consecutively to out. */
+static inline int go_blake2s_sum_records( const blake2s_state *S, uint8_t *out, size_t outlen,
+                                        const uint8_t *in, size_t stride, size_t count )
+{
+  size_t i;
+  for( i = 0; i < count; ++i )
+  {
+    blake2s_state copy = *S;
+    blake2s_update( &copy, in + i * stride, stride );
+    if( blake2s_final( &copy, out + i * outlen, outlen ) < 0 ) return -1;
+  }
+  return 0;
+}
+
+/* Hashes in from scratch with the parameter block P, keyed with the keylen
+   bytes of key as the first message block if keylen is not 0, and writes
+   the outlen-byte digest to out: a one-shot digest in a single call. */
+static inline int go_blake2s_sum_param( uint8_t *out, size_t outlen, const uint8_t *P,
+                                        const uint8_t *key, size_t keylen,
+                                        const void *in, size_t inlen )
+{
+  blake2s_state S;
+  uint8_t block[BLAKE2S_BLOCKBYTES];
+  if( blake2s_init_param( &S, ( const blake2s_param * )P ) < 0 ) return -1;
+  if( keylen > 0 )
+  {
+    memset( block, 0, sizeof( block ) );
+    memcpy( block, key, keylen );
+    blake2s_update( &S, block, sizeof( block ) );
+    memset( block, 0, sizeof( block ) );
+  }
+  blake2s_update( &S, in, inlen );
+  return blake2s_final( &S, out, outlen );
+}
+
+/* Absorbs up to four buffers in order, as successive calls to update
+   would, so that Go passes them in one call; unused ones have length 0.
+   Go may pass the buffers as arguments, but not as an array of pointers
+   without pinning them. */
+static inline int go_blake2s_update4( blake2s_state *S,
+                                      const void *in0, size_t inlen0, const void *in1, size_t inlen1,
+                                      const void *in2, size_t inlen2, const void *in3, size_t inlen3 )
+{
+  if( blake2s_update( S, in0, inlen0 ) < 0 ) return -1;
+  if( blake2s_update( S, in1, inlen1 ) < 0 ) return -1;
+  if( blake2s_update( S, in2, inlen2 ) < 0 ) return -1;
+  return blake2s_update( S, in3, inlen3 );
+}
+
+/* Writes to out the BLAKE2X output blocks from block first on: each is
+   the digest of the outbytes-byte root digest h0 under the parameter
+   block P, with the node offset set to the block index, and the digest
+   length to a full block, or to what is left of out for the last one. */
+static inline int go_blake2s_xof_blocks( uint8_t *out, size_t outlen, const uint8_t *P,
+                                         const uint8_t *h0, uint32_t first )
+{
+  uint8_t param[32];
+  blake2s_state S;
+  size_t off, n;
+  uint32_t i = first;
+  memcpy( param, P, sizeof( param ) );
+  for( off = 0; off < outlen; off += n, ++i )
+  {
+    n = outlen - off < BLAKE2S_OUTBYTES ? outlen - off : BLAKE2S_OUTBYTES;
+    param[0] = ( uint8_t )n;
+    param[8] = ( uint8_t )i;
+    param[9] = ( uint8_t )( i >> 8 );
+    param[10] = ( uint8_t )( i >> 16 );
+    param[11] = ( uint8_t )( i >> 24 );
+    if( blake2s_init_param( &S, ( const blake2s_param * )param ) < 0 ) return -1;
+    blake2s_update( &S, h0, BLAKE2S_OUTBYTES );
+    if( blake2s_final( &S, out + off, n ) < 0 ) return -1;
+  }
+  return 0;
+}
+
+/* Bits of the x86 instruction set extensions reported by go_simd_compiled
+   and go_simd_cpu. */
+enum
+{
+  GO_SIMD_SSE2  = 1 << 0,
+  GO_SIMD_SSSE3 = 1 << 1,
+  GO_SIMD_SSE41 = 1 << 2,
+  GO_SIMD_AVX   = 1 << 3,
+  GO_SIMD_XOP   = 1 << 4,
+  GO_SIMD_AVX2  = 1 << 5
+};
+
+/* Returns the extensions the bundled sources were compiled for, or -1 for
+   the system libb2, which was compiled separately. */
+static inline int go_simd_compiled( void )
+{
+#if defined(BLAKE2_SYSTEM_LIBB2)
+  return -1;
+#else
+  int f = 0;
+#if defined(__SSE2__) || defined(__x86_64__) || defined(__amd64__)
+  f |= GO_SIMD_SSE2;
+#endif
+#if defined(__SSSE3__)
+  f |= GO_SIMD_SSSE3;
+#endif
+#if defined(__SSE4_1__)
+  f |= GO_SIMD_SSE41;
+#endif
+#if defined(__AVX__)
+  f |= GO_SIMD_AVX;
+#endif
+#if defined(__XOP__)
+  f |= GO_SIMD_XOP;
+#endif
+#if defined(__AVX2__)
+  f |= GO_SIMD_AVX2;
+#endif
+  return f;
+#endif
+}
+
+/* Returns the extensions the CPU supports. */
+static inline int go_simd_cpu( void )
+{
+  int f = 0;
+  __builtin_cpu_init();
+  if( __builtin_cpu_supports( "sse2" ) ) f |= GO_SIMD_SSE2;
+  if( __builtin_cpu_supports( "ssse3" ) ) f |= GO_SIMD_SSSE3;
+  if( __builtin_cpu_supports( "sse4.1" ) ) f |= GO_SIMD_SSE41;
+  if( __builtin_cpu_supports( "avx" ) ) f |= GO_SIMD_AVX;
+  if( __builtin_cpu_supports( "xop" ) ) f |= GO_SIMD_XOP;
+  if( __builtin_cpu_supports( "avx2" ) ) f |= GO_SIMD_AVX2;
+  return f;
+}
+
+#endif
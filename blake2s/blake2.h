@@ -12,6 +12,13 @@
    More information about the BLAKE2 hash function can be found at
    https://blake2.net.
 */
+/* The package directory is on the include path when cgo compiles its
+   generated files, so this header shadows the system one when linking
+   against libb2 (build tag system_libb2); defer to it in that case. */
+#if defined(BLAKE2_SYSTEM_LIBB2)
+#include_next <blake2.h>
+#else
+
 #ifndef BLAKE2_H
 #define BLAKE2_H
 
@@ -194,3 +201,5 @@ extern "C" {
 #endif
 
 #endif
+
+#endif /* BLAKE2_SYSTEM_LIBB2 */
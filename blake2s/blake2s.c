@@ -1,3 +1,11 @@
+//go:build !system_libb2 && cgo && !windows && !purego && !tinygo && (amd64 || 386)
+// +build !system_libb2
+// +build cgo
+// +build !windows
+// +build !purego
+// +build !tinygo
+// +build amd64 386
+
 /*
    BLAKE2 reference source code package - optimized C implementations
 
@@ -1,21 +1,79 @@
 package blake2s
 
 import (
-	// #cgo CFLAGS: -O3
-	// #include "blake2.h"
-	"C"
+	"errors"
 	"hash"
-	"unsafe"
+	"sync"
+	"time"
 )
 
-type digest struct {
+// Digest is a BLAKE2s hash, returned by New and the other constructors. It
+// implements hash.Hash, and adds the methods specific to BLAKE2s, such as
+// Finalize, WriteV and ParamBlock. The zero value is not usable; a Digest
+// is not safe for concurrent use.
+type Digest struct {
 	blockSize  int
-	state      C.blake2s_state
+	state      state
 	key        []byte
-	param      C.blake2s_param
+	param      [32]byte
 	isLastNode bool
+	leaves     *leafHasher
+	written    uint64
+	// backend is the name of the backend of state, for Stats.
+	backend string
+	// scratch holds the bytes written by WriteCopy; allocated on first
+	// use.
+	scratch *[scratchSize]byte
+	// err is the BackendError of a failed initialization or
+	// finalization, until Reset succeeds.
+	err error
+	// debug tracks misuses in blake2_debug builds.
+	debug debugState
 }
 
+// Parameter limits of BLAKE2s, in bytes.
+const (
+	// BlockSize is the size of the blocks the message is processed in.
+	BlockSize = 64
+	// MaxDigestSize is the largest digest size.
+	MaxDigestSize = 32
+	// MaxKeySize is the largest key size.
+	MaxKeySize = 32
+	// KeySize is the recommended key size, which gives keyed hashing
+	// its full 256-bit security.
+	KeySize = 32
+	// SaltSize is the size of the salt parameter.
+	SaltSize = 8
+	// PersonalSize is the size of the personalization parameter.
+	PersonalSize = 8
+)
+
+var (
+	// ErrDigestSize is returned for digest sizes outside [1,
+	// MaxDigestSize], and inner hash sizes above MaxDigestSize. Use
+	// blake2b for digests longer than 32 bytes.
+	ErrDigestSize = errors.New("blake2s: digest size out of range (use blake2b for more than 32 bytes)")
+	// ErrKeySize is returned for keys longer than MaxKeySize.
+	ErrKeySize = errors.New("blake2s: key longer than 32 bytes")
+	// ErrSaltSize is returned for salts longer than SaltSize.
+	ErrSaltSize = errors.New("blake2s: salt longer than 8 bytes")
+	// ErrPersonalSize is returned for personalizations longer than
+	// PersonalSize.
+	ErrPersonalSize = errors.New("blake2s: personalization longer than 8 bytes")
+	// ErrTreeDisabled is returned for configs with tree parameters in
+	// builds with the blake2_notree tag.
+	ErrTreeDisabled = errors.New("blake2s: tree hashing disabled by the blake2_notree build tag")
+	// ErrFinalized is returned by the Write methods and Final of a digest
+	// finalized by Final or Finalize, until it is Reset.
+	ErrFinalized = errors.New("blake2s: digest used after Final")
+	// ErrInitFailed is wrapped by the BackendError of a backend failing
+	// to initialize a state.
+	ErrInitFailed = errors.New("blake2s: backend failed to initialize the state")
+	// ErrFinalFailed is wrapped by the BackendError of a backend failing
+	// to compute a digest.
+	ErrFinalFailed = errors.New("blake2s: backend failed to finalize")
+)
+
 // Tree contains parameters for tree hashing. Each node in the tree
 // can be hashed concurrently, and incremental changes can be done in
 // a Merkle tree fashion.
@@ -35,124 +93,578 @@ type Tree struct {
 	// Offset of this node within this level of the tree. 0 for the
 	// first, leftmost, leaf, or sequential mode.
 	NodeOffset uint32
-	// Inner hash byte length, in the range [0, 64]. 0 for sequential
+	// Inner hash byte length, in the range [0, 32]. 0 for sequential
 	// mode.
 	InnerHashSize uint8
 
 	// IsLastNode indicates this node is the last, rightmost, node of
 	// a level of the tree.
 	IsLastNode bool
+
+	// HashLeaves makes the digest the root of a two-level tree that
+	// splits the written data into leaves of LeafSize bytes and hashes
+	// them itself, on several goroutines when a single Write spans many
+	// leaves. It requires Fanout 0, MaxDepth 2, NodeDepth 1 and a
+	// non-zero LeafSize and InnerHashSize; the root is the last node of
+	// its level, whatever IsLastNode says.
+	HashLeaves bool
 }
 
 // Config contains parameters for the hash function that affect its
 // output.
 type Config struct {
-	// Digest byte length, in the range [1, 64]. If 0, default size of 64 bytes is used.
+	// Digest byte length, in the range [1, 32]. If 0, default size of 32 bytes is used.
+	// For longer digests, use the blake2b package.
 	Size uint8
-	// Key is up to 64 arbitrary bytes, for keyed hashing mode. Can be nil.
+	// Key is up to 32 arbitrary bytes, for keyed hashing mode. Can be nil.
 	Key []byte
-	// Salt is up to 16 arbitrary bytes, used to randomize the hash. Can be nil.
+	// Salt is up to 8 arbitrary bytes, used to randomize the hash. Can be nil.
 	Salt []byte
-	// Personal is up to 16 arbitrary bytes, used to make the hash
+	// Personal is up to 8 arbitrary bytes, used to make the hash
 	// function unique for each application. Can be nil.
 	Personal []byte
+	// HashLongParams allows a Salt or Personal longer than its field,
+	// such as a long application label: it is replaced by its BLAKE2s
+	// digest of the field size, as PersonalFromString derives it, rather
+	// than rejected. Values that fit are used as they are.
+	HashLongParams bool
 
 	// Parameters for tree hashing. Set to nil to use default
 	// sequential mode.
 	Tree *Tree
 }
 
+// Validate checks config against the parameter limits, and checks that a
+// Tree with HashLeaves set has the parameters leaf hashing requires, as
+// Tree.Validate does. A nil config is valid.
+func (config *Config) Validate() error {
+	switch {
+	case config == nil:
+		return nil
+	case config.Size > MaxDigestSize:
+		return ErrDigestSize
+	case len(config.Key) > MaxKeySize:
+		return ErrKeySize
+	case len(config.Salt) > SaltSize && !config.HashLongParams:
+		return ErrSaltSize
+	case len(config.Personal) > PersonalSize && !config.HashLongParams:
+		return ErrPersonalSize
+	case config.Tree != nil && !treeHashing:
+		return ErrTreeDisabled
+	case config.Tree != nil && config.Tree.InnerHashSize > MaxDigestSize:
+		return ErrDigestSize
+	case config.Tree != nil && config.Tree.HashLeaves:
+		return config.Tree.validateHashLeaves()
+	}
+	return nil
+}
+
 // New returns a new custom blake2s hash.
 //
-// If config is nil, uses a 64-byte digest size.
-func New(config *Config) *digest {
-	d := &digest{
-		blockSize: 64,
-		param: C.blake2s_param{
-			digest_length: 32,
-			fanout:        1,
-			depth:         1,
-		},
+// If config is nil, uses a 32-byte digest size. It panics with the error
+// returned by config.Validate if config is invalid; in particular, BLAKE2s
+// digests are at most 32 bytes long, and the blake2b package provides
+// longer ones.
+func New(config *Config) *Digest {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	var p Params
+	p.init(config)
+	return p.New()
+}
+
+// NewUnkeyed returns a new unkeyed 256-bit BLAKE2S hash, meant to be
+// composed with crypto/hmac:
+//
+//	mac := hmac.New(blake2s.NewUnkeyed, key)
+//
+// Each call returns an independent digest; its BlockSize is the BLAKE2S
+// block size of 64 bytes and Reset restores its initial state. BLAKE2 has
+// a keyed mode of its own, which is faster than HMAC; HMAC is for
+// protocols that require it.
+func NewUnkeyed() hash.Hash {
+	return New(&Config{Size: 32})
+}
+
+// New256 returns a new 256-bit BLAKE2S hash with the given secret key. A
+// nil or empty key gives an unkeyed hash. It panics with ErrKeySize if
+// key is longer than MaxKeySize; NewKeyed returns the error instead.
+func New256(key []byte) hash.Hash {
+	return mustKeyed(&Config{Size: 32}, key)
+}
+
+// New224 is like New256, for a 224-bit hash.
+func New224(key []byte) hash.Hash {
+	return mustKeyed(&Config{Size: 28}, key)
+}
+
+// New160 is like New256, for a 160-bit hash.
+func New160(key []byte) hash.Hash {
+	return mustKeyed(&Config{Size: 20}, key)
+}
+
+// New128 is like New256, for a 128-bit hash.
+func New128(key []byte) hash.Hash {
+	return mustKeyed(&Config{Size: 16}, key)
+}
+
+// New256WithConfig returns a new BLAKE2S hash configured by config, keyed
+// with key instead of config.Key. A nil or empty key gives an unkeyed
+// hash. It panics with ErrKeySize if key is longer than MaxKeySize, and
+// with the error of config.Validate for invalid configs;
+// NewKeyedWithConfig returns them instead.
+func New256WithConfig(config *Config, key []byte) hash.Hash {
+	h, err := NewKeyedWithConfig(config, key)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// NewKeyed returns a new size-byte BLAKE2S hash with the given secret key,
+// as New256 and its variants do, but returns ErrDigestSize and ErrKeySize
+// instead of panicking, for sizes and keys that come from configuration.
+// A nil or empty key gives an unkeyed hash.
+func NewKeyed(size int, key []byte) (hash.Hash, error) {
+	if size < 1 || size > MaxDigestSize {
+		return nil, ErrDigestSize
 	}
+	return newKeyed(&Config{Size: uint8(size)}, key)
+}
+
+// NewKeyedWithConfig is like New256WithConfig, but returns the errors it
+// panics with.
+func NewKeyedWithConfig(config *Config, key []byte) (hash.Hash, error) {
+	c := Config{}
 	if config != nil {
-		if config.Size != 0 {
-			d.param.digest_length = C.uint8_t(config.Size)
+		c = *config
+	}
+	return newKeyed(&c, key)
+}
+
+func mustKeyed(config *Config, key []byte) hash.Hash {
+	h, err := newKeyed(config, key)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// newKeyed returns a hash configured by config and keyed with key, which
+// it sets in config.
+func newKeyed(config *Config, key []byte) (hash.Hash, error) {
+	if len(key) > MaxKeySize {
+		return nil, ErrKeySize
+	}
+	config.Key = key
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return New(config), nil
+}
+
+// ChainValue returns the current chaining value, the eight words h[0..7]
+// of the internal state. The pure Go and C reference backends expose it;
+// ok is false with the OpenSSL backend, and after Finalize.
+func (d *Digest) ChainValue() (h [8]uint32, ok bool) {
+	if s, ok := d.state.(rawState); ok {
+		return s.chainValue()
+	}
+	return h, false
+}
+
+// Counter returns the 64-bit message byte counter as its two words t[0]
+// (low) and t[1] (high). It only counts bytes already compressed into the
+// chaining value, not those still buffered. Like ChainValue, ok is false
+// with the OpenSSL backend, and after Finalize.
+func (d *Digest) Counter() (t [2]uint32, ok bool) {
+	if s, ok := d.state.(rawState); ok {
+		return s.counter()
+	}
+	return t, false
+}
+
+// Clone returns an independent copy of the digest, including the data
+// written so far.
+func (d *Digest) Clone() hash.Hash {
+	c := *d
+	c.state = d.state.clone()
+	c.key = append([]byte(nil), d.key...)
+	c.scratch = nil
+	if d.leaves != nil {
+		c.leaves = d.leaves.clone()
+	}
+	return &c
+}
+
+// Len returns the number of message bytes written since the digest was
+// created or last reset, not counting the key block.
+func (d *Digest) Len() uint64 {
+	return d.written
+}
+
+// ParamBlock returns the encoded 32-byte parameter block the digest was
+// initialized with.
+func (d *Digest) ParamBlock() [32]byte {
+	return d.param
+}
+
+func (d *Digest) BlockSize() int {
+	return d.blockSize
+}
+
+func (d *Digest) Size() int {
+	return int(d.param[0])
+}
+
+func (d *Digest) Reset() {
+	d.debug.enter()
+	defer d.debug.leave()
+	d.debug.reset()
+	if f, ok := d.state.(finalizedState); ok {
+		d.state = f.s
+	}
+	if err := d.state.init(&d.param, d.isLastNode); err != nil {
+		d.err = d.backendError(ErrInitFailed, err)
+		return
+	}
+	d.err = nil
+	d.writeKey(d.key)
+	if d.leaves != nil {
+		d.leaves.reset()
+	}
+	d.written = 0
+}
+
+// Finalize appends the digest to dst like Sum, but finalizes the state in
+// place where the backend allows it, saving the copy Sum makes so that
+// writing can continue; one-shot hashing pipelines do not need it. The
+// digest must be Reset before further use: until then, Write, WriteCopy,
+// WriteV and Final return ErrFinalized, and Sum, Finalize and Clone
+// panic, as they do with the BackendError of a failed backend.
+func (d *Digest) Finalize(dst []byte) []byte {
+	d.debug.enter()
+	defer d.debug.leave()
+	d.debug.sum(d)
+	dst, err := d.finalize(dst)
+	if err != nil {
+		panic(err)
+	}
+	return dst
+}
+
+func (d *Digest) finalize(dst []byte) ([]byte, error) {
+	f, ok := d.state.(finalizer)
+	if d.err != nil || !ok || d.leaves != nil {
+		var err error
+		if dst, err = d.sum(dst); err != nil {
+			return dst, err
 		}
-		if len(config.Key) > 0 {
-			// let the C library worry about the exact limit; we just
-			// worry about fitting into the variable
-			if len(config.Key) > 255 {
-				panic("blake2s key too long")
-			}
-			d.param.key_length = C.uint8_t(len(config.Key))
-			d.key = config.Key
+	} else {
+		n := len(dst)
+		dst = append(dst, make([]byte, d.Size())...)
+		if err := f.finalize(dst[n:]); err != nil {
+			d.err = d.backendError(ErrFinalFailed, err)
+			return dst[:n], d.err
 		}
-		salt := (*[C.BLAKE2S_SALTBYTES]byte)(unsafe.Pointer(&d.param.salt[0]))
-		copy(salt[:], config.Salt)
-		personal := (*[C.BLAKE2S_PERSONALBYTES]byte)(unsafe.Pointer(&d.param.personal[0]))
-		copy(personal[:], config.Personal)
-
-		if config.Tree != nil {
-			d.param.fanout = C.uint8_t(config.Tree.Fanout)
-			d.param.depth = C.uint8_t(config.Tree.MaxDepth)
-			d.param.leaf_length = C.uint32_t(config.Tree.LeafSize)
-			d.param.node_offset = C.uint32_t(config.Tree.NodeOffset)
-			d.param.node_depth = C.uint8_t(config.Tree.NodeDepth)
-			d.param.inner_length = C.uint8_t(config.Tree.InnerHashSize)
-
-			d.isLastNode = config.Tree.IsLastNode
+		if st := currentStats(); st != nil {
+			st.Finalized(d.backend)
 		}
 	}
-	d.Reset()
-	return d
+	d.state = finalizedState{d.state}
+	return dst, nil
 }
 
-// New256 returns a new 256-bit BLAKE2S hash with the given secret key.
-func New256(key []byte) hash.Hash {
-	d := New(nil)
-	if C.blake2s_init_key(&d.state, C.size_t(32), unsafe.Pointer(&key[0]), C.size_t(len(key))) < 0 {
-		panic("blake2s: unable to init key")
+// Final returns the digest of the data written, finalizing the state as
+// Finalize does. Unlike Finalize, it returns ErrFinalized instead of
+// panicking if the digest is already finalized, so that long-lived or
+// pooled digests used again without a Reset fail in a defined way, and
+// it returns the BackendError of a failed backend.
+func (d *Digest) Final() ([]byte, error) {
+	if d.finalized() {
+		return nil, ErrFinalized
 	}
-	return d
+	d.debug.enter()
+	defer d.debug.leave()
+	d.debug.sum(d)
+	return d.finalize(nil)
 }
 
-func New256WithConfig(config *Config, key []byte) hash.Hash {
-	config.Key = key
-	d := New(config)
-	if C.blake2s_init_parametrized(&d.state, &d.param, unsafe.Pointer(&key[0])) < 0 {
-		panic("blake2s: unable to init key")
+// finalized reports whether the digest has been finalized since it was
+// last Reset.
+func (d *Digest) finalized() bool {
+	_, ok := d.state.(finalizedState)
+	return ok
+}
+
+func (d *Digest) Write(buf []byte) (int, error) {
+	if d.finalized() {
+		return 0, ErrFinalized
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.debug.enter()
+	defer d.debug.leave()
+	n := len(buf)
+	d.debug.wrote(n)
+	d.written += uint64(n)
+	s := currentStats()
+	var start time.Time
+	if s != nil {
+		start = time.Now()
 	}
-	return d
+	d.write(buf)
+	if s != nil {
+		s.Hashed(d.backend, n, time.Since(start))
+	}
+	return n, nil
 }
 
-func (d *digest) BlockSize() int {
-	return d.blockSize
+// scratchSize is the size of the buffer WriteCopy copies through.
+const scratchSize = 8 * BlockSize
+
+// WriteCopy is like Write, but copies buf through an internal buffer
+// before hashing it, so that buf does not escape to the heap: passing a
+// slice to Write, which hands it to an interface or C call, forces it
+// there. Tight loops hashing small stack-allocated records with
+// WriteCopy thus do not allocate. Large buffers are better written with
+// Write, which avoids the copy.
+func (d *Digest) WriteCopy(buf []byte) (int, error) {
+	if d.finalized() {
+		return 0, ErrFinalized
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.debug.enter()
+	defer d.debug.leave()
+	n := len(buf)
+	d.debug.wrote(n)
+	d.written += uint64(n)
+	s := currentStats()
+	var start time.Time
+	if s != nil {
+		start = time.Now()
+	}
+	if d.scratch == nil {
+		d.scratch = new([scratchSize]byte)
+	}
+	for len(buf) > 0 {
+		k := copy(d.scratch[:], buf)
+		d.write(d.scratch[:k])
+		buf = buf[k:]
+	}
+	if s != nil {
+		s.Hashed(d.backend, n, time.Since(start))
+	}
+	return n, nil
 }
 
-func (d *digest) Size() int {
-	return int(d.param.digest_length)
+// WriteV writes the concatenation of bufs, as successive calls to Write
+// would, without concatenating them. Backends that can absorb several
+// buffers in a single call get them at once, which saves a cgo call per
+// buffer when messages are assembled from short parts, such as a header
+// and a body.
+func (d *Digest) WriteV(bufs ...[]byte) (int, error) {
+	if d.finalized() {
+		return 0, ErrFinalized
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.debug.enter()
+	defer d.debug.leave()
+	n := 0
+	for _, buf := range bufs {
+		n += len(buf)
+	}
+	d.debug.wrote(n)
+	d.written += uint64(n)
+	s := currentStats()
+	var start time.Time
+	if s != nil {
+		start = time.Now()
+	}
+	if v, ok := d.state.(vectorUpdater); ok && d.leaves == nil {
+		d.absorbV(v, bufs)
+	} else {
+		for _, buf := range bufs {
+			d.write(buf)
+		}
+	}
+	if s != nil {
+		s.Hashed(d.backend, n, time.Since(start))
+	}
+	return n, nil
 }
 
-func (d *digest) Reset() {
-	if C.blake2s_init_param(&d.state, &d.param) < 0 {
-		panic("blake2s: unable to reset")
+// absorbV passes bufs to v in groups of up to four buffers and maxUpdate
+// bytes, and larger buffers on their own through absorb, keeping each
+// call short as Write does.
+func (d *Digest) absorbV(v vectorUpdater, bufs [][]byte) {
+	for len(bufs) > 0 {
+		if len(bufs[0]) > maxUpdate {
+			d.absorb(bufs[0])
+			bufs = bufs[1:]
+			continue
+		}
+		var group [4][]byte
+		i, total := 0, 0
+		for i < len(group) && i < len(bufs) && total+len(bufs[i]) <= maxUpdate {
+			group[i] = bufs[i]
+			total += len(bufs[i])
+			i++
+		}
+		v.updateV(group)
+		bufs = bufs[i:]
 	}
-	if d.isLastNode {
-		d.state.last_node = C.uint8_t(1)
+}
+
+// write hashes buf, through the leaf hasher if there is one.
+func (d *Digest) write(buf []byte) {
+	if d.leaves != nil {
+		d.leaves.write(d.state, buf)
+	} else {
+		d.absorb(buf)
 	}
 }
 
-func (d *digest) Write(buf []byte) (int, error) {
+// absorb passes buf to the state, in pieces of at most maxUpdate bytes.
+func (d *Digest) absorb(buf []byte) {
+	for len(buf) > maxUpdate {
+		d.state.update(buf[:maxUpdate])
+		buf = buf[maxUpdate:]
+	}
 	if len(buf) > 0 {
-		C.blake2s_update(&d.state, unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+		d.state.update(buf)
+	}
+}
+
+// maxUpdate bounds the bytes passed to the backend at once. A goroutine
+// in a C call cannot be preempted, and splitting huge writes keeps each
+// call short on 32-bit hosts, where hashing gigabytes takes seconds.
+var maxUpdate = 1 << 28
+
+// ResetWithKey resets the digest to its initial state, keyed with key
+// instead of the key it was created with, so that it can be reused for
+// another MAC key. A nil or empty key makes it unkeyed. The other
+// parameters are kept.
+func (d *Digest) ResetWithKey(key []byte) error {
+	if len(key) > MaxKeySize {
+		return ErrKeySize
+	}
+	d.param[1] = uint8(len(key))
+	d.key = append(d.key[:0], key...)
+	if d.leaves != nil {
+		d.leaves = newLeafHasher(&d.param, d.key, d.backend)
+	}
+	d.Reset()
+	return nil
+}
+
+// Sum appends the digest to buf. It panics with the BackendError of the
+// digest if the backend failed; Final returns it instead.
+func (d *Digest) Sum(buf []byte) []byte {
+	d.debug.enter()
+	defer d.debug.leave()
+	d.debug.sum(d)
+	buf, err := d.sum(buf)
+	if err != nil {
+		panic(err)
 	}
-	return len(buf), nil
+	return buf
 }
 
-func (d *digest) Sum(buf []byte) []byte {
+func (d *Digest) sum(buf []byte) ([]byte, error) {
+	if d.err != nil {
+		return buf, d.err
+	}
 	digest := make([]byte, d.Size())
-	// Make a copy of d.state so that caller can keep writing and summing.
 	s := d.state
-	C.blake2s_final(&s, unsafe.Pointer(&digest[0]), C.size_t(d.Size()))
-	return append(buf, digest...)
+	if d.leaves != nil {
+		s = s.clone()
+		d.leaves.finish(s)
+	}
+	// final works on a copy of the state so that caller can keep writing
+	// and summing.
+	if err := s.final(digest); err != nil {
+		return buf, d.backendError(ErrFinalFailed, err)
+	}
+	if st := currentStats(); st != nil {
+		st.Finalized(d.backend)
+	}
+	return append(buf, digest...), nil
+}
+
+// writeKey absorbs key, zero-padded to a full block, as keyed hashing
+// requires after initialization. Reset calls it with the digest's key.
+func (d *Digest) writeKey(key []byte) {
+	if len(key) == 0 {
+		return
+	}
+	var block [BlockSize]byte
+	copy(block[:], key)
+	d.state.update(block[:])
+	for i := range block {
+		block[i] = 0
+	}
+}
+
+// sumScratch holds the buffers sum passes to the backend, which would
+// escape to the heap as locals, so that one-shot sums do not allocate.
+type sumScratch struct {
+	param [32]byte
+	out   [MaxDigestSize]byte
+}
+
+var sumScratches = sync.Pool{New: func() interface{} { return new(sumScratch) }}
+
+// sum computes a one-shot digest of in, keyed with key and personalized
+// with personal if they are not empty. Backends that can do it in a
+// single call compute it without a digest, provided in is short enough
+// for one call, as Write keeps them.
+func sum(out, in, key, personal []byte) error {
+	if len(out) == 0 || len(out) > MaxDigestSize {
+		return ErrDigestSize
+	}
+	if len(key) > MaxKeySize {
+		return ErrKeySize
+	}
+	if len(personal) > PersonalSize {
+		return ErrPersonalSize
+	}
+	o, ok := defaultBackend.(oneShotBackend)
+	if !ok || len(in) > maxUpdate {
+		d := New(&Config{Size: uint8(len(out)), Key: key, Personal: personal})
+		d.Write(in)
+		copy(out, d.Sum(nil))
+		return nil
+	}
+	sc := sumScratches.Get().(*sumScratch)
+	defer sumScratches.Put(sc)
+	sc.param = [32]byte{}
+	sc.param[0] = uint8(len(out)) // digest length
+	sc.param[1] = uint8(len(key)) // key length
+	sc.param[2] = 1               // fanout
+	sc.param[3] = 1               // depth
+	copy(sc.param[24:32], personal)
+	st := currentStats()
+	var start time.Time
+	if st != nil {
+		start = time.Now()
+	}
+	err := o.sumOnce(sc.out[:len(out)], &sc.param, key, in)
+	copy(out, sc.out[:len(out)])
+	sc.out = [MaxDigestSize]byte{}
+	if err != nil {
+		return err
+	}
+	if st != nil {
+		name := defaultBackend.name()
+		st.Hashed(name, len(in), time.Since(start))
+		st.Finalized(name)
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package blake2s
 
 import (
+	"bytes"
+	"hash"
 	"log"
 	"testing"
 )
@@ -30,3 +32,260 @@ func TestExampleNewKeyedBlake2S(t *testing.T) {
 	h.Write([]byte("foo"))
 	log.Printf("%x", h.Sum(nil))
 }
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStateAccessors(t *testing.T) {
+	h := New(&Config{Size: 16, Personal: []byte("personal")})
+	p := h.ParamBlock()
+	if p[0] != 16 || p[2] != 1 || p[3] != 1 || string(p[24:32]) != "personal" {
+		t.Errorf("bad parameter block: %X", p)
+	}
+	if cv, ok := h.ChainValue(); !ok || cv[0] != 0x6A09E667^0x01010010 {
+		t.Errorf("bad chaining value: %X", cv)
+	}
+	h.Write(make([]byte, 65))
+	if c, ok := h.Counter(); !ok || c != [2]uint32{64, 0} {
+		t.Errorf("bad counter: %v", c)
+	}
+	h.Finalize(nil)
+	if _, ok := h.ChainValue(); ok {
+		t.Error("chaining value exposed after Finalize")
+	}
+}
+
+func TestTruncatedKeyed(t *testing.T) {
+	key := []byte("Squeamish Ossifrage")
+	for _, c := range []struct {
+		new  func([]byte) hash.Hash
+		size int
+	}{{New128, 16}, {New160, 20}, {New224, 28}, {New256, 32}} {
+		h := c.new(key)
+		h.Write([]byte("foo"))
+		want := make([]byte, c.size)
+		sum(want, []byte("foo"), key, nil)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%d-byte digest: got %x, want %x", c.size, got, want)
+		}
+		if k, err := NewKeyed(c.size, key); err != nil || !bytes.Equal(sumOf(k, "foo"), want) {
+			t.Errorf("NewKeyed(%d) differs: %v", c.size, err)
+		}
+		if _, err := NewKeyed(c.size, make([]byte, 33)); err != ErrKeySize {
+			t.Errorf("%d-byte digest with a 33-byte key: got %v, want ErrKeySize", c.size, err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != ErrKeySize {
+					t.Errorf("%d-byte digest with a 33-byte key: panic %v, want ErrKeySize", c.size, r)
+				}
+			}()
+			c.new(make([]byte, 33))
+		}()
+	}
+	for _, size := range []int{0, 33} {
+		if _, err := NewKeyed(size, nil); err != ErrDigestSize {
+			t.Errorf("NewKeyed(%d): got %v, want ErrDigestSize", size, err)
+		}
+	}
+	if _, err := NewKeyedWithConfig(&Config{Salt: make([]byte, 9)}, key); err != ErrSaltSize {
+		t.Errorf("NewKeyedWithConfig with a long salt: got %v, want ErrSaltSize", err)
+	}
+}
+
+func sumOf(h hash.Hash, s string) []byte {
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+func TestNew256Unkeyed(t *testing.T) {
+	want := New(nil)
+	want.Write([]byte("foo"))
+	for _, key := range [][]byte{nil, {}} {
+		h := New256(key)
+		h.Write([]byte("foo"))
+		if !bytes.Equal(h.Sum(nil), want.Sum(nil)) {
+			t.Errorf("New256(%#v) is not the unkeyed hash", key)
+		}
+	}
+}
+
+func TestKeyedReset(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("one two three"))
+	want := h.Sum(nil)
+
+	h.Reset()
+	h.Write([]byte("one two three"))
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("after Reset: got %X, want %X", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		config *Config
+		err    error
+	}{
+		{nil, nil},
+		{&Config{Size: MaxDigestSize, Key: make([]byte, MaxKeySize), Salt: make([]byte, SaltSize), Personal: make([]byte, PersonalSize)}, nil},
+		{&Config{Size: MaxDigestSize + 1}, ErrDigestSize},
+		{&Config{Key: make([]byte, MaxKeySize+1)}, ErrKeySize},
+		{&Config{Salt: make([]byte, SaltSize+1)}, ErrSaltSize},
+		{&Config{Personal: make([]byte, PersonalSize+1)}, ErrPersonalSize},
+	} {
+		if err := c.config.Validate(); err != c.err {
+			t.Errorf("%+v: got %v, want %v", c.config, err, c.err)
+		}
+	}
+
+	defer func() {
+		if err := recover(); err != ErrSaltSize {
+			t.Errorf("New with a long salt panicked with %v, want ErrSaltSize", err)
+		}
+	}()
+	New(&Config{Salt: make([]byte, SaltSize+1)})
+}
+
+func TestDefaultSize(t *testing.T) {
+	if n := New(nil).Size(); n != 32 {
+		t.Errorf("default digest size %d, want 32", n)
+	}
+	if err := (&Config{Size: 33}).Validate(); err != ErrDigestSize {
+		t.Errorf("33-byte digest: got %v, want ErrDigestSize", err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write(make([]byte, 1000))
+	h.Write([]byte("abc"))
+	if n := h.Len(); n != 1003 {
+		t.Errorf("Len() = %d, want 1003", n)
+	}
+	if n := h.Clone().(*Digest).Len(); n != 1003 {
+		t.Errorf("clone: Len() = %d, want 1003", n)
+	}
+	h.Reset()
+	if n := h.Len(); n != 0 {
+		t.Errorf("after Reset: Len() = %d, want 0", n)
+	}
+}
+
+func TestLargeWrite(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	want := New(nil)
+	want.Write(data)
+
+	defer func(n int) { maxUpdate = n }(maxUpdate)
+	maxUpdate = 100
+	d := New(nil)
+	if n, err := d.Write(data); n != len(data) || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if !bytes.Equal(d.Sum(nil), want.Sum(nil)) || d.Len() != uint64(len(data)) {
+		t.Errorf("split write: %x, want %x", d.Sum(nil), want.Sum(nil))
+	}
+}
+
+// TestCounterCarry checks that the low word of the byte counter carries
+// into the high one in every backend, as for streams longer than the low
+// word can count, restoring a state instead of hashing such a stream.
+func TestCounterCarry(t *testing.T) {
+	param := New(nil).param
+	data := make([]byte, 3*BlockSize)
+	var want []byte
+	for name, b := range backends {
+		s := b.newState()
+		if err := s.init(&param, false); err != nil {
+			t.Fatal(err)
+		}
+		saved, ok := s.(savableState)
+		if ok {
+			_, ok = saved.pending()
+		}
+		if !ok {
+			continue
+		}
+		h, _ := saved.chainValue()
+		saved.restore(h, [2]uint32{^uint32(0) - BlockSize + 1, 0}, nil)
+		saved.update(data)
+		if c, _ := saved.counter(); c != [2]uint32{BlockSize, 1} {
+			t.Errorf("%s: counter %d", name, c)
+		}
+		sum := make([]byte, MaxDigestSize)
+		if err := saved.final(sum); err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want = sum
+		} else if !bytes.Equal(sum, want) {
+			t.Errorf("%s: digest %x, want %x", name, sum, want)
+		}
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	for _, cfg := range []*Config{nil, {Size: 20, Key: []byte("key")}} {
+		d := New(cfg)
+		d.Write([]byte("hello, world"))
+		want := d.Sum([]byte("prefix"))
+		if got := d.Finalize([]byte("prefix")); !bytes.Equal(got, want) {
+			t.Errorf("Finalize = %x, want %x", got, want)
+		}
+		if n, err := d.Write([]byte("more")); n != 0 || err != ErrFinalized {
+			t.Errorf("Write after Finalize = %d, %v, want ErrFinalized", n, err)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Sum after Finalize did not panic")
+				}
+			}()
+			d.Sum(nil)
+		}()
+
+		d.Reset()
+		d.Write([]byte("hello, world"))
+		if got := d.Finalize(nil); !bytes.Equal(got, want[len("prefix"):]) {
+			t.Errorf("Finalize after Reset = %x, want %x", got, want[len("prefix"):])
+		}
+	}
+}
+
+func TestFinal(t *testing.T) {
+	d := New(nil)
+	d.Write([]byte("hello, world"))
+	want := d.Sum(nil)
+	got, err := d.Final()
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("Final = %x, %v, want %x", got, err, want)
+	}
+	if _, err := d.Final(); err != ErrFinalized {
+		t.Errorf("second Final: %v, want ErrFinalized", err)
+	}
+	for name, write := range map[string]func() (int, error){
+		"Write":     func() (int, error) { return d.Write([]byte("more")) },
+		"WriteCopy": func() (int, error) { return d.WriteCopy([]byte("more")) },
+		"WriteV":    func() (int, error) { return d.WriteV([]byte("mo"), []byte("re")) },
+	} {
+		if n, err := write(); n != 0 || err != ErrFinalized {
+			t.Errorf("%s after Final = %d, %v, want ErrFinalized", name, n, err)
+		}
+	}
+	if d.Len() != 12 {
+		t.Errorf("Len after rejected writes = %d, want 12", d.Len())
+	}
+
+	d.Reset()
+	d.Write([]byte("hello, world"))
+	if got, err := d.Final(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("Final after Reset = %x, %v, want %x", got, err, want)
+	}
+}
@@ -0,0 +1,36 @@
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckpointWriter(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	w := NewCheckpointWriter(nil, 300)
+	w.Write(data[:10])
+	w.Write(data[10:650])
+	w.Write(data[650:])
+
+	cps := w.Checkpoints()
+	if len(cps) != 3 {
+		t.Fatalf("got %d checkpoints, want 3", len(cps))
+	}
+	for i, cp := range cps {
+		h := New(nil)
+		h.Write(data[:(i+1)*300])
+		if !bytes.Equal(cp, h.Sum(nil)) {
+			t.Errorf("checkpoint %d mismatch", i)
+		}
+	}
+
+	h := New(nil)
+	h.Write(data)
+	if !bytes.Equal(w.Sum(nil), h.Sum(nil)) {
+		t.Error("final sum mismatch")
+	}
+}
@@ -0,0 +1,23 @@
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	for _, config := range append([]*Config{nil, {Key: []byte("key")}}, treeConfigs...) {
+		h := New(config)
+		h.Write([]byte("one two "))
+		c := h.Clone()
+		h.Write([]byte("three"))
+		c.Write([]byte("three"))
+		if !bytes.Equal(c.Sum(nil), h.Sum(nil)) {
+			t.Errorf("config %+v: clone digest differs", config)
+		}
+		c.Write([]byte("four"))
+		if bytes.Equal(c.Sum(nil), h.Sum(nil)) {
+			t.Errorf("config %+v: clone shares state with the original", config)
+		}
+	}
+}
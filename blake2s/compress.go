@@ -0,0 +1,13 @@
+package blake2s
+
+// F is the BLAKE2s compression function, for building custom modes and
+// computing test vectors. It compresses the message block m, as 16
+// little-endian words, into the chaining value h, with the byte counter
+// t; f sets the final block flag. The last node flag is left clear.
+func F(h *[8]uint32, m *[16]uint32, t uint64, f bool) {
+	var f0 uint32
+	if f {
+		f0 = ^uint32(0)
+	}
+	compressWords(h, m, uint32(t), uint32(t>>32), f0, 0, uint32(len(sigma)))
+}
@@ -0,0 +1,24 @@
+package blake2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestF(t *testing.T) {
+	// An unkeyed 32-byte hash of "abc" is a single final block.
+	h := iv
+	h[0] ^= 0x01010020
+	m := [16]uint32{0x636261}
+	F(&h, &m, 3, true)
+
+	got := make([]byte, 32)
+	for i, w := range h {
+		binary.LittleEndian.PutUint32(got[4*i:], w)
+	}
+	want := Sum256([]byte("abc"))
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("F = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package blake2s
+
+import (
+	"hash"
+	"sync"
+)
+
+// Concurrent is a digest that is safe for concurrent use by multiple
+// goroutines. Each Write is absorbed atomically, but writes from
+// different goroutines are absorbed in the order they take the lock, so
+// the digest is only deterministic if the callers order their writes.
+type Concurrent struct {
+	mu sync.Mutex
+	d  *Digest
+}
+
+// NewConcurrent returns a new hash configured by config, as with New,
+// that is safe for concurrent use.
+func NewConcurrent(config *Config) *Concurrent {
+	return &Concurrent{d: New(config)}
+}
+
+func (c *Concurrent) Write(buf []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.d.Write(buf)
+}
+
+func (c *Concurrent) Sum(buf []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.d.Sum(buf)
+}
+
+func (c *Concurrent) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.d.Reset()
+}
+
+// Len returns the number of message bytes written since the digest was
+// created or last reset.
+func (c *Concurrent) Len() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.d.Len()
+}
+
+func (c *Concurrent) Size() int {
+	return c.d.Size()
+}
+
+func (c *Concurrent) BlockSize() int {
+	return c.d.BlockSize()
+}
+
+// Clone returns an independent copy of the digest, itself safe for
+// concurrent use.
+func (c *Concurrent) Clone() hash.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &Concurrent{d: c.d.Clone().(*Digest)}
+}
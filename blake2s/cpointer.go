@@ -0,0 +1,63 @@
+package blake2s
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrCPointer is returned by WriteCPointer and SumIntoCPointer for
+// negative lengths, nil pointers to a non-empty buffer, and buffers
+// shorter than the digest.
+var ErrCPointer = errors.New("blake2s: invalid C buffer")
+
+// maxCBuffer is the longest buffer viewed as a single slice, which fits
+// the address space of 32-bit platforms; longer ones are hashed in parts.
+const maxCBuffer = 1 << 30
+
+// cBuffer returns the n bytes at ptr as a slice, without copying them.
+func cBuffer(ptr unsafe.Pointer, n int) []byte {
+	return (*[maxCBuffer]byte)(ptr)[:n:n]
+}
+
+// WriteCPointer writes the n bytes at ptr, typically memory allocated by
+// a C library, as Write would, hashing them in place: programs calling
+// into C need not copy such buffers into Go memory, nor build slices over
+// them, first.
+//
+// The n bytes at ptr must stay allocated, and must not be modified, until
+// WriteCPointer returns; it keeps no reference to them afterwards. Builds
+// with the blake2_debug tag check that they did not change meanwhile, as
+// another thread of the C library writing to them would make, and report
+// ErrCBufferChanged to the misuse handler otherwise.
+func (d *Digest) WriteCPointer(ptr unsafe.Pointer, n int) (int, error) {
+	if n < 0 || ptr == nil && n > 0 {
+		return 0, ErrCPointer
+	}
+	written := 0
+	for written < n {
+		k := n - written
+		if k > maxCBuffer {
+			k = maxCBuffer
+		}
+		b := cBuffer(unsafe.Pointer(uintptr(ptr)+uintptr(written)), k)
+		d.debug.borrow(b)
+		m, err := d.Write(b)
+		d.debug.giveBack(b)
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// SumIntoCPointer writes the digest, as Sum computes it, to the n bytes at
+// ptr, which must hold at least Size bytes, and returns its length. Like
+// Sum, it panics with the BackendError of a failed backend.
+func (d *Digest) SumIntoCPointer(ptr unsafe.Pointer, n int) (int, error) {
+	if ptr == nil || n < d.Size() {
+		return 0, ErrCPointer
+	}
+	b := cBuffer(ptr, d.Size())
+	return len(d.Sum(b[:0])), nil
+}
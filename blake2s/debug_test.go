@@ -0,0 +1,74 @@
+//go:build blake2_debug
+// +build blake2_debug
+
+package blake2s
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestMisuse(t *testing.T) {
+	var mu sync.Mutex
+	var got []error
+	SetMisuseHandler(func(m *Misuse) {
+		mu.Lock()
+		got = append(got, m.Err)
+		mu.Unlock()
+		if len(m.Stack) == 0 {
+			t.Error("misuse without stack")
+		}
+	})
+	defer SetMisuseHandler(nil)
+	expect := func(name string, want ...error) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if len(got) != len(want) {
+			t.Errorf("%s: misuses %v, want %v", name, got, want)
+		}
+		for i := range want {
+			if i < len(got) && !errors.Is(got[i], want[i]) {
+				t.Errorf("%s: misuse %v, want %v", name, got[i], want[i])
+			}
+		}
+		got = nil
+	}
+
+	d := New(nil)
+	d.Write([]byte("message"))
+	d.Sum(nil)
+	d.Reset()
+	d.Write([]byte("next"))
+	d.Sum(nil)
+	expect("proper use")
+
+	d.Write([]byte("more"))
+	d.Write([]byte("more"))
+	expect("write after Sum", ErrWriteAfterSum)
+
+	m := New(&Config{Key: []byte("key")})
+	m.Sum(nil)
+	expect("empty MAC", ErrEmptyMAC)
+	m.Write([]byte("x"))
+	m.Final()
+	expect("MAC after Sum", ErrWriteAfterSum)
+
+	d.debug.enter()
+	d.Write([]byte("x"))
+	d.debug.leave()
+	expect("concurrent use", ErrConcurrentUse)
+
+	// A C buffer changing while hashed, simulated between the write and
+	// the check.
+	buf := []byte("C buffer")
+	d.Reset()
+	d.WriteCPointer(unsafe.Pointer(&buf[0]), len(buf))
+	expect("unchanged C buffer")
+	d.debug.borrow(buf)
+	buf[0]++
+	d.debug.giveBack(buf)
+	expect("changed C buffer", ErrCBufferChanged)
+}
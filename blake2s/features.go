@@ -0,0 +1,131 @@
+package blake2s
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Features describes the implementation new digests use, and why a
+// faster one is not, so that applications can explain performance
+// differences between hosts.
+type Features struct {
+	// Backend is the name of the implementation used by new digests.
+	Backend string
+	// Backends are the names of the implementations in this build.
+	Backends []string
+	// SIMD lists the x86 extensions the bundled C sources were compiled
+	// for, such as "sse2" or "avx", when they are the Backend; the pure
+	// Go implementation uses none, and those of OpenSSL and of the system
+	// libb2 are unknown.
+	SIMD []string
+	// CPU lists the extensions of the same set that the CPU supports,
+	// when they can be detected, which needs cgo on x86.
+	CPU []string
+	// Notes explain the choice of Backend and SIMD, such as a backend
+	// missing from the build, an environment override or a CPU with
+	// extensions the C sources were not compiled for.
+	Notes []string
+}
+
+// simdNames are the names of the bits of the extension sets of the C
+// sources.
+var simdNames = []string{"sse2", "ssse3", "sse4.1", "avx", "xop", "avx2"}
+
+// simdUsed are the extensions the C sources have code paths for; they
+// have none for AVX2.
+const simdUsed = 1<<5 - 1
+
+func simdList(bits int) []string {
+	var l []string
+	for i, name := range simdNames {
+		if bits&(1<<i) != 0 {
+			l = append(l, name)
+		}
+	}
+	return l
+}
+
+// simdBackend is implemented by backends that can report the x86
+// extensions they use.
+type simdBackend interface {
+	// simd returns the bit sets of the extensions the backend was
+	// compiled for, or -1 if unknown, and of those the CPU supports.
+	simd() (compiled, cpu int)
+}
+
+// ActiveFeatures returns the Features of new digests.
+func ActiveFeatures() Features {
+	f := Features{Backend: Backend(), Backends: Backends()}
+	if _, ok := backends["cgo-ref"]; !ok {
+		f.Notes = append(f.Notes, "cgo-ref not built: "+noRefReason())
+	}
+	if env := os.Getenv(EnvBackend); env != "" {
+		if _, ok := backends[env]; !ok {
+			f.Notes = append(f.Notes, fmt.Sprintf("%s=%s ignored: not in this build", EnvBackend, env))
+		} else if !backendSet {
+			f.Notes = append(f.Notes, fmt.Sprintf("%s selected by %s", env, EnvBackend))
+		}
+	}
+	if backendSet {
+		f.Notes = append(f.Notes, f.Backend+" selected by SetBackend")
+	}
+
+	var cpu int
+	if b, ok := backends["cgo-ref"].(simdBackend); ok {
+		var compiled int
+		compiled, cpu = b.simd()
+		f.CPU = simdList(cpu)
+		if f.Backend == "cgo-ref" {
+			if compiled < 0 {
+				f.Notes = append(f.Notes, "the system libb2 was compiled separately; its extensions are unknown")
+			} else {
+				f.SIMD = simdList(compiled)
+				if missing := simdList(cpu &^ compiled & simdUsed); len(missing) > 0 {
+					f.Notes = append(f.Notes, fmt.Sprintf("the CPU supports %s, which the C sources were not compiled for; rebuild with CGO_CFLAGS=-march=native to use them",
+						strings.Join(missing, ", ")))
+				}
+			}
+		}
+	}
+	switch f.Backend {
+	case "pure-go":
+		f.Notes = append(f.Notes, "the pure Go implementation uses no SIMD extensions")
+	case "openssl":
+		f.Notes = append(f.Notes, "OpenSSL selects its own code paths at run time")
+	}
+	return f
+}
+
+// noRefReason explains why the bundled C sources are not in the build,
+// in the order of the build constraints of generic_default.go.
+func noRefReason() string {
+	switch {
+	case !cgoEnabled:
+		return "cgo is disabled"
+	case runtime.GOOS == "windows":
+		return "the C sources are not built on Windows"
+	case runtime.GOARCH != "amd64" && runtime.GOARCH != "386":
+		return "the C sources need x86 SSE2, and the architecture is " + runtime.GOARCH
+	}
+	return "built with the purego or tinygo tag"
+}
+
+// String returns a one-line summary of f, for logs and support reports.
+func (f Features) String() string {
+	var b strings.Builder
+	b.WriteString(f.Backend)
+	if len(f.SIMD) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(f.SIMD, ", "))
+	}
+	fmt.Fprintf(&b, "; available: %s", strings.Join(f.Backends, ", "))
+	if len(f.CPU) > 0 {
+		fmt.Fprintf(&b, "; CPU: %s", strings.Join(f.CPU, ", "))
+	}
+	for _, n := range f.Notes {
+		b.WriteString("; ")
+		b.WriteString(n)
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package blake2s
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestActiveFeatures(t *testing.T) {
+	saved, savedSet := defaultBackend, backendSet
+	defer func() { defaultBackend, backendSet = saved, savedSet }()
+	defer os.Setenv(EnvBackend, os.Getenv(EnvBackend))
+	os.Setenv(EnvBackend, "")
+	backendSet = false
+
+	f := ActiveFeatures()
+	if f.Backend != Backend() || len(f.Backends) != len(Backends()) {
+		t.Errorf("ActiveFeatures() = %+v", f)
+	}
+	if _, ok := backends["cgo-ref"]; ok != (len(f.CPU) > 0) {
+		t.Errorf("CPU features %v with cgo-ref built: %v", f.CPU, ok)
+	}
+	if Backend() == "cgo-ref" && len(f.SIMD) == 0 && !strings.Contains(f.String(), "libb2") {
+		t.Errorf("cgo-ref without SIMD: %v", f)
+	}
+
+	if err := SetBackend("pure-go"); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(EnvBackend, "no-such-backend")
+	f = ActiveFeatures()
+	if f.SIMD != nil {
+		t.Errorf("pure Go SIMD = %v", f.SIMD)
+	}
+	s := f.String()
+	for _, want := range []string{"pure-go", "selected by SetBackend", EnvBackend + "=no-such-backend ignored"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q does not mention %q", s, want)
+		}
+	}
+}
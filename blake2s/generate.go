@@ -0,0 +1,3 @@
+package blake2s
+
+//go:generate go run ../blake2b/gen_sums.go -pkg blake2s -bits 128,160,224,256
@@ -0,0 +1,197 @@
+package blake2s
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// genericBackend is the pure Go implementation, used where the bundled C
+// sources cannot be built.
+type genericBackend struct{}
+
+func (genericBackend) name() string { return "pure-go" }
+
+func (genericBackend) newState() state {
+	return new(genericState)
+}
+
+var iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var sigma = [10][16]uint8{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// genericState follows the reference implementation, including keeping
+// the last block buffered until more data arrives, so its chaining value
+// and counter match those of the C backend at every point.
+type genericState struct {
+	h        [8]uint32
+	t        [2]uint32
+	buf      [BlockSize]byte
+	n        int
+	outlen   int
+	lastNode bool
+	// rounds, if not zero, reduces the number of rounds; see
+	// NewReducedRounds.
+	rounds int
+}
+
+func (g *genericState) init(param *[32]byte, lastNode bool) error {
+	if param[0] == 0 || param[0] > MaxDigestSize || param[1] > MaxDigestSize {
+		return errors.New("blake2s: invalid parameters")
+	}
+	*g = genericState{outlen: int(param[0]), lastNode: lastNode, rounds: g.rounds}
+	for i := range g.h {
+		g.h[i] = iv[i] ^ binary.LittleEndian.Uint32(param[4*i:])
+	}
+	return nil
+}
+
+func (g *genericState) update(buf []byte) {
+	if fill := BlockSize - g.n; len(buf) > fill {
+		copy(g.buf[g.n:], buf[:fill])
+		g.increment(BlockSize)
+		compress(&g.h, &g.t, 0, 0, g.rounds, g.buf[:])
+		g.n = 0
+		buf = buf[fill:]
+		for len(buf) > BlockSize {
+			g.increment(BlockSize)
+			compress(&g.h, &g.t, 0, 0, g.rounds, buf[:BlockSize])
+			buf = buf[BlockSize:]
+		}
+	}
+	g.n += copy(g.buf[g.n:], buf)
+}
+
+func (g *genericState) increment(n uint32) {
+	var carry uint32
+	g.t[0], carry = bits.Add32(g.t[0], n, 0)
+	g.t[1] += carry
+}
+
+func (g *genericState) final(out []byte) error {
+	// Work on a copy so that the caller can keep writing.
+	c := *g
+	return c.finalize(out)
+}
+
+func (c *genericState) finalize(out []byte) error {
+	if len(out) != c.outlen {
+		return errors.New("blake2s: invalid output length")
+	}
+	for i := c.n; i < len(c.buf); i++ {
+		c.buf[i] = 0
+	}
+	c.increment(uint32(c.n))
+	var f1 uint32
+	if c.lastNode {
+		f1 = ^uint32(0)
+	}
+	compress(&c.h, &c.t, ^uint32(0), f1, c.rounds, c.buf[:])
+
+	var sum [MaxDigestSize]byte
+	for i, v := range c.h {
+		binary.LittleEndian.PutUint32(sum[4*i:], v)
+	}
+	copy(out, sum[:])
+	return nil
+}
+
+func (g *genericState) sumRecords(out, data []byte, stride int) error {
+	for i := 0; len(data) > 0; i++ {
+		c := *g
+		c.update(data[:stride])
+		if err := c.finalize(out[i*c.outlen : (i+1)*c.outlen]); err != nil {
+			return err
+		}
+		data = data[stride:]
+	}
+	return nil
+}
+
+func (g *genericState) clone() state {
+	c := *g
+	return &c
+}
+
+func (g *genericState) chainValue() ([8]uint32, bool) {
+	return g.h, true
+}
+
+func (g *genericState) counter() ([2]uint32, bool) {
+	return g.t, true
+}
+
+func (g *genericState) pending() ([]byte, bool) {
+	// The number of rounds is not part of the saved state.
+	if g.rounds != 0 {
+		return nil, false
+	}
+	return append([]byte(nil), g.buf[:g.n]...), true
+}
+
+func (g *genericState) restore(h [8]uint32, t [2]uint32, pending []byte) {
+	g.h, g.t, g.n = h, t, 0
+	g.update(pending)
+}
+
+// compress applies the BLAKE2s compression function to the block m, with
+// finalization flags f0 and f1. If rounds is zero, it computes all rounds.
+func compress(h *[8]uint32, t *[2]uint32, f0, f1 uint32, rounds int, m []byte) {
+	var w [16]uint32
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(m[4*i:])
+	}
+	if rounds == 0 {
+		rounds = len(sigma)
+	}
+	compressWords(h, &w, t[0], t[1], f0, f1, uint32(rounds))
+}
+
+// compressWords is compress with the message block already decoded into
+// words, computing exactly rounds rounds. Rounds past the tenth reuse the
+// rows of sigma from the first.
+func compressWords(h *[8]uint32, w *[16]uint32, t0, t1, f0, f1 uint32, rounds uint32) {
+	v := [16]uint32{
+		h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7],
+		iv[0], iv[1], iv[2], iv[3], iv[4] ^ t0, iv[5] ^ t1, iv[6] ^ f0, iv[7] ^ f1,
+	}
+	for r := uint32(0); r < rounds; r++ {
+		s := &sigma[r%10]
+		mix(&v, 0, 4, 8, 12, w[s[0]], w[s[1]])
+		mix(&v, 1, 5, 9, 13, w[s[2]], w[s[3]])
+		mix(&v, 2, 6, 10, 14, w[s[4]], w[s[5]])
+		mix(&v, 3, 7, 11, 15, w[s[6]], w[s[7]])
+		mix(&v, 0, 5, 10, 15, w[s[8]], w[s[9]])
+		mix(&v, 1, 6, 11, 12, w[s[10]], w[s[11]])
+		mix(&v, 2, 7, 8, 13, w[s[12]], w[s[13]])
+		mix(&v, 3, 4, 9, 14, w[s[14]], w[s[15]])
+	}
+	for i := range h {
+		h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+func mix(v *[16]uint32, a, b, c, d int, x, y uint32) {
+	v[a] += v[b] + x
+	v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+	v[a] += v[b] + y
+	v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+}
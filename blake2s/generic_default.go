@@ -0,0 +1,11 @@
+//go:build !cgo || windows || purego || tinygo || !(amd64 || 386)
+// +build !cgo windows purego tinygo !amd64,!386
+
+package blake2s
+
+// The bundled C sources require SSE2 and a POSIX toolchain. Without cgo,
+// which includes js/wasm and wasip1, with TinyGo, on Windows and on other
+// architectures than x86, including big-endian ones, the pure Go
+// implementation is the default. The purego build tag selects it
+// everywhere.
+var defaultBackend backend = genericBackend{}
@@ -0,0 +1,87 @@
+package blake2s
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"testing"
+)
+
+// refHMAC computes HMAC as defined in RFC 2104.
+func refHMAC(key, msg []byte) []byte {
+	const blockSize = 64
+	if len(key) > blockSize {
+		h := NewUnkeyed()
+		h.Write(key)
+		key = h.Sum(nil)
+	}
+	ipad := make([]byte, blockSize)
+	opad := make([]byte, blockSize)
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	inner := NewUnkeyed()
+	inner.Write(ipad)
+	inner.Write(msg)
+	outer := NewUnkeyed()
+	outer.Write(opad)
+	outer.Write(inner.Sum(nil))
+	return outer.Sum(nil)
+}
+
+func TestHMAC(t *testing.T) {
+	msg := []byte("one two three")
+	for _, key := range [][]byte{[]byte("key"), bytes.Repeat([]byte("k"), 200)} {
+		want := refHMAC(key, msg)
+		mac := hmac.New(NewUnkeyed, key)
+		if mac.BlockSize() != 64 {
+			t.Errorf("BlockSize() = %d, want 64", mac.BlockSize())
+		}
+		for i := 0; i < 2; i++ {
+			mac.Write(msg)
+			if got := mac.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("key of %d bytes, run %d: got %X, want %X", len(key), i, got, want)
+			}
+			mac.Reset()
+		}
+	}
+}
+
+func TestNewCopiesKey(t *testing.T) {
+	key := []byte("key")
+	h := New(&Config{Key: key})
+	key[0] = 'K'
+	if string(h.key) != "key" {
+		t.Error("digest shares the key slice with its config")
+	}
+}
+
+func TestNew256WithConfigKeepsConfig(t *testing.T) {
+	config := &Config{Personal: []byte("personal")}
+	New256WithConfig(config, []byte("key"))
+	if config.Key != nil {
+		t.Error("New256WithConfig modified its config")
+	}
+	New256WithConfig(nil, []byte("key"))
+}
+
+func TestResetWithKey(t *testing.T) {
+	h := New(&Config{Size: 32, Key: []byte("first key")})
+	h.Write([]byte("garbage"))
+	for _, key := range [][]byte{[]byte("second key"), nil} {
+		if err := h.ResetWithKey(key); err != nil {
+			t.Fatal(err)
+		}
+		h.Write([]byte("message"))
+		want := make([]byte, 32)
+		sum(want, []byte("message"), key, nil)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("key %q: got %x, want %x", key, got, want)
+		}
+	}
+	if err := h.ResetWithKey(make([]byte, 33)); err != ErrKeySize {
+		t.Errorf("long key: got %v, want ErrKeySize", err)
+	}
+}
@@ -0,0 +1,71 @@
+package blake2s
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Misuses of digests, reported to the misuse handler in builds with the
+// blake2_debug tag.
+var (
+	// ErrConcurrentUse reports a digest used by several goroutines at
+	// once, which corrupts its state.
+	ErrConcurrentUse = errors.New("blake2s: digest used concurrently")
+	// ErrWriteAfterSum reports a Write after Sum without Reset, which is
+	// valid, to checkpoint a running digest, but is also the mistake of
+	// reusing a digest for the next message without resetting it.
+	ErrWriteAfterSum = errors.New("blake2s: write after Sum without Reset")
+	// ErrEmptyMAC reports the Sum of a keyed digest to which nothing was
+	// written, the MAC of an empty message, which is more often a
+	// message lost on the way than one meant to be empty.
+	ErrEmptyMAC = errors.New("blake2s: MAC of an empty message")
+	// ErrCBufferChanged reports a buffer passed to WriteCPointer that
+	// changed while it was hashed, so that the digest matches neither
+	// its old nor its new content.
+	ErrCBufferChanged = errors.New("blake2s: C buffer changed while hashed")
+)
+
+// Misuse is a misuse of a digest detected in a blake2_debug build.
+type Misuse struct {
+	// Err is ErrConcurrentUse, ErrWriteAfterSum, ErrEmptyMAC or
+	// ErrCBufferChanged.
+	Err error
+	// Stack is the stack trace of the goroutine that misused the
+	// digest.
+	Stack []byte
+}
+
+func (m *Misuse) Error() string {
+	return fmt.Sprintf("%v\n%s", m.Err, m.Stack)
+}
+
+func (m *Misuse) Unwrap() error {
+	return m.Err
+}
+
+// misuseBox holds the misuse handler, as atomic.Value does not store nil.
+type misuseBox struct {
+	f func(*Misuse)
+}
+
+var misuseHandler atomic.Value
+
+// SetMisuseHandler makes builds with the blake2_debug tag report misuses
+// of digests to f, instead of printing them to standard error, which is
+// the default, to help find the cause of wrong digests in large
+// programs. The tag makes digests track their use, which slows them
+// down, and is meant for tests and debugging sessions: other builds
+// detect nothing, and f is never called. A nil f restores the default.
+func SetMisuseHandler(f func(*Misuse)) {
+	misuseHandler.Store(misuseBox{f})
+}
+
+func reportMisuse(m *Misuse) {
+	if b, _ := misuseHandler.Load().(misuseBox); b.f != nil {
+		b.f(m)
+		return
+	}
+	fmt.Fprintln(os.Stderr, m)
+}
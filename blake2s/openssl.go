@@ -0,0 +1,277 @@
+//go:build openssl && cgo && !windows && !purego && !tinygo && (amd64 || 386)
+// +build openssl
+// +build cgo
+// +build !windows
+// +build !purego
+// +build !tinygo
+// +build amd64 386
+
+package blake2s
+
+import (
+	// #cgo pkg-config: libcrypto
+	// #include <openssl/core_names.h>
+	// #include <openssl/evp.h>
+	// #include <openssl/params.h>
+	//
+	// static EVP_MD_CTX *go_ossl_md_new( void )
+	// {
+	//   EVP_MD_CTX *ctx = EVP_MD_CTX_new();
+	//   if( ctx != NULL && !EVP_DigestInit_ex( ctx, EVP_blake2s256(), NULL ) )
+	//   {
+	//     EVP_MD_CTX_free( ctx );
+	//     return NULL;
+	//   }
+	//   return ctx;
+	// }
+	//
+	// static EVP_MD_CTX *go_ossl_md_dup( const EVP_MD_CTX *in )
+	// {
+	//   EVP_MD_CTX *ctx = EVP_MD_CTX_new();
+	//   if( ctx != NULL && !EVP_MD_CTX_copy_ex( ctx, in ) )
+	//   {
+	//     EVP_MD_CTX_free( ctx );
+	//     return NULL;
+	//   }
+	//   return ctx;
+	// }
+	//
+	// static int go_ossl_md_final( const EVP_MD_CTX *in, uint8_t *out )
+	// {
+	//   EVP_MD_CTX *ctx = go_ossl_md_dup( in );
+	//   int ok = ctx != NULL && EVP_DigestFinal_ex( ctx, out, NULL );
+	//   EVP_MD_CTX_free( ctx );
+	//   return ok;
+	// }
+	//
+	// static EVP_MAC_CTX *go_ossl_mac_new( const uint8_t *key, size_t keylen, size_t outlen,
+	//                                      const uint8_t *salt, const uint8_t *personal )
+	// {
+	//   EVP_MAC *mac = EVP_MAC_fetch( NULL, "BLAKE2SMAC", NULL );
+	//   EVP_MAC_CTX *ctx;
+	//   OSSL_PARAM params[4];
+	//
+	//   if( mac == NULL ) return NULL;
+	//   ctx = EVP_MAC_CTX_new( mac );
+	//   EVP_MAC_free( mac );
+	//   if( ctx == NULL ) return NULL;
+	//
+	//   params[0] = OSSL_PARAM_construct_size_t( OSSL_MAC_PARAM_SIZE, &outlen );
+	//   params[1] = OSSL_PARAM_construct_octet_string( OSSL_MAC_PARAM_SALT, ( void * )salt, 8 );
+	//   params[2] = OSSL_PARAM_construct_octet_string( OSSL_MAC_PARAM_CUSTOM, ( void * )personal, 8 );
+	//   params[3] = OSSL_PARAM_construct_end();
+	//   if( !EVP_MAC_init( ctx, key, keylen, params ) )
+	//   {
+	//     EVP_MAC_CTX_free( ctx );
+	//     return NULL;
+	//   }
+	//   return ctx;
+	// }
+	//
+	// static int go_ossl_mac_final( const EVP_MAC_CTX *in, uint8_t *out, size_t outlen )
+	// {
+	//   EVP_MAC_CTX *ctx = EVP_MAC_CTX_dup( in );
+	//   size_t n = 0;
+	//   int ok = ctx != NULL && EVP_MAC_final( ctx, out, &n, outlen ) && n == outlen;
+	//   EVP_MAC_CTX_free( ctx );
+	//   return ok;
+	// }
+	"C"
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// The openssl build tag routes hashing through the BLAKE2 implementation
+// of OpenSSL 3.0 or later, linked with pkg-config. OpenSSL only supports
+// unkeyed hashing with the default parameters, and keyed hashing with a
+// custom size, salt and personalization; tree hashing and the other
+// unkeyed configurations fall back to the bundled implementation.
+func init() {
+	backends[opensslBackend{}.name()] = opensslBackend{}
+	defaultBackend = opensslBackend{}
+	useEnvBackend()
+}
+
+type opensslBackend struct{}
+
+func (opensslBackend) name() string { return "openssl" }
+
+func (opensslBackend) newState() state {
+	o := new(opensslState)
+	runtime.SetFinalizer(o, (*opensslState).free)
+	return o
+}
+
+// opensslState hashes with an OpenSSL digest context for unkeyed hashing,
+// or a MAC context for keyed hashing. As the backend sees the key only as
+// the first message block, the MAC context is created once that block
+// has been absorbed.
+type opensslState struct {
+	md  *C.EVP_MD_CTX
+	mac *C.EVP_MAC_CTX
+	ref *refState
+
+	param    [32]byte
+	keyBlock [64]byte
+	nKey     int
+	err      error
+}
+
+func (o *opensslState) init(param *[32]byte, lastNode bool) error {
+	o.free()
+	o.param = *param
+	o.nKey = 0
+	o.err = nil
+
+	if !opensslSupports(param, lastNode) {
+		o.ref = newRefState()
+		return o.ref.init(param, lastNode)
+	}
+	if param[1] > 0 {
+		// Wait for the key block.
+		return nil
+	}
+	o.md = C.go_ossl_md_new()
+	if o.md == nil {
+		return errors.New("blake2s: unable to initialize OpenSSL digest")
+	}
+	return nil
+}
+
+// opensslSupports reports whether OpenSSL can hash with the given
+// parameters: sequential mode, and for unkeyed hashing also the default
+// size, salt and personalization.
+func opensslSupports(param *[32]byte, lastNode bool) bool {
+	if lastNode || param[2] != 1 || param[3] != 1 || !allZero(param[4:16]) {
+		return false
+	}
+	return param[1] > 0 || (param[0] == 32 && allZero(param[16:32]))
+}
+
+func allZero(buf []byte) bool {
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *opensslState) update(buf []byte) {
+	// The finalizer of o frees its contexts, which the C calls use.
+	defer runtime.KeepAlive(o)
+	if o.ref != nil {
+		o.ref.update(buf)
+		return
+	}
+	if keyLen := int(o.param[1]); keyLen > 0 && o.mac == nil && o.err == nil {
+		n := copy(o.keyBlock[o.nKey:], buf)
+		o.nKey += n
+		buf = buf[n:]
+		if o.nKey < len(o.keyBlock) {
+			return
+		}
+		o.mac = C.go_ossl_mac_new((*C.uint8_t)(&o.keyBlock[0]), C.size_t(keyLen), C.size_t(o.param[0]),
+			(*C.uint8_t)(&o.param[16]), (*C.uint8_t)(&o.param[24]))
+		for i := range o.keyBlock {
+			o.keyBlock[i] = 0
+		}
+		if o.mac == nil {
+			o.err = errors.New("blake2s: unable to initialize OpenSSL MAC")
+			return
+		}
+	}
+	if len(buf) == 0 {
+		return
+	}
+	switch {
+	case o.mac != nil:
+		C.EVP_MAC_update(o.mac, (*C.uchar)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)))
+	case o.md != nil:
+		C.EVP_DigestUpdate(o.md, unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+	}
+}
+
+func (o *opensslState) final(out []byte) error {
+	defer runtime.KeepAlive(o)
+	switch {
+	case o.ref != nil:
+		return o.ref.final(out)
+	case o.err != nil:
+		return o.err
+	case o.mac != nil:
+		if C.go_ossl_mac_final(o.mac, (*C.uint8_t)(&out[0]), C.size_t(len(out))) == 0 {
+			return errors.New("blake2s: OpenSSL MAC failed")
+		}
+	case o.md != nil:
+		if len(out) != 32 || C.go_ossl_md_final(o.md, (*C.uint8_t)(&out[0])) == 0 {
+			return errors.New("blake2s: OpenSSL digest failed")
+		}
+	default:
+		return errors.New("blake2s: key block not absorbed")
+	}
+	return nil
+}
+
+func (o *opensslState) clone() state {
+	defer runtime.KeepAlive(o)
+	c := &opensslState{param: o.param, keyBlock: o.keyBlock, nKey: o.nKey, err: o.err}
+	if o.ref != nil {
+		c.ref = o.ref.clone().(*refState)
+	}
+	if o.md != nil {
+		c.md = C.go_ossl_md_dup(o.md)
+	}
+	if o.mac != nil {
+		c.mac = C.EVP_MAC_CTX_dup(o.mac)
+	}
+	if (o.md != nil && c.md == nil) || (o.mac != nil && c.mac == nil) {
+		c.err = errors.New("blake2s: unable to copy OpenSSL context")
+	}
+	runtime.SetFinalizer(c, (*opensslState).free)
+	return c
+}
+
+// chainValue reports false while OpenSSL does the hashing, as its state
+// cannot be read.
+func (o *opensslState) chainValue() (h [8]uint32, ok bool) {
+	if o.ref == nil {
+		return h, false
+	}
+	return o.ref.chainValue()
+}
+
+func (o *opensslState) counter() (t [2]uint32, ok bool) {
+	if o.ref == nil {
+		return t, false
+	}
+	return o.ref.counter()
+}
+
+// pending reports false while OpenSSL does the hashing, as its state
+// cannot be saved.
+func (o *opensslState) pending() ([]byte, bool) {
+	if o.ref == nil {
+		return nil, false
+	}
+	return o.ref.pending()
+}
+
+// restore is only called once pending has reported true, with o.ref set.
+func (o *opensslState) restore(h [8]uint32, t [2]uint32, pending []byte) {
+	o.ref.restore(h, t, pending)
+}
+
+// free releases the OpenSSL contexts.
+func (o *opensslState) free() {
+	if o.md != nil {
+		C.EVP_MD_CTX_free(o.md)
+		o.md = nil
+	}
+	if o.mac != nil {
+		C.EVP_MAC_CTX_free(o.mac)
+		o.mac = nil
+	}
+	o.ref = nil
+}
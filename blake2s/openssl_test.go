@@ -0,0 +1,36 @@
+//go:build openssl && cgo && !windows && !purego && !tinygo && (amd64 || 386)
+// +build openssl
+// +build cgo
+// +build !windows
+// +build !purego
+// +build !tinygo
+// +build amd64 386
+
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOpenSSLBackend(t *testing.T) {
+	for _, config := range []*Config{
+		nil,
+		{Size: 32, Key: []byte("key"), Salt: []byte("salt"), Personal: []byte("personal")},
+	} {
+		ref := New(config)
+		ref.state = refBackend{}.newState()
+		ref.Reset()
+		ossl := New(config)
+		if o := ossl.state.(*opensslState); o.ref != nil {
+			t.Fatal("OpenSSL backend fell back to the bundled implementation")
+		}
+
+		data := make([]byte, 1000)
+		ref.Write(data)
+		ossl.Write(data)
+		if !bytes.Equal(ref.Sum(nil), ossl.Sum(nil)) {
+			t.Errorf("digests differ for config %+v", config)
+		}
+	}
+}
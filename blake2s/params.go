@@ -0,0 +1,43 @@
+package blake2s
+
+import "encoding/binary"
+
+// KeySize returns the length of the key the digest was configured with,
+// 0 if unkeyed. The key itself is not exposed.
+func (d *Digest) KeySize() int {
+	return int(d.param[1])
+}
+
+// Salt returns a copy of the salt the digest was configured with, as
+// encoded in the parameter block: SaltSize bytes, padded with zeros, or
+// the digest of a long salt with Config.HashLongParams.
+func (d *Digest) Salt() []byte {
+	return append([]byte(nil), d.param[16:24]...)
+}
+
+// Personal returns a copy of the personalization the digest was
+// configured with, as encoded in the parameter block, like Salt.
+func (d *Digest) Personal() []byte {
+	return append([]byte(nil), d.param[24:32]...)
+}
+
+// Tree returns the tree parameters the digest was configured with, or
+// nil in sequential mode. Wrappers can compare them, with Size, KeySize,
+// Salt and Personal, to check that two digests are configured alike;
+// ParamBlock gives all of them at once.
+func (d *Digest) Tree() *Tree {
+	t := &Tree{
+		Fanout:        d.param[2],
+		MaxDepth:      d.param[3],
+		LeafSize:      binary.LittleEndian.Uint32(d.param[4:8]),
+		NodeOffset:    binary.LittleEndian.Uint32(d.param[8:12]),
+		NodeDepth:     d.param[14],
+		InnerHashSize: d.param[15],
+		IsLastNode:    d.isLastNode,
+		HashLeaves:    d.leaves != nil,
+	}
+	if *t == (Tree{Fanout: 1, MaxDepth: 1}) {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,46 @@
+package blake2s
+
+import "hash"
+
+// prefixMAC is an unkeyed hash that absorbs the key as a message prefix.
+// It exposes only the methods of hash.Hash, as the others of Digest, such
+// as Len and ResetWithKey, would see the key as part of the message.
+type prefixMAC struct {
+	d   *Digest
+	key []byte
+}
+
+// NewPrefixMAC returns a MAC computed as the unkeyed, size-byte BLAKE2s
+// digest of key || message, as some legacy systems do. It is only meant
+// to interoperate with them: new protocols should use the keyed mode of
+// New instead, which the specification defines for MACs.
+func NewPrefixMAC(key []byte, size int) (hash.Hash, error) {
+	if size < 1 || size > MaxDigestSize {
+		return nil, ErrDigestSize
+	}
+	m := &prefixMAC{d: New(&Config{Size: uint8(size)}), key: append([]byte(nil), key...)}
+	m.Reset()
+	return m, nil
+}
+
+func (m *prefixMAC) Write(p []byte) (int, error) {
+	return m.d.Write(p)
+}
+
+func (m *prefixMAC) Sum(b []byte) []byte {
+	return m.d.Sum(b)
+}
+
+// Reset restores the initial state, with the key absorbed.
+func (m *prefixMAC) Reset() {
+	m.d.Reset()
+	m.d.Write(m.key)
+}
+
+func (m *prefixMAC) Size() int {
+	return m.d.Size()
+}
+
+func (m *prefixMAC) BlockSize() int {
+	return m.d.BlockSize()
+}
@@ -0,0 +1,117 @@
+package blake2s
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrMissingKey is returned by ProfileKeyedMAC32 for an empty key.
+var ErrMissingKey = errors.New("blake2s: MAC profile without a key")
+
+// ProfileFastChecksum returns the Config of 32-byte unkeyed digests, for
+// checksums and content addressing. It is registered as "fast-checksum".
+func ProfileFastChecksum() *Config {
+	return &Config{Size: 32}
+}
+
+// ProfileKeyedMAC32 returns the Config of 32-byte MACs keyed with a copy
+// of key, which must not be empty. The profile registered as "mac-32" has
+// no key; set the Key of the Config returned by LookupProfile.
+func ProfileKeyedMAC32(key []byte) (*Config, error) {
+	if len(key) == 0 {
+		return nil, ErrMissingKey
+	}
+	c := &Config{Size: 32, Key: append([]byte(nil), key...)}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ProfileTree4x1MiB returns the Config of the first leaf of a tree with a
+// fanout of 4, unlimited depth, 1 MiB leaves and 32-byte inner digests.
+// The Config of every other node is given by its Tree.NodeConfig. It is
+// registered as "tree-4x1mib", except in builds with the blake2_notree
+// tag, where Config.Validate rejects it.
+func ProfileTree4x1MiB() *Config {
+	t := &Tree{Fanout: 4, MaxDepth: 255, LeafSize: 1 << 20, InnerHashSize: MaxDigestSize}
+	return t.NodeConfig(0, 0, false)
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = make(map[string]*Config)
+)
+
+func init() {
+	RegisterProfile("fast-checksum", ProfileFastChecksum())
+	RegisterProfile("mac-32", &Config{Size: 32})
+	RegisterProfile("tree-4x1mib", ProfileTree4x1MiB())
+}
+
+// RegisterProfile makes a copy of cfg available by name to LookupProfile,
+// so that the services of an organization can share hashing parameters
+// by name rather than copy Config literals. Names are not case sensitive.
+// It returns the error of cfg.Validate, or of cfg.Tree.Validate, for
+// invalid configs, and panics if the name is already registered.
+func RegisterProfile(name string, cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg != nil {
+		if err := cfg.Tree.Validate(); err != nil {
+			return err
+		}
+	}
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	name = strings.ToLower(name)
+	if _, dup := profiles[name]; dup {
+		panic("blake2s: RegisterProfile called twice for profile " + name)
+	}
+	profiles[name] = cfg.clone()
+	return nil
+}
+
+// LookupProfile returns a copy of the Config registered as name, in any
+// case, which the caller may modify.
+func LookupProfile(name string) (*Config, bool) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	cfg, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return cfg.clone(), true
+}
+
+// Profiles returns the names of the registered profiles, sorted.
+func Profiles() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clone returns a deep copy of c, which may be nil.
+func (c *Config) clone() *Config {
+	d := new(Config)
+	if c == nil {
+		return d
+	}
+	*d = *c
+	d.Key = append([]byte(nil), c.Key...)
+	d.Salt = append([]byte(nil), c.Salt...)
+	d.Personal = append([]byte(nil), c.Personal...)
+	if c.Tree != nil {
+		t := *c.Tree
+		d.Tree = &t
+	}
+	return d
+}
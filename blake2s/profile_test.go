@@ -0,0 +1,85 @@
+package blake2s
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	if _, err := ProfileKeyedMAC32(nil); err != ErrMissingKey {
+		t.Errorf("ProfileKeyedMAC32(nil): err = %v", err)
+	}
+	if _, err := ProfileKeyedMAC32(make([]byte, MaxKeySize+1)); err != ErrKeySize {
+		t.Errorf("long key: err = %v", err)
+	}
+	key := []byte("secret")
+	mac, err := ProfileKeyedMAC32(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key[0] = 'S'
+	if mac.Size != 32 || !bytes.Equal(mac.Key, []byte("secret")) {
+		t.Errorf("ProfileKeyedMAC32 = %+v", mac)
+	}
+
+	tree := ProfileTree4x1MiB()
+	if err := tree.Tree.Validate(); err != nil || tree.Tree.Fanout != 4 || tree.Tree.LeafSize != 1<<20 {
+		t.Errorf("ProfileTree4x1MiB = %+v, %v", tree.Tree, err)
+	}
+
+	want := map[string]*Config{
+		"fast-checksum": ProfileFastChecksum(),
+		"MAC-32":        {Size: 32},
+	}
+	if treeHashing {
+		want["tree-4x1MiB"] = tree
+	}
+	for name, want := range want {
+		got, ok := LookupProfile(name)
+		if !ok || got.Size != want.Size || !reflect.DeepEqual(got.Tree, want.Tree) {
+			t.Errorf("LookupProfile(%q) = %+v, %v", name, got, ok)
+		}
+	}
+}
+
+func TestRegisterProfile(t *testing.T) {
+	cfg := &Config{Size: 20, Personal: []byte("example")}
+	if err := RegisterProfile("test-profile", cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Personal[0] = 'E'
+	got, ok := LookupProfile("Test-Profile")
+	if !ok || got.Size != 20 || string(got.Personal) != "example" {
+		t.Fatalf("LookupProfile = %+v, %v", got, ok)
+	}
+	got.Personal[0] = 'E'
+	if again, _ := LookupProfile("test-profile"); string(again.Personal) != "example" {
+		t.Error("LookupProfile returned the registered Config")
+	}
+	found := false
+	for _, name := range Profiles() {
+		found = found || name == "test-profile"
+	}
+	if !found {
+		t.Errorf("Profiles() = %v", Profiles())
+	}
+
+	if err := RegisterProfile("bad-size", &Config{Size: MaxDigestSize + 1}); err != ErrDigestSize {
+		t.Errorf("invalid size: err = %v", err)
+	}
+	wantTree := ErrTree
+	if !treeHashing {
+		wantTree = ErrTreeDisabled
+	}
+	if err := RegisterProfile("bad-tree", &Config{Tree: &Tree{Fanout: 2, MaxDepth: 2}}); !errors.Is(err, wantTree) {
+		t.Errorf("invalid tree: err = %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterProfile did not panic on a duplicate name")
+		}
+	}()
+	RegisterProfile("FAST-checksum", &Config{})
+}
@@ -0,0 +1,26 @@
+//go:build blake2_research
+// +build blake2_research
+
+package blake2s
+
+// NewReducedRounds returns a BLAKE2s digest that computes only the given
+// number of rounds, in the range [1, 10], of the compression function.
+//
+// UNSAFE: reduced-round BLAKE2s is not a secure hash function. It exists,
+// behind the blake2_research build tag, for cryptanalysis and protocol
+// experiments only, and must never protect real data. It always uses the
+// pure Go implementation, and keeps its number of rounds through Reset,
+// Clone and the hashing of leaves. It cannot be marshaled.
+func NewReducedRounds(config *Config, rounds int) *Digest {
+	if rounds < 1 || rounds > 10 {
+		panic("blake2s: rounds out of range")
+	}
+	d := New(config)
+	d.state = &genericState{rounds: rounds}
+	d.backend = genericBackend{}.name()
+	if d.leaves != nil {
+		d.leaves = newLeafHasher(&d.param, d.key, d.backend)
+	}
+	d.Reset()
+	return d
+}
@@ -0,0 +1,54 @@
+//go:build blake2_research
+// +build blake2_research
+
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReducedRounds(t *testing.T) {
+	data := []byte("one two three")
+
+	h := New(nil)
+	h.Write(data)
+	want := h.Sum(nil)
+
+	full := NewReducedRounds(nil, 10)
+	full.Write(data)
+	if got := full.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("10 rounds: got %X, want %X", got, want)
+	}
+
+	reduced := NewReducedRounds(nil, 4)
+	reduced.Write(data)
+	if bytes.Equal(reduced.Sum(nil), want) {
+		t.Error("4 rounds gave the full-round digest")
+	}
+	if reduced.backend != (genericBackend{}).name() {
+		t.Errorf("backend %q, want the pure Go one", reduced.backend)
+	}
+
+	// The paths recreating the state keep the number of rounds.
+	sum := reduced.Sum(nil)
+	reduced.Reset()
+	reduced.Write(data)
+	if got := reduced.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("after Reset: got %X, want %X", got, sum)
+	}
+
+	config := &Config{Tree: &Tree{MaxDepth: 2, NodeDepth: 1, LeafSize: 64, InnerHashSize: 32, HashLeaves: true}}
+	leaves := NewReducedRounds(config, 10)
+	fullLeaves := New(config)
+	reducedLeaves := NewReducedRounds(config, 4)
+	for _, d := range []*Digest{leaves, fullLeaves, reducedLeaves} {
+		d.Write(make([]byte, 1000))
+	}
+	if !bytes.Equal(leaves.Sum(nil), fullLeaves.Sum(nil)) {
+		t.Error("10 rounds with leaves differ from the full-round digest")
+	}
+	if bytes.Equal(reducedLeaves.Sum(nil), fullLeaves.Sum(nil)) {
+		t.Error("4 rounds with leaves gave the full-round digest")
+	}
+}
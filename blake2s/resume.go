@@ -0,0 +1,93 @@
+package blake2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	checkpointMagic   = "B2CK"
+	checkpointVersion = 1
+	checkpointSumSize = 32
+	// maxCheckpointState bounds the state length read from a checkpoint.
+	maxCheckpointState = 1 << 12
+)
+
+// ErrCheckpoint is returned by ResumeFromCheckpoint for checkpoints that
+// are truncated, corrupted or of an unknown version.
+var ErrCheckpoint = errors.New("blake2s: invalid checkpoint")
+
+// SaveCheckpoint writes the state of d to w, so that a long hashing job
+// can be resumed with ResumeFromCheckpoint after a restart. The
+// checkpoint is versioned and ends with a BLAKE2s-256 checksum of its
+// contents, which catches truncated and corrupted files; it is not
+// authenticated. Like MarshalBinary, whose encoding it wraps, it contains
+// the key of keyed digests.
+func (d *Digest) SaveCheckpoint(w io.Writer) error {
+	state, err := d.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 0, len(checkpointMagic)+1+4+len(state)+checkpointSumSize)
+	b = append(b, checkpointMagic...)
+	b = append(b, checkpointVersion)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(state)))
+	b = append(b, n[:]...)
+	b = append(b, state...)
+	b = append(b, checkpointSum(b)...)
+	_, err = w.Write(b)
+	return err
+}
+
+// ResumeFromCheckpoint reads a checkpoint written by SaveCheckpoint and
+// returns a digest in the saved state. It returns ErrCheckpoint if the
+// checkpoint is invalid, and ErrVersion if it was written by a newer
+// version of the package.
+func ResumeFromCheckpoint(r io.Reader) (*Digest, error) {
+	header := make([]byte, len(checkpointMagic)+1+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, checkpointError(err)
+	}
+	if string(header[:len(checkpointMagic)]) != checkpointMagic {
+		return nil, ErrCheckpoint
+	}
+	if header[len(checkpointMagic)] != checkpointVersion {
+		return nil, ErrVersion
+	}
+	n := binary.LittleEndian.Uint32(header[len(checkpointMagic)+1:])
+	if n > maxCheckpointState {
+		return nil, ErrCheckpoint
+	}
+	rest := make([]byte, int(n)+checkpointSumSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, checkpointError(err)
+	}
+	state, sum := rest[:n], rest[n:]
+	if !bytes.Equal(sum, checkpointSum(append(header, state...))) {
+		return nil, ErrCheckpoint
+	}
+	d := new(Digest)
+	if err := d.UnmarshalBinary(state); err == ErrVersion {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrCheckpoint
+	}
+	return d, nil
+}
+
+func checkpointSum(b []byte) []byte {
+	d := New(&Config{Size: checkpointSumSize})
+	d.Write(b)
+	return d.Sum(nil)
+}
+
+// checkpointError maps a short read to ErrCheckpoint.
+func checkpointError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCheckpoint
+	}
+	return err
+}
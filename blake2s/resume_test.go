@@ -0,0 +1,52 @@
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	h := New(&Config{Size: 32, Key: []byte("key"), Personal: []byte("job")})
+	h.Write([]byte("first half, "))
+
+	var buf bytes.Buffer
+	if err := h.SaveCheckpoint(&buf); err == errMarshalState {
+		t.Skip("backend cannot save its state")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ResumeFromCheckpoint(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write([]byte("second half"))
+	r.Write([]byte("second half"))
+	if !bytes.Equal(r.Sum(nil), h.Sum(nil)) {
+		t.Error("resumed digest differs")
+	}
+}
+
+func TestCheckpointInvalid(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("hello"))
+	var buf bytes.Buffer
+	if err := h.SaveCheckpoint(&buf); err == errMarshalState {
+		t.Skip("backend cannot save its state")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	corrupted := append([]byte(nil), b...)
+	corrupted[20] ^= 1
+	version := append([]byte(nil), b...)
+	version[4] = 2
+	for i, bad := range [][]byte{nil, b[:8], b[:len(b)-1], corrupted} {
+		if _, err := ResumeFromCheckpoint(bytes.NewReader(bad)); err != ErrCheckpoint {
+			t.Errorf("%d: ResumeFromCheckpoint returned %v, want %v", i, err, ErrCheckpoint)
+		}
+	}
+	if _, err := ResumeFromCheckpoint(bytes.NewReader(version)); err != ErrVersion {
+		t.Errorf("newer version: ResumeFromCheckpoint returned %v, want %v", err, ErrVersion)
+	}
+}
@@ -0,0 +1,63 @@
+package blake2s
+
+import (
+	"bytes"
+	"errors"
+)
+
+// selftestResult is the BLAKE2s-256 "grand hash" of all the digests
+// computed by SelfTest, as given in RFC 7693, Appendix E.
+var selftestResult = []byte{
+	0x6A, 0x41, 0x1F, 0x08, 0xCE, 0x25, 0xAD, 0xCD,
+	0xFB, 0x02, 0xAB, 0xA6, 0x41, 0x45, 0x1C, 0xEC,
+	0x53, 0xC5, 0x98, 0xB2, 0x4F, 0x4F, 0xC7, 0x87,
+	0xFB, 0xDC, 0x88, 0x79, 0x7F, 0x4C, 0x1D, 0xFE,
+}
+
+// SelfTest runs the self-test procedure of RFC 7693, Appendix E, which
+// hashes deterministic inputs of various lengths with various digest
+// sizes, both unkeyed and keyed, and checks the hash of all the results
+// against a known answer. It returns an error if the linked
+// implementation does not produce the expected output.
+func SelfTest() error {
+	var (
+		in  [1024]byte
+		key [32]byte
+		md  [32]byte
+	)
+
+	h := New(&Config{Size: 32})
+	for _, outlen := range []int{16, 20, 28, 32} {
+		for _, inlen := range []int{0, 3, 64, 65, 255, 1024} {
+			selftestSeq(in[:inlen], uint32(inlen))
+			if err := sum(md[:outlen], in[:inlen], nil, nil); err != nil {
+				return err
+			}
+			h.Write(md[:outlen])
+
+			selftestSeq(key[:outlen], uint32(outlen))
+			if err := sum(md[:outlen], in[:inlen], key[:outlen], nil); err != nil {
+				return err
+			}
+			h.Write(md[:outlen])
+		}
+	}
+
+	if !bytes.Equal(h.Sum(nil), selftestResult) {
+		return errors.New("blake2s: self-test failed")
+	}
+	return nil
+}
+
+// selftestSeq fills out with the deterministic Fibonacci sequence used by
+// the RFC 7693 self-test.
+func selftestSeq(out []byte, seed uint32) {
+	a := 0xDEAD4BAD * seed
+	b := uint32(1)
+	for i := range out {
+		t := a + b
+		a = b
+		b = t
+		out[i] = byte(t >> 24)
+	}
+}
@@ -0,0 +1,40 @@
+package blake2s
+
+// Sumer is the read-only view of a digest returned by Snapshot.
+type Sumer interface {
+	// Sum appends the digest of the data written before the snapshot
+	// was taken to b.
+	Sum(b []byte) []byte
+	// Size returns the digest length.
+	Size() int
+}
+
+// snapshot is a frozen copy of a digest. Sum never changes the state of
+// a digest, so it can be called concurrently on a copy nobody writes to.
+type snapshot struct {
+	d *Digest
+}
+
+func (s snapshot) Sum(b []byte) []byte {
+	return s.d.Sum(b)
+}
+
+func (s snapshot) Size() int {
+	return s.d.Size()
+}
+
+// Snapshot returns an immutable copy of the current state, on which
+// several goroutines can call Sum concurrently while d keeps receiving
+// writes.
+func (d *Digest) Snapshot() Sumer {
+	return snapshot{d.Clone().(*Digest)}
+}
+
+// Snapshot returns an immutable copy of the current state, on which
+// several goroutines can call Sum concurrently while c keeps receiving
+// writes.
+func (c *Concurrent) Snapshot() Sumer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.d.Snapshot()
+}
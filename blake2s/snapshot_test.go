@@ -0,0 +1,32 @@
+package blake2s
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	h := New(&Config{Key: []byte("key")})
+	h.Write([]byte("one two three"))
+	want := h.Sum(nil)
+	s := h.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := s.Sum(nil); !bytes.Equal(got, want) {
+					t.Errorf("got %X, want %X", got, want)
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		h.Write([]byte("more"))
+	}
+	wg.Wait()
+}
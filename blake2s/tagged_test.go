@@ -0,0 +1,37 @@
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTagged(t *testing.T) {
+	h := New(&Config{Size: 32})
+	h.Write([]byte("my protocol/challenge"))
+	tagHash := h.Sum(nil)
+	ref := New(&Config{Size: 32})
+	ref.Write(tagHash)
+	ref.Write(tagHash)
+	ref.Write([]byte("message"))
+	want := ref.Sum(nil)
+
+	tagged := NewTagged("my protocol/challenge")
+	for i := 0; i < 2; i++ {
+		tagged.Write([]byte("message"))
+		if got := tagged.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("round %d: digest %X, want %X", i, got, want)
+		}
+		c := tagged.Clone()
+		c.Write([]byte("more"))
+		if bytes.Equal(c.Sum(nil), tagged.Sum(nil)) {
+			t.Errorf("round %d: clone shares state", i)
+		}
+		tagged.Reset()
+	}
+
+	other := NewTagged("my protocol/nonce")
+	other.Write([]byte("message"))
+	if bytes.Equal(other.Sum(nil), want) {
+		t.Error("different tags give the same digest")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !blake2_notree
+// +build !blake2_notree
+
+package blake2s
+
+import "encoding/binary"
+
+// treeHashing reports whether tree hashing is built in; see notree.go.
+const treeHashing = true
+
+// setTree encodes the tree hashing parameters t into the parameter block.
+func (p *Params) setTree(t *Tree) {
+	p.param[2] = t.Fanout
+	p.param[3] = t.MaxDepth
+	binary.LittleEndian.PutUint32(p.param[4:8], t.LeafSize)
+	binary.LittleEndian.PutUint32(p.param[8:12], t.NodeOffset)
+	p.param[14] = t.NodeDepth
+	p.param[15] = t.InnerHashSize
+
+	p.isLastNode = t.IsLastNode
+	if t.HashLeaves {
+		// Config.Validate checked the parameters leaf hashing requires.
+		p.isLastNode = true
+		p.hashLeaves = true
+	}
+}
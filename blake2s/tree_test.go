@@ -0,0 +1,164 @@
+//go:build !blake2_notree
+// +build !blake2_notree
+
+package blake2s
+
+import (
+	"bytes"
+	"testing"
+)
+
+// treeConfigs are tree hashing configs exercised by other tests.
+var treeConfigs = []*Config{
+	{Tree: &Tree{Fanout: 2, MaxDepth: 2, LeafSize: 4096, NodeOffset: 1, IsLastNode: true}},
+	{Size: 32, Tree: &Tree{Fanout: 2, MaxDepth: 2, NodeDepth: 1, InnerHashSize: 32}},
+}
+
+func TestParamBlockByteOrder(t *testing.T) {
+	h := New(&Config{Tree: &Tree{Fanout: 2, MaxDepth: 3, LeafSize: 0x01020304, NodeOffset: 0x05060708}})
+	p := h.ParamBlock()
+	if want := []byte{32, 0, 2, 3, 4, 3, 2, 1, 8, 7, 6, 5}; !bytes.Equal(p[:12], want) {
+		t.Errorf("parameter block starts with %X, want %X", p[:12], want)
+	}
+
+	g := genericBackend{}.newState().(*genericState)
+	g.init(&p, false)
+	if want := iv[0] ^ 0x03020020; g.h[0] != want {
+		t.Errorf("h[0] = %#x, want %#x", g.h[0], want)
+	}
+}
+
+func TestHashLeaves(t *testing.T) {
+	const leafSize = 1024
+	tree := Tree{MaxDepth: 2, LeafSize: leafSize, InnerHashSize: 32}
+	data := make([]byte, 40*leafSize+5)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	for _, n := range []int{0, 1, leafSize - 1, leafSize, leafSize + 1, 8 * leafSize, len(data)} {
+		// Hash the tree by hand.
+		root := tree
+		root.NodeDepth = 1
+		root.IsLastNode = true
+		want := New(&Config{Tree: &root})
+		for off := 0; off == 0 || off < n; off += leafSize {
+			end := off + leafSize
+			if end > n {
+				end = n
+			}
+			leaf := tree
+			leaf.NodeOffset = uint32(off / leafSize)
+			leaf.IsLastNode = end == n
+			h := New(&Config{Size: 32, Tree: &leaf})
+			h.Write(data[off:end])
+			want.Write(h.Sum(nil))
+		}
+
+		root.HashLeaves = true
+		whole := New(&Config{Tree: &root})
+		whole.Write(data[:n])
+		pieces := New(&Config{Tree: &root})
+		for off := 0; off < n; off += 100 {
+			end := off + 100
+			if end > n {
+				end = n
+			}
+			pieces.Write(data[off:end])
+		}
+
+		if got := whole.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+			t.Errorf("%d bytes in one write: wrong digest", n)
+		}
+		if got := pieces.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+			t.Errorf("%d bytes in small writes: wrong digest", n)
+		}
+	}
+}
+
+func TestMarshalBinaryLeaves(t *testing.T) {
+	h := New(&Config{Tree: &Tree{MaxDepth: 2, NodeDepth: 1, LeafSize: 1024, InnerHashSize: 32, HashLeaves: true}})
+	if _, err := h.MarshalBinary(); err != errMarshalState {
+		t.Errorf("MarshalBinary returned %v, want %v", err, errMarshalState)
+	}
+}
+
+// cloneCountingState counts the copies made of it and of its copies.
+type cloneCountingState struct {
+	state
+	clones *int
+}
+
+func (c *cloneCountingState) clone() state {
+	*c.clones++
+	return &cloneCountingState{state: c.state.clone(), clones: c.clones}
+}
+
+func TestHashLeavesDigestState(t *testing.T) {
+	// Leaves are hashed with copies of the state of the digest, not with
+	// states of the default backend.
+	config := &Config{Tree: &Tree{MaxDepth: 2, NodeDepth: 1, LeafSize: 1024, InnerHashSize: 32, HashLeaves: true}}
+	want := New(config)
+	want.Write(make([]byte, 3000))
+
+	clones := 0
+	d := New(config)
+	d.state = &cloneCountingState{state: genericBackend{}.newState(), clones: &clones}
+	d.Reset()
+	d.Write(make([]byte, 3000))
+	if got := d.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("got %x, want %x", got, want.Sum(nil))
+	}
+	// Two leaves, the copy Sum makes and the last leaf.
+	if clones != 4 {
+		t.Errorf("%d copies of the state, want 4", clones)
+	}
+}
+
+func TestNodeConfigHashLeaves(t *testing.T) {
+	// Hashing the nodes of a two-level tree one by one with the configs
+	// from NodeConfig gives the digest HashLeaves computes.
+	data := make([]byte, 3*1000+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	shape := &Tree{MaxDepth: 2, LeafSize: 1000, InnerHashSize: 24}
+
+	rootConfig := shape.NodeConfig(1, 0, true)
+	rootConfig.Size = 32
+	root := New(rootConfig)
+	for i := 0; i*1000 < len(data); i++ {
+		end := (i + 1) * 1000
+		if end > len(data) {
+			end = len(data)
+		}
+		leaf := New(shape.NodeConfig(0, uint32(i), end == len(data)))
+		leaf.Write(data[i*1000 : end])
+		root.Write(leaf.Sum(nil))
+	}
+
+	tree := *shape
+	tree.NodeDepth = 1
+	tree.HashLeaves = true
+	h := New(&Config{Size: 32, Tree: &tree})
+	h.Write(data)
+	if got, want := root.Sum(nil), h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("node-by-node digest %x, want %x", got, want)
+	}
+}
+
+func TestTreeAccessor(t *testing.T) {
+	leaves := &Tree{MaxDepth: 2, LeafSize: 1024, NodeDepth: 1, InnerHashSize: 16, HashLeaves: true}
+	for _, cfg := range append(treeConfigs, &Config{Tree: leaves}) {
+		want := *cfg.Tree
+		if want.HashLeaves {
+			want.IsLastNode = true
+		}
+		if got := New(cfg).Tree(); got == nil || *got != want {
+			t.Errorf("Tree() = %+v, want %+v", got, want)
+		}
+	}
+	if tr := New(&Config{Tree: &Tree{Fanout: 1, MaxDepth: 1}}).Tree(); tr != nil {
+		t.Errorf("sequential Tree() = %+v, want nil", tr)
+	}
+}
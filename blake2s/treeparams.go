@@ -0,0 +1,79 @@
+package blake2s
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTree is returned by Tree.Validate for inconsistent tree parameters.
+var ErrTree = errors.New("blake2s: inconsistent tree parameters")
+
+// Validate checks that the parameters of t are consistent with each other,
+// as the BLAKE2 specification lays them out, since hand-filled trees are
+// the main source of interoperability bugs:
+//
+//   - MaxDepth is at least 1;
+//   - sequential mode, Fanout 1 or MaxDepth 1, has both set to 1 and the
+//     other parameters zero;
+//   - otherwise InnerHashSize is in [1, MaxDigestSize] and NodeDepth is
+//     below MaxDepth, unless MaxDepth is 255, for unlimited;
+//   - HashLeaves has the parameters it requires.
+//
+// The returned error wraps ErrTree. A nil tree is valid. New does not call
+// Validate, so that unusual trees can still be built.
+func (t *Tree) Validate() error {
+	switch {
+	case t == nil:
+		return nil
+	case t.MaxDepth == 0:
+		return fmt.Errorf("%w: MaxDepth is 0", ErrTree)
+	case t.Fanout == 1 || t.MaxDepth == 1:
+		if t.Fanout != 1 || t.MaxDepth != 1 {
+			return fmt.Errorf("%w: sequential mode needs Fanout 1 and MaxDepth 1", ErrTree)
+		}
+		if t.LeafSize != 0 || t.NodeDepth != 0 || t.NodeOffset != 0 || t.InnerHashSize != 0 ||
+			t.IsLastNode || t.HashLeaves {
+			return fmt.Errorf("%w: sequential mode with node parameters", ErrTree)
+		}
+		return nil
+	case t.InnerHashSize == 0 || t.InnerHashSize > MaxDigestSize:
+		return fmt.Errorf("%w: InnerHashSize %d out of range", ErrTree, t.InnerHashSize)
+	case t.MaxDepth != 255 && t.NodeDepth >= t.MaxDepth:
+		return fmt.Errorf("%w: NodeDepth %d not below MaxDepth %d", ErrTree, t.NodeDepth, t.MaxDepth)
+	case t.HashLeaves:
+		return t.validateHashLeaves()
+	}
+	return nil
+}
+
+// validateHashLeaves checks the parameters HashLeaves requires, which
+// Config.Validate checks too, since leaf hashing cannot do without them.
+func (t *Tree) validateHashLeaves() error {
+	if t.Fanout != 0 || t.MaxDepth != 2 || t.NodeDepth != 1 || t.NodeOffset != 0 || t.LeafSize == 0 || t.InnerHashSize == 0 {
+		return fmt.Errorf("%w: HashLeaves needs Fanout 0, MaxDepth 2, NodeDepth 1, NodeOffset 0, a LeafSize and an InnerHashSize", ErrTree)
+	}
+	return nil
+}
+
+// NodeConfig returns the Config of the node at the given depth, offset in
+// its level and position, last in its level or not, of a tree with the
+// shape of t: Fanout, MaxDepth, LeafSize and InnerHashSize. Its Size is
+// InnerHashSize, the digest size of every node but the root; set the Size
+// of the root to the digest size wanted, and the Key, Salt and Personal of
+// every node as the application requires. NodeConfig panics with the
+// error returned by Validate if the node parameters are inconsistent.
+func (t *Tree) NodeConfig(depth uint8, offset uint32, isLast bool) *Config {
+	node := &Tree{
+		Fanout:        t.Fanout,
+		MaxDepth:      t.MaxDepth,
+		LeafSize:      t.LeafSize,
+		NodeDepth:     depth,
+		NodeOffset:    offset,
+		InnerHashSize: t.InnerHashSize,
+		IsLastNode:    isLast,
+	}
+	if err := node.Validate(); err != nil {
+		panic(err)
+	}
+	return &Config{Size: t.InnerHashSize, Tree: node}
+}
@@ -0,0 +1,64 @@
+package blake2s
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTreeValidate(t *testing.T) {
+	valid := []*Tree{
+		nil,
+		{Fanout: 1, MaxDepth: 1},
+		{Fanout: 2, MaxDepth: 3, LeafSize: 4096, NodeDepth: 2, InnerHashSize: 32},
+		{Fanout: 0, MaxDepth: 255, NodeDepth: 200, InnerHashSize: 1},
+		{MaxDepth: 2, LeafSize: 1024, NodeDepth: 1, InnerHashSize: MaxDigestSize, HashLeaves: true},
+	}
+	for _, tree := range valid {
+		if err := tree.Validate(); err != nil {
+			t.Errorf("%+v: %v", tree, err)
+		}
+	}
+
+	invalid := []*Tree{
+		{},
+		{Fanout: 1, MaxDepth: 2, InnerHashSize: 32},
+		{Fanout: 1, MaxDepth: 1, LeafSize: 64},
+		{Fanout: 1, MaxDepth: 1, IsLastNode: true},
+		{Fanout: 2, MaxDepth: 2},
+		{Fanout: 2, MaxDepth: 2, InnerHashSize: MaxDigestSize + 1},
+		{Fanout: 2, MaxDepth: 2, NodeDepth: 2, InnerHashSize: 32},
+		{MaxDepth: 3, LeafSize: 1024, NodeDepth: 1, InnerHashSize: 32, HashLeaves: true},
+		{MaxDepth: 2, NodeDepth: 1, InnerHashSize: 32, HashLeaves: true},
+	}
+	for _, tree := range invalid {
+		if err := tree.Validate(); !errors.Is(err, ErrTree) {
+			t.Errorf("%+v: error %v, want ErrTree", tree, err)
+		}
+		if !tree.HashLeaves || !treeHashing {
+			continue
+		}
+		// Config.Validate rejects them too, rather than New panicking.
+		if err := (&Config{Tree: tree}).Validate(); !errors.Is(err, ErrTree) {
+			t.Errorf("Config with %+v: error %v, want ErrTree", tree, err)
+		}
+		if _, err := NewParams(&Config{Tree: tree}); !errors.Is(err, ErrTree) {
+			t.Errorf("NewParams with %+v: error %v, want ErrTree", tree, err)
+		}
+	}
+}
+
+func TestNodeConfig(t *testing.T) {
+	shape := &Tree{Fanout: 4, MaxDepth: 3, LeafSize: 4096, InnerHashSize: 32, HashLeaves: true}
+	c := shape.NodeConfig(1, 3, true)
+	want := Tree{Fanout: 4, MaxDepth: 3, LeafSize: 4096, NodeDepth: 1, NodeOffset: 3, InnerHashSize: 32, IsLastNode: true}
+	if c.Size != 32 || *c.Tree != want {
+		t.Errorf("NodeConfig = %+v, tree %+v; want tree %+v", c, *c.Tree, want)
+	}
+
+	defer func() {
+		if !errors.Is(recover().(error), ErrTree) {
+			t.Error("NodeConfig did not panic with ErrTree")
+		}
+	}()
+	shape.NodeConfig(3, 0, false)
+}
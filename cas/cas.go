@@ -0,0 +1,158 @@
+// Package cas stores blobs, such as the chunks of the chunker package or
+// the files of a manifest, under their BLAKE2b digests: content-addressed
+// storage, where a blob is stored once however often it is put, and
+// whatever is read back can be checked against its address.
+//
+// DirStore keeps blobs in a directory, as files named by the hexadecimal
+// digest, sharded into subdirectories by its first byte:
+//
+//	dir/3f/3fa1...c2
+//
+// Blobs too large to hold in memory are streamed into a DirStore with a
+// Writer, which learns their address as it writes them.
+package cas
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Size is the length of digests.
+const Size = 32
+
+// Digest is the address of a blob: the 32-byte BLAKE2b digest of its
+// content.
+type Digest [Size]byte
+
+// Sum returns the digest of data.
+func Sum(data []byte) Digest {
+	return blake2b.Sum256(data)
+}
+
+// String returns the digest in lowercase hexadecimal.
+func (d Digest) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// ParseDigest parses a digest in hexadecimal, as String returns it.
+func ParseDigest(s string) (Digest, error) {
+	var d Digest
+	if len(s) != 2*Size {
+		return d, ErrDigest
+	}
+	if _, err := hex.Decode(d[:], []byte(s)); err != nil {
+		return d, ErrDigest
+	}
+	return d, nil
+}
+
+var (
+	// ErrNotFound is returned by Store.Get for blobs not in the store.
+	ErrNotFound = errors.New("cas: blob not found")
+	// ErrCorrupt is returned for stored blobs that do not match their
+	// digest.
+	ErrCorrupt = errors.New("cas: blob does not match its digest")
+	// ErrDigest is returned by ParseDigest for malformed digests.
+	ErrDigest = errors.New("cas: invalid digest")
+)
+
+// Store is a content-addressed blob store.
+type Store interface {
+	// Put stores data, if not stored yet, and returns its digest.
+	Put(data []byte) (Digest, error)
+	// Get returns the blob with digest d, or ErrNotFound. Blobs that no
+	// longer match their digest are reported with ErrCorrupt rather
+	// than returned.
+	Get(d Digest) ([]byte, error)
+	// Has reports whether the blob with digest d is stored.
+	Has(d Digest) (bool, error)
+}
+
+// DirStore is a Store keeping blobs as files in a directory. It is safe
+// for concurrent use, also by several processes sharing the directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a DirStore keeping blobs in dir, which it creates
+// if needed.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// path returns the name of the file of the blob with digest d.
+func (s *DirStore) path(d Digest) string {
+	name := d.String()
+	return filepath.Join(s.dir, name[:2], name)
+}
+
+// Put implements Store. The blob is written to a temporary file, synced,
+// read back and checked against its digest before it is renamed into
+// place, so that a blob in the store is complete and correct as written.
+// The directory holding it is then synced, so that the blob survives a
+// crash once Put returns.
+func (s *DirStore) Put(data []byte) (Digest, error) {
+	d := Sum(data)
+	name := s.path(d)
+	if _, err := os.Stat(name); err == nil {
+		return d, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return d, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return d, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return d, err
+	}
+	if err := verifyFile(f.Name(), d); err != nil {
+		return d, err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		return d, err
+	}
+	return d, syncDir(filepath.Dir(name))
+}
+
+// Get implements Store.
+func (s *DirStore) Get(d Digest) ([]byte, error) {
+	data, err := os.ReadFile(s.path(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if Sum(data) != d {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, d)
+	}
+	return data, nil
+}
+
+// Has implements Store. It does not check the blob against its digest.
+func (s *DirStore) Has(d Digest) (bool, error) {
+	_, err := os.Stat(s.path(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,66 @@
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "blobs")
+	s, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ Store = s
+
+	data := []byte("chunk of content")
+	d, err := s.Put(data)
+	if err != nil || d != Sum(data) {
+		t.Fatalf("Put = %v, %v", d, err)
+	}
+	if d2, err := s.Put(data); err != nil || d2 != d {
+		t.Errorf("second Put = %v, %v", d2, err)
+	}
+	name := filepath.Join(dir, d.String()[:2], d.String())
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("blob file: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(name))
+	if len(entries) != 1 {
+		t.Errorf("%d files in the shard, want 1", len(entries))
+	}
+	if ok, err := s.Has(d); !ok || err != nil {
+		t.Errorf("Has = %v, %v", ok, err)
+	}
+	if got, err := s.Get(d); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+
+	missing := Sum([]byte("missing"))
+	if ok, err := s.Has(missing); ok || err != nil {
+		t.Errorf("Has of a missing blob = %v, %v", ok, err)
+	}
+	if _, err := s.Get(missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing blob: %v, want ErrNotFound", err)
+	}
+
+	os.WriteFile(name, []byte("chunk of c0ntent"), 0644)
+	if _, err := s.Get(d); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Get of a corrupt blob: %v, want ErrCorrupt", err)
+	}
+}
+
+func TestParseDigest(t *testing.T) {
+	d := Sum([]byte("x"))
+	if got, err := ParseDigest(d.String()); err != nil || got != d {
+		t.Errorf("ParseDigest(%s) = %v, %v", d, got, err)
+	}
+	for _, s := range []string{"", "00", d.String()[1:] + "z"} {
+		if _, err := ParseDigest(s); err != ErrDigest {
+			t.Errorf("ParseDigest(%q): %v, want ErrDigest", s, err)
+		}
+	}
+}
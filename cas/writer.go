@@ -0,0 +1,140 @@
+package cas
+
+import (
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Writer streams a blob of unknown digest into a DirStore, for blobs too
+// large to hold in memory for Put. It writes to a temporary file while
+// hashing, and Close moves the file to the address of its content. A
+// Writer is not safe for concurrent use.
+type Writer struct {
+	s      *DirStore
+	f      *os.File
+	h      hash.Hash
+	d      Digest
+	closed bool
+}
+
+// Create returns a Writer adding a blob to s.
+func (s *DirStore) Create() (*Writer, error) {
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{s: s, f: f, h: blake2b.New(&blake2b.Config{Size: Size})}, nil
+}
+
+// Write writes p to the blob.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fs.ErrClosed
+	}
+	n, err := w.f.Write(p)
+	w.h.Write(p[:n])
+	return n, err
+}
+
+// Close stores the blob written. Like Put, it syncs the temporary file,
+// checks it against the digest of the data written, and renames it into
+// place, then syncs the directory holding it, so that a blob in the store
+// survives a crash once Close returns. If the blob is already stored,
+// the temporary file is removed instead. On error, the temporary file is
+// removed and the blob is not stored. Close returns fs.ErrClosed if
+// called again.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	tmp := w.f.Name()
+	defer os.Remove(tmp)
+	err := w.f.Sync()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	copy(w.d[:], w.h.Sum(nil))
+
+	name := w.s.path(w.d)
+	if _, err := os.Stat(name); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	if err := verifyFile(tmp, w.d); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+// Abort discards the blob written. It returns fs.ErrClosed after Close or
+// Abort.
+func (w *Writer) Abort() error {
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	err := w.f.Close()
+	if rerr := os.Remove(w.f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// Digest returns the digest of the blob, its address in the store, once
+// Close has succeeded.
+func (w *Writer) Digest() Digest {
+	return w.d
+}
+
+// syncDir syncs the directory dir, so that a rename into it is durable.
+// Directories cannot be opened for syncing on Windows, where it is
+// skipped.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// verifyFile checks that the file name holds the blob with digest d.
+func verifyFile(name string, d Digest) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := blake2b.New(&blake2b.Config{Size: Size})
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	var got Digest
+	copy(got[:], h.Sum(nil))
+	if got != d {
+		return fmt.Errorf("%w: %v written", ErrCorrupt, d)
+	}
+	return nil
+}
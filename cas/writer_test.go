@@ -0,0 +1,69 @@
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("streamed content "), 10000)
+
+	w, err := s.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Digest() != Sum(data) {
+		t.Errorf("Digest = %v, want %v", w.Digest(), Sum(data))
+	}
+	if got, err := s.Get(w.Digest()); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get = %d bytes, %v", len(got), err)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Write after Close: %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Content already stored is discarded, leaving the blob as it was.
+	name := s.path(w.Digest())
+	info, _ := os.Stat(name)
+	w, _ = s.Create()
+	w.Write(data)
+	if err := w.Close(); err != nil || w.Digest() != Sum(data) {
+		t.Errorf("Close of a stored blob = %v, digest %v", err, w.Digest())
+	}
+	if again, _ := os.Stat(name); !os.SameFile(info, again) {
+		t.Error("stored blob replaced")
+	}
+
+	w, _ = s.Create()
+	io.Copy(w, strings.NewReader("aborted"))
+	if err := w.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s.Has(Sum([]byte("aborted"))); ok {
+		t.Error("aborted blob stored")
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+}
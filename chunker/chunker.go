@@ -0,0 +1,225 @@
+// Package chunker splits a stream into content-defined chunks with the
+// FastCDC algorithm and fingerprints each chunk with BLAKE2.
+//
+// Chunk boundaries depend only on the bytes around them, so inserting or
+// removing data in a stream only changes the chunks near the edit; the
+// others keep their digests. That makes the chunks suitable for
+// deduplicating backup and sync tools.
+package chunker
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Default chunk sizes, as suggested by the FastCDC paper.
+const (
+	DefaultMinSize = 2 << 10
+	DefaultAvgSize = 8 << 10
+	DefaultMaxSize = 64 << 10
+)
+
+// Config contains the chunking parameters. The zero value uses the
+// defaults.
+type Config struct {
+	// MinSize, AvgSize and MaxSize bound the chunk lengths and set the
+	// expected length. AvgSize is rounded down to a power of two. If 0,
+	// the defaults are used.
+	MinSize, AvgSize, MaxSize int
+	// New returns the hash used to fingerprint chunks. If nil, 32-byte
+	// BLAKE2b is used.
+	New func() hash.Hash
+}
+
+// Chunk is a chunk of the stream.
+type Chunk struct {
+	// Offset is the position of the chunk in the stream.
+	Offset int64
+	// Length is the chunk length.
+	Length int
+	// Digest is the fingerprint of the chunk.
+	Digest []byte
+	// Data holds the chunk contents. It is only valid until the next
+	// call to Next.
+	Data []byte
+}
+
+// Chunker reads a stream and splits it into chunks.
+type Chunker struct {
+	r      io.Reader
+	h      hash.Hash
+	min    int
+	avg    int
+	max    int
+	maskS  uint64
+	maskL  uint64
+	buf    []byte
+	start  int
+	end    int
+	offset int64
+	err    error
+}
+
+// gear is the table of random values the rolling hash is built from. It
+// is derived from BLAKE2b so that it does not have to be spelled out.
+var gear = func() (g [256]uint64) {
+	var out [64]byte
+	for i := 0; i < len(g); i += 8 {
+		h := blake2b.New(nil)
+		h.Write([]byte{'g', 'e', 'a', 'r', byte(i)})
+		h.Sum(out[:0])
+		for j := 0; j < 8; j++ {
+			g[i+j] = binary.LittleEndian.Uint64(out[8*j:])
+		}
+	}
+	return g
+}()
+
+// ErrInvalidSizes is returned by Config.Validate for chunk sizes that are
+// not ordered MinSize < AvgSize < MaxSize.
+var ErrInvalidSizes = errors.New("chunker: invalid chunk sizes")
+
+// sizes returns the chunk sizes of config, with the defaults filled in and
+// the average rounded down to a power of two, and its exponent.
+func (config *Config) sizes() (min, avg, max, n int) {
+	min, avg, max = DefaultMinSize, DefaultAvgSize, DefaultMaxSize
+	if config != nil {
+		if config.MinSize != 0 {
+			min = config.MinSize
+		}
+		if config.AvgSize != 0 {
+			avg = config.AvgSize
+		}
+		if config.MaxSize != 0 {
+			max = config.MaxSize
+		}
+	}
+	if avg > 0 {
+		n = bits.Len(uint(avg)) - 1
+		avg = 1 << n
+	}
+	return min, avg, max, n
+}
+
+// Validate checks that the sizes of config are ordered MinSize < AvgSize
+// < MaxSize once AvgSize is rounded down to a power of two, as normalized
+// chunking requires, and returns ErrInvalidSizes otherwise. A nil config
+// is valid.
+func (config *Config) Validate() error {
+	min, avg, max, _ := config.sizes()
+	if min <= 0 || min >= avg || avg >= max {
+		return ErrInvalidSizes
+	}
+	return nil
+}
+
+// New returns a Chunker reading from r. It panics with the error of
+// config.Validate if config is invalid; call Validate first for sizes that
+// come from users.
+func New(r io.Reader, config *Config) *Chunker {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	c := &Chunker{r: r}
+	var n int
+	c.min, c.avg, c.max, n = config.sizes()
+	if config != nil && config.New != nil {
+		c.h = config.New()
+	}
+	if c.h == nil {
+		c.h = blake2b.New(&blake2b.Config{Size: 32})
+	}
+
+	// Normalized chunking: below the expected size, cut points are made
+	// harder to find by testing two more bits; above it, easier by
+	// testing two fewer.
+	c.maskS = mask(n + 2)
+	c.maskL = mask(n - 2)
+	c.buf = make([]byte, 2*c.max)
+	return c
+}
+
+// mask returns a mask of the n most significant bits, which are the best
+// mixed ones in the gear hash.
+func mask(n int) uint64 {
+	if n <= 0 {
+		return 0
+	}
+	if n > 64 {
+		n = 64
+	}
+	return ^uint64(0) << (64 - n)
+}
+
+// Next returns the next chunk of the stream. At the end of the stream,
+// it returns io.EOF.
+func (c *Chunker) Next() (Chunk, error) {
+	if c.end-c.start < c.max && c.err == nil {
+		c.fill()
+	}
+	if c.start == c.end {
+		if c.err == nil || c.err == io.EOF {
+			return Chunk{}, io.EOF
+		}
+		return Chunk{}, c.err
+	}
+
+	data := c.buf[c.start:c.end]
+	n := c.cut(data)
+	data = data[:n]
+
+	c.h.Reset()
+	c.h.Write(data)
+	chunk := Chunk{Offset: c.offset, Length: n, Digest: c.h.Sum(nil), Data: data}
+	c.start += n
+	c.offset += int64(n)
+	return chunk, nil
+}
+
+// fill moves the buffered data to the front of the buffer and reads until
+// it holds at least MaxSize bytes or the reader fails.
+func (c *Chunker) fill() {
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	for c.end < c.max && c.err == nil {
+		var n int
+		n, c.err = c.r.Read(c.buf[c.end:])
+		c.end += n
+	}
+}
+
+// cut returns the length of the chunk at the start of data.
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.min {
+		return n
+	}
+	if n > c.max {
+		n = c.max
+	}
+	normal := c.avg
+	if normal > n {
+		normal = n
+	}
+
+	var fp uint64
+	i := c.min
+	for ; i < normal; i++ {
+		fp = fp<<1 + gear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = fp<<1 + gear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func chunks(t *testing.T, data []byte, config *Config) []Chunk {
+	var all []Chunk
+	c := New(bytes.NewReader(data), config)
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return all
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk.Data = append([]byte(nil), chunk.Data...)
+		all = append(all, chunk)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	all := chunks(t, data, nil)
+	var offset int64
+	for i, chunk := range all {
+		if chunk.Offset != offset || chunk.Length != len(chunk.Data) {
+			t.Fatalf("chunk %d: offset %d, length %d, want offset %d", i, chunk.Offset, chunk.Length, offset)
+		}
+		if chunk.Length > DefaultMaxSize || (chunk.Length < DefaultMinSize && i != len(all)-1) {
+			t.Errorf("chunk %d: length %d out of bounds", i, chunk.Length)
+		}
+		if !bytes.Equal(chunk.Data, data[offset:offset+int64(chunk.Length)]) {
+			t.Fatalf("chunk %d: wrong data", i)
+		}
+		h := blake2b.New(&blake2b.Config{Size: 32})
+		h.Write(chunk.Data)
+		if !bytes.Equal(chunk.Digest, h.Sum(nil)) {
+			t.Errorf("chunk %d: wrong digest", i)
+		}
+		offset += int64(chunk.Length)
+	}
+	if offset != int64(len(data)) {
+		t.Errorf("chunks cover %d bytes, want %d", offset, len(data))
+	}
+	if avg := len(data) / len(all); avg < DefaultAvgSize/2 || avg > DefaultAvgSize*2 {
+		t.Errorf("average chunk length %d, want about %d", avg, DefaultAvgSize)
+	}
+}
+
+func TestChunksSurviveInsertion(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(2)).Read(data)
+	edited := append(append(append([]byte(nil), data[:1000]...), "inserted"...), data[1000:]...)
+
+	seen := make(map[string]bool)
+	for _, chunk := range chunks(t, data, nil) {
+		seen[string(chunk.Digest)] = true
+	}
+	all := chunks(t, edited, nil)
+	changed := 0
+	for _, chunk := range all {
+		if !seen[string(chunk.Digest)] {
+			changed++
+		}
+	}
+	if changed > 2 {
+		t.Errorf("%d of %d chunks changed after a small insertion", changed, len(all))
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	if all := chunks(t, nil, nil); len(all) != 0 {
+		t.Errorf("got %d chunks for empty input", len(all))
+	}
+}
+
+func TestInvalidSizes(t *testing.T) {
+	for _, config := range []*Config{
+		{MinSize: 4096, AvgSize: 1024},
+		// AvgSize is rounded down to 4096, below MinSize.
+		{MinSize: 5000, AvgSize: 6000, MaxSize: 65536},
+		{MinSize: -1},
+		{AvgSize: 1 << 20},
+	} {
+		if err := config.Validate(); err != ErrInvalidSizes {
+			t.Errorf("%+v: got %v, want ErrInvalidSizes", config, err)
+		}
+	}
+	if err := (*Config)(nil).Validate(); err != nil {
+		t.Errorf("nil config: %v", err)
+	}
+	if err := (&Config{MinSize: 4000, AvgSize: 6000}).Validate(); err != nil {
+		t.Errorf("AvgSize rounded to 4096: %v", err)
+	}
+
+	defer func() {
+		if recover() != ErrInvalidSizes {
+			t.Error("New did not panic with ErrInvalidSizes")
+		}
+	}()
+	New(bytes.NewReader(nil), &Config{MinSize: 4096, AvgSize: 1024})
+}
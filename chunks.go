@@ -0,0 +1,75 @@
+package blake2
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrChunkSize is returned by NewChunkScanner for chunk sizes that are not
+// positive.
+var ErrChunkSize = errors.New("blake2: invalid chunk size")
+
+// ChunkScanner reads a stream in chunks of a fixed size and hashes each of
+// them, so that per-chunk integrity data can be produced as the stream is
+// read, without holding the whole list.
+type ChunkScanner struct {
+	r     io.Reader
+	cfg   *Config
+	h     Hasher
+	buf   []byte
+	index int
+	sum   []byte
+	err   error
+}
+
+// NewChunkScanner returns a ChunkScanner reading r in chunks of chunkSize
+// bytes, the last one possibly shorter. Chunks are hashed with cfg, of the
+// variant given by its Variant field; cfg may be nil.
+func NewChunkScanner(r io.Reader, chunkSize int, cfg *Config) (*ChunkScanner, error) {
+	if chunkSize <= 0 {
+		return nil, ErrChunkSize
+	}
+	h, err := newConfigHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkScanner{r: r, cfg: cfg, h: h, buf: make([]byte, chunkSize), index: -1}, nil
+}
+
+// Scan reads and hashes the next chunk. It returns false at the end of the
+// input or on an error, reported by Err.
+func (cs *ChunkScanner) Scan() bool {
+	if cs.err != nil {
+		return false
+	}
+	n, err := io.ReadFull(cs.r, cs.buf)
+	if n == 0 || err != nil && err != io.ErrUnexpectedEOF {
+		cs.err = err
+		cs.sum = nil
+		return false
+	}
+	cs.h.Reset()
+	cs.h.Write(cs.buf[:n])
+	cs.sum = cs.h.Sum(nil)
+	cs.index++
+	return true
+}
+
+// Index returns the index of the current chunk, from 0.
+func (cs *ChunkScanner) Index() int {
+	return cs.index
+}
+
+// Digest returns the digest of the current chunk. Its Sum is not modified
+// by later calls to Scan.
+func (cs *ChunkScanner) Digest() Digest {
+	return Digest{Config: cs.cfg, Sum: cs.sum}
+}
+
+// Err returns the first error other than io.EOF encountered by Scan.
+func (cs *ChunkScanner) Err() error {
+	if cs.err == io.EOF {
+		return nil
+	}
+	return cs.err
+}
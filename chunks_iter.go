@@ -0,0 +1,35 @@
+//go:build go1.23
+// +build go1.23
+
+package blake2
+
+import (
+	"io"
+	"iter"
+)
+
+// ChunkSums returns an iterator over the indexes and BLAKE2b-512 digests
+// of the chunks of chunkSize bytes read from r, the last one possibly
+// shorter. Chunks are read and hashed lazily, as the iteration proceeds.
+// The iteration stops at the first read error, which err reports once
+// the iteration is over, as ChunkScanner.Err does. ChunkSums panics if
+// chunkSize is not positive.
+func ChunkSums(r io.Reader, chunkSize int) (seq iter.Seq2[int, Digest], err func() error) {
+	if chunkSize <= 0 {
+		panic(ErrChunkSize)
+	}
+	var scanErr error
+	seq = func(yield func(int, Digest) bool) {
+		cs, err := NewChunkScanner(r, chunkSize, nil)
+		if err != nil {
+			panic(err)
+		}
+		for cs.Scan() {
+			if !yield(cs.Index(), cs.Digest()) {
+				return
+			}
+		}
+		scanErr = cs.Err()
+	}
+	return seq, func() error { return scanErr }
+}
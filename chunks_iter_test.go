@@ -0,0 +1,61 @@
+//go:build go1.23
+// +build go1.23
+
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestChunkSums(t *testing.T) {
+	data := bytes.Repeat([]byte("chunk"), 20)
+	var n int
+	sums, sumsErr := ChunkSums(bytes.NewReader(data), 30)
+	for i, d := range sums {
+		if i != n {
+			t.Errorf("index %d, want %d", i, n)
+		}
+		end := (i + 1) * 30
+		if end > len(data) {
+			end = len(data)
+		}
+		if !bytes.Equal(d.Sum, digestOf(t, d, data[i*30:end])) || len(d.Sum) != 64 {
+			t.Errorf("chunk %d: digest %x does not match", i, d.Sum)
+		}
+		n++
+	}
+	if n != 4 {
+		t.Errorf("%d chunks, want 4", n)
+	}
+	if err := sumsErr(); err != nil {
+		t.Error(err)
+	}
+
+	sums, _ = ChunkSums(bytes.NewReader(data), 30)
+	for i := range sums {
+		if i > 0 {
+			t.Fatal("iteration continued after break")
+		}
+		break
+	}
+}
+
+func TestChunkSumsReadError(t *testing.T) {
+	errRead := errors.New("read failed")
+	r := io.MultiReader(bytes.NewReader(make([]byte, 70)), iotest.ErrReader(errRead))
+	sums, sumsErr := ChunkSums(r, 30)
+	var n int
+	for range sums {
+		n++
+	}
+	if n > 2 {
+		t.Errorf("%d chunks before the error, want at most 2", n)
+	}
+	if err := sumsErr(); err != errRead {
+		t.Errorf("err = %v, want %v", err, errRead)
+	}
+}
@@ -0,0 +1,65 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestChunkScanner(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 25)
+	cfg := &Config{Variant: BLAKE2s, Size: 16}
+	cs, err := NewChunkScanner(iotest.OneByteReader(bytes.NewReader(data)), 100, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for ; cs.Scan(); n++ {
+		if cs.Index() != n {
+			t.Errorf("Index = %d, want %d", cs.Index(), n)
+		}
+		end := (n + 1) * 100
+		if end > len(data) {
+			end = len(data)
+		}
+		d := cs.Digest()
+		if d.Config != cfg || !bytes.Equal(d.Sum, digestOf(t, d, data[n*100:end])) {
+			t.Errorf("chunk %d: digest %x does not match", n, d.Sum)
+		}
+	}
+	if err := cs.Err(); err != nil || n != 3 {
+		t.Errorf("scanned %d chunks, error %v; want 3, nil", n, err)
+	}
+
+	if _, err := NewChunkScanner(strings.NewReader(""), 0, nil); err != ErrChunkSize {
+		t.Errorf("chunk size 0: error %v, want ErrChunkSize", err)
+	}
+}
+
+func TestChunkScannerError(t *testing.T) {
+	fail := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("abcd"), iotest.ErrReader(fail))
+	cs, err := NewChunkScanner(r, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cs.Scan() {
+		t.Fatal("first chunk not scanned")
+	}
+	if cs.Scan() || cs.Err() != fail {
+		t.Errorf("Err = %v, want %v", cs.Err(), fail)
+	}
+}
+
+// digestOf returns the digest of data computed as d describes.
+func digestOf(t *testing.T, d Digest, data []byte) []byte {
+	h, err := d.newHasher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write(data)
+	return h.Sum(nil)
+}
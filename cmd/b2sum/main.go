@@ -0,0 +1,223 @@
+// Command b2sum prints or checks BLAKE2 checksums, like the b2sum of GNU
+// coreutils, and keyed BLAKE2 MACs.
+//
+// Usage:
+//
+//	b2sum [-a blake2b|blake2s] [-l bits] [--tag] [--key key] [file ...]
+//	b2sum -c [--key key] [list ...]
+//	b2sum --keyed-check --key key [list ...]
+//	b2sum --bench [-a blake2b|blake2s] [--bench-time duration]
+//
+// With no file, or when a file is -, standard input is read. A key is the
+// name of a file holding it, or else its hexadecimal encoding; with a key,
+// the listed digests are MACs, which only holders of the key can produce
+// or check. --keyed-check checks like -c, but requires a key, so that a
+// list of MACs is never accepted as plain checksums by mistake.
+//
+// --bench prints the throughput of each implementation available in the
+// build on this machine, for a range of message sizes, to help choose one.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jadeydi/blake2"
+	"github.com/jadeydi/blake2/sumfile"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// osFS opens files by their names on the command line, relative or
+// absolute, which os.DirFS rejects.
+type osFS struct {
+	stdin io.Reader
+}
+
+func (o osFS) Open(name string) (fs.File, error) {
+	if name == "-" {
+		return stdinFile{o.stdin}, nil
+	}
+	return os.Open(name)
+}
+
+// stdinFile is standard input as an fs.File.
+type stdinFile struct {
+	io.Reader
+}
+
+func (stdinFile) Stat() (fs.FileInfo, error) { return nil, errors.New("b2sum: stat of standard input") }
+func (stdinFile) Close() error               { return nil }
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("b2sum", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	alg := flags.String("a", "blake2b", "algorithm, blake2b or blake2s")
+	bits := flags.Int("l", 0, "digest length in bits, a multiple of 8; 0 for the largest")
+	tag := flags.Bool("tag", false, "print BSD-style tagged lines")
+	check := flags.Bool("c", false, "check the checksums listed in the files")
+	keyedCheck := flags.Bool("keyed-check", false, "check MACs listed in the files; requires --key")
+	keyArg := flags.String("key", "", "key, as a file name or hexadecimal, for MACs")
+	bench := flags.Bool("bench", false, "measure the throughput of each implementation")
+	benchTime := flags.Duration("bench-time", 250*time.Millisecond, "time to measure each implementation and size for")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *bench {
+		return runBench(*alg, *benchTime, stdout, stderr)
+	}
+
+	var key []byte
+	if *keyArg != "" {
+		var err error
+		if key, err = sumfile.LoadKey(*keyArg); err != nil {
+			fmt.Fprintln(stderr, "b2sum:", err)
+			return 2
+		}
+	}
+	if *keyedCheck && key == nil {
+		fmt.Fprintln(stderr, "b2sum: --keyed-check requires --key")
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	fsys := osFS{stdin}
+	if *check || *keyedCheck {
+		return checkLists(fsys, files, key, stdout, stderr)
+	}
+
+	algorithm, err := algorithmName(*alg, *bits)
+	if err != nil {
+		fmt.Fprintln(stderr, "b2sum:", err)
+		return 2
+	}
+	// Untagged lines are taken as BLAKE2b, so BLAKE2s needs tags.
+	w := sumfile.NewWriter(stdout, *tag || strings.HasPrefix(algorithm, "BLAKE2s"))
+	status := 0
+	for _, name := range files {
+		e, err := sum(fsys, name, algorithm, key)
+		if err == nil {
+			err = w.Write(e)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "b2sum: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// algorithmName returns the sumfile algorithm tag of alg with a digest of
+// bits bits, or the largest digest if bits is 0.
+func algorithmName(alg string, bits int) (string, error) {
+	var name string
+	var max int
+	switch strings.ToLower(alg) {
+	case "blake2b":
+		name, max = "BLAKE2b", 512
+	case "blake2s":
+		name, max = "BLAKE2s", 256
+	default:
+		return "", fmt.Errorf("unknown algorithm %q", alg)
+	}
+	if bits == 0 {
+		bits = max
+	}
+	if bits < 8 || bits > max || bits%8 != 0 {
+		return "", fmt.Errorf("invalid length %d for %s", bits, name)
+	}
+	return fmt.Sprintf("%s-%d", name, bits), nil
+}
+
+// runBench prints the throughput of each backend for the algorithm alg,
+// and returns the exit status.
+func runBench(alg string, d time.Duration, stdout, stderr io.Writer) int {
+	var v blake2.Variant
+	switch strings.ToLower(alg) {
+	case "blake2b":
+		v = blake2.BLAKE2b
+	case "blake2s":
+		v = blake2.BLAKE2s
+	default:
+		fmt.Fprintf(stderr, "b2sum: unknown algorithm %q\n", alg)
+		return 2
+	}
+	results, err := blake2.MeasureBackends(v, nil, d)
+	if err != nil {
+		fmt.Fprintln(stderr, "b2sum:", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%v\tsize\tMB/s\t\n", v)
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t\n", r.Backend, r.Size, r.BytesPerSecond/1e6)
+	}
+	w.Flush()
+	return 0
+}
+
+// sum returns the entry of the file name, hashed with algorithm and key.
+func sum(fsys fs.FS, name, algorithm string, key []byte) (sumfile.Entry, error) {
+	e := sumfile.Entry{Algorithm: algorithm, Path: name}
+	h, err := e.NewKeyedHash(key)
+	if err != nil {
+		return e, err
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return e, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return e, err
+	}
+	e.Digest = h.Sum(nil)
+	return e, nil
+}
+
+// checkLists checks the files listed in the checksum lists files, and
+// returns the exit status: 0 if all match, 1 otherwise.
+func checkLists(fsys osFS, files []string, key []byte, stdout, stderr io.Writer) int {
+	status := 0
+	for _, name := range files {
+		f, err := fsys.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "b2sum: %v\n", err)
+			status = 1
+			continue
+		}
+		failed, err := sumfile.VerifyKeyed(f, fsys, key, func(r sumfile.Result) {
+			switch {
+			case r.Err == nil:
+				fmt.Fprintf(stdout, "%s: OK\n", r.Path)
+			case errors.Is(r.Err, sumfile.ErrMismatch):
+				fmt.Fprintf(stdout, "%s: FAILED\n", r.Path)
+			default:
+				fmt.Fprintf(stdout, "%s: FAILED open or read\n", r.Path)
+				fmt.Fprintf(stderr, "b2sum: %s: %v\n", r.Path, r.Err)
+			}
+		})
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "b2sum: %s: %v\n", name, err)
+			status = 1
+		}
+		if failed > 0 {
+			fmt.Fprintf(stderr, "b2sum: WARNING: %d computed checksums did NOT match\n", failed)
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func b2sum(t *testing.T, stdin string, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), status
+}
+
+func TestSum(t *testing.T) {
+	// The BLAKE2b-512 digest of "abc".
+	const abc = "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	if out, status := b2sum(t, "abc"); status != 0 || out != abc+"  -\n" {
+		t.Errorf("b2sum = %q, %d", out, status)
+	}
+	if out, _ := b2sum(t, "abc", "-a", "blake2s", "-l", "128"); !strings.HasPrefix(out, "BLAKE2s-128 (-) = ") {
+		t.Errorf("BLAKE2s output %q is not tagged", out)
+	}
+	if _, status := b2sum(t, "", "-l", "12"); status != 2 {
+		t.Errorf("invalid length: status %d, want 2", status)
+	}
+}
+
+func TestKeyedCheck(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	keyFile := filepath.Join(dir, "key")
+	os.WriteFile(data, []byte("payload"), 0644)
+	os.WriteFile(keyFile, []byte("secret key"), 0600)
+
+	list, status := b2sum(t, "", "--key", keyFile, "-l", "256", data)
+	if status != 0 {
+		t.Fatalf("b2sum --key: status %d", status)
+	}
+	plain, _ := b2sum(t, "", "-l", "256", data)
+	if list == plain {
+		t.Fatal("keyed and unkeyed digests are equal")
+	}
+
+	if out, status := b2sum(t, list, "--keyed-check", "--key", keyFile); status != 0 || out != data+": OK\n" {
+		t.Errorf("--keyed-check = %q, %d", out, status)
+	}
+	if out, status := b2sum(t, list, "--keyed-check", "--key", "0123"); status != 1 || out != data+": FAILED\n" {
+		t.Errorf("--keyed-check with the wrong key = %q, %d", out, status)
+	}
+	if _, status := b2sum(t, list, "--keyed-check"); status != 2 {
+		t.Errorf("--keyed-check without a key: status %d, want 2", status)
+	}
+	if _, status := b2sum(t, list, "-c"); status != 1 {
+		t.Errorf("-c of a keyed list without the key: status %d, want 1", status)
+	}
+}
+
+func TestBench(t *testing.T) {
+	out, status := b2sum(t, "", "--bench", "-a", "blake2s", "--bench-time", "1ms")
+	if status != 0 || !strings.Contains(out, "BLAKE2s") || !strings.Contains(out, "pure-go") {
+		t.Errorf("b2sum --bench = %q, %d", out, status)
+	}
+	if _, status := b2sum(t, "", "--bench", "-a", "md5"); status != 2 {
+		t.Errorf("--bench of an unknown algorithm: status %d, want 2", status)
+	}
+}
@@ -0,0 +1,39 @@
+package blake2
+
+import (
+	"crypto/rand"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// commitPersonal separates commitments from other uses of BLAKE2b.
+var commitPersonal = []byte("blake2 commit")
+
+// Commit returns a commitment to value and the opening that reveals it.
+// The opening is a random 32-byte key, and the commitment the 32-byte
+// BLAKE2b MAC of value under that key: it hides value until the opening
+// is published, as long as value is not guessable, and binds the
+// committer to value, as finding another value and opening with the same
+// commitment is as hard as finding a BLAKE2b collision. It panics if the
+// system random source fails.
+func Commit(value []byte) (commitment, opening []byte) {
+	opening = make([]byte, blake2b.KeySize)
+	if _, err := rand.Read(opening); err != nil {
+		panic("blake2: unable to read random opening: " + err.Error())
+	}
+	return commit(value, opening), opening
+}
+
+// VerifyOpening reports whether opening opens commitment to value.
+func VerifyOpening(commitment, value, opening []byte) bool {
+	if len(opening) != blake2b.KeySize {
+		return false
+	}
+	return Equal(commit(value, opening), commitment)
+}
+
+func commit(value, opening []byte) []byte {
+	h := blake2b.New(&blake2b.Config{Size: 32, Key: opening, Personal: commitPersonal})
+	h.Write(value)
+	return h.Sum(nil)
+}
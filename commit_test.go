@@ -0,0 +1,27 @@
+package blake2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommit(t *testing.T) {
+	value := []byte("my sealed bid: 42")
+	c, o := Commit(value)
+	if len(c) != 32 || len(o) != 32 {
+		t.Fatalf("commitment of %d bytes, opening of %d bytes", len(c), len(o))
+	}
+	if !VerifyOpening(c, value, o) {
+		t.Error("opening does not verify")
+	}
+	if VerifyOpening(c, []byte("my sealed bid: 43"), o) {
+		t.Error("opening verifies another value")
+	}
+	if VerifyOpening(c, value, o[:31]) {
+		t.Error("truncated opening verifies")
+	}
+	c2, o2 := Commit(value)
+	if bytes.Equal(c, c2) || bytes.Equal(o, o2) {
+		t.Error("commitments to the same value are equal")
+	}
+}
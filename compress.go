@@ -0,0 +1,51 @@
+package blake2
+
+import "io"
+
+// CompressingWriter writes data to a compressor, such as a gzip.Writer,
+// while hashing the uncompressed bytes, so that an artifact can be
+// compressed and its digest computed in a single pass.
+type CompressingWriter struct {
+	c io.Writer
+	h Hasher
+	n int64
+}
+
+// NewCompressingWriter returns a CompressingWriter writing to compressor,
+// which writes the compressed output to its own destination, and hashing
+// with cfg, of the variant given by its Variant field; cfg may be nil.
+func NewCompressingWriter(compressor io.Writer, cfg *Config) (*CompressingWriter, error) {
+	h, err := newConfigHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressingWriter{c: compressor, h: h}, nil
+}
+
+// Write compresses and hashes p. Only the bytes accepted by the
+// compressor are hashed.
+func (w *CompressingWriter) Write(p []byte) (int, error) {
+	n, err := w.c.Write(p)
+	w.h.Write(p[:n])
+	w.n += int64(n)
+	return n, err
+}
+
+// Close closes the compressor if it is an io.Closer, flushing the
+// compressed output. It does not close the compressor's destination.
+func (w *CompressingWriter) Close() error {
+	if c, ok := w.c.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Sum returns the digest of the uncompressed bytes written so far.
+func (w *CompressingWriter) Sum() []byte {
+	return w.h.Sum(nil)
+}
+
+// Len returns the number of uncompressed bytes written so far.
+func (w *CompressingWriter) Len() int64 {
+	return w.n
+}
@@ -0,0 +1,49 @@
+package blake2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCompressingWriter(t *testing.T) {
+	data := []byte(strings.Repeat("compressible data ", 1000))
+	var out bytes.Buffer
+	w, err := NewCompressingWriter(gzip.NewWriter(&out), &Config{Size: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Len() != int64(len(data)) || out.Len() >= len(data) {
+		t.Errorf("wrote %d bytes, compressed to %d", w.Len(), out.Len())
+	}
+
+	r, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("decompressed %d bytes, %v", len(got), err)
+	}
+	h, _ := NewHasher(BLAKE2b, &Config{Size: 32})
+	h.Write(data)
+	if !bytes.Equal(w.Sum(), h.Sum(nil)) {
+		t.Errorf("digest %x, want %x", w.Sum(), h.Sum(nil))
+	}
+
+	// Writers that are not closers are fine too.
+	var plain bytes.Buffer
+	w, _ = NewCompressingWriter(&plain, nil)
+	w.Write(data)
+	if err := w.Close(); err != nil || plain.Len() != len(data) {
+		t.Errorf("Close = %v, wrote %d bytes", err, plain.Len())
+	}
+}
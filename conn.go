@@ -0,0 +1,82 @@
+package blake2
+
+import (
+	"net"
+	"sync"
+)
+
+// Conn is a net.Conn that hashes the bytes written to and read from it,
+// so that protocols can derive transcript digests, as for channel
+// binding, without buffering the transcript.
+type Conn struct {
+	net.Conn
+	send, recv transcript
+}
+
+// transcript is a hash that can be summed while another goroutine writes
+// to it.
+type transcript struct {
+	mu sync.Mutex
+	h  Hasher
+}
+
+func (t *transcript) write(p []byte) {
+	t.mu.Lock()
+	t.h.Write(p)
+	t.mu.Unlock()
+}
+
+func (t *transcript) sum() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.h.Sum(nil)
+}
+
+// WrapConn returns a Conn hashing the bytes written to c with sendCfg and
+// those read from c with recvCfg, of the variants given by their Variant
+// fields. Either config may be nil.
+func WrapConn(c net.Conn, sendCfg, recvCfg *Config) (*Conn, error) {
+	send, err := newConfigHasher(sendCfg)
+	if err != nil {
+		return nil, err
+	}
+	recv, err := newConfigHasher(recvCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: c, send: transcript{h: send}, recv: transcript{h: recv}}, nil
+}
+
+// newConfigHasher returns a hash configured by cfg, of the variant given
+// by its Variant field, BLAKE2b if unset.
+func newConfigHasher(cfg *Config) (Hasher, error) {
+	variant := BLAKE2b
+	if cfg != nil && cfg.Variant != 0 {
+		variant = cfg.Variant
+	}
+	return NewHasher(variant, cfg)
+}
+
+// Read reads from the connection and hashes the bytes read.
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.recv.write(p[:n])
+	return n, err
+}
+
+// Write writes to the connection and hashes the bytes written.
+func (c *Conn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.send.write(p[:n])
+	return n, err
+}
+
+// SendSum returns the digest of the bytes written so far.
+func (c *Conn) SendSum() []byte {
+	return c.send.sum()
+}
+
+// RecvSum returns the digest of the bytes read so far.
+func (c *Conn) RecvSum() []byte {
+	return c.recv.sum()
+}
@@ -0,0 +1,51 @@
+package blake2
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWrapConn(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+	cfg := &Config{Variant: BLAKE2s, Personal: []byte("transcr")}
+	ca, err := WrapConn(a, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ca.Close()
+	cb, err := WrapConn(b, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		ca.Write([]byte("hello, "))
+		ca.Write([]byte("world"))
+		io.Copy(io.Discard, ca)
+	}()
+	buf := make([]byte, 12)
+	if _, err := io.ReadFull(cb, buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Write([]byte("reply")); err != nil {
+		t.Fatal(err)
+	}
+
+	h, _ := NewHasher(BLAKE2b, nil)
+	h.Write([]byte("hello, world"))
+	if !bytes.Equal(ca.SendSum(), h.Sum(nil)) || !bytes.Equal(cb.RecvSum(), h.Sum(nil)) {
+		t.Errorf("sent digests %x and %x, want %x", ca.SendSum(), cb.RecvSum(), h.Sum(nil))
+	}
+	h, _ = NewHasher(BLAKE2s, cfg)
+	h.Write([]byte("reply"))
+	if !bytes.Equal(cb.SendSum(), h.Sum(nil)) {
+		t.Errorf("reply digest %x, want %x", cb.SendSum(), h.Sum(nil))
+	}
+
+	if _, err := WrapConn(a, &Config{Variant: BLAKE2s, Size: 64}, nil); err == nil {
+		t.Error("WrapConn accepted an invalid config")
+	}
+}
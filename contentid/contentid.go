@@ -0,0 +1,81 @@
+// Package contentid mints short identifiers for objects from their
+// content, as BLAKE2b digests encoded in base32 or base58. The same
+// content always gets the same ID, and different contents get different
+// IDs as long as the digest is long enough to make collisions
+// improbable: with the default 16-byte digests, about 2^64 objects.
+package contentid
+
+import (
+	"hash"
+	"io"
+
+	"github.com/jadeydi/blake2"
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Encoding is the text encoding of IDs.
+type Encoding int
+
+const (
+	// Base32 is lowercase RFC 4648 base32 without padding, which is
+	// case-insensitive and safe in file names and URLs.
+	Base32 Encoding = iota
+	// Base58 is base58 with the Bitcoin alphabet, which is shorter and
+	// has no look-alike characters.
+	Base58
+)
+
+// DefaultSize is the digest size used when Generator.Size is 0.
+const DefaultSize = 16
+
+// Generator mints IDs. The zero value mints base32 IDs of 16-byte digests
+// without namespace.
+type Generator struct {
+	// Size is the digest size in bytes, in the range [1, 64]. If 0,
+	// DefaultSize is used.
+	Size int
+	// Encoding is the text encoding of IDs.
+	Encoding Encoding
+	// Namespace, if not empty, is the domain of the hash (see
+	// blake2b.NewWithDomain), so that the same content gets unrelated
+	// IDs in different namespaces.
+	Namespace string
+}
+
+// ID returns the ID of content. It panics if g.Size is out of range.
+func (g *Generator) ID(content []byte) string {
+	h := g.newHash()
+	h.Write(content)
+	return g.encode(h.Sum(nil))
+}
+
+// IDFromReader returns the ID of the content read from r until EOF.
+func (g *Generator) IDFromReader(r io.Reader) (string, error) {
+	h := g.newHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return g.encode(h.Sum(nil)), nil
+}
+
+func (g *Generator) newHash() hash.Hash {
+	size := g.Size
+	if size == 0 {
+		size = DefaultSize
+	}
+	if size < 0 || size > blake2b.MaxDigestSize {
+		panic("contentid: digest size out of range")
+	}
+	config := &blake2b.Config{Size: uint8(size)}
+	if g.Namespace != "" {
+		return blake2b.NewWithDomain(g.Namespace, config)
+	}
+	return blake2b.New(config)
+}
+
+func (g *Generator) encode(sum []byte) string {
+	if g.Encoding == Base58 {
+		return blake2.Base58.Encode(sum)
+	}
+	return blake2.Base32.Encode(sum)
+}
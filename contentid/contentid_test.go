@@ -0,0 +1,43 @@
+package contentid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	content := []byte("object content")
+	var g Generator
+	id := g.ID(content)
+	if len(id) != 26 || strings.ToLower(id) != id {
+		t.Errorf("ID %q is not 26 lowercase base32 characters", id)
+	}
+	if id != g.ID(content) {
+		t.Error("ID is not deterministic")
+	}
+	if got, err := g.IDFromReader(strings.NewReader(string(content))); err != nil || got != id {
+		t.Errorf("IDFromReader returned %q, %v, want %q", got, err, id)
+	}
+	if g.ID([]byte("other content")) == id {
+		t.Error("different contents get the same ID")
+	}
+
+	ns := Generator{Namespace: "users"}
+	if ns.ID(content) == id {
+		t.Error("namespace does not change the ID")
+	}
+	short := Generator{Size: 8, Encoding: Base58}
+	if id := short.ID(content); len(id) < 8 || len(id) > 11 {
+		t.Errorf("8-byte base58 ID %q has unexpected length", id)
+	}
+}
+
+func TestSizeOutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("ID did not panic")
+		}
+	}()
+	g := Generator{Size: 65}
+	g.ID(nil)
+}
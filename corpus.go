@@ -0,0 +1,32 @@
+package blake2
+
+import (
+	"io"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// corpusPersonal separates generated test data from other uses of
+// BLAKE2Xb.
+var corpusPersonal = []byte("blake2 corpus")
+
+// GenerateBytes returns n pseudorandom bytes determined by label, so that
+// integration tests and load generators can reproduce the same payloads
+// in every service from short labels, such as "upload/large-1". They are
+// the first n bytes of the BLAKE2Xb output of unknown length, unkeyed and
+// personalized with "blake2 corpus", of the label: shorter payloads of a
+// label are prefixes of longer ones, and other implementations of
+// BLAKE2X can derive them too. The output is not secret; do not use it
+// for keys. GenerateBytes panics if n is negative or above 256 GiB.
+func GenerateBytes(label string, n int) []byte {
+	x, err := blake2b.NewXOF(blake2b.UnknownOutputLength, &blake2b.Config{Personal: corpusPersonal})
+	if err != nil {
+		panic(err)
+	}
+	x.Write([]byte(label))
+	b := make([]byte, n)
+	if _, err := io.ReadFull(x, b); err != nil {
+		panic(err)
+	}
+	return b
+}
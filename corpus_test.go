@@ -0,0 +1,26 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateBytes(t *testing.T) {
+	long := GenerateBytes("upload/large-1", 100000)
+	if short := GenerateBytes("upload/large-1", 100); !bytes.Equal(short, long[:100]) {
+		t.Error("shorter output is not a prefix of the longer one")
+	}
+	if other := GenerateBytes("upload/large-2", 100); bytes.Equal(other, long[:100]) {
+		t.Error("different labels give the same bytes")
+	}
+	if len(GenerateBytes("", 0)) != 0 {
+		t.Error("GenerateBytes(\"\", 0) is not empty")
+	}
+
+	// The derivation is stable across releases.
+	const want = "54e33d5fa72e1f77"
+	if got := hex.EncodeToString(GenerateBytes("example", 8)); got != want {
+		t.Errorf("GenerateBytes(example, 8) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+// Package dedup indexes chunks by their BLAKE2 fingerprints, so that
+// backup and sync tools store each distinct chunk once. Together with the
+// chunker package, it makes a deduplication pipeline:
+//
+//	idx := dedup.NewIndex(nil, 1e6)
+//	recipe, err := idx.Dedup(file, nil, func(c chunker.Chunk) (dedup.Location, error) {
+//		// Store c.Data somewhere new and return where.
+//	})
+//
+// The recipe, the list of chunk digests, rebuilds the file from the
+// stored chunks.
+package dedup
+
+import (
+	"io"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/chunker"
+)
+
+// Location is where a chunk is stored.
+type Location struct {
+	// Container names the file, object or pack holding the chunk.
+	Container string
+	// Offset is the position of the chunk in its container.
+	Offset int64
+	// Length is the chunk length.
+	Length int
+}
+
+// Store maps chunk digests to locations. MemoryStore keeps them in
+// memory; an on-disk store, such as a key-value database, makes the index
+// persistent.
+type Store interface {
+	// Get returns the location of the chunk with digest, and false if
+	// there is none.
+	Get(digest []byte) (Location, bool, error)
+	// Put records the location of the chunk with digest.
+	Put(digest []byte, loc Location) error
+}
+
+// MemoryStore is a Store in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	locs map[string]Location
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{locs: make(map[string]Location)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(digest []byte) (Location, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	loc, ok := s.locs[string(digest)]
+	return loc, ok, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(digest []byte, loc Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locs[string(digest)] = loc
+	return nil
+}
+
+// Len returns the number of chunks recorded.
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.locs)
+}
+
+// Index is a chunk index with a Bloom filter in front of its store, so
+// that looking up new chunks, the common case when backing up new data,
+// rarely reaches a slow store. It is not safe for concurrent use.
+type Index struct {
+	store  Store
+	filter *bloom
+}
+
+// bloomK is the number of filter bits per digest, which with
+// bloomBitsPerEntry bits per expected entry gives about 1% false
+// positives.
+const (
+	bloomK            = 7
+	bloomBitsPerEntry = 10
+)
+
+// NewIndex returns an index over store, sized for about expected chunks;
+// more chunks raise the rate of false positives of the filter, which
+// only costs store lookups. If store is nil, a new MemoryStore is used.
+// Chunks already in store are not added to the filter: insert them again
+// after opening an existing store.
+func NewIndex(store Store, expected int) *Index {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Index{store: store, filter: newBloom(expected)}
+}
+
+// bloom is a Bloom filter of digests.
+type bloom struct {
+	bits []uint64
+	m    uint64
+}
+
+// newBloom returns a filter sized for about expected digests.
+func newBloom(expected int) *bloom {
+	if expected < 1 {
+		expected = 1
+	}
+	m := uint64(expected) * bloomBitsPerEntry
+	return &bloom{bits: make([]uint64, (m+63)/64), m: m}
+}
+
+func (b *bloom) mayContain(digest []byte) bool {
+	for _, i := range blake2b.HashK(digest, bloomK, b.m) {
+		if b.bits[i/64]&(1<<(i%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bloom) add(digest []byte) {
+	for _, i := range blake2b.HashK(digest, bloomK, b.m) {
+		b.bits[i/64] |= 1 << (i % 64)
+	}
+}
+
+// MayContain reports whether the chunk with digest may be in the index.
+// False means it is certainly not.
+func (x *Index) MayContain(digest []byte) bool {
+	return x.filter.mayContain(digest)
+}
+
+// Lookup returns the location of the chunk with digest, and false if it
+// is not in the index.
+func (x *Index) Lookup(digest []byte) (Location, bool, error) {
+	if !x.MayContain(digest) {
+		return Location{}, false, nil
+	}
+	return x.store.Get(digest)
+}
+
+// Insert records the location of the chunk with digest.
+func (x *Index) Insert(digest []byte, loc Location) error {
+	if err := x.store.Put(digest, loc); err != nil {
+		return err
+	}
+	x.filter.add(digest)
+	return nil
+}
+
+// Dedup splits r into chunks with the chunker configured by config, which
+// may be nil, and calls save for each chunk not in the index, inserting
+// it at the location save returns. It returns the digests of all the
+// chunks of r, in order, and the error of config.Validate for invalid
+// configs.
+func (x *Index) Dedup(r io.Reader, config *chunker.Config, save func(chunker.Chunk) (Location, error)) ([][]byte, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var recipe [][]byte
+	c := chunker.New(r, config)
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return recipe, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		recipe = append(recipe, chunk.Digest)
+		_, ok, err := x.Lookup(chunk.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		loc, err := save(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if err := x.Insert(chunk.Digest, loc); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package dedup
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/chunker"
+)
+
+func TestIndex(t *testing.T) {
+	x := NewIndex(nil, 1000)
+	digest := func(i int) []byte {
+		h := blake2b.New(&blake2b.Config{Size: 32})
+		fmt.Fprint(h, i)
+		return h.Sum(nil)
+	}
+	for i := 0; i < 1000; i++ {
+		if err := x.Insert(digest(i), Location{Container: "pack", Offset: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		loc, ok, err := x.Lookup(digest(i))
+		if err != nil || !ok || loc.Offset != int64(i) {
+			t.Fatalf("Lookup(%d) = %+v, %v, %v", i, loc, ok, err)
+		}
+	}
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if x.MayContain(digest(i)) {
+			falsePositives++
+		}
+		if _, ok, _ := x.Lookup(digest(i)); ok {
+			t.Fatalf("Lookup(%d) found a chunk never inserted", i)
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("%d false positives in 10000 lookups", falsePositives)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	data := make([]byte, 200<<10)
+	rand.New(rand.NewSource(1)).Read(data)
+	var pack bytes.Buffer
+	save := func(c chunker.Chunk) (Location, error) {
+		loc := Location{Container: "pack", Offset: int64(pack.Len()), Length: c.Length}
+		pack.Write(c.Data)
+		return loc, nil
+	}
+
+	store := NewMemoryStore()
+	x := NewIndex(store, 100)
+	recipe, err := x.Dedup(bytes.NewReader(data), nil, save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := pack.Len()
+	if first != len(data) {
+		t.Errorf("saved %d bytes, want %d", first, len(data))
+	}
+
+	// The same data with a small edit only saves the chunks around it.
+	edited := append(append([]byte(nil), data[:100<<10]...), data[100<<10+10:]...)
+	if _, err := x.Dedup(bytes.NewReader(edited), nil, save); err != nil {
+		t.Fatal(err)
+	}
+	if added := pack.Len() - first; added > 3*chunker.DefaultMaxSize {
+		t.Errorf("edit saved %d more bytes", added)
+	}
+
+	// The recipe rebuilds the data.
+	var rebuilt []byte
+	for _, d := range recipe {
+		loc, ok, err := x.Lookup(d)
+		if !ok || err != nil {
+			t.Fatalf("chunk %x missing", d)
+		}
+		rebuilt = append(rebuilt, pack.Bytes()[loc.Offset:loc.Offset+int64(loc.Length)]...)
+	}
+	if !bytes.Equal(rebuilt, data) {
+		t.Error("rebuilt data differs")
+	}
+	if store.Len() < len(recipe) {
+		t.Errorf("store has %d chunks, recipe %d", store.Len(), len(recipe))
+	}
+}
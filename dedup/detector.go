@@ -0,0 +1,92 @@
+package dedup
+
+import "github.com/jadeydi/blake2/blake2b"
+
+// keySize is the length of the digests a Detector remembers.
+const keySize = 16
+
+// detectorPersonal separates item digests from other uses of BLAKE2b.
+var detectorPersonal = []byte("blake2 dedup")
+
+// Strategy is how a Detector remembers the items it has seen.
+type Strategy int
+
+const (
+	// Exact remembers a 16-byte digest of each distinct item, so that
+	// duplicates are reported without error, with memory growing with
+	// the number of distinct items.
+	Exact Strategy = iota
+	// Bloom remembers items in a Bloom filter sized for the expected
+	// number of items, in about 10 bits each whatever their number.
+	// Duplicates are always reported, but about 1% of new items are
+	// also reported as duplicates, more past the expected number.
+	Bloom
+)
+
+// Detector reports duplicates in a stream of items, such as records or
+// files in an ingestion pipeline, as they arrive. It is not safe for
+// concurrent use.
+type Detector struct {
+	seen   map[[keySize]byte]struct{}
+	filter *bloom
+	n, dup int
+}
+
+// NewDetector returns a Detector remembering items with strategy s,
+// sized for about expected distinct items.
+func NewDetector(s Strategy, expected int) *Detector {
+	if s == Bloom {
+		return &Detector{filter: newBloom(expected)}
+	}
+	if expected < 0 {
+		expected = 0
+	}
+	return &Detector{seen: make(map[[keySize]byte]struct{}, expected)}
+}
+
+// Seen reports whether item was seen before, and records it.
+func (d *Detector) Seen(item []byte) bool {
+	var key [keySize]byte
+	h := blake2b.New(&blake2b.Config{Size: keySize, Personal: detectorPersonal})
+	h.Write(item)
+	h.Sum(key[:0])
+	return d.seenKey(key)
+}
+
+// SeenDigest is like Seen for an item given by its digest, such as the
+// chunk digests of a recipe, which is not hashed again. Digests of at
+// least 16 bytes are used directly; shorter ones are hashed as items.
+func (d *Detector) SeenDigest(digest []byte) bool {
+	if len(digest) < keySize {
+		return d.Seen(digest)
+	}
+	var key [keySize]byte
+	copy(key[:], digest)
+	return d.seenKey(key)
+}
+
+func (d *Detector) seenKey(key [keySize]byte) bool {
+	d.n++
+	var dup bool
+	if d.filter != nil {
+		dup = d.filter.mayContain(key[:])
+		if !dup {
+			d.filter.add(key[:])
+		}
+	} else {
+		_, dup = d.seen[key]
+		if !dup {
+			d.seen[key] = struct{}{}
+		}
+	}
+	if dup {
+		d.dup++
+	}
+	return dup
+}
+
+// Stats returns the number of items seen and how many of them were
+// reported as duplicates.
+func (d *Detector) Stats() (items, duplicates int) {
+	return d.n, d.dup
+}
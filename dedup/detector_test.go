@@ -0,0 +1,42 @@
+package dedup
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestDetector(t *testing.T) {
+	for _, s := range []Strategy{Exact, Bloom} {
+		d := NewDetector(s, 1000)
+		for i := 0; i < 1000; i++ {
+			d.Seen([]byte("item " + strconv.Itoa(i)))
+		}
+		_, falsePositives := d.Stats()
+		for i := 0; i < 1000; i += 10 {
+			if !d.Seen([]byte("item " + strconv.Itoa(i))) {
+				t.Errorf("strategy %d: duplicate %d not reported", s, i)
+			}
+		}
+		items, dups := d.Stats()
+		if items != 1100 || dups != falsePositives+100 {
+			t.Errorf("strategy %d: Stats = %d, %d", s, items, dups)
+		}
+		switch {
+		case s == Exact && falsePositives != 0:
+			t.Errorf("exact detector reported %d false duplicates", falsePositives)
+		case s == Bloom && falsePositives > 30:
+			t.Errorf("Bloom detector reported %d false duplicates of 1000", falsePositives)
+		}
+	}
+
+	d := NewDetector(Exact, 0)
+	sum := blake2b.Sum256([]byte("chunk"))
+	if d.SeenDigest(sum[:]) || !d.SeenDigest(sum[:]) {
+		t.Error("SeenDigest does not detect a repeated digest")
+	}
+	if d.SeenDigest([]byte("short")) || !d.Seen([]byte("short")) {
+		t.Error("short digests are not hashed as items")
+	}
+}
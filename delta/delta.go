@@ -0,0 +1,293 @@
+// Package delta computes rsync-style deltas: the holder of an old version
+// of a file sends its signature, the weak rolling checksum and BLAKE2b
+// digest of each block, and the holder of the new version answers with
+// the operations that rebuild it from the old one, copying the blocks
+// both have and inserting the rest.
+package delta
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/internal/wire"
+)
+
+// StrongSize is the length of the BLAKE2b block digests.
+const StrongSize = 16
+
+var magic = []byte("B2DS\x01")
+
+var (
+	// ErrInvalid is returned when decoding a malformed signature.
+	ErrInvalid = errors.New("delta: invalid signature")
+	// ErrVersion is returned when decoding a signature encoded by a newer
+	// version of the package.
+	ErrVersion = errors.New("delta: unsupported signature version")
+	// ErrBlock is returned by Patch for operations copying blocks the
+	// signature does not have.
+	ErrBlock = errors.New("delta: block index out of range")
+)
+
+// Block is the signature of a block of the old version.
+type Block struct {
+	// Weak is the rolling checksum of the block.
+	Weak uint32
+	// Strong is the BLAKE2b digest of the block.
+	Strong []byte
+}
+
+// Signature is the signature of the old version of a file.
+type Signature struct {
+	// BlockSize is the length of every block but the last, which may be
+	// shorter.
+	BlockSize int
+	// Length is the length of the old version.
+	Length int64
+	// Blocks holds the signature of each block.
+	Blocks []Block
+}
+
+// NewSignature reads r to the end and returns the signature of its
+// content, cut into blocks of blockSize bytes. It panics if blockSize is
+// not positive.
+func NewSignature(r io.Reader, blockSize int) (*Signature, error) {
+	if blockSize <= 0 {
+		panic("delta: block size must be positive")
+	}
+	s := &Signature{BlockSize: blockSize}
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			s.Length += int64(n)
+			s.Blocks = append(s.Blocks, Block{Weak: weakSum(buf[:n]), Strong: strongSum(buf[:n])})
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return s, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// blockLen returns the length of block i.
+func (s *Signature) blockLen(i int) int {
+	if i == len(s.Blocks)-1 {
+		return int(s.Length - int64(i)*int64(s.BlockSize))
+	}
+	return s.BlockSize
+}
+
+// MarshalBinary encodes the signature as a magic string, the block size
+// and length as uvarints, then the weak checksum, as 4 little-endian
+// bytes, and strong digest of each block.
+func (s *Signature) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(magic)
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(s.BlockSize))])
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(s.Length))])
+	for _, b := range s.Blocks {
+		binary.LittleEndian.PutUint32(tmp[:], b.Weak)
+		buf.Write(tmp[:4])
+		buf.Write(b.Strong)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a signature encoded by MarshalBinary.
+func (s *Signature) UnmarshalBinary(data []byte) error {
+	data, version, ok := wire.Header(data, string(magic))
+	if !ok {
+		return ErrInvalid
+	}
+	if version != magic[len(magic)-1] {
+		return ErrVersion
+	}
+	blockSize, data, ok := wire.Uvarint(data)
+	if !ok || blockSize == 0 || blockSize > math.MaxInt32 {
+		return ErrInvalid
+	}
+	length, data, ok := wire.Uvarint(data)
+	if !ok || length > 1<<62 {
+		return ErrInvalid
+	}
+
+	count := length / blockSize
+	if length%blockSize != 0 {
+		count++
+	}
+	if !wire.Count(data, 4+StrongSize, count) {
+		return ErrInvalid
+	}
+	blocks := make([]Block, count)
+	for i := range blocks {
+		b := data[i*(4+StrongSize):]
+		blocks[i] = Block{
+			Weak:   binary.LittleEndian.Uint32(b),
+			Strong: append([]byte(nil), b[4:4+StrongSize]...),
+		}
+	}
+	*s = Signature{BlockSize: int(blockSize), Length: int64(length), Blocks: blocks}
+	return nil
+}
+
+// OpKind is the kind of an Op.
+type OpKind int
+
+const (
+	// Copy copies a block of the old version.
+	Copy OpKind = iota
+	// Insert inserts literal data.
+	Insert
+)
+
+// Op is an operation rebuilding the new version.
+type Op struct {
+	Kind OpKind
+	// Block is the index of the block copied by a Copy.
+	Block int
+	// Data is the data inserted by an Insert.
+	Data []byte
+}
+
+// Diff returns the operations that rebuild data from the old version
+// with signature sig. Blocks are found at any offset of data, by their
+// weak checksum first, confirmed by their BLAKE2b digest. Insert data
+// aliases data.
+func Diff(sig *Signature, data []byte) []Op {
+	var ops []Op
+	bs := sig.BlockSize
+	full := len(sig.Blocks)
+	if full > 0 && sig.blockLen(full-1) != bs {
+		full-- // the short last block only matches at the end
+	}
+	index := make(map[uint32][]int, full)
+	for i := 0; i < full; i++ {
+		index[sig.Blocks[i].Weak] = append(index[sig.Blocks[i].Weak], i)
+	}
+	match := func(i int, b []byte) bool {
+		return subtle.ConstantTimeCompare(strongSum(b), sig.Blocks[i].Strong) == 1
+	}
+
+	literal := 0
+	flush := func(end int) {
+		if end > literal {
+			ops = append(ops, Op{Kind: Insert, Data: data[literal:end]})
+		}
+	}
+	var r rolling
+	pos := 0
+	if len(data) >= bs {
+		r.init(data[:bs])
+	}
+	for pos+bs <= len(data) {
+		found := -1
+		for _, i := range index[r.sum()] {
+			if match(i, data[pos:pos+bs]) {
+				found = i
+				break
+			}
+		}
+		if found >= 0 {
+			flush(pos)
+			ops = append(ops, Op{Kind: Copy, Block: found})
+			pos += bs
+			literal = pos
+			if pos+bs <= len(data) {
+				r.init(data[pos : pos+bs])
+			}
+			continue
+		}
+		if pos+bs < len(data) {
+			r.roll(data[pos], data[pos+bs])
+		}
+		pos++
+	}
+
+	end := len(data)
+	if full < len(sig.Blocks) {
+		last := len(sig.Blocks) - 1
+		if n := sig.blockLen(last); end-n >= literal && match(last, data[end-n:]) {
+			flush(end - n)
+			ops = append(ops, Op{Kind: Copy, Block: last})
+			return ops
+		}
+	}
+	flush(end)
+	return ops
+}
+
+// Patch writes the new version to w, applying ops to old, the old version
+// with signature sig.
+func Patch(w io.Writer, old io.ReaderAt, sig *Signature, ops []Op) error {
+	buf := make([]byte, sig.BlockSize)
+	for _, op := range ops {
+		if op.Kind == Insert {
+			if _, err := w.Write(op.Data); err != nil {
+				return err
+			}
+			continue
+		}
+		if op.Block < 0 || op.Block >= len(sig.Blocks) {
+			return ErrBlock
+		}
+		b := buf[:sig.blockLen(op.Block)]
+		if n, err := old.ReadAt(b, int64(op.Block)*int64(sig.BlockSize)); n < len(b) {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func strongSum(b []byte) []byte {
+	h := blake2b.New(&blake2b.Config{Size: StrongSize})
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// weakSum returns the rolling checksum of b.
+func weakSum(b []byte) uint32 {
+	var r rolling
+	r.init(b)
+	return r.sum()
+}
+
+// rolling is the rolling checksum of rsync: with the window x[0..n-1],
+// a is the sum of the x[i] and b the sum of the (n-i)*x[i], both modulo
+// 2^16.
+type rolling struct {
+	a, b uint16
+	n    uint16
+}
+
+func (r *rolling) init(window []byte) {
+	r.a, r.b, r.n = 0, 0, uint16(len(window))
+	for i, x := range window {
+		r.a += uint16(x)
+		r.b += uint16(len(window)-i) * uint16(x)
+	}
+}
+
+// roll slides the window by one byte, removing out and adding in.
+func (r *rolling) roll(out, in byte) {
+	r.a += uint16(in) - uint16(out)
+	r.b += r.a - r.n*uint16(out)
+}
+
+func (r *rolling) sum() uint32 {
+	return uint32(r.a) | uint32(r.b)<<16
+}
@@ -0,0 +1,99 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRolling(t *testing.T) {
+	data := make([]byte, 300)
+	rand.New(rand.NewSource(1)).Read(data)
+	const n = 64
+	var r rolling
+	r.init(data[:n])
+	for i := 1; i+n <= len(data); i++ {
+		r.roll(data[i-1], data[i-1+n])
+		if want := weakSum(data[i : i+n]); r.sum() != want {
+			t.Fatalf("offset %d: rolled checksum %08x, want %08x", i, r.sum(), want)
+		}
+	}
+}
+
+func TestDiffPatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	old := make([]byte, 10000)
+	rng.Read(old)
+	edited := append(append(append([]byte(nil), old[:3000]...), []byte("inserted text")...), old[3100:]...)
+
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"same", old},
+		{"edited", edited},
+		{"prefix", append([]byte("prefix"), old...)},
+		{"truncated", old[:5000]},
+		{"empty", nil},
+		{"unrelated", bytes.Repeat([]byte("x"), 2000)},
+	} {
+		sig, err := NewSignature(bytes.NewReader(old), 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ops := Diff(sig, c.data)
+		var out bytes.Buffer
+		if err := Patch(&out, bytes.NewReader(old), sig, ops); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !bytes.Equal(out.Bytes(), c.data) {
+			t.Errorf("%s: patched data differs", c.name)
+		}
+		var inserted int
+		for _, op := range ops {
+			inserted += len(op.Data)
+		}
+		if c.name == "same" && inserted != 0 {
+			t.Errorf("same: %d bytes inserted", inserted)
+		}
+		if c.name == "edited" && inserted > 2*512+13 {
+			t.Errorf("edited: %d bytes inserted", inserted)
+		}
+	}
+}
+
+func TestSignatureMarshal(t *testing.T) {
+	sig, err := NewSignature(bytes.NewReader(make([]byte, 1300)), 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig.Blocks) != 3 || sig.Length != 1300 {
+		t.Fatalf("%d blocks, length %d", len(sig.Blocks), sig.Length)
+	}
+	b, err := sig.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Signature
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, sig) {
+		t.Error("decoded signature differs")
+	}
+	if err := got.UnmarshalBinary(b[:len(b)-1]); err != ErrInvalid {
+		t.Errorf("truncated signature: %v", err)
+	}
+	// A block size of 1<<31 does not fit in an int on 32-bit platforms.
+	if err := got.UnmarshalBinary([]byte("B2DS\x01\x80\x80\x80\x80\x08\x00")); err != ErrInvalid {
+		t.Errorf("block size 1<<31: %v", err)
+	}
+}
+
+func TestPatchBlockRange(t *testing.T) {
+	sig := &Signature{BlockSize: 4}
+	if err := Patch(new(bytes.Buffer), bytes.NewReader(nil), sig, []Op{{Kind: Copy, Block: 0}}); err != ErrBlock {
+		t.Errorf("Patch returned %v, want ErrBlock", err)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+package delta
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	s, _ := NewSignature(bytes.NewReader(make([]byte, 2500)), 1000)
+	b, _ := s.MarshalBinary()
+	f.Add(b)
+	f.Add([]byte("B2DS\x01\x01\x80\x80\x80\x80\x80\x80\x80\x80\x40"))
+	f.Add([]byte("B2DS\x01\x80\x80\x80\x80\x08\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Signature
+		if s.UnmarshalBinary(data) != nil {
+			return
+		}
+		if b, err := s.MarshalBinary(); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("accepted encoding does not round-trip: %x", data)
+		}
+	})
+}
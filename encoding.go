@@ -0,0 +1,189 @@
+package blake2
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encoding is a text encoding of digests.
+type Encoding int
+
+const (
+	// Hex is lowercase hexadecimal, as printed by b2sum.
+	Hex Encoding = iota + 1
+	// HexUpper is uppercase hexadecimal.
+	HexUpper
+	// Base32 is lowercase RFC 4648 base32 without padding, which is
+	// case-insensitive and safe in file names and URLs.
+	Base32
+	// Base64URL is RFC 4648 base64 with the URL and file name safe
+	// alphabet, without padding.
+	Base64URL
+	// Base58 is base58 with the Bitcoin alphabet, which has no look-alike
+	// characters.
+	Base58
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case Hex:
+		return "hex"
+	case HexUpper:
+		return "HEX"
+	case Base32:
+		return "base32"
+	case Base64URL:
+		return "base64url"
+	case Base58:
+		return "base58"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+var (
+	// ErrEncoding is returned for unknown encodings.
+	ErrEncoding = errors.New("blake2: unknown encoding")
+	// ErrDigestText is returned for text that is not a valid encoded
+	// digest.
+	ErrDigestText = errors.New("blake2: invalid encoded digest")
+)
+
+var base32Encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Encode returns the text encoding of sum. It panics for unknown
+// encodings.
+func (e Encoding) Encode(sum []byte) string {
+	switch e {
+	case Hex:
+		return hex.EncodeToString(sum)
+	case HexUpper:
+		return strings.ToUpper(hex.EncodeToString(sum))
+	case Base32:
+		return base32Encoding.EncodeToString(sum)
+	case Base64URL:
+		return base64.RawURLEncoding.EncodeToString(sum)
+	case Base58:
+		return encodeBase58(sum)
+	}
+	panic(ErrEncoding)
+}
+
+// Decode returns the digest encoded by s. Hexadecimal and base32 are
+// decoded whatever their case. It returns ErrDigestText for invalid text.
+func (e Encoding) Decode(s string) ([]byte, error) {
+	var sum []byte
+	var err error
+	switch e {
+	case Hex, HexUpper:
+		sum, err = hex.DecodeString(s)
+	case Base32:
+		sum, err = base32Encoding.DecodeString(strings.ToLower(s))
+	case Base64URL:
+		sum, err = base64.RawURLEncoding.DecodeString(s)
+	case Base58:
+		sum, err = decodeBase58(s)
+	default:
+		return nil, ErrEncoding
+	}
+	if err != nil {
+		return nil, ErrDigestText
+	}
+	return sum, nil
+}
+
+// Encode returns the text encoding of d.Sum. It panics for unknown
+// encodings.
+func (d Digest) Encode(e Encoding) string {
+	return e.Encode(d.Sum)
+}
+
+// ParseDigest returns the digest encoded by s with e, computed with cfg,
+// which may be nil. It returns ErrDigestText for invalid text, and for
+// digests whose length is not cfg.Size when it is set.
+func ParseDigest(s string, e Encoding, cfg *Config) (Digest, error) {
+	sum, err := e.Decode(s)
+	if err != nil {
+		return Digest{}, err
+	}
+	if len(sum) == 0 || (cfg != nil && cfg.Size != 0 && len(sum) != int(cfg.Size)) {
+		return Digest{}, ErrDigestText
+	}
+	return Digest{Config: cfg, Sum: sum}, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// maxBase58Len is the length of the longest base58 encoding of a 64-byte
+// digest, the largest there is: 64 * log(256)/log(58) rounded up. Longer
+// text is rejected before decoding, which takes quadratic time.
+const maxBase58Len = 88
+
+// encodeBase58 encodes b as a big-endian number in base 58, with a '1'
+// for each leading zero byte.
+func encodeBase58(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	// Each byte takes at most log(256)/log(58) < 1.37 digits.
+	digits := make([]byte, 0, len(b)*137/100+1)
+	for _, v := range b[zeros:] {
+		carry := int(v)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+// decodeBase58 is the inverse of encodeBase58, for texts of at most
+// maxBase58Len digits.
+func decodeBase58(s string) ([]byte, error) {
+	if len(s) > maxBase58Len {
+		return nil, ErrDigestText
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// Each digit takes at most log(58)/log(256) < 0.74 bytes.
+	num := make([]byte, 0, len(s)*74/100+1)
+	for i := zeros; i < len(s); i++ {
+		d := strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, ErrDigestText
+		}
+		carry := d
+		for j := range num {
+			carry += int(num[j]) * 58
+			num[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			num = append(num, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(num))
+	for i, b := range num {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var base58Tests = []struct{ hex, want string }{
+	{"", ""},
+	{"00", "1"},
+	{"0000", "11"},
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"00000000000000000000", "1111111111"},
+	{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	{"516b6fcd0f", "ABnLTmg"},
+	{"572e4794", "3EFU7m"},
+}
+
+func TestBase58(t *testing.T) {
+	for _, c := range base58Tests {
+		b, _ := hex.DecodeString(c.hex)
+		if got := Base58.Encode(b); got != c.want {
+			t.Errorf("Base58.Encode(%s) = %q, want %q", c.hex, got, c.want)
+		}
+		if got, err := Base58.Decode(c.want); err != nil || !bytes.Equal(got, b) {
+			t.Errorf("Base58.Decode(%q) = %x, %v, want %s", c.want, got, err, c.hex)
+		}
+	}
+	if _, err := Base58.Decode("0OIl"); err != ErrDigestText {
+		t.Errorf("Base58.Decode of invalid digits: %v", err)
+	}
+
+	max := bytes.Repeat([]byte{0xff}, 64)
+	if n := len(Base58.Encode(max)); n != maxBase58Len {
+		t.Errorf("64-byte digest encoded in %d digits, want %d", n, maxBase58Len)
+	}
+	if _, err := Base58.Decode(strings.Repeat("z", maxBase58Len+1)); err != ErrDigestText {
+		t.Errorf("Base58.Decode of %d digits: %v", maxBase58Len+1, err)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	sum, _ := hex.DecodeString("00fb7f3ec8a1")
+	d := Digest{Sum: sum}
+	for _, c := range []struct {
+		enc  Encoding
+		want string
+	}{
+		{Hex, "00fb7f3ec8a1"},
+		{HexUpper, "00FB7F3EC8A1"},
+		{Base32, "ad5x6pwiue"},
+		{Base64URL, "APt_Psih"},
+		{Base58, "1VNi4rjJ"},
+	} {
+		s := d.Encode(c.enc)
+		if s != c.want {
+			t.Errorf("%v: %q, want %q", c.enc, s, c.want)
+		}
+		got, err := ParseDigest(s, c.enc, &Config{Size: 6})
+		if err != nil || !bytes.Equal(got.Sum, sum) {
+			t.Errorf("%v: ParseDigest(%q) = %x, %v", c.enc, s, got.Sum, err)
+		}
+	}
+	if got, err := Hex.Decode("00FB7F3EC8A1"); err != nil || !bytes.Equal(got, sum) {
+		t.Errorf("Hex.Decode of uppercase: %x, %v", got, err)
+	}
+	if got, err := Base32.Decode("AD5X6PWIUE"); err != nil || !bytes.Equal(got, sum) {
+		t.Errorf("Base32.Decode of uppercase: %x, %v", got, err)
+	}
+	if _, err := ParseDigest("00fb", Hex, &Config{Size: 6}); err != ErrDigestText {
+		t.Errorf("ParseDigest of a short digest: %v", err)
+	}
+	if _, err := ParseDigest("zz", Hex, nil); err != ErrDigestText {
+		t.Errorf("ParseDigest of invalid hex: %v", err)
+	}
+	if _, err := Encoding(0).Decode("00"); err != ErrEncoding {
+		t.Errorf("Decode with unknown encoding: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package blake2
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// equalDigestSize is the digest length EqualReaders compares streams by.
+const equalDigestSize = 32
+
+// Equal reports whether the digests a and b are equal, in time that does
+// not depend on their contents. Use it rather than bytes.Equal to check
+// MACs, so that timing does not reveal how many leading bytes matched.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// EqualHex reports whether digest equals the hexadecimal digest s, in
+// either case, in time that does not depend on the digest contents. It
+// returns false if s is not valid hexadecimal.
+func EqualHex(digest []byte, s string) bool {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return false
+	}
+	return Equal(digest, b)
+}
+
+// EqualReaders reports whether a and b yield the same bytes, for instance
+// to verify a copy, by hashing both to the end, each on its own goroutine,
+// and comparing their BLAKE2b digests. It returns the first read error.
+func EqualReaders(a, b io.Reader) (bool, error) {
+	var da, db [equalDigestSize]byte
+	var errA, errB error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errA = hashReader(a, da[:])
+	}()
+	errB = hashReader(b, db[:])
+	wg.Wait()
+	if errA != nil {
+		return false, errA
+	}
+	if errB != nil {
+		return false, errB
+	}
+	return da == db, nil
+}
+
+// hashReader writes the digest of r to out.
+func hashReader(r io.Reader, out []byte) error {
+	h := blake2b.New(&blake2b.Config{Size: equalDigestSize})
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	h.Sum(out[:0])
+	return nil
+}
+
+// EqualReadersChunked is like EqualReaders, but compares the streams
+// chunk by chunk, as their chunks of chunkSize bytes are read and hashed,
+// and returns false at the first chunk that differs, without reading the
+// rest. It returns once both goroutines have hashed the chunk they were
+// on. It panics if chunkSize is not positive.
+func EqualReadersChunked(a, b io.Reader, chunkSize int) (bool, error) {
+	if chunkSize <= 0 {
+		panic("blake2: chunk size must be positive")
+	}
+	done := make(chan struct{})
+	ca := make(chan chunkDigest, 1)
+	cb := make(chan chunkDigest, 1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go hashChunks(a, chunkSize, ca, done, &wg)
+	go hashChunks(b, chunkSize, cb, done, &wg)
+	defer wg.Wait()
+	defer close(done)
+
+	for {
+		da, okA := <-ca
+		db, okB := <-cb
+		switch {
+		case okA && da.err != nil:
+			return false, da.err
+		case okB && db.err != nil:
+			return false, db.err
+		case okA != okB:
+			// One stream is longer.
+			return false, nil
+		case !okA:
+			return true, nil
+		case da.sum != db.sum:
+			return false, nil
+		}
+	}
+}
+
+// chunkDigest is the digest of a chunk, or the error reading it.
+type chunkDigest struct {
+	sum [equalDigestSize]byte
+	err error
+}
+
+// hashChunks sends to out the digests of the chunks of size bytes of r,
+// the last one possibly shorter, or the error reading it, and closes out
+// at the end of r. It stops early when done is closed.
+func hashChunks(r io.Reader, size int, out chan<- chunkDigest, done <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer close(out)
+	h := blake2b.New(&blake2b.Config{Size: equalDigestSize})
+	buf := make([]byte, size)
+	for {
+		n, err := io.ReadFull(r, buf)
+		var c chunkDigest
+		switch err {
+		case nil, io.ErrUnexpectedEOF:
+			h.Reset()
+			h.Write(buf[:n])
+			h.Sum(c.sum[:0])
+		case io.EOF:
+			return
+		default:
+			c.err = err
+		}
+		select {
+		case out <- c:
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
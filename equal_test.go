@@ -0,0 +1,96 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEqual(t *testing.T) {
+	d := []byte{0xde, 0xad, 0xbe, 0xef}
+	for _, c := range []struct {
+		b    []byte
+		want bool
+	}{
+		{[]byte{0xde, 0xad, 0xbe, 0xef}, true},
+		{[]byte{0xde, 0xad, 0xbe, 0xee}, false},
+		{[]byte{0xde, 0xad, 0xbe}, false},
+		{nil, false},
+	} {
+		if got := Equal(d, c.b); got != c.want {
+			t.Errorf("Equal(%x, %x) = %v, want %v", d, c.b, got, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		s    string
+		want bool
+	}{
+		{"deadbeef", true},
+		{"DEADBEEF", true},
+		{"deadbeee", false},
+		{"deadbee", false},
+		{"deadbeefzz", false},
+		{"", false},
+	} {
+		if got := EqualHex(d, c.s); got != c.want {
+			t.Errorf("EqualHex(%x, %q) = %v, want %v", d, c.s, got, c.want)
+		}
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestEqualReaders(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	changed := append([]byte(nil), data...)
+	changed[5000] ^= 1
+	errRead := errors.New("read failed")
+	for _, c := range []struct {
+		name string
+		b    func() io.Reader
+		want bool
+		err  error
+	}{
+		{"equal", func() io.Reader { return iotest.OneByteReader(bytes.NewReader(data)) }, true, nil},
+		{"changed", func() io.Reader { return bytes.NewReader(changed) }, false, nil},
+		{"shorter", func() io.Reader { return bytes.NewReader(data[:len(data)-1]) }, false, nil},
+		{"longer", func() io.Reader { return bytes.NewReader(append(data[:len(data):len(data)], 0)) }, false, nil},
+		{"error", func() io.Reader { return io.MultiReader(bytes.NewReader(data[:10]), iotest.ErrReader(errRead)) }, false, errRead},
+	} {
+		if got, err := EqualReaders(bytes.NewReader(data), c.b()); got != c.want || err != c.err {
+			t.Errorf("EqualReaders %s = %v, %v, want %v, %v", c.name, got, err, c.want, c.err)
+		}
+		for _, size := range []int{1000, 4096, len(data)} {
+			if got, err := EqualReadersChunked(bytes.NewReader(data), c.b(), size); got != c.want || err != c.err {
+				t.Errorf("EqualReadersChunked %s with chunks of %d = %v, %v, want %v, %v", c.name, size, got, err, c.want, c.err)
+			}
+		}
+	}
+
+	// The comparison stops soon after the first differing chunk.
+	big := make([]byte, 64<<20)
+	a := &countingReader{r: bytes.NewReader(big)}
+	b := &countingReader{r: io.MultiReader(bytes.NewReader([]byte{1}), bytes.NewReader(big))}
+	if got, err := EqualReadersChunked(a, b, 1<<16); got || err != nil {
+		t.Fatalf("EqualReadersChunked = %v, %v", got, err)
+	}
+	if a.n > 1<<20 || b.n > 1<<20 {
+		t.Errorf("read %d and %d bytes after the first chunk differed", a.n, b.n)
+	}
+}
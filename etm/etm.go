@@ -0,0 +1,210 @@
+// Package etm authenticates streams encrypted with a stream cipher, such
+// as AES-CTR or ChaCha20, with keyed BLAKE2b in the encrypt-then-MAC
+// composition, so that applications do not have to frame and MAC the
+// ciphertext themselves.
+//
+// The ciphertext is cut into segments of SegmentSize bytes, the last one
+// possibly shorter or empty, each followed by its tag:
+//
+//	tag = MAC(key, seq || final || segment)
+//
+// where seq is the index of the segment as an 8-byte little-endian
+// integer, final is 1 for the last segment and 0 for the others, and MAC
+// is the 32-byte keyed BLAKE2b, personalized for this package. A Reader
+// thus detects segments altered, reordered, removed or appended, and a
+// stream truncated at a segment boundary, and returns only plaintext it
+// has verified.
+//
+// The MAC key must be independent of the cipher key, and a cipher key and
+// IV pair must never encrypt two streams.
+package etm
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+const (
+	// SegmentSize is the length of the ciphertext of every segment but
+	// the last.
+	SegmentSize = 64 << 10
+	// TagSize is the length of segment tags.
+	TagSize = 32
+)
+
+var personal = []byte("blake2 etm")
+
+var (
+	// ErrKey is returned by NewWriter and NewReader for an empty MAC key
+	// or one longer than blake2b.MaxKeySize.
+	ErrKey = errors.New("etm: MAC key must be 1 to 64 bytes")
+	// ErrAuth is returned by Reader.Read for streams that do not match
+	// their tags, or are truncated.
+	ErrAuth = errors.New("etm: message authentication failed")
+	// ErrClosed is returned by Writer.Write after Close.
+	ErrClosed = errors.New("etm: write after Close")
+)
+
+// newMAC returns the segment MAC keyed with key.
+func newMAC(key []byte) (hash.Hash, error) {
+	if len(key) == 0 || len(key) > blake2b.MaxKeySize {
+		return nil, ErrKey
+	}
+	return blake2b.New(&blake2b.Config{Size: TagSize, Key: key, Personal: personal}), nil
+}
+
+// tag appends to dst the tag of segment number seq.
+func tag(mac hash.Hash, dst []byte, seq uint64, final bool, segment []byte) []byte {
+	var header [9]byte
+	binary.LittleEndian.PutUint64(header[:8], seq)
+	if final {
+		header[8] = 1
+	}
+	mac.Reset()
+	mac.Write(header[:])
+	mac.Write(segment)
+	return mac.Sum(dst)
+}
+
+// Writer encrypts what is written to it and writes the ciphertext,
+// framed in tagged segments, to an underlying writer.
+type Writer struct {
+	w      io.Writer
+	s      cipher.Stream
+	mac    hash.Hash
+	buf    []byte
+	seq    uint64
+	closed bool
+}
+
+// NewWriter returns a Writer encrypting with s and authenticating with
+// the MAC key key, writing to w.
+func NewWriter(w io.Writer, s cipher.Stream, key []byte) (*Writer, error) {
+	mac, err := newMAC(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, s: s, mac: mac, buf: make([]byte, 0, SegmentSize+TagSize)}, nil
+}
+
+// Write encrypts p. Segments are written once full and followed by more
+// data, so the last one is only written by Close.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(w.buf) == SegmentSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		k := SegmentSize - len(w.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		start := len(w.buf)
+		w.buf = w.buf[:start+k]
+		w.s.XORKeyStream(w.buf[start:], p[:k])
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// flush writes the buffered segment with its tag.
+func (w *Writer) flush(final bool) error {
+	out := tag(w.mac, w.buf, w.seq, final, w.buf)
+	w.seq++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(out)
+	return err
+}
+
+// Close writes the last segment. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// Reader verifies and decrypts a stream written by a Writer.
+type Reader struct {
+	r       *bufio.Reader
+	s       cipher.Stream
+	mac     hash.Hash
+	sum     [TagSize]byte
+	seq     uint64
+	plain   []byte
+	pending []byte
+	done    bool
+	err     error
+}
+
+// NewReader returns a Reader of the plaintext of the stream read from r,
+// decrypting with s and verifying with the MAC key key.
+func NewReader(r io.Reader, s cipher.Stream, key []byte) (*Reader, error) {
+	mac, err := newMAC(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{r: bufio.NewReaderSize(r, SegmentSize+TagSize+1), s: s, mac: mac, plain: make([]byte, SegmentSize)}, nil
+}
+
+// Read returns plaintext of segments that have been verified. It returns
+// ErrAuth, which is sticky, at the first segment that fails verification;
+// the plaintext returned before stays authentic, but is incomplete.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// next reads, verifies and decrypts the next segment into pending. A
+// segment is the last one if the stream ends before a full segment and
+// its tag, and one more byte.
+func (r *Reader) next() error {
+	b, err := r.r.Peek(SegmentSize + TagSize + 1)
+	final := err != nil
+	if final && err != io.EOF {
+		return err
+	}
+	if final {
+		if len(b) < TagSize {
+			return ErrAuth
+		}
+	} else {
+		b = b[:SegmentSize+TagSize]
+	}
+	segment := b[:len(b)-TagSize]
+	copy(r.sum[:], b[len(segment):])
+	if subtle.ConstantTimeCompare(tag(r.mac, nil, r.seq, final, segment), r.sum[:]) != 1 {
+		return ErrAuth
+	}
+	r.plain = r.plain[:len(segment)]
+	r.s.XORKeyStream(r.plain, segment)
+	r.r.Discard(len(b))
+	r.seq++
+	r.pending = r.plain
+	r.done = final
+	return nil
+}
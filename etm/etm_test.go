@@ -0,0 +1,112 @@
+package etm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"testing"
+)
+
+var (
+	cipherKey = bytes.Repeat([]byte{1}, 16)
+	iv        = bytes.Repeat([]byte{2}, aes.BlockSize)
+	macKey    = []byte("independent MAC key")
+)
+
+func stream() cipher.Stream {
+	b, _ := aes.NewCipher(cipherKey)
+	return cipher.NewCTR(b, iv)
+}
+
+func encrypt(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, stream(), macKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write in uneven pieces.
+	for p := plain; len(p) > 0; {
+		k := 1000
+		if k > len(p) {
+			k = len(p)
+		}
+		w.Write(p[:k])
+		p = p[k:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("x")); err != ErrClosed {
+		t.Errorf("Write after Close: %v, want ErrClosed", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(t *testing.T, sealed []byte) ([]byte, error) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(sealed), stream(), macKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, SegmentSize - 1, SegmentSize, SegmentSize + 1, 3*SegmentSize + 17} {
+		plain := make([]byte, n)
+		for i := range plain {
+			plain[i] = byte(i)
+		}
+		sealed := encrypt(t, plain)
+		segments := n/SegmentSize + 1
+		if n > 0 && n%SegmentSize == 0 {
+			segments--
+		}
+		if len(sealed) != n+segments*TagSize {
+			t.Errorf("%d bytes: sealed length %d, want %d", n, len(sealed), n+segments*TagSize)
+		}
+		if n > 0 && bytes.Contains(sealed, plain) {
+			t.Errorf("%d bytes: plaintext not encrypted", n)
+		}
+		got, err := decrypt(t, sealed)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%d bytes: decrypted %d bytes, %v", n, len(got), err)
+		}
+	}
+}
+
+func TestTampering(t *testing.T) {
+	plain := make([]byte, 2*SegmentSize+100)
+	sealed := encrypt(t, plain)
+	seg := SegmentSize + TagSize
+	flipped := append([]byte(nil), sealed...)
+	flipped[seg+5] ^= 1
+	swapped := append(append(append([]byte(nil), sealed[seg:2*seg]...), sealed[:seg]...), sealed[2*seg:]...)
+	for _, c := range []struct {
+		name   string
+		sealed []byte
+		good   int
+	}{
+		{"flipped bit", flipped, SegmentSize},
+		{"swapped segments", swapped, 0},
+		{"truncated at a segment", sealed[:2*seg], SegmentSize},
+		{"truncated in a tag", sealed[:len(sealed)-1], 2 * SegmentSize},
+		{"appended byte", append(append([]byte(nil), sealed...), 0), 2 * SegmentSize},
+		{"empty", nil, 0},
+	} {
+		got, err := decrypt(t, c.sealed)
+		if err != ErrAuth || len(got) != c.good || !bytes.Equal(got, plain[:len(got)]) {
+			t.Errorf("%s: %d bytes, %v, want %d bytes and ErrAuth", c.name, len(got), err, c.good)
+		}
+	}
+
+	r, _ := NewReader(bytes.NewReader(sealed), stream(), []byte("wrong key"))
+	if _, err := io.ReadAll(r); err != ErrAuth {
+		t.Errorf("wrong MAC key: %v, want ErrAuth", err)
+	}
+	if _, err := NewWriter(io.Discard, stream(), nil); err != ErrKey {
+		t.Errorf("empty MAC key: %v, want ErrKey", err)
+	}
+}
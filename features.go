@@ -0,0 +1,26 @@
+package blake2
+
+import (
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// FeatureReport describes the implementations new hashes of both variants
+// use, and why faster ones are not; see blake2b.Features.
+type FeatureReport struct {
+	BLAKE2b blake2b.Features
+	BLAKE2s blake2s.Features
+}
+
+// Features returns the FeatureReport of new hashes, so that applications
+// can explain performance differences between hosts, such as the C
+// sources left out by a build without cgo, a CPU with extensions they
+// were not compiled for, or a BLAKE2_BACKEND override.
+func Features() FeatureReport {
+	return FeatureReport{BLAKE2b: blake2b.ActiveFeatures(), BLAKE2s: blake2s.ActiveFeatures()}
+}
+
+// String returns a summary of r on two lines, one per variant.
+func (r FeatureReport) String() string {
+	return "BLAKE2b: " + r.BLAKE2b.String() + "\nBLAKE2s: " + r.BLAKE2s.String()
+}
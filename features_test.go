@@ -0,0 +1,21 @@
+package blake2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestFeatures(t *testing.T) {
+	r := Features()
+	if r.BLAKE2b.Backend != blake2b.Backend() || r.BLAKE2s.Backend != blake2s.Backend() {
+		t.Errorf("Features() = %+v", r)
+	}
+	lines := strings.Split(r.String(), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "BLAKE2b: "+r.BLAKE2b.Backend) ||
+		!strings.HasPrefix(lines[1], "BLAKE2s: "+r.BLAKE2s.Backend) {
+		t.Errorf("String() = %q", r.String())
+	}
+}
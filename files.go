@@ -0,0 +1,125 @@
+package blake2
+
+import (
+	"hash"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Result is the outcome of hashing a file with HashFiles, or a reader with
+// AsyncHasher.
+type Result struct {
+	// Path is the file name, as received.
+	Path string
+	// Size is the number of bytes hashed.
+	Size int64
+	// Digest is the 64-byte BLAKE2b digest of the file, or the digest
+	// configured for AsyncHasher, nil on error.
+	Digest []byte
+	// Err is the error opening or reading the file or reader.
+	Err error
+}
+
+// HashFiles hashes the files named on paths with BLAKE2b-512, as b2sum
+// does, on up to workers goroutines, or runtime.NumCPU() if workers is
+// not positive. It sends a result for each path, in the order the files
+// are done, and closes the returned channel once paths is closed and
+// every file is hashed. Workers wait for results to be received, so that
+// a slow consumer holds back the hashing instead of buffering results.
+// On Linux, the holes of sparse files are hashed as the zeros they read
+// as, without reading them.
+func HashFiles(paths <-chan string, workers int) <-chan Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			h := blake2b.New(nil)
+			for name := range paths {
+				results <- hashFile(h, name)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func hashFile(h hash.Hash, name string) Result {
+	r := Result{Path: name}
+	f, err := os.Open(name)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	h.Reset()
+	if info.Mode().IsRegular() {
+		r.Size, r.Err = copyFile(h, f, info.Size())
+	} else {
+		r.Size, r.Err = io.Copy(h, f)
+	}
+	if r.Err == nil {
+		r.Digest = h.Sum(nil)
+	}
+	return r
+}
+
+// SumMapped returns the BLAKE2b-512 digest of the file name, as HashFiles
+// computes it, for backup tools hashing large files. On Linux, with the
+// pure Go backend, the file is mapped into memory in large windows, with
+// the kernel advised to read ahead, and each window is hashed in place,
+// without copying it into a buffer, then unmapped. Files that cannot be
+// mapped, files on other systems, and files hashed by the C backends are
+// read instead.
+//
+// As with any memory mapping, reading the pages past the end of a file
+// truncated while it is hashed faults. SumMapped reports the fault as an
+// error, which Go can only do for faults in Go code: that is why the C
+// backends, which would crash the program, never hash mappings.
+func SumMapped(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := blake2b.New(nil)
+	if info.Mode().IsRegular() {
+		_, err = hashMapped(h, f, info.Size())
+	} else {
+		_, err = io.Copy(h, f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// hashMapped writes the contents of f, of size bytes, to w, a digest of
+// the blake2b backend in use, from memory mappings if that backend is the
+// pure Go one, and by reading f otherwise.
+func hashMapped(w io.Writer, f *os.File, size int64) (int64, error) {
+	if blake2b.Backend() != "pure-go" {
+		return copyFile(w, f, size)
+	}
+	return mapFile(w, f, size)
+}
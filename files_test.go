@@ -0,0 +1,63 @@
+package blake2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestHashFiles(t *testing.T) {
+	dir := t.TempDir()
+	want := make(map[string][]byte)
+	sizes := make(map[string]int64)
+	var names []string
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(dir, string(rune('a'+i)))
+		data := bytes.Repeat([]byte{byte(i)}, i*1000)
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		h := blake2b.New(nil)
+		h.Write(data)
+		want[name] = h.Sum(nil)
+		sizes[name] = int64(len(data))
+		names = append(names, name)
+	}
+	missing := filepath.Join(dir, "missing")
+	names = append(names, missing)
+
+	paths := make(chan string)
+	go func() {
+		for _, name := range names {
+			paths <- name
+		}
+		close(paths)
+	}()
+	var got []string
+	for r := range HashFiles(paths, 4) {
+		got = append(got, r.Path)
+		if r.Path == missing {
+			if r.Err == nil || r.Digest != nil {
+				t.Errorf("missing file: %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil || !bytes.Equal(r.Digest, want[r.Path]) || r.Size != sizes[r.Path] {
+			t.Errorf("%s: %x, %v", r.Path, r.Digest, r.Err)
+		}
+	}
+	sort.Strings(got)
+	sort.Strings(names)
+	if len(got) != len(names) {
+		t.Fatalf("%d results, want %d", len(got), len(names))
+	}
+	for i := range got {
+		if got[i] != names[i] {
+			t.Fatalf("results for %v, want %v", got, names)
+		}
+	}
+}
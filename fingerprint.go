@@ -0,0 +1,117 @@
+package blake2
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// variant returns the variant of d, BLAKE2b if its config does not set
+// one.
+func (d Digest) variant() Variant {
+	if d.Config != nil && d.Config.Variant != 0 {
+		return d.Config.Variant
+	}
+	return BLAKE2b
+}
+
+// Fingerprint returns d.Sum in the format of OpenSSH fingerprints, as the
+// name of the variant and the unpadded base64 digest, such as
+// "BLAKE2s:3q2+7w...".
+func (d Digest) Fingerprint() string {
+	return d.variant().String() + ":" + base64.RawStdEncoding.EncodeToString(d.Sum)
+}
+
+// FingerprintHex returns d.Sum as colon-separated pairs of hexadecimal
+// digits, in the format of legacy MD5 fingerprints, such as "de:ad:be:ef".
+func (d Digest) FingerprintHex() string {
+	s := hex.EncodeToString(d.Sum)
+	var b strings.Builder
+	for i := 0; i < len(s); i += 2 {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		b.WriteString(s[i : i+2])
+	}
+	return b.String()
+}
+
+// Size of the randomart field, and its symbols by number of visits, with
+// 'S' and 'E' marking the start and end.
+const (
+	artWidth   = 17
+	artHeight  = 9
+	artSymbols = " .o+=*BOX@%&#/^SE"
+)
+
+// Randomart returns the OpenSSH randomart visualization of d.Sum, which
+// makes fingerprints easier to compare at a glance: a 17×9 field walked by
+// a drunken bishop driven by the digest bits, framed with title, such as
+// "ED25519 256", at the top and the variant name at the bottom. The
+// output holds 11 newline-terminated lines.
+func (d Digest) Randomart(title string) string {
+	var field [artWidth][artHeight]int
+	x, y := artWidth/2, artHeight/2
+	last := len(artSymbols) - 1
+	for _, v := range d.Sum {
+		for i := 0; i < 4; i++ {
+			if v&1 != 0 {
+				x++
+			} else {
+				x--
+			}
+			if v&2 != 0 {
+				y++
+			} else {
+				y--
+			}
+			x = clamp(x, artWidth-1)
+			y = clamp(y, artHeight-1)
+			if field[x][y] < last-2 {
+				field[x][y]++
+			}
+			v >>= 2
+		}
+	}
+	field[artWidth/2][artHeight/2] = last - 1
+	field[x][y] = last
+
+	var b strings.Builder
+	artBorder(&b, title)
+	for y := 0; y < artHeight; y++ {
+		b.WriteByte('|')
+		for x := 0; x < artWidth; x++ {
+			b.WriteByte(artSymbols[field[x][y]])
+		}
+		b.WriteString("|\n")
+	}
+	artBorder(&b, d.variant().String())
+	return b.String()
+}
+
+func clamp(v, max int) int {
+	switch {
+	case v < 0:
+		return 0
+	case v > max:
+		return max
+	}
+	return v
+}
+
+// artBorder writes a border line with label centered in brackets,
+// truncated as OpenSSH does.
+func artBorder(b *strings.Builder, label string) {
+	if label != "" {
+		label = "[" + label + "]"
+		if len(label) > artWidth-1 {
+			label = label[:artWidth-1]
+		}
+	}
+	pad := (artWidth - len(label)) / 2
+	b.WriteByte('+')
+	b.WriteString(strings.Repeat("-", pad))
+	b.WriteString(label)
+	b.WriteString(strings.Repeat("-", artWidth-pad-len(label)))
+	b.WriteString("+\n")
+}
@@ -0,0 +1,49 @@
+package blake2
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	sum, _ := hex.DecodeString("deadbeef00")
+	d := Digest{Config: &Config{Variant: BLAKE2s}, Sum: sum}
+	if got, want := d.Fingerprint(), "BLAKE2s:3q2+7wA"; got != want {
+		t.Errorf("Fingerprint = %q, want %q", got, want)
+	}
+	if got, want := d.FingerprintHex(), "de:ad:be:ef:00"; got != want {
+		t.Errorf("FingerprintHex = %q, want %q", got, want)
+	}
+	if got, want := (Digest{Sum: sum}).Fingerprint(), "BLAKE2b:3q2+7wA"; got != want {
+		t.Errorf("Fingerprint without config = %q, want %q", got, want)
+	}
+}
+
+func TestRandomart(t *testing.T) {
+	// The field of an Ed25519 key fingerprint from ssh-keygen -lv, whose
+	// SHA-256 digest is below.
+	sum, _ := hex.DecodeString("cfd3052d22cc91b176c88566af6a2eeb43a29d0a73443cc304af05d22dc7a4c5")
+	want := strings.Join([]string{
+		"+--[ED25519 256]--+",
+		"|+o.=o   o+.      |",
+		"|.*ooE  +==   .   |",
+		"|  Xo   oO.o o .  |",
+		"| + o   . o.. o   |",
+		"|. .     S.    .  |",
+		"| .. .   .o . .   |",
+		"|oo.+   .  + .    |",
+		"|ooo o o    .     |",
+		"|.. .o*.          |",
+		"+----[BLAKE2b]----+",
+		"",
+	}, "\n")
+	if got := (Digest{Sum: sum}).Randomart("ED25519 256"); got != want {
+		t.Errorf("Randomart =\n%s\nwant\n%s", got, want)
+	}
+
+	long := (Digest{Sum: sum}).Randomart("ED25519-CERT 256 bits")
+	if lines := strings.Split(long, "\n"); lines[0] != "+[ED25519-CERT 25-+" {
+		t.Errorf("truncated title line %q", lines[0])
+	}
+}
@@ -0,0 +1,82 @@
+package blake2
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// FoldRule is how Fold reduces a digest to a narrower width.
+type FoldRule int
+
+const (
+	// Truncate keeps the first bytes of the digest. Every bit of a BLAKE2
+	// digest depends on the whole input, so its prefixes are as good as
+	// digests of their width; prefer it.
+	Truncate FoldRule = iota
+	// XORFold splits the digest into pieces of the width, the last one
+	// possibly shorter, and XORs them together, the last one into the
+	// first bytes. It suits schemas whose documentation requires every
+	// digest bit to be used, and gives the same collision resistance.
+	XORFold
+)
+
+// ErrFoldWidth is returned by Fold for widths outside [1, len(digest)]
+// and unknown rules.
+var ErrFoldWidth = errors.New("blake2: invalid fold width")
+
+// Items that folded digests can identify before a collision becomes
+// likely, by the birthday bound: among n random values of w bits, two
+// collide with probability about n²/2^(w+1). A table that may outgrow
+// them needs a wider column, or a check of the full digest on a match.
+const (
+	// SafeItems64 is the number of items whose 8-byte digests, as in a
+	// former CRC-64 column, collide with probability one in a million.
+	SafeItems64 = 6_000_000
+	// LikelyCollision64 is the number of items whose 8-byte digests
+	// collide with probability one half, about 2^32.
+	LikelyCollision64 = 5_000_000_000
+	// SafeItems128 is the number of items whose 16-byte digests, as in a
+	// former MD5 column, collide with probability one in a million.
+	SafeItems128 = 26_000_000_000_000_000
+)
+
+// Fold returns digest reduced to width bytes by rule, for storing BLAKE2
+// digests in columns sized for legacy checksums, such as 8 bytes for
+// CRC-64 or 16 for MD5, without a schema change. Both rules are
+// deterministic and documented, so that other implementations can fold
+// the same way. To hash new data, NewHasher with a Size of width gives a
+// digest of that width directly; it differs from the folded one.
+func Fold(digest []byte, width int, rule FoldRule) ([]byte, error) {
+	if width < 1 || width > len(digest) {
+		return nil, ErrFoldWidth
+	}
+	out := make([]byte, width)
+	switch rule {
+	case Truncate:
+		copy(out, digest)
+	case XORFold:
+		for i, b := range digest {
+			out[i%width] ^= b
+		}
+	default:
+		return nil, ErrFoldWidth
+	}
+	return out, nil
+}
+
+// Fold64 returns the first 8 bytes of digest, of at least 8 bytes, as a
+// big-endian integer, for BIGINT columns that held CRC-64 checksums;
+// convert it to int64 for signed columns. It panics if digest is shorter.
+func Fold64(digest []byte) uint64 {
+	return binary.BigEndian.Uint64(digest[:8])
+}
+
+// CollisionProbability returns the probability that two of items random
+// digests of width bytes collide, by the birthday bound, to judge whether
+// a folded width suits a table.
+func CollisionProbability(width int, items float64) float64 {
+	// 1 - exp(-n(n-1)/2^(w+1)), accurate for small probabilities too.
+	x := items * (items - 1) / math.Ldexp(2, 8*width)
+	return -math.Expm1(-x)
+}
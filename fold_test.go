@@ -0,0 +1,65 @@
+package blake2
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestFold(t *testing.T) {
+	d := blake2b.Sum512([]byte("abc"))
+	// Pinned so that other implementations can check their folding.
+	for _, tt := range []struct {
+		width int
+		rule  FoldRule
+		want  string
+	}{
+		{8, Truncate, "ba80a53f981c4d0d"},
+		{8, XORFold, "c9b1ba50d4c26343"},
+		{5, XORFold, "cca24a68e8"},
+		{64, Truncate, hex.EncodeToString(d[:])},
+		{64, XORFold, hex.EncodeToString(d[:])},
+	} {
+		got, err := Fold(d[:], tt.width, tt.rule)
+		if err != nil {
+			t.Fatalf("Fold(%d, %d): %v", tt.width, tt.rule, err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("Fold(%d, %d) = %x, want %s", tt.width, tt.rule, got, tt.want)
+		}
+	}
+	if got := Fold64(d[:]); got != 0xba80a53f981c4d0d {
+		t.Errorf("Fold64 = %#x", got)
+	}
+
+	for _, tt := range []struct {
+		width int
+		rule  FoldRule
+	}{{0, Truncate}, {65, XORFold}, {8, FoldRule(2)}} {
+		if _, err := Fold(d[:], tt.width, tt.rule); err != ErrFoldWidth {
+			t.Errorf("Fold(%d, %d) error = %v, want ErrFoldWidth", tt.width, tt.rule, err)
+		}
+	}
+}
+
+func TestCollisionProbability(t *testing.T) {
+	for _, tt := range []struct {
+		width int
+		items float64
+		want  float64
+	}{
+		{8, SafeItems64, 1e-6},
+		{8, LikelyCollision64, 0.5},
+		{16, SafeItems128, 1e-6},
+	} {
+		p := CollisionProbability(tt.width, tt.items)
+		if math.Abs(p-tt.want)/tt.want > 0.1 {
+			t.Errorf("CollisionProbability(%d, %g) = %g, want about %g", tt.width, tt.items, p, tt.want)
+		}
+	}
+	if p := CollisionProbability(8, 1); p != 0 {
+		t.Errorf("CollisionProbability(8, 1) = %g, want 0", p)
+	}
+}
@@ -0,0 +1,203 @@
+// Package frame encodes the records of an append-only log, such as a
+// write-ahead log, as frames carrying a BLAKE2b checksum, and decodes
+// them on replay, detecting records torn by a crash and corrupted ones.
+//
+// A frame is the length of the record as a 4-byte little-endian integer,
+// a 16-byte checksum, then the record:
+//
+//	length || checksum || record
+//
+// The checksum is the 16-byte BLAKE2b digest, personalized for framing,
+// of the length followed by the record. With a key, it is a keyed MAC
+// instead, which only holders of the key can produce, so that records
+// cannot be forged by whoever can write to the log.
+package frame
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+const (
+	// ChecksumSize is the length of frame checksums.
+	ChecksumSize = 16
+	// HeaderSize is the length of the header preceding each record.
+	HeaderSize = 4 + ChecksumSize
+	// DefaultMaxSize is the largest record accepted by default.
+	DefaultMaxSize = 16 << 20
+)
+
+var personal = []byte("blake2 frame")
+
+var (
+	// ErrTorn is returned by Decoder.Decode for a frame cut short by the
+	// end of the log, as left by a crash during Encode.
+	ErrTorn = errors.New("frame: torn record")
+	// ErrCorrupt is returned by Decoder.Decode for a frame that does not
+	// match its checksum, or whose length exceeds the maximum.
+	ErrCorrupt = errors.New("frame: corrupt record")
+	// ErrTooLarge is returned by Encoder.Encode for records longer than
+	// the maximum.
+	ErrTooLarge = errors.New("frame: record too large")
+)
+
+// Options configure an Encoder or a Decoder. The zero value is valid.
+type Options struct {
+	// Key makes checksums keyed MACs. It is up to blake2b.MaxKeySize
+	// bytes; nil for plain checksums.
+	Key []byte
+	// MaxSize is the largest record, which bounds the memory a corrupt
+	// length can make Decode allocate. If 0, DefaultMaxSize.
+	MaxSize int
+}
+
+func (o *Options) maxSize() int {
+	if o == nil || o.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+	return o.MaxSize
+}
+
+// newChecksum returns the hash computing frame checksums with the key of
+// o, which may be nil.
+func newChecksum(o *Options) (hash.Hash, error) {
+	var key []byte
+	if o != nil {
+		key = o.Key
+	}
+	cfg := &blake2b.Config{Size: ChecksumSize, Key: key, Personal: personal}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return blake2b.New(cfg), nil
+}
+
+// checksum writes to header[4:] the checksum of record, whose length is
+// in header[:4].
+func checksum(h hash.Hash, header, record []byte) []byte {
+	h.Reset()
+	h.Write(header[:4])
+	h.Write(record)
+	return h.Sum(header[4:4])
+}
+
+// Encoder writes records as frames.
+type Encoder struct {
+	w   io.Writer
+	h   hash.Hash
+	max int
+	buf []byte
+	off int64
+}
+
+// NewEncoder returns an Encoder writing frames to w, configured by o,
+// which may be nil. It returns an error if the key is too long.
+func NewEncoder(w io.Writer, o *Options) (*Encoder, error) {
+	h, err := newChecksum(o)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{w: w, h: h, max: o.maxSize()}, nil
+}
+
+// Encode writes record as a frame, in a single call to Write so that a
+// crash tears at most the last frame of an append-only file.
+func (e *Encoder) Encode(record []byte) error {
+	if len(record) > e.max || uint64(len(record)) > math.MaxUint32 {
+		return ErrTooLarge
+	}
+	var header [HeaderSize]byte
+	e.buf = append(e.buf[:0], header[:]...)
+	binary.LittleEndian.PutUint32(e.buf, uint32(len(record)))
+	checksum(e.h, e.buf, record)
+	e.buf = append(e.buf, record...)
+	n, err := e.w.Write(e.buf)
+	e.off += int64(n)
+	return err
+}
+
+// Offset returns the number of bytes written.
+func (e *Encoder) Offset() int64 {
+	return e.off
+}
+
+// Decoder reads frames, for instance to replay a log.
+type Decoder struct {
+	r      io.Reader
+	h      hash.Hash
+	max    int
+	header [HeaderSize]byte
+	sum    [ChecksumSize]byte
+	off    int64
+	err    error
+}
+
+// NewDecoder returns a Decoder reading frames from r, configured by o,
+// which may be nil, with the options the frames were encoded with. It
+// returns an error if the key is too long.
+func NewDecoder(r io.Reader, o *Options) (*Decoder, error) {
+	h, err := newChecksum(o)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{r: r, h: h, max: o.maxSize()}, nil
+}
+
+// Decode returns the next record. It returns io.EOF at the end of the
+// log, and an error wrapping ErrTorn or ErrCorrupt, with the offset of
+// the frame, for a frame that is incomplete or does not match its
+// checksum; errors are sticky. A torn frame is expected at the end of a
+// log after a crash, and the log is typically truncated to Offset then.
+// A frame whose length was corrupted may be reported as torn.
+func (d *Decoder) Decode() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	record, err := d.decode()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	d.off += int64(HeaderSize + len(record))
+	return record, nil
+}
+
+func (d *Decoder) decode() ([]byte, error) {
+	switch _, err := io.ReadFull(d.r, d.header[:]); err {
+	case nil:
+	case io.ErrUnexpectedEOF:
+		return nil, fmt.Errorf("%w at offset %d", ErrTorn, d.off)
+	default:
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(d.header[:4])
+	if uint64(n) > uint64(d.max) {
+		return nil, fmt.Errorf("%w at offset %d: length %d", ErrCorrupt, d.off, n)
+	}
+	record := make([]byte, n)
+	switch _, err := io.ReadFull(d.r, record); err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		return nil, fmt.Errorf("%w at offset %d", ErrTorn, d.off)
+	default:
+		return nil, err
+	}
+	copy(d.sum[:], d.header[4:])
+	if subtle.ConstantTimeCompare(checksum(d.h, d.header[:], record), d.sum[:]) != 1 {
+		return nil, fmt.Errorf("%w at offset %d", ErrCorrupt, d.off)
+	}
+	return record, nil
+}
+
+// Offset returns the offset of the end of the last record decoded, up to
+// which the log is intact.
+func (d *Decoder) Offset() int64 {
+	return d.off
+}
@@ -0,0 +1,126 @@
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+var records = [][]byte{[]byte("first"), nil, []byte("third record"), bytes.Repeat([]byte{7}, 1000)}
+
+func encode(t *testing.T, o *Options) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	e, err := NewEncoder(&buf, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err := e.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e.Offset() != int64(buf.Len()) {
+		t.Errorf("Offset = %d, want %d", e.Offset(), buf.Len())
+	}
+	return buf.Bytes()
+}
+
+// decodeAll decodes log, returning the records and the error ending them.
+func decodeAll(t *testing.T, log []byte, o *Options) ([][]byte, *Decoder, error) {
+	t.Helper()
+	d, err := NewDecoder(bytes.NewReader(log), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]byte
+	for {
+		r, err := d.Decode()
+		if err != nil {
+			return got, d, err
+		}
+		got = append(got, r)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, o := range []*Options{nil, {Key: []byte("log key")}} {
+		log := encode(t, o)
+		got, d, err := decodeAll(t, log, o)
+		if err != io.EOF || len(got) != len(records) {
+			t.Fatalf("decoded %d records, %v", len(got), err)
+		}
+		for i := range records {
+			if !bytes.Equal(got[i], records[i]) {
+				t.Errorf("record %d = %q, want %q", i, got[i], records[i])
+			}
+		}
+		if d.Offset() != int64(len(log)) {
+			t.Errorf("Offset = %d, want %d", d.Offset(), len(log))
+		}
+	}
+}
+
+func TestTorn(t *testing.T) {
+	log := encode(t, nil)
+	last := len(log) - HeaderSize - len(records[len(records)-1])
+	for _, cut := range []int{1, HeaderSize - 1, HeaderSize, HeaderSize + 500} {
+		got, d, err := decodeAll(t, log[:last+cut], nil)
+		if !errors.Is(err, ErrTorn) || len(got) != len(records)-1 || d.Offset() != int64(last) {
+			t.Errorf("cut %d: %d records, offset %d, %v", cut, len(got), d.Offset(), err)
+		}
+		if _, err2 := d.Decode(); err2 != err {
+			t.Errorf("cut %d: error not sticky: %v", cut, err2)
+		}
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	log := encode(t, nil)
+	second := HeaderSize + len(records[0])
+	for _, pos := range []int{0, 4, HeaderSize} {
+		bad := append([]byte(nil), log...)
+		bad[pos] ^= 1
+		got, d, err := decodeAll(t, bad, nil)
+		if !errors.Is(err, ErrCorrupt) || len(got) != 0 || d.Offset() != 0 {
+			t.Errorf("byte %d flipped: %d records, %v", pos, len(got), err)
+		}
+	}
+
+	// A length beyond the maximum is rejected before reading the record.
+	bad := append([]byte(nil), log...)
+	bad[second+3] = 0xff
+	if got, _, err := decodeAll(t, bad, nil); !errors.Is(err, ErrCorrupt) || len(got) != 1 {
+		t.Errorf("huge length: %d records, %v", len(got), err)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	log := encode(t, &Options{Key: []byte("log key")})
+	if _, _, err := decodeAll(t, log, &Options{Key: []byte("other key")}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("wrong key: %v, want ErrCorrupt", err)
+	}
+	if _, _, err := decodeAll(t, log, nil); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("no key: %v, want ErrCorrupt", err)
+	}
+	if _, err := NewEncoder(io.Discard, &Options{Key: make([]byte, blake2b.MaxKeySize+1)}); err != blake2b.ErrKeySize {
+		t.Errorf("long key: %v, want ErrKeySize", err)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	var buf bytes.Buffer
+	e, _ := NewEncoder(&buf, &Options{MaxSize: 10})
+	if err := e.Encode(make([]byte, 11)); err != ErrTooLarge {
+		t.Errorf("Encode of 11 bytes: %v, want ErrTooLarge", err)
+	}
+	if err := e.Encode(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := decodeAll(t, buf.Bytes(), &Options{MaxSize: 9}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Decode of a record over MaxSize: %v, want ErrCorrupt", err)
+	}
+}
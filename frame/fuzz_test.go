@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzDecoder(f *testing.F) {
+	var buf bytes.Buffer
+	e, _ := NewEncoder(&buf, nil)
+	e.Encode([]byte("record"))
+	f.Add(buf.Bytes())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := NewDecoder(bytes.NewReader(data), nil)
+		if err != nil {
+			return
+		}
+		for i := 0; i < 1000; i++ {
+			if _, err := d.Decode(); err != nil {
+				return
+			}
+		}
+	})
+}
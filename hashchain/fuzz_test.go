@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package hashchain
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	c := New()
+	c.Append([]byte("entry"))
+	b, _ := c.MarshalBinary()
+	f.Add(b)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var c Chain
+		if c.UnmarshalBinary(data) != nil {
+			return
+		}
+		if b, err := c.MarshalBinary(); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("accepted encoding does not round-trip: %x", data)
+		}
+	})
+}
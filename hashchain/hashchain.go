@@ -0,0 +1,127 @@
+// Package hashchain links the records of an append-only log, such as an
+// audit log or an event store, into a chain of BLAKE2b digests, so that
+// changing, removing or reordering a record changes every later digest.
+//
+// The digest of a record is the 32-byte BLAKE2b digest of the digest of
+// the previous record followed by the record:
+//
+//	digest = H(prev || record)
+//
+// where prev is 32 zero bytes for the first record. Publishing or signing
+// the latest digest, the head, commits to the whole log.
+package hashchain
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/internal/wire"
+)
+
+// DigestSize is the length of record digests.
+const DigestSize = 32
+
+var magic = []byte("B2HC\x01")
+
+var (
+	// ErrTampered is returned by Verify when a record does not match its
+	// digest.
+	ErrTampered = errors.New("hashchain: log has been tampered with")
+	// ErrInvalid is returned when decoding a malformed chain.
+	ErrInvalid = errors.New("hashchain: invalid encoding")
+	// ErrVersion is returned when decoding a chain encoded by a newer
+	// version of the package.
+	ErrVersion = errors.New("hashchain: unsupported encoding version")
+)
+
+// Entry is a record of the log with its digest.
+type Entry struct {
+	Record []byte
+	Digest []byte
+}
+
+// Chain is the state of a hash chain: the number of records appended and
+// the digest of the last one.
+type Chain struct {
+	n    uint64
+	head []byte
+}
+
+// New returns an empty chain.
+func New() *Chain {
+	return &Chain{head: make([]byte, DigestSize)}
+}
+
+// Link returns the digest of record following a record with digest prev.
+func Link(prev, record []byte) []byte {
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	h.Write(prev)
+	h.Write(record)
+	return h.Sum(nil)
+}
+
+// Append appends record to the chain and returns its entry.
+func (c *Chain) Append(record []byte) Entry {
+	c.head = Link(c.head, record)
+	c.n++
+	return Entry{Record: record, Digest: append([]byte(nil), c.head...)}
+}
+
+// Head returns the digest of the last record, or 32 zero bytes if the
+// chain is empty.
+func (c *Chain) Head() []byte {
+	return append([]byte(nil), c.head...)
+}
+
+// Len returns the number of records appended.
+func (c *Chain) Len() uint64 {
+	return c.n
+}
+
+// Verify checks that each entry of the full log, from the first record
+// on, matches its digest. The returned error wraps ErrTampered and names
+// the first entry that does not. Comparing the digest of the last entry
+// with a trusted head then verifies the whole log.
+func Verify(log []Entry) error {
+	c := New()
+	for i, e := range log {
+		c.Append(e.Record)
+		if subtle.ConstantTimeCompare(c.head, e.Digest) != 1 {
+			return fmt.Errorf("%w: entry %d", ErrTampered, i)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the chain as a magic string, the number of
+// records as a uvarint, then the head, so that appending can continue
+// after a restart.
+func (c *Chain) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(magic)
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], c.n)])
+	buf.Write(c.head)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a chain encoded by MarshalBinary.
+func (c *Chain) UnmarshalBinary(data []byte) error {
+	data, version, ok := wire.Header(data, string(magic))
+	if !ok {
+		return ErrInvalid
+	}
+	if version != magic[len(magic)-1] {
+		return ErrVersion
+	}
+	n, data, ok := wire.Uvarint(data)
+	if !ok || len(data) != DigestSize {
+		return ErrInvalid
+	}
+	*c = Chain{n: n, head: append([]byte(nil), data...)}
+	return nil
+}
@@ -0,0 +1,55 @@
+package hashchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	c := New()
+	var log []Entry
+	for _, r := range []string{"login alice", "read file", "logout alice"} {
+		log = append(log, c.Append([]byte(r)))
+	}
+	if c.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", c.Len())
+	}
+	if want := Link(Link(Link(make([]byte, DigestSize), log[0].Record), log[1].Record), log[2].Record); !bytes.Equal(c.Head(), want) {
+		t.Errorf("head %x, want %x", c.Head(), want)
+	}
+	if err := Verify(log); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+
+	log[1].Record = []byte("write file")
+	if err := Verify(log); !errors.Is(err, ErrTampered) {
+		t.Errorf("changed record: Verify returned %v", err)
+	}
+	log[1].Record = []byte("read file")
+	if err := Verify([]Entry{log[0], log[2]}); !errors.Is(err, ErrTampered) {
+		t.Errorf("removed record: Verify returned %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	c := New()
+	c.Append([]byte("one"))
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r Chain
+	if err := r.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	a1, a2 := c.Append([]byte("two")), r.Append([]byte("two"))
+	if !bytes.Equal(a1.Digest, a2.Digest) || r.Len() != 2 {
+		t.Error("restored chain differs")
+	}
+	for _, bad := range [][]byte{nil, b[:len(b)-1], append(b, 0)} {
+		if err := new(Chain).UnmarshalBinary(bad); err != ErrInvalid {
+			t.Errorf("UnmarshalBinary(%x) returned %v", bad, err)
+		}
+	}
+}
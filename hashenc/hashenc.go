@@ -0,0 +1,112 @@
+// Package hashenc writes primitive values to a hash in fixed, documented
+// encodings, for canonical transcript hashing in protocols:
+//
+//   - unsigned and signed integers as little-endian integers of their
+//     size, signed ones in two's complement;
+//   - booleans as one byte, 1 for true and 0 for false;
+//   - length-prefixed byte slices and strings as their length, as a
+//     64-bit little-endian integer, followed by their bytes.
+//
+// Booleans, 64-bit integers and length-prefixed values are encoded as
+// structhash encodes them, without its kind tags. Length prefixes keep
+// consecutive variable-length values apart, so that writing "ab" then
+// "c" differs from writing "a" then "bc".
+package hashenc
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Writer writes encoded values to an underlying writer, typically a
+// hash.Hash. Errors are sticky: after the first error, writes do nothing
+// and Err reports it. Writes to a hash.Hash never fail.
+type Writer struct {
+	w   io.Writer
+	buf [8]byte
+	err error
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(b)
+	}
+}
+
+// Write writes p as is, with no length prefix, so that Writer can be
+// passed on as an io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.write(p)
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+// WriteUint8 writes v as one byte.
+func (w *Writer) WriteUint8(v uint8) {
+	w.buf[0] = v
+	w.write(w.buf[:1])
+}
+
+// WriteUint16 writes v as 2 little-endian bytes.
+func (w *Writer) WriteUint16(v uint16) {
+	binary.LittleEndian.PutUint16(w.buf[:], v)
+	w.write(w.buf[:2])
+}
+
+// WriteUint32 writes v as 4 little-endian bytes.
+func (w *Writer) WriteUint32(v uint32) {
+	binary.LittleEndian.PutUint32(w.buf[:], v)
+	w.write(w.buf[:4])
+}
+
+// WriteUint64 writes v as 8 little-endian bytes.
+func (w *Writer) WriteUint64(v uint64) {
+	binary.LittleEndian.PutUint64(w.buf[:], v)
+	w.write(w.buf[:8])
+}
+
+// WriteInt32 writes v as 4 little-endian bytes, in two's complement.
+func (w *Writer) WriteInt32(v int32) {
+	w.WriteUint32(uint32(v))
+}
+
+// WriteInt64 writes v as 8 little-endian bytes, in two's complement.
+func (w *Writer) WriteInt64(v int64) {
+	w.WriteUint64(uint64(v))
+}
+
+// WriteBool writes v as one byte, 1 for true and 0 for false.
+func (w *Writer) WriteBool(v bool) {
+	if v {
+		w.WriteUint8(1)
+	} else {
+		w.WriteUint8(0)
+	}
+}
+
+// WriteLenPrefixedBytes writes the length of b as 8 little-endian bytes,
+// then b.
+func (w *Writer) WriteLenPrefixedBytes(b []byte) {
+	w.WriteUint64(uint64(len(b)))
+	w.write(b)
+}
+
+// WriteLenPrefixedString writes s like WriteLenPrefixedBytes.
+func (w *Writer) WriteLenPrefixedString(s string) {
+	w.WriteUint64(uint64(len(s)))
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, s)
+	}
+}
+
+// Err returns the first error returned by the underlying writer.
+func (w *Writer) Err() error {
+	return w.err
+}
@@ -0,0 +1,71 @@
+package hashenc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestEncodings(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteUint8(0xab)
+	w.WriteUint16(0x0102)
+	w.WriteUint32(0x01020304)
+	w.WriteUint64(0x0102030405060708)
+	w.WriteInt32(-2)
+	w.WriteInt64(-1)
+	w.WriteBool(true)
+	w.WriteBool(false)
+	w.WriteLenPrefixedBytes([]byte("ab"))
+	w.WriteLenPrefixedString("c")
+	w.Write([]byte("raw"))
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := "ab" + "0201" + "04030201" + "0807060504030201" +
+		"feffffff" + "ffffffffffffffff" + "01" + "00" +
+		"0200000000000000" + "6162" + "0100000000000000" + "63" + "726177"
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("encoding = %s\nwant       %s", got, want)
+	}
+}
+
+func TestLengthPrefixes(t *testing.T) {
+	sum := func(parts ...string) []byte {
+		h := blake2b.New(&blake2b.Config{Size: 32})
+		w := NewWriter(h)
+		for _, p := range parts {
+			w.WriteLenPrefixedString(p)
+		}
+		return h.Sum(nil)
+	}
+	if bytes.Equal(sum("ab", "c"), sum("a", "bc")) {
+		t.Error("different splits give the same digest")
+	}
+}
+
+type failWriter struct{ n int }
+
+var errFail = errors.New("write failed")
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	f.n++
+	return 0, errFail
+}
+
+func TestStickyError(t *testing.T) {
+	f := new(failWriter)
+	w := NewWriter(f)
+	w.WriteUint32(1)
+	w.WriteLenPrefixedString("x")
+	if _, err := w.Write([]byte("y")); err != errFail {
+		t.Errorf("Write error %v, want %v", err, errFail)
+	}
+	if w.Err() != errFail || f.n != 1 {
+		t.Errorf("Err = %v after %d writes, want %v after 1", w.Err(), f.n, errFail)
+	}
+}
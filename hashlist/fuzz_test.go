@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package hashlist
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	l, _ := New(bytes.NewReader(make([]byte, 2500)), 1000)
+	b, _ := l.MarshalBinary()
+	f.Add(b)
+	f.Add([]byte("B2HL\x01\x01\x80\x80\x80\x80\x80\x80\x80\x80\x40"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var l List
+		if l.UnmarshalBinary(data) != nil {
+			return
+		}
+		if b, err := l.MarshalBinary(); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("accepted encoding does not round-trip: %x", data)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+// Package hashlist records BLAKE2b digests of fixed-size chunks of
+// content, so that ranged downloads can be verified chunk by chunk.
+//
+// The digest of each chunk is the 32-byte BLAKE2b digest of its bytes.
+// The top digest, which identifies the whole content, is the 32-byte
+// BLAKE2b digest of the concatenated chunk digests.
+package hashlist
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/internal/wire"
+)
+
+// DigestSize is the length of chunk and top digests.
+const DigestSize = 32
+
+var magic = []byte("B2HL\x01")
+
+var (
+	// ErrMismatch is returned when a chunk does not match its digest.
+	ErrMismatch = errors.New("hashlist: chunk digest mismatch")
+	// ErrInvalid is returned when decoding a malformed hash list.
+	ErrInvalid = errors.New("hashlist: invalid encoding")
+	// ErrVersion is returned when decoding a hash list encoded by a
+	// newer version of the package.
+	ErrVersion = errors.New("hashlist: unsupported encoding version")
+	// ErrRange is returned for chunk indexes or byte ranges outside the
+	// content.
+	ErrRange = errors.New("hashlist: out of range")
+)
+
+// List is the list of chunk digests of some content.
+type List struct {
+	// ChunkSize is the length of every chunk but the last, which may be
+	// shorter.
+	ChunkSize int64
+	// Length is the length of the content.
+	Length int64
+	// Digests holds the digest of each chunk.
+	Digests [][]byte
+}
+
+// New reads r to the end and returns the hash list of its content, cut
+// into chunks of chunkSize bytes. It panics if chunkSize is not positive.
+func New(r io.Reader, chunkSize int64) (*List, error) {
+	if chunkSize <= 0 {
+		panic("hashlist: chunk size must be positive")
+	}
+	l := &List{ChunkSize: chunkSize}
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	for {
+		h.Reset()
+		n, err := io.Copy(h, io.LimitReader(r, chunkSize))
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return l, nil
+		}
+		l.Length += n
+		l.Digests = append(l.Digests, h.Sum(nil))
+		if n < chunkSize {
+			return l, nil
+		}
+	}
+}
+
+// Top returns the top digest, the digest of the concatenated chunk
+// digests.
+func (l *List) Top() []byte {
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	for _, d := range l.Digests {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// ChunkRange returns the indexes of the first and last chunks holding the
+// n bytes starting at offset, and the offset of the first one, from where
+// a ranged download has to start to be verifiable.
+func (l *List) ChunkRange(offset, n int64) (first, last int, start int64, err error) {
+	if offset < 0 || n <= 0 || offset+n > l.Length {
+		return 0, 0, 0, ErrRange
+	}
+	first = int(offset / l.ChunkSize)
+	last = int((offset + n - 1) / l.ChunkSize)
+	return first, last, int64(first) * l.ChunkSize, nil
+}
+
+// chunkLen returns the length of chunk i.
+func (l *List) chunkLen(i int) int64 {
+	if i == len(l.Digests)-1 {
+		return l.Length - int64(i)*l.ChunkSize
+	}
+	return l.ChunkSize
+}
+
+// VerifyChunk checks that data is chunk i of the content.
+func (l *List) VerifyChunk(i int, data []byte) error {
+	if i < 0 || i >= len(l.Digests) {
+		return ErrRange
+	}
+	if int64(len(data)) != l.chunkLen(i) {
+		return ErrMismatch
+	}
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	h.Write(data)
+	if subtle.ConstantTimeCompare(h.Sum(nil), l.Digests[i]) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// Range is a range of bytes of the content.
+type Range struct {
+	Offset, Length int64
+}
+
+// Update brings the list up to date with the content of r, length bytes
+// long, which differs from the content l was computed from only in the
+// changed byte ranges, and possibly in length, as a sync tool tracking
+// edits to a large file knows. It reads and hashes again only the chunks
+// overlapping changed ranges, and the chunks from the old last one on if
+// the length changed, and returns how many it hashed. Ranges past the
+// end of the content are ignored. The list is unchanged on error.
+func (l *List) Update(r io.ReaderAt, length int64, changed []Range) (int, error) {
+	if length < 0 {
+		return 0, ErrRange
+	}
+	count := int((length + l.ChunkSize - 1) / l.ChunkSize)
+	var dirty [][2]int
+	for _, c := range changed {
+		if c.Offset < 0 || c.Length < 0 {
+			return 0, ErrRange
+		}
+		if c.Length == 0 || c.Offset >= length {
+			continue
+		}
+		end := c.Offset + c.Length
+		if end > length {
+			end = length
+		}
+		dirty = append(dirty, [2]int{int(c.Offset / l.ChunkSize), int((end - 1) / l.ChunkSize)})
+	}
+	if length != l.Length {
+		first := len(l.Digests) - 1
+		if count-1 < first {
+			first = count - 1
+		}
+		if first < 0 {
+			first = 0
+		}
+		if first < count {
+			dirty = append(dirty, [2]int{first, count - 1})
+		}
+	}
+	sort.Slice(dirty, func(i, j int) bool { return dirty[i][0] < dirty[j][0] })
+
+	updated := &List{ChunkSize: l.ChunkSize, Length: length, Digests: make([][]byte, count)}
+	copy(updated.Digests, l.Digests)
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	next, hashed := 0, 0
+	for _, d := range dirty {
+		if d[0] < next {
+			d[0] = next
+		}
+		for i := d[0]; i <= d[1]; i++ {
+			h.Reset()
+			n := updated.chunkLen(i)
+			k, err := io.Copy(h, io.NewSectionReader(r, int64(i)*l.ChunkSize, n))
+			if err == nil && k != n {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return 0, err
+			}
+			updated.Digests[i] = h.Sum(nil)
+			hashed++
+		}
+		if d[1]+1 > next {
+			next = d[1] + 1
+		}
+	}
+	*l = *updated
+	return hashed, nil
+}
+
+// MarshalBinary encodes the hash list as a magic string, the chunk size
+// and content length as uvarints, then the chunk digests.
+func (l *List) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(magic)
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(l.ChunkSize))])
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(l.Length))])
+	for _, d := range l.Digests {
+		buf.Write(d)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a hash list encoded by MarshalBinary.
+func (l *List) UnmarshalBinary(data []byte) error {
+	data, version, ok := wire.Header(data, string(magic))
+	if !ok {
+		return ErrInvalid
+	}
+	if version != magic[len(magic)-1] {
+		return ErrVersion
+	}
+	chunkSize, data, ok := wire.Uvarint(data)
+	if !ok || chunkSize == 0 || chunkSize > 1<<62 {
+		return ErrInvalid
+	}
+	length, data, ok := wire.Uvarint(data)
+	if !ok || length > 1<<62 {
+		return ErrInvalid
+	}
+
+	count := length / chunkSize
+	if length%chunkSize != 0 {
+		count++
+	}
+	if !wire.Count(data, DigestSize, count) {
+		return ErrInvalid
+	}
+	digests := make([][]byte, count)
+	for i := range digests {
+		digests[i] = append([]byte(nil), data[i*DigestSize:(i+1)*DigestSize]...)
+	}
+	*l = List{ChunkSize: int64(chunkSize), Length: int64(length), Digests: digests}
+	return nil
+}
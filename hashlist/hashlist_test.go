@@ -0,0 +1,140 @@
+package hashlist
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestHashList(t *testing.T) {
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	l, err := New(bytes.NewReader(data), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Length != 2500 || len(l.Digests) != 3 {
+		t.Fatalf("length %d, %d chunks", l.Length, len(l.Digests))
+	}
+	for i := 0; i < 3; i++ {
+		end := (i + 1) * 1000
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := l.VerifyChunk(i, data[i*1000:end]); err != nil {
+			t.Errorf("chunk %d: %v", i, err)
+		}
+	}
+	if err := l.VerifyChunk(1, data[:1000]); err != ErrMismatch {
+		t.Errorf("wrong chunk: got %v, want ErrMismatch", err)
+	}
+	if err := l.VerifyChunk(3, nil); err != ErrRange {
+		t.Errorf("chunk 3: got %v, want ErrRange", err)
+	}
+
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	for _, d := range l.Digests {
+		h.Write(d)
+	}
+	if !bytes.Equal(l.Top(), h.Sum(nil)) {
+		t.Error("wrong top digest")
+	}
+
+	first, last, start, err := l.ChunkRange(999, 2)
+	if err != nil || first != 0 || last != 1 || start != 0 {
+		t.Errorf("ChunkRange(999, 2) = %d, %d, %d, %v", first, last, start, err)
+	}
+	if _, _, _, err := l.ChunkRange(2000, 501); err != ErrRange {
+		t.Errorf("ChunkRange past the end: got %v, want ErrRange", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	l, err := New(bytes.NewReader(make([]byte, 5000)), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := l.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got List
+	if err := got.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, l) {
+		t.Errorf("round trip: got %+v, want %+v", got, l)
+	}
+	if err := got.UnmarshalBinary(enc[:len(enc)-1]); err != ErrInvalid {
+		t.Errorf("truncated: got %v, want ErrInvalid", err)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	l, err := New(bytes.NewReader(nil), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Length != 0 || len(l.Digests) != 0 {
+		t.Errorf("got %+v for empty content", l)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	const chunk = 100
+	content := make([]byte, 1050)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	edit := func(data []byte, off int, b ...byte) []byte {
+		data = append([]byte(nil), data...)
+		copy(data[off:], b)
+		return data
+	}
+	for _, tt := range []struct {
+		name    string
+		data    []byte
+		changed []Range
+		hashed  int
+	}{
+		{"unchanged", content, nil, 0},
+		{"one byte", edit(content, 250, 0xff), []Range{{250, 1}}, 1},
+		{"across chunks", edit(content, 390, make([]byte, 20)...), []Range{{390, 20}}, 2},
+		{"overlapping ranges", edit(content, 0, 9, 9), []Range{{0, 150}, {100, 10}, {120, 200}}, 4},
+		{"last chunk", edit(content, 1049, 0), []Range{{1049, 1}}, 1},
+		{"grown", append(append([]byte(nil), content...), make([]byte, 230)...), nil, 3},
+		{"shrunk", content[:420], nil, 1},
+		{"shrunk to a chunk", content[:400], []Range{{390, 100}}, 1},
+		{"emptied", nil, []Range{{0, 1050}}, 0},
+	} {
+		l, err := New(bytes.NewReader(content), chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed, err := l.Update(bytes.NewReader(tt.data), int64(len(tt.data)), tt.changed)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		want, _ := New(bytes.NewReader(tt.data), chunk)
+		if hashed != tt.hashed || l.Length != want.Length || len(l.Digests) != len(want.Digests) || !bytes.Equal(l.Top(), want.Top()) {
+			t.Errorf("%s: hashed %d chunks, list %+v, want %d, %+v", tt.name, hashed, l, tt.hashed, want)
+		}
+	}
+
+	l, _ := New(bytes.NewReader(content), chunk)
+	top := l.Top()
+	if _, err := l.Update(bytes.NewReader(content), int64(len(content)), []Range{{-1, 5}}); err != ErrRange {
+		t.Errorf("negative offset: %v, want ErrRange", err)
+	}
+	if _, err := l.Update(bytes.NewReader(content[:500]), 800, nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("short reader: %v, want io.ErrUnexpectedEOF", err)
+	}
+	if !bytes.Equal(l.Top(), top) {
+		t.Error("list changed by a failed Update")
+	}
+}
@@ -0,0 +1,160 @@
+package hashlist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrResponse is returned by VerifyResponse for responses that are not
+// a verifiable part of the content.
+var ErrResponse = errors.New("hashlist: unverifiable HTTP response")
+
+// RangeHeader returns the value of the Range header requesting the n
+// bytes starting at offset, extended to whole chunks so that the
+// response can be verified, for instance from an untrusted mirror.
+func (l *List) RangeHeader(offset, n int64) (string, error) {
+	_, last, start, err := l.ChunkRange(offset, n)
+	if err != nil {
+		return "", err
+	}
+	end := int64(last)*l.ChunkSize + l.chunkLen(last)
+	return fmt.Sprintf("bytes=%d-%d", start, end-1), nil
+}
+
+// VerifyResponse returns a ChunkReader of the content from offset on,
+// read from the body of resp: a 206 Partial Content response whose
+// range starts at a chunk boundary at or before offset, as RangeHeader
+// requests, or a 200 OK response with the whole content. Bytes are
+// returned only once the chunk holding them has been verified. It
+// returns an error wrapping ErrResponse for other responses; the caller
+// closes the body in every case.
+func (l *List) VerifyResponse(resp *http.Response, offset int64) (*ChunkReader, error) {
+	if offset < 0 || offset > l.Length {
+		return nil, ErrRange
+	}
+	var start int64
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusPartialContent:
+		var total int64
+		var err error
+		start, total, err = parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+		if total >= 0 && total != l.Length {
+			return nil, fmt.Errorf("%w: content length %d, want %d", ErrResponse, total, l.Length)
+		}
+	default:
+		return nil, fmt.Errorf("%w: status %s", ErrResponse, resp.Status)
+	}
+	if start%l.ChunkSize != 0 || start > offset {
+		return nil, fmt.Errorf("%w: range starts at %d, not at a chunk boundary up to %d", ErrResponse, start, offset)
+	}
+	return &ChunkReader{l: l, r: resp.Body, next: int(start / l.ChunkSize), off: start, skip: offset - start}, nil
+}
+
+// parseContentRange returns the first byte and the complete length, or
+// -1 if unknown, of a Content-Range header value.
+func parseContentRange(v string) (start, total int64, err error) {
+	bad := fmt.Errorf("%w: Content-Range %q", ErrResponse, v)
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, bad
+	}
+	v = v[len("bytes "):]
+	slash := strings.IndexByte(v, '/')
+	dash := strings.IndexByte(v, '-')
+	if slash < 0 || dash < 0 || dash > slash {
+		return 0, 0, bad
+	}
+	if start, err = strconv.ParseInt(v[:dash], 10, 64); err != nil || start < 0 {
+		return 0, 0, bad
+	}
+	if v[slash+1:] == "*" {
+		return start, -1, nil
+	}
+	if total, err = strconv.ParseInt(v[slash+1:], 10, 64); err != nil {
+		return 0, 0, bad
+	}
+	return start, total, nil
+}
+
+// ChunkReader reads content that it verifies chunk by chunk against a
+// hash list, for downloads resumable from any mirror: each chunk is read
+// whole, then checked, and only then are its bytes returned. It fails
+// with an error wrapping ErrMismatch at the first chunk that does not
+// match, and with io.ErrUnexpectedEOF if its source ends within a chunk.
+// It returns io.EOF at the end of the content, or where its source ends
+// at a chunk boundary, as the response to a Range request does; Offset
+// tells which. Errors are sticky.
+type ChunkReader struct {
+	l       *List
+	r       io.Reader
+	next    int
+	off     int64
+	skip    int64
+	buf     []byte
+	pending []byte
+	err     error
+}
+
+// NewChunkReader returns a ChunkReader of r, which holds the content from
+// the start of chunk first on.
+func (l *List) NewChunkReader(r io.Reader, first int) *ChunkReader {
+	return &ChunkReader{l: l, r: r, next: first, off: int64(first) * l.ChunkSize}
+}
+
+func (c *ChunkReader) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.err = c.fill()
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	c.off += int64(n)
+	return n, nil
+}
+
+// fill reads and verifies the next chunk into pending, leaving out the
+// bytes to skip.
+func (c *ChunkReader) fill() error {
+	if c.next >= len(c.l.Digests) {
+		return io.EOF
+	}
+	n := c.l.chunkLen(c.next)
+	if int64(cap(c.buf)) < n {
+		c.buf = make([]byte, n)
+	}
+	c.buf = c.buf[:n]
+	if _, err := io.ReadFull(c.r, c.buf); err != nil {
+		return err
+	}
+	if err := c.l.VerifyChunk(c.next, c.buf); err != nil {
+		return fmt.Errorf("%w: chunk %d", err, c.next)
+	}
+	c.next++
+	c.pending = c.buf
+	if c.skip > 0 {
+		k := c.skip
+		if k > n {
+			k = n
+		}
+		c.pending = c.pending[k:]
+		c.skip -= k
+		c.off += k
+	}
+	return nil
+}
+
+// Offset returns the offset in the content of the next byte Read
+// returns. After an error, a download resumes from there with
+// RangeHeader and VerifyResponse.
+func (c *ChunkReader) Offset() int64 {
+	return c.off
+}
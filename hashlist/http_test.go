@@ -0,0 +1,112 @@
+package hashlist
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// get requests the content from url, with the Range header rng if not
+// empty.
+func get(t *testing.T, url, rng string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestVerifyResponse(t *testing.T) {
+	data := make([]byte, 10500)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	l, _ := New(bytes.NewReader(data), 1000)
+	bad := append([]byte(nil), data...)
+	bad[5500] ^= 1
+	serve := func(content []byte) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		}))
+	}
+	good, mirror := serve(data), serve(bad)
+	defer good.Close()
+	defer mirror.Close()
+
+	resp := get(t, good.URL, "")
+	cr, err := l.VerifyResponse(resp, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(cr); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("full response: %d bytes, %v", len(got), err)
+	}
+	resp.Body.Close()
+
+	rng, err := l.RangeHeader(1234, 3000)
+	if err != nil || rng != "bytes=1000-4999" {
+		t.Fatalf("RangeHeader = %q, %v", rng, err)
+	}
+	resp = get(t, good.URL, rng)
+	cr, err = l.VerifyResponse(resp, 1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(cr); err != nil || !bytes.Equal(got, data[1234:5000]) || cr.Offset() != 5000 {
+		t.Errorf("ranged response: %d bytes up to %d, %v", len(got), cr.Offset(), err)
+	}
+	resp.Body.Close()
+
+	// A download from a corrupt mirror stops before the bad chunk, and
+	// resumes from another.
+	var out bytes.Buffer
+	resp = get(t, mirror.URL, "")
+	cr, _ = l.VerifyResponse(resp, 0)
+	if _, err := io.Copy(&out, cr); !errors.Is(err, ErrMismatch) || cr.Offset() != 5000 {
+		t.Fatalf("corrupt mirror: offset %d, %v", cr.Offset(), err)
+	}
+	resp.Body.Close()
+	rng, _ = l.RangeHeader(cr.Offset(), l.Length-cr.Offset())
+	resp = get(t, good.URL, rng)
+	cr, err = l.VerifyResponse(resp, cr.Offset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(&out, cr); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("resumed download: %d bytes, %v", out.Len(), err)
+	}
+	resp.Body.Close()
+
+	// Ranges that cannot be verified are rejected.
+	for _, rng := range []string{"bytes=10-2999", "bytes=2000-2999"} {
+		resp = get(t, good.URL, rng)
+		if _, err := l.VerifyResponse(resp, 1500); !errors.Is(err, ErrResponse) {
+			t.Errorf("%s from offset 1500: %v, want ErrResponse", rng, err)
+		}
+		resp.Body.Close()
+	}
+	short, _ := New(bytes.NewReader(data[:9000]), 1000)
+	resp = get(t, good.URL, "bytes=0-999")
+	if _, err := short.VerifyResponse(resp, 0); !errors.Is(err, ErrResponse) {
+		t.Errorf("response for longer content: %v, want ErrResponse", err)
+	}
+	resp.Body.Close()
+	if _, err := l.VerifyResponse(&http.Response{StatusCode: 404, Status: "404 Not Found"}, 0); !errors.Is(err, ErrResponse) {
+		t.Errorf("404 response: %v, want ErrResponse", err)
+	}
+
+	// A body ending within a chunk is reported.
+	cr = l.NewChunkReader(bytes.NewReader(data[:2500]), 0)
+	if got, err := io.ReadAll(cr); err != io.ErrUnexpectedEOF || len(got) != 2000 {
+		t.Errorf("truncated body: %d bytes, %v", len(got), err)
+	}
+}
@@ -0,0 +1,88 @@
+package hashlist
+
+import (
+	"io"
+	"io/fs"
+	"runtime"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/merkle"
+)
+
+// NewParallel is like New, but hashes up to workers chunks at a time on
+// separate goroutines while reading the next ones, as P2P tools do to
+// build piece tables of large files. If workers is 0, GOMAXPROCS
+// goroutines are used. It panics if chunkSize is not positive.
+func NewParallel(r io.Reader, chunkSize int64, workers int) (*List, error) {
+	if chunkSize <= 0 {
+		panic("hashlist: chunk size must be positive")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	l := &List{ChunkSize: chunkSize}
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, workers)
+		digests []*[]byte
+	)
+	for {
+		buf := make([]byte, chunkSize)
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			wg.Wait()
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		l.Length += int64(n)
+		digest := new([]byte)
+		digests = append(digests, digest)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(chunk []byte) {
+			defer wg.Done()
+			h := blake2b.New(&blake2b.Config{Size: DigestSize})
+			h.Write(chunk)
+			*digest = h.Sum(nil)
+			<-sem
+		}(buf[:n])
+		if int64(n) < chunkSize {
+			break
+		}
+	}
+	wg.Wait()
+	for _, d := range digests {
+		l.Digests = append(l.Digests, *d)
+	}
+	return l, nil
+}
+
+// NewFiles returns the hash list of the files of fsys with the given
+// names, laid out one after the other as in a multi-file torrent, so that
+// chunks may span files. It hashes with NewParallel.
+func NewFiles(fsys fs.FS, names []string, chunkSize int64, workers int) (*List, error) {
+	readers := make([]io.Reader, len(names))
+	for i, name := range names {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		readers[i] = f
+	}
+	return NewParallel(io.MultiReader(readers...), chunkSize, workers)
+}
+
+// Root returns the pieces root: the root of the Merkle tree, as built by
+// the merkle package, whose leaves are the chunk digests. Unlike Top, it
+// allows proving a single chunk digest against the root.
+func (l *List) Root() []byte {
+	b := merkle.NewBuilder()
+	for _, d := range l.Digests {
+		b.Add(d)
+	}
+	return b.Root()
+}
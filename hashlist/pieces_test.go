@@ -0,0 +1,58 @@
+package hashlist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jadeydi/blake2/merkle"
+)
+
+func TestNewParallel(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, n := range []int{0, 999, 1000, 10000} {
+		want, err := New(bytes.NewReader(data[:n]), 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, workers := range []int{0, 1, 3} {
+			got, err := NewParallel(bytes.NewReader(data[:n]), 1000, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%d bytes, %d workers: hash list differs", n, workers)
+			}
+		}
+	}
+}
+
+func TestNewFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":     {Data: bytes.Repeat([]byte("a"), 1500)},
+		"dir/b.txt": {Data: bytes.Repeat([]byte("b"), 700)},
+	}
+	got, err := NewFiles(fsys, []string{"a.txt", "dir/b.txt"}, 1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := New(bytes.NewReader(append(bytes.Repeat([]byte("a"), 1500), bytes.Repeat([]byte("b"), 700)...)), 1024)
+	if !reflect.DeepEqual(got, want) {
+		t.Error("multi-file hash list differs from the concatenation")
+	}
+	if _, err := NewFiles(fsys, []string{"missing"}, 1024, 2); err == nil {
+		t.Error("missing file accepted")
+	}
+
+	b := merkle.NewBuilder()
+	for _, d := range got.Digests {
+		b.Add(d)
+	}
+	if !bytes.Equal(got.Root(), b.Root()) {
+		t.Error("Root differs from the Merkle root of the chunk digests")
+	}
+}
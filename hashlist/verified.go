@@ -0,0 +1,120 @@
+package hashlist
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Chunk verdicts of a VerifiedReader.
+const (
+	unchecked uint8 = iota
+	intact
+	corrupt
+)
+
+// VerifiedReader reads content from an io.ReaderAt, such as a file,
+// checking each chunk against the hash list the first time it is read,
+// for random-access consumers, such as zip readers and databases, that
+// should pay only for verifying the chunks they touch. Verdicts are
+// cached: later reads of an intact chunk read only the bytes requested,
+// and reads of a corrupt chunk fail with ErrMismatch without reading it
+// again. Caching trusts the source not to change after a chunk has been
+// verified.
+//
+// Its embedded io.SectionReader provides Read, Seek and Size, so that it
+// is also an io.ReadSeeker. ReadAt is safe for concurrent use; Read and
+// Seek are not.
+type VerifiedReader struct {
+	*io.SectionReader
+	l        *List
+	r        io.ReaderAt
+	mu       sync.Mutex
+	verdicts []uint8
+}
+
+// NewVerifiedReader returns a VerifiedReader of the content of r, which l
+// is the hash list of.
+func (l *List) NewVerifiedReader(r io.ReaderAt) *VerifiedReader {
+	v := &VerifiedReader{l: l, r: r, verdicts: make([]uint8, len(l.Digests))}
+	v.SectionReader = io.NewSectionReader(v, 0, l.Length)
+	return v
+}
+
+// ReadAt reads len(p) bytes at offset off of the content, after
+// verifying the chunks holding them. It returns an error wrapping
+// ErrMismatch, with the index of the chunk, if one of them is corrupt,
+// and io.EOF if the content ends before p is filled.
+func (v *VerifiedReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, ErrRange
+	}
+	if off >= v.l.Length {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < v.l.Length {
+		i := int(off / v.l.ChunkSize)
+		start := int64(i) * v.l.ChunkSize
+		end := start + v.l.chunkLen(i)
+		want := p[n:]
+		if int64(len(want)) > end-off {
+			want = want[:end-off]
+		}
+		k, err := v.readChunk(i, want, off-start)
+		n += k
+		off += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readChunk fills p with the bytes at offset off of chunk i, verifying
+// the chunk first unless it was already found intact.
+func (v *VerifiedReader) readChunk(i int, p []byte, off int64) (int, error) {
+	start := int64(i) * v.l.ChunkSize
+	v.mu.Lock()
+	verdict := v.verdicts[i]
+	v.mu.Unlock()
+	switch verdict {
+	case intact:
+		return readFull(v.r, p, start+off)
+	case corrupt:
+		return 0, fmt.Errorf("%w: chunk %d", ErrMismatch, i)
+	}
+
+	chunk := make([]byte, v.l.chunkLen(i))
+	if _, err := readFull(v.r, chunk, start); err != nil {
+		return 0, err
+	}
+	err := v.l.VerifyChunk(i, chunk)
+	verdict = intact
+	if err != nil {
+		verdict = corrupt
+	}
+	v.mu.Lock()
+	v.verdicts[i] = verdict
+	v.mu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("%w: chunk %d", err, i)
+	}
+	return copy(p, chunk[off:]), nil
+}
+
+// readFull reads len(p) bytes at off from r, with io.ErrUnexpectedEOF if
+// r ends first.
+func readFull(r io.ReaderAt, p []byte, off int64) (int, error) {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return n, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
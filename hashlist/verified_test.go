@@ -0,0 +1,97 @@
+package hashlist
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// countingReaderAt counts the bytes read from r.
+type countingReaderAt struct {
+	r  io.ReaderAt
+	mu sync.Mutex
+	n  int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.mu.Lock()
+	c.n += n
+	c.mu.Unlock()
+	return n, err
+}
+
+func TestVerifiedReader(t *testing.T) {
+	data := make([]byte, 10500)
+	for i := range data {
+		data[i] = byte(i * 11)
+	}
+	l, _ := New(bytes.NewReader(data), 1000)
+	bad := append([]byte(nil), data...)
+	bad[7200] ^= 1
+	src := &countingReaderAt{r: bytes.NewReader(bad)}
+	v := l.NewVerifiedReader(src)
+
+	// A read within a chunk verifies that chunk only, once.
+	p := make([]byte, 10)
+	if n, err := v.ReadAt(p, 2500); n != 10 || err != nil || !bytes.Equal(p, data[2500:2510]) {
+		t.Fatalf("ReadAt = %d, %v", n, err)
+	}
+	if src.n != 1000 {
+		t.Errorf("read %d bytes to verify one chunk", src.n)
+	}
+	v.ReadAt(p, 2000)
+	if src.n != 1010 {
+		t.Errorf("read %d bytes, want 1010 after reading a verified chunk", src.n)
+	}
+
+	// Reads across chunks, and up to the end.
+	p = make([]byte, 3000)
+	if n, err := v.ReadAt(p, 3500); n != 3000 || err != nil || !bytes.Equal(p, data[3500:6500]) {
+		t.Errorf("ReadAt across chunks = %d, %v", n, err)
+	}
+	if n, err := v.ReadAt(p, 9000); n != 1500 || err != io.EOF || !bytes.Equal(p[:n], data[9000:]) {
+		t.Errorf("ReadAt at the end = %d, %v", n, err)
+	}
+
+	// A corrupt chunk fails, also on later reads, which do not read it.
+	if n, err := v.ReadAt(p[:500], 6800); n != 200 || !errors.Is(err, ErrMismatch) {
+		t.Errorf("ReadAt of a corrupt chunk = %d, %v", n, err)
+	}
+	before := src.n
+	if _, err := v.ReadAt(p[:1], 7999); !errors.Is(err, ErrMismatch) || src.n != before {
+		t.Errorf("second ReadAt of a corrupt chunk: %v, read %d bytes", err, src.n-before)
+	}
+
+	// It is an io.ReadSeeker.
+	v.Seek(5000, io.SeekStart)
+	if got, err := io.ReadAll(v); !errors.Is(err, ErrMismatch) || !bytes.Equal(got, data[5000:7000]) {
+		t.Errorf("ReadAll from 5000: %d bytes, %v", len(got), err)
+	}
+	if v.Size() != l.Length {
+		t.Errorf("Size = %d", v.Size())
+	}
+	if _, err := v.ReadAt(p, -1); err != ErrRange {
+		t.Errorf("negative offset: %v", err)
+	}
+
+	// Concurrent readers of an intact source.
+	v = l.NewVerifiedReader(bytes.NewReader(data))
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			p := make([]byte, 777)
+			for off := int64(g * 100); off < l.Length; off += 777 {
+				n, err := v.ReadAt(p, off)
+				if (err != nil && err != io.EOF) || !bytes.Equal(p[:n], data[off:off+int64(n)]) {
+					t.Errorf("concurrent ReadAt at %d = %d, %v", off, n, err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,158 @@
+// Package hashservice provides an http.Handler that hashes request bodies
+// with BLAKE2, to run an internal hashing service.
+//
+// Bodies are sent with POST or PUT and streamed through the hash. The
+// hash is selected by query parameters or, equivalently, headers:
+//
+//	variant, Blake2-Variant  blake2b (the default) or blake2s
+//	size, Blake2-Size        digest size in bytes; the largest by default
+//	Blake2-Key               key for a MAC, in hexadecimal
+//
+// Keys are only accepted in a header, since URLs end up in logs. The
+// response is a JSON object:
+//
+//	{"variant":"BLAKE2b","size":32,"length":11,"digest":"<hex>"}
+package hashservice
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jadeydi/blake2"
+)
+
+// DefaultMaxBodySize is the body size limit of handlers whose Options do
+// not set one.
+const DefaultMaxBodySize = 1 << 30
+
+// Options configure a handler.
+type Options struct {
+	// MaxBodySize is the size limit of request bodies, in bytes; larger
+	// bodies get a 413 response. If 0, DefaultMaxBodySize.
+	MaxBodySize int64
+	// MaxConcurrent is the number of requests hashed at once; requests
+	// beyond it get a 503 response. If 0, there is no limit.
+	MaxConcurrent int
+}
+
+// Response is the body of successful responses.
+type Response struct {
+	// Variant is "BLAKE2b" or "BLAKE2s".
+	Variant string `json:"variant"`
+	// Size is the digest size, in bytes.
+	Size int `json:"size"`
+	// Length is the length of the request body.
+	Length int64 `json:"length"`
+	// Digest is the digest, in hexadecimal.
+	Digest string `json:"digest"`
+}
+
+type handler struct {
+	max int64
+	sem chan struct{}
+}
+
+// NewHandler returns a handler hashing request bodies as configured by o.
+func NewHandler(o Options) http.Handler {
+	h := &handler{max: o.MaxBodySize}
+	if h.max <= 0 {
+		h.max = DefaultMaxBodySize
+	}
+	if o.MaxConcurrent > 0 {
+		h.sem = make(chan struct{}, o.MaxConcurrent)
+	}
+	return h
+}
+
+// param returns the request parameter name, from the query or from the
+// header.
+func param(r *http.Request, name, header string) string {
+	if v := r.URL.Query().Get(name); v != "" {
+		return v
+	}
+	return r.Header.Get(header)
+}
+
+// newHasher returns the hash selected by the parameters of r.
+func newHasher(r *http.Request) (blake2.Hasher, blake2.Variant, error) {
+	variant := blake2.BLAKE2b
+	switch v := strings.ToLower(param(r, "variant", "Blake2-Variant")); v {
+	case "", "blake2b":
+	case "blake2s":
+		variant = blake2.BLAKE2s
+	default:
+		return nil, 0, fmt.Errorf("unknown variant %q", v)
+	}
+	size := variant.MaxSize()
+	if v := param(r, "size", "Blake2-Size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid size %q", v)
+		}
+		size = n
+	}
+	if r.URL.Query().Get("key") != "" {
+		return nil, 0, errors.New("keys must be sent in the Blake2-Key header")
+	}
+	key, err := hex.DecodeString(r.Header.Get("Blake2-Key"))
+	if err != nil {
+		return nil, 0, errors.New("invalid key encoding")
+	}
+	h, err := variant.NewSize(size, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return h, variant, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.ContentLength > h.max {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	hasher, variant, err := newHasher(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+			defer func() { <-h.sem }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many concurrent requests", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	n, err := io.Copy(hasher, io.LimitReader(r.Body, h.max+1))
+	switch {
+	case err != nil:
+		http.Error(w, "reading request body: "+err.Error(), http.StatusBadRequest)
+		return
+	case n > h.max:
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Variant: variant.String(),
+		Size:    hasher.Size(),
+		Length:  n,
+		Digest:  hex.EncodeToString(hasher.Sum(nil)),
+	})
+}
@@ -0,0 +1,125 @@
+package hashservice
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2"
+)
+
+func post(h http.Handler, target, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(Options{})
+	key := []byte("service key")
+	for _, c := range []struct {
+		target  string
+		header  http.Header
+		variant blake2.Variant
+		size    int
+		key     []byte
+	}{
+		{"/", nil, blake2.BLAKE2b, 64, nil},
+		{"/?variant=blake2s&size=16", nil, blake2.BLAKE2s, 16, nil},
+		{"/", http.Header{"Blake2-Variant": {"blake2s"}, "Blake2-Size": {"20"}}, blake2.BLAKE2s, 20, nil},
+		{"/?size=32", http.Header{"Blake2-Key": {hex.EncodeToString(key)}}, blake2.BLAKE2b, 32, key},
+	} {
+		w := post(h, c.target, "hello world", c.header)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", c.target, w.Code, w.Body)
+		}
+		var resp Response
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := c.variant.NewSize(c.size, c.key)
+		want.Write([]byte("hello world"))
+		if resp.Variant != c.variant.String() || resp.Size != c.size || resp.Length != 11 ||
+			resp.Digest != hex.EncodeToString(want.Sum(nil)) {
+			t.Errorf("%s: response %+v", c.target, resp)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := NewHandler(Options{MaxBodySize: 10})
+	for _, c := range []struct {
+		target string
+		body   string
+		header http.Header
+		code   int
+	}{
+		{"/?variant=md5", "", nil, http.StatusBadRequest},
+		{"/?variant=blake2s&size=33", "", nil, http.StatusBadRequest},
+		{"/?size=x", "", nil, http.StatusBadRequest},
+		{"/?key=00", "", nil, http.StatusBadRequest},
+		{"/", "", http.Header{"Blake2-Key": {"zz"}}, http.StatusBadRequest},
+		{"/", "more than ten bytes", nil, http.StatusRequestEntityTooLarge},
+	} {
+		if w := post(h, c.target, c.body, c.header); w.Code != c.code {
+			t.Errorf("%s: status %d, want %d", c.target, w.Code, c.code)
+		}
+	}
+
+	// A body of unknown length is cut at the limit too.
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("more than ten bytes"))
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("unknown length: status %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status %d", w.Code)
+	}
+}
+
+// blockingReader blocks reads until release is closed.
+type blockingReader struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	close(b.started)
+	<-b.release
+	return 0, io.EOF
+}
+
+func TestHandlerConcurrency(t *testing.T) {
+	h := NewHandler(Options{MaxConcurrent: 1})
+	body := &blockingReader{started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", body))
+		done <- w.Code
+	}()
+	<-body.started
+	if w := post(h, "/", "x", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("second request: status %d, want 503", w.Code)
+	}
+	close(body.release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request: status %d", code)
+	}
+	if w := post(h, "/", "x", nil); w.Code != http.StatusOK {
+		t.Errorf("request after the first: status %d", w.Code)
+	}
+}
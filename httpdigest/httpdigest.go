@@ -0,0 +1,270 @@
+// Package httpdigest provides HTTP middleware that computes BLAKE2
+// digests of message bodies and carries them in Digest and ETag headers.
+//
+// Digest headers follow RFC 3230: a comma-separated list of
+// algorithm=base64(digest) pairs, of which the BLAKE2 entry is checked and
+// any others are ignored.
+package httpdigest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// ErrDigestMismatch is returned when reading a body whose digest does not
+// match the value announced in its Digest header.
+var ErrDigestMismatch = errors.New("httpdigest: digest mismatch")
+
+// Algorithm is a named hash function usable in Digest headers.
+type Algorithm struct {
+	// Name is the algorithm token used in the Digest header.
+	Name string
+	// New returns a new hash computing the digest.
+	New func() hash.Hash
+}
+
+var (
+	// BLAKE2b512 is unkeyed BLAKE2b with a 64-byte digest.
+	BLAKE2b512 = Algorithm{Name: "blake2b-512", New: blake2b.NewBlake2B}
+	// BLAKE2s256 is unkeyed BLAKE2s with a 32-byte digest.
+	BLAKE2s256 = Algorithm{Name: "blake2s-256", New: func() hash.Hash { return blake2s.New(nil) }}
+)
+
+// header formats digest as a Digest header value.
+func (a Algorithm) header(digest []byte) string {
+	return a.Name + "=" + base64.StdEncoding.EncodeToString(digest)
+}
+
+// lookup returns the digest announced for a in the Digest header value v.
+func (a Algorithm) lookup(v string) ([]byte, bool) {
+	for _, part := range strings.Split(v, ",") {
+		i := strings.IndexByte(part, '=')
+		if i < 0 || !strings.EqualFold(strings.TrimSpace(part[:i]), a.Name) {
+			continue
+		}
+		d, err := base64.StdEncoding.DecodeString(strings.TrimSpace(part[i+1:]))
+		if err != nil {
+			return nil, false
+		}
+		return d, true
+	}
+	return nil, false
+}
+
+// etag formats digest as a strong entity tag.
+func etag(digest []byte) string {
+	return strconv.Quote(hex.EncodeToString(digest))
+}
+
+// verifyingBody hashes a body as it is read and fails with
+// ErrDigestMismatch at EOF if the digest differs from want.
+type verifyingBody struct {
+	rc   io.ReadCloser
+	h    hash.Hash
+	want []byte
+}
+
+func (b *verifyingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.h.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(b.h.Sum(nil), b.want) {
+		err = ErrDigestMismatch
+	}
+	return n, err
+}
+
+func (b *verifyingBody) Close() error {
+	return b.rc.Close()
+}
+
+// verify wraps body so that reading it checks the digest announced in
+// the header value v, if any.
+func (a Algorithm) verify(body io.ReadCloser, v string) io.ReadCloser {
+	if body == nil || body == http.NoBody {
+		return body
+	}
+	want, ok := a.lookup(v)
+	if !ok {
+		return body
+	}
+	return &verifyingBody{rc: body, h: a.New(), want: want}
+}
+
+// Handler returns a handler that verifies the Digest header of incoming
+// request bodies while h reads them, and that sets the Digest and ETag
+// headers of the responses written by h. Bodies of requests announcing a
+// wrong digest fail to read with ErrDigestMismatch.
+//
+// Because headers must precede the body, responses are buffered in full
+// before being sent. A GET or HEAD request whose If-None-Match header
+// matches the ETag, as RFC 7232 defines, is answered with 304 Not Modified.
+// Responses to HEAD requests, and 304 responses of h, have no body to
+// hash: they keep the Digest, ETag and Content-Length headers h set, if
+// any, and get none otherwise.
+//
+// The http.ResponseWriter passed to h forwards Hijack to w, for protocol
+// upgrades. It does not implement http.Flusher, since nothing is sent
+// before h returns.
+func Handler(h http.Handler, alg Algorithm) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = alg.verify(r.Body, r.Header.Get("Digest"))
+
+		bw := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(bw, r)
+		if bw.hijacked {
+			return
+		}
+
+		hdr := w.Header()
+		hashed := r.Method != http.MethodHead && bw.status != http.StatusNotModified
+		if hashed {
+			d := alg.New()
+			d.Write(bw.buf.Bytes())
+			sum := d.Sum(nil)
+			hdr.Set("Digest", alg.header(sum))
+			if hdr.Get("ETag") == "" {
+				hdr.Set("ETag", etag(sum))
+			}
+		}
+		if bw.status == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+			hdr.Get("ETag") != "" && noneMatch(r.Header.Values("If-None-Match"), hdr.Get("ETag")) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if hashed {
+			hdr.Set("Content-Length", strconv.Itoa(bw.buf.Len()))
+		}
+		w.WriteHeader(bw.status)
+		w.Write(bw.buf.Bytes())
+	})
+}
+
+// noneMatch reports whether the If-None-Match header values match the
+// entity tag etag, as RFC 7232, section 3.2, defines: "*" matches any
+// tag, and the tags of the comma-separated lists are compared weakly,
+// ignoring their W/ prefix.
+func noneMatch(values []string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range values {
+		for {
+			v = strings.TrimLeft(v, " \t,")
+			if v == "" {
+				break
+			}
+			if v[0] == '*' {
+				return true
+			}
+			tag, rest, ok := scanETag(v)
+			if !ok {
+				// Ignore the rest of a malformed list.
+				break
+			}
+			if strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+			v = rest
+		}
+	}
+	return false
+}
+
+// scanETag splits the entity tag, weak or strong, at the start of s from
+// the rest of s. Entity tags are quoted, and may hold commas.
+func scanETag(s string) (etag, rest string, ok bool) {
+	start := s
+	s = strings.TrimPrefix(s, "W/")
+	if len(s) < 2 || s[0] != '"' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s[1:], '"')
+	if end < 0 {
+		return "", "", false
+	}
+	n := len(start) - len(s) + end + 2
+	return start[:n], start[n:], true
+}
+
+// bufferedWriter holds back a response until the handler returns.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status   int
+	buf      bytes.Buffer
+	hijacked bool
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Hijack hijacks the underlying connection, if w allows it. The response
+// buffered so far is discarded.
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httpdigest: connection cannot be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Transport is an http.RoundTripper that sets the Digest header of
+// outgoing request bodies and verifies the Digest header of response
+// bodies as they are read.
+type Transport struct {
+	// Algorithm is the hash function used for the Digest header.
+	Algorithm Algorithm
+	// Base is the underlying transport. If nil, http.DefaultTransport
+	// is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper. Request bodies are read in full
+// to compute their digest before the request is sent.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		d := t.Algorithm.New()
+		d.Write(body)
+
+		// RoundTrip must not modify the caller's request.
+		req = req.Clone(req.Context())
+		req.Header.Set("Digest", t.Algorithm.header(d.Sum(nil)))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = t.Algorithm.verify(resp.Body, resp.Header.Get("Digest"))
+	return resp, nil
+}
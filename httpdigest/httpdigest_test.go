@@ -0,0 +1,162 @@
+package httpdigest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Digest") == "" {
+			t.Error("request has no Digest header")
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write(body)
+	}), BLAKE2b512))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Algorithm: BLAKE2b512}}
+	resp, err := c.Post(srv.URL, "text/plain", strings.NewReader("one two three"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "one two three" {
+		t.Errorf("got body %q", body)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Digest"), "blake2b-512=") || resp.Header.Get("ETag") == "" {
+		t.Errorf("missing digest headers: %v", resp.Header)
+	}
+}
+
+func TestHandlerNotModified(t *testing.T) {
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}), BLAKE2s256)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	tag := rec.Header().Get("ETag")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want 304", rec.Code)
+	}
+}
+
+func TestHandlerWithoutBody(t *testing.T) {
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}), BLAKE2s256)
+	for _, c := range []struct{ method, path string }{{"HEAD", "/"}, {"GET", "/cached"}} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+		for _, name := range []string{"Digest", "ETag", "Content-Length"} {
+			if v := rec.Header().Get(name); v != "" {
+				t.Errorf("%s %s: %s header %q set for an empty body", c.method, c.path, name, v)
+			}
+		}
+	}
+
+	// Headers set by the handler are kept.
+	h = Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Length", "5")
+	}), BLAKE2s256)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("HEAD", "/", nil))
+	if rec.Header().Get("ETag") != `"v1"` || rec.Header().Get("Content-Length") != "5" {
+		t.Errorf("HEAD headers %v", rec.Header())
+	}
+	req := httptest.NewRequest("HEAD", "/", nil)
+	req.Header.Set("If-None-Match", `W/"v1"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("HEAD with a matching tag: got status %d, want 304", rec.Code)
+	}
+}
+
+func TestHandlerHijack(t *testing.T) {
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "discarded")
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		rw.Flush()
+	}), BLAKE2s256))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hijacked" || resp.Header.Get("Digest") != "" {
+		t.Errorf("got body %q, headers %v", body, resp.Header)
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	const etag = `"abc"`
+	for _, c := range []struct {
+		values []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{`"abc"`}, true},
+		{[]string{`W/"abc"`}, true},
+		{[]string{"*"}, true},
+		{[]string{`"xyz", "abc"`}, true},
+		{[]string{`"xyz"`, `W/"abc"`}, true},
+		{[]string{`"a,b", "abc"`}, true},
+		{[]string{`"xyz", W/"ab"`}, false},
+		{[]string{`abc`}, false},
+		{[]string{`"abc`}, false},
+	} {
+		if got := noneMatch(c.values, etag); got != c.want {
+			t.Errorf("noneMatch(%q, %s) = %t, want %t", c.values, etag, got, c.want)
+		}
+	}
+	if !noneMatch([]string{`"abc"`}, `W/"abc"`) {
+		t.Error("weak entity tag not matched")
+	}
+}
+
+func TestTransportMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Digest", "blake2s-256=AAAA, sha-256=BBBB")
+		io.WriteString(w, "tampered")
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Algorithm: BLAKE2s256}}
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err != ErrDigestMismatch {
+		t.Errorf("got error %v, want ErrDigestMismatch", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package wire decodes the fields shared by the binary encodings of this
+// module strictly, so that every value has a single encoding, and that
+// untrusted input, such as a hash list fetched from a mirror, is rejected
+// rather than crash or confuse its parser.
+package wire
+
+import "encoding/binary"
+
+// Header splits b after magic, the name of a format followed by its
+// version byte, as in "B2HL\x01". It returns the version of the encoding
+// in b, which may differ from the one of magic, and false if b does not
+// start with the name.
+func Header(b []byte, magic string) (rest []byte, version byte, ok bool) {
+	name := magic[:len(magic)-1]
+	if len(b) < len(magic) || string(b[:len(name)]) != name {
+		return nil, 0, false
+	}
+	return b[len(magic):], b[len(name)], true
+}
+
+// Uvarint decodes the unsigned varint at the start of b, as
+// binary.PutUvarint encodes it, and returns it with the rest of b. It
+// returns false if b is truncated, if the value overflows 64 bits, or if
+// it is not in its shortest encoding.
+func Uvarint(b []byte) (v uint64, rest []byte, ok bool) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, false
+	}
+	var buf [binary.MaxVarintLen64]byte
+	if binary.PutUvarint(buf[:], v) != n {
+		return 0, nil, false
+	}
+	return v, b[n:], true
+}
+
+// Count returns the number of records of size bytes in b, and false if b
+// is not a whole number of them or does not hold want of them. Unlike
+// comparing len(b) with want*size, it cannot overflow.
+func Count(b []byte, size int, want uint64) bool {
+	return len(b)%size == 0 && uint64(len(b)/size) == want
+}
@@ -0,0 +1,43 @@
+package wire
+
+import "testing"
+
+func TestHeader(t *testing.T) {
+	rest, v, ok := Header([]byte("B2HL\x02data"), "B2HL\x01")
+	if !ok || v != 2 || string(rest) != "data" {
+		t.Errorf("Header = %q, %d, %v", rest, v, ok)
+	}
+	for _, b := range []string{"", "B2HL", "B2XX\x01"} {
+		if _, _, ok := Header([]byte(b), "B2HL\x01"); ok {
+			t.Errorf("Header(%q) accepted", b)
+		}
+	}
+}
+
+func TestUvarint(t *testing.T) {
+	v, rest, ok := Uvarint([]byte{0xac, 0x02, 9})
+	if !ok || v != 300 || len(rest) != 1 {
+		t.Errorf("Uvarint = %d, %x, %v", v, rest, ok)
+	}
+	for _, b := range [][]byte{
+		nil,
+		{0x80},
+		{0x81, 0x00}, // 1, not minimal
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02}, // overflow
+	} {
+		if _, _, ok := Uvarint(b); ok {
+			t.Errorf("Uvarint(%x) accepted", b)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	b := make([]byte, 64)
+	if !Count(b, 32, 2) || Count(b, 32, 1) || Count(b[:63], 32, 2) {
+		t.Error("Count miscounts")
+	}
+	// 2^59 records of 32 bytes overflow 64 bits to 0.
+	if Count(nil, 32, 1<<59) {
+		t.Error("Count overflows")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package journal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzRecover(f *testing.F) {
+	f.Add([]byte("data"), []byte{})
+	f.Fuzz(func(t *testing.T, data, journal []byte) {
+		r, err := Recover(bytes.NewReader(data), bytes.NewReader(journal))
+		if err == nil && (r.DataEnd > int64(len(data)) || r.JournalEnd > int64(len(journal))) {
+			t.Errorf("recovery past the end of the files: %+v", r)
+		}
+	})
+}
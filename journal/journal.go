@@ -0,0 +1,212 @@
+// Package journal keeps an append-only checksum journal of the data
+// written to a companion file, for storage engines that need to know,
+// after a crash, which of their data is intact.
+//
+// Each Write appends the data to the data file and an entry
+//
+//	offset || length || digest
+//
+// to the journal file, with the offset and length of the data as 8-byte
+// little-endian integers and its 32-byte BLAKE2b digest, encoded as a
+// checksummed record of the frame package. The data file is synced
+// before the entry is written, and the journal file after, so that an
+// entry never describes data that did not reach the disk: after a crash,
+// Recover finds the entries that match the data, and Open truncates both
+// files to them.
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/frame"
+)
+
+const (
+	// digestSize is the length of entry digests.
+	digestSize = 32
+	// entrySize is the length of encoded entries.
+	entrySize = 8 + 8 + digestSize
+)
+
+var (
+	// ErrCorrupt is returned by Recover for journals with corrupt
+	// entries, or entries that do not follow each other in the data
+	// file.
+	ErrCorrupt = errors.New("journal: corrupt journal")
+	// ErrMismatch is returned by Recover for data that does not match
+	// its entry, other than at the end of the data file.
+	ErrMismatch = errors.New("journal: data does not match its entry")
+)
+
+// Entry describes data written with Writer.Write.
+type Entry struct {
+	// Offset and Length locate the data in the data file.
+	Offset, Length int64
+	// Digest is the BLAKE2b-256 digest of the data.
+	Digest [digestSize]byte
+}
+
+func (e Entry) encode() []byte {
+	b := make([]byte, entrySize)
+	binary.LittleEndian.PutUint64(b, uint64(e.Offset))
+	binary.LittleEndian.PutUint64(b[8:], uint64(e.Length))
+	copy(b[16:], e.Digest[:])
+	return b
+}
+
+// Recovery is the result of Recover.
+type Recovery struct {
+	// Entries are the entries whose data is intact, in order.
+	Entries []Entry
+	// DataEnd is the end of the data of the last entry, to which the
+	// data file is truncated after a crash.
+	DataEnd int64
+	// JournalEnd is the end of the last entry in the journal file, to
+	// which it is truncated after a crash.
+	JournalEnd int64
+}
+
+// Recover reads the entries of journal and checks the data they describe
+// in data. A torn entry at the end of the journal, and a last entry whose
+// data is missing or does not match, are what a crash leaves and are
+// left out of the result; other damage is reported with an error
+// wrapping ErrCorrupt or ErrMismatch.
+func Recover(data io.ReaderAt, journal io.Reader) (*Recovery, error) {
+	dec, err := frame.NewDecoder(journal, &frame.Options{MaxSize: entrySize})
+	if err != nil {
+		return nil, err
+	}
+	r := new(Recovery)
+	var pending *Entry
+	for {
+		b, err := dec.Decode()
+		if err == io.EOF || errors.Is(err, frame.ErrTorn) {
+			break
+		}
+		if err != nil || len(b) != entrySize {
+			return nil, fmt.Errorf("%w: entry %d: %v", ErrCorrupt, len(r.Entries), err)
+		}
+		if pending != nil {
+			return nil, fmt.Errorf("%w: entry %d", ErrMismatch, len(r.Entries))
+		}
+		e := Entry{
+			Offset: int64(binary.LittleEndian.Uint64(b)),
+			Length: int64(binary.LittleEndian.Uint64(b[8:])),
+		}
+		copy(e.Digest[:], b[16:])
+		if e.Offset != r.DataEnd || e.Length < 0 {
+			return nil, fmt.Errorf("%w: entry %d does not follow the previous one", ErrCorrupt, len(r.Entries))
+		}
+		if !intact(data, e) {
+			pending = &e
+			continue
+		}
+		r.Entries = append(r.Entries, e)
+		r.DataEnd = e.Offset + e.Length
+		r.JournalEnd = dec.Offset()
+	}
+	return r, nil
+}
+
+// intact reports whether data holds the data of e.
+func intact(data io.ReaderAt, e Entry) bool {
+	h := blake2b.New(&blake2b.Config{Size: digestSize})
+	n, err := io.Copy(h, io.NewSectionReader(data, e.Offset, e.Length))
+	return err == nil && n == e.Length && bytes.Equal(h.Sum(nil), e.Digest[:])
+}
+
+// Writer appends data and journal entries to a pair of files.
+type Writer struct {
+	data, journal *os.File
+	enc           *frame.Encoder
+	off           int64
+}
+
+// Open opens, or creates, the data and journal files named data and
+// journal, recovers them as Recover does, truncates both to the intact
+// entries, and returns a Writer appending to them with the recovery. It
+// fails if Recover does.
+func Open(data, journal string) (*Writer, *Recovery, error) {
+	df, err := os.OpenFile(data, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	jf, err := os.OpenFile(journal, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		df.Close()
+		return nil, nil, err
+	}
+	w, r, err := open(df, jf)
+	if err != nil {
+		df.Close()
+		jf.Close()
+		return nil, nil, err
+	}
+	return w, r, nil
+}
+
+func open(df, jf *os.File) (*Writer, *Recovery, error) {
+	r, err := Recover(df, jf)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range []struct {
+		f   *os.File
+		end int64
+	}{{jf, r.JournalEnd}, {df, r.DataEnd}} {
+		if err := t.f.Truncate(t.end); err != nil {
+			return nil, nil, err
+		}
+		if _, err := t.f.Seek(t.end, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+	}
+	enc, err := frame.NewEncoder(jf, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Writer{data: df, journal: jf, enc: enc, off: r.DataEnd}, r, nil
+}
+
+// Write appends p to the data file and its entry to the journal, syncing
+// the data before writing the entry and the journal after, and returns
+// the entry. Each call syncs both files, so callers batch small writes.
+// After an error, the files are left as a crash would leave them, and
+// the Writer must be closed and the files opened again.
+func (w *Writer) Write(p []byte) (Entry, error) {
+	e := Entry{Offset: w.off, Length: int64(len(p)), Digest: blake2b.Sum256(p)}
+	if _, err := w.data.Write(p); err != nil {
+		return Entry{}, err
+	}
+	if err := w.data.Sync(); err != nil {
+		return Entry{}, err
+	}
+	if err := w.enc.Encode(e.encode()); err != nil {
+		return Entry{}, err
+	}
+	if err := w.journal.Sync(); err != nil {
+		return Entry{}, err
+	}
+	w.off += e.Length
+	return e, nil
+}
+
+// Offset returns the length of the data written.
+func (w *Writer) Offset() int64 {
+	return w.off
+}
+
+// Close closes both files.
+func (w *Writer) Close() error {
+	err := w.data.Close()
+	if jerr := w.journal.Close(); err == nil {
+		err = jerr
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	data, journal := filepath.Join(dir, "data"), filepath.Join(dir, "journal")
+	w, r, err := Open(data, journal)
+	if err != nil || len(r.Entries) != 0 || r.DataEnd != 0 {
+		t.Fatalf("Open of new files = %+v, %v", r, err)
+	}
+	records := [][]byte{[]byte("first"), nil, []byte("third record")}
+	for _, p := range records {
+		e, err := w.Write(p)
+		if err != nil || e.Length != int64(len(p)) || e.Digest != blake2b.Sum256(p) {
+			t.Fatalf("Write = %+v, %v", e, err)
+		}
+	}
+	if w.Offset() != 17 {
+		t.Errorf("Offset = %d", w.Offset())
+	}
+	w.Close()
+
+	// A crash after the data is synced, before the entry is written,
+	// and a torn entry.
+	f, _ := os.OpenFile(data, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte("lost"))
+	f.Close()
+	jb, _ := os.ReadFile(journal)
+	intactLen := int64(len(jb))
+	os.WriteFile(journal, append(jb, jb[:30]...), 0644)
+
+	w, r, err = Open(data, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Entries) != 3 || r.DataEnd != 17 || r.JournalEnd != intactLen || r.Entries[2].Offset != 5 {
+		t.Errorf("recovery %+v", r)
+	}
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if b, _ := os.ReadFile(data); string(b) != "firstthird recordafter" {
+		t.Errorf("data file %q", b)
+	}
+	df, _ := os.Open(data)
+	jf, _ := os.Open(journal)
+	r, err = Recover(df, jf)
+	df.Close()
+	jf.Close()
+	if err != nil || len(r.Entries) != 4 || r.DataEnd != 22 {
+		t.Errorf("Recover = %+v, %v", r, err)
+	}
+}
+
+func TestRecoverDamage(t *testing.T) {
+	dir := t.TempDir()
+	data, journal := filepath.Join(dir, "data"), filepath.Join(dir, "journal")
+	w, _, _ := Open(data, journal)
+	w.Write([]byte("alpha"))
+	w.Write([]byte("bravo"))
+	w.Close()
+	db, _ := os.ReadFile(data)
+	jb, _ := os.ReadFile(journal)
+
+	recover := func(db, jb []byte) (*Recovery, error) {
+		return Recover(bytes.NewReader(db), bytes.NewReader(jb))
+	}
+	// Damaged data of the last entry is rolled back, of others reported.
+	bad := append([]byte(nil), db...)
+	bad[7] ^= 1
+	if r, err := recover(bad, jb); err != nil || len(r.Entries) != 1 || r.DataEnd != 5 {
+		t.Errorf("damaged last entry: %+v, %v", r, err)
+	}
+	bad = append([]byte(nil), db...)
+	bad[1] ^= 1
+	if _, err := recover(bad, jb); !errors.Is(err, ErrMismatch) {
+		t.Errorf("damaged first entry: %v, want ErrMismatch", err)
+	}
+	bad = append([]byte(nil), jb...)
+	bad[25] ^= 1
+	if _, err := recover(db, bad); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("damaged journal: %v, want ErrCorrupt", err)
+	}
+	if _, _, err := Open(data, filepath.Join(dir, "missing", "journal")); err == nil {
+		t.Error("Open succeeded without a journal directory")
+	}
+}
@@ -0,0 +1,222 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrDuplicateKey is returned by CanonicalJSON and SumJSON for documents
+// with an object holding the same key twice, which RFC 8785 rules out.
+var ErrDuplicateKey = errors.New("blake2: duplicate JSON object key")
+
+// SumJSON returns the digest of the canonical JSON encoding of v, as
+// returned by CanonicalJSON, with a hash of the variant given by
+// cfg.Variant configured by cfg, which may be nil. Services hashing the
+// same document thus agree on its digest whatever the key order,
+// whitespace and number formatting of their encoders.
+func SumJSON(v interface{}, cfg *Config) ([]byte, error) {
+	b, err := CanonicalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	variant := BLAKE2b
+	if cfg != nil && cfg.Variant != 0 {
+		variant = cfg.Variant
+	}
+	h, err := NewHasher(variant, cfg)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(b)
+	return h.Sum(nil), nil
+}
+
+// CanonicalJSON returns the JSON Canonicalization Scheme (RFC 8785)
+// encoding of v: v is encoded with encoding/json, then re-encoded
+// without whitespace, with object keys sorted by their UTF-16 code
+// units, numbers in their shortest ECMAScript form and strings with
+// minimal escaping. Pass a json.RawMessage to canonicalize a JSON text.
+// Documents with duplicate object keys, which are not I-JSON (RFC 7493)
+// as RFC 8785 requires, fail with ErrDuplicateKey rather than collapse
+// into one of their values.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	doc, err := decodeJSON(d)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes the next value of d as Decode does into an empty
+// interface, but token by token, so as to reject duplicate object keys.
+func decodeJSON(d *json.Decoder) (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		a := []interface{}{}
+		for d.More() {
+			v, err := decodeJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err = d.Token() // ]
+		return a, err
+	case json.Delim('{'):
+		m := make(map[string]interface{})
+		for d.More() {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			k := tok.(string)
+			if _, dup := m[k]; dup {
+				return nil, ErrDuplicateKey
+			}
+			if m[k], err = decodeJSON(d); err != nil {
+				return nil, err
+			}
+		}
+		_, err = d.Token() // }
+		return m, err
+	}
+	return tok, nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return err
+		}
+		s, err := formatNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return errors.New("blake2: unexpected JSON value")
+	}
+	return nil
+}
+
+// formatNumber formats f as ECMAScript's Number.prototype.toString does.
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("blake2: JSON number out of range")
+	}
+	if f == 0 {
+		return "0", nil // also for -0
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	// Go writes at least two exponent digits, ECMAScript as few as
+	// needed.
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(s, 'e')
+	mantissa, sign, exp := s[:i], s[i+1], strings.TrimLeft(s[i+2:], "0")
+	return mantissa + "e" + string(sign) + exp, nil
+}
+
+// writeString writes s as a JSON string, escaping only what RFC 8785
+// requires.
+func writeString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares a and b by their UTF-16 code units, as RFC 8785
+// sorts object keys.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,102 @@
+package blake2
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// TestCanonicalJSON checks the example of RFC 8785, section 3.2.
+func TestCanonicalJSON(t *testing.T) {
+	in := json.RawMessage(`{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`)
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	got, err := CanonicalJSON(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+// TestFormatNumber checks the number samples of RFC 8785, appendix B.
+func TestFormatNumber(t *testing.T) {
+	for _, c := range []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	} {
+		got, err := formatNumber(math.Float64frombits(c.bits))
+		if err != nil || got != c.want {
+			t.Errorf("%016x: got %q, %v, want %q", c.bits, got, err, c.want)
+		}
+	}
+}
+
+func TestSumJSON(t *testing.T) {
+	a, err := SumJSON(json.RawMessage(`{"b": 1.0, "a": [true, "x"]}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SumJSON(map[string]interface{}{"a": []interface{}{true, "x"}, "b": 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(a, b) {
+		t.Error("equivalent documents have different digests")
+	}
+	want := blake2b.New(nil)
+	want.Write([]byte(`{"a":[true,"x"],"b":1}`))
+	if !Equal(a, want.Sum(nil)) {
+		t.Error("digest is not that of the canonical encoding")
+	}
+	if s, _ := SumJSON("x", &Config{Variant: BLAKE2s}); len(s) != 32 {
+		t.Errorf("BLAKE2s digest of %d bytes", len(s))
+	}
+	if _, err := SumJSON(make(chan int), nil); err == nil {
+		t.Error("unencodable value accepted")
+	}
+	for _, doc := range []string{`{"a": 1, "a": 2}`, `[{"b": {"a": 1, "\u0061": 1}}]`} {
+		if _, err := SumJSON(json.RawMessage(doc), nil); err != ErrDuplicateKey {
+			t.Errorf("%s: got %v, want ErrDuplicateKey", doc, err)
+		}
+	}
+}
+
+func TestLessUTF16(t *testing.T) {
+	// U+FB33 sorts after U+1F600, a surrogate pair, in UTF-16 order
+	// but before it in code point order.
+	if !lessUTF16("\U0001F600", "דּ") {
+		t.Error("keys not sorted by UTF-16 code units")
+	}
+}
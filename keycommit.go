@@ -0,0 +1,88 @@
+package blake2
+
+import (
+	"errors"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// CommittingTagSize is the length of key-committing tags.
+const CommittingTagSize = 64
+
+// ErrCommittingKey is returned for committing MAC keys that are empty or
+// longer than blake2b.MaxKeySize.
+var ErrCommittingKey = errors.New("blake2: committing MAC key must be 1 to 64 bytes")
+
+var (
+	// keyCommitPersonal separates key commitments from other uses of
+	// BLAKE2b.
+	keyCommitPersonal = []byte("blake2 keycommit")
+	// committedPersonal separates the MACs of committing tags from plain
+	// keyed BLAKE2b.
+	committedPersonal = []byte("blake2 committed")
+)
+
+// committingMAC is the Hasher of NewCommittingMAC.
+type committingMAC struct {
+	mac        cloner
+	commitment [32]byte
+}
+
+// NewCommittingMAC returns a MAC whose tags commit to the key, for
+// designs, such as multi-recipient encryption or key rotation, where a
+// tag must not verify under two different keys. Plain MACs only promise
+// that a tag cannot be forged without the key; whoever knows several
+// keys may find a message with the same tag under all of them.
+//
+// A tag is the 32-byte BLAKE2b hash of the key followed by the 32-byte
+// keyed BLAKE2b of the message, each personalized for this purpose, so
+// that a tag valid under two keys requires a BLAKE2b collision. The hash
+// of the key reveals nothing useful about a random key, but allows
+// offline guessing of low-entropy ones, as any MAC tag does.
+func NewCommittingMAC(key []byte) (Hasher, error) {
+	if len(key) == 0 || len(key) > blake2b.MaxKeySize {
+		return nil, ErrCommittingKey
+	}
+	m := &committingMAC{mac: blake2b.New(&blake2b.Config{Size: 32, Key: key, Personal: committedPersonal})}
+	h := blake2b.New(&blake2b.Config{Size: 32, Personal: keyCommitPersonal})
+	h.Write(key)
+	h.Sum(m.commitment[:0])
+	return m, nil
+}
+
+func (m *committingMAC) Write(p []byte) (int, error) {
+	return m.mac.Write(p)
+}
+
+func (m *committingMAC) Sum(b []byte) []byte {
+	return m.mac.Sum(append(b, m.commitment[:]...))
+}
+
+func (m *committingMAC) Reset()         { m.mac.Reset() }
+func (m *committingMAC) Size() int      { return CommittingTagSize }
+func (m *committingMAC) BlockSize() int { return m.mac.BlockSize() }
+
+func (m *committingMAC) Clone() Hasher {
+	return &committingMAC{mac: m.mac.Clone().(cloner), commitment: m.commitment}
+}
+
+// SignCommitting returns the key-committing tag of message under key, as
+// NewCommittingMAC computes it.
+func SignCommitting(key, message []byte) ([]byte, error) {
+	m, err := NewCommittingMAC(key)
+	if err != nil {
+		return nil, err
+	}
+	m.Write(message)
+	return m.Sum(nil), nil
+}
+
+// VerifyCommitting reports whether tag is the key-committing tag of
+// message under key, comparing in constant time.
+func VerifyCommitting(key, message, tag []byte) bool {
+	want, err := SignCommitting(key, message)
+	if err != nil || len(tag) != CommittingTagSize {
+		return false
+	}
+	return Equal(want, tag)
+}
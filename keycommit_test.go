@@ -0,0 +1,64 @@
+package blake2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestCommittingMAC(t *testing.T) {
+	key := []byte("first key")
+	msg := []byte("message")
+	tag, err := SignCommitting(key, msg)
+	if err != nil || len(tag) != CommittingTagSize {
+		t.Fatalf("SignCommitting = %x, %v", tag, err)
+	}
+	if !VerifyCommitting(key, msg, tag) {
+		t.Error("tag does not verify")
+	}
+
+	// The tag is made of the key commitment and the personalized MAC.
+	commitment := blake2b.New(&blake2b.Config{Size: 32, Personal: []byte("blake2 keycommit")})
+	commitment.Write(key)
+	mac := blake2b.New(&blake2b.Config{Size: 32, Key: key, Personal: []byte("blake2 committed")})
+	mac.Write(msg)
+	if want := mac.Sum(commitment.Sum(nil)); !bytes.Equal(tag, want) {
+		t.Errorf("tag = %x, want %x", tag, want)
+	}
+
+	for _, c := range []struct {
+		name          string
+		key, msg, tag []byte
+	}{
+		{"other key", []byte("second key"), msg, tag},
+		{"other message", key, []byte("massage"), tag},
+		{"short tag", key, msg, tag[:32]},
+		{"empty key", nil, msg, tag},
+	} {
+		if VerifyCommitting(c.key, c.msg, c.tag) {
+			t.Errorf("%s: tag verifies", c.name)
+		}
+	}
+	if _, err := SignCommitting(make([]byte, 65), msg); err != ErrCommittingKey {
+		t.Errorf("long key: %v, want ErrCommittingKey", err)
+	}
+
+	// Streaming, with Clone and Reset.
+	m, _ := NewCommittingMAC(key)
+	m.Write(msg[:3])
+	c := m.Clone()
+	m.Write(msg[3:])
+	if !bytes.Equal(m.Sum(nil), tag) || m.Size() != CommittingTagSize {
+		t.Error("streamed tag differs")
+	}
+	c.Write(msg[3:])
+	if !bytes.Equal(c.Sum(nil), tag) {
+		t.Error("cloned tag differs")
+	}
+	m.Reset()
+	m.Write(msg)
+	if !bytes.Equal(m.Sum([]byte("x"))[1:], tag) {
+		t.Error("tag after Reset differs")
+	}
+}
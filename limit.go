@@ -0,0 +1,54 @@
+package blake2
+
+import "errors"
+
+// ErrLimit is returned by LimitedHasher and AsyncHasher.SubmitLimited
+// for input longer than their limit.
+var ErrLimit = errors.New("blake2: input exceeds size limit")
+
+// LimitedHasher is a Hasher that hashes at most a given number of bytes,
+// so that services hashing untrusted input, such as uploads, cannot tie
+// up their hashing workers with unbounded streams. A Write past the limit
+// hashes the bytes up to it and returns ErrLimit, which stops io.Copy;
+// later Writes fail too, until Reset.
+type LimitedHasher struct {
+	Hasher
+	max, n   int64
+	exceeded bool
+}
+
+// NewLimitedHasher returns a LimitedHasher hashing up to max bytes with h.
+func NewLimitedHasher(h Hasher, max int64) *LimitedHasher {
+	return &LimitedHasher{Hasher: h, max: max}
+}
+
+// Write hashes p, or the bytes of p up to the limit.
+func (l *LimitedHasher) Write(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrLimit
+	}
+	if int64(len(p)) > l.max-l.n {
+		k := l.max - l.n
+		l.Hasher.Write(p[:k])
+		l.n = l.max
+		l.exceeded = true
+		return int(k), ErrLimit
+	}
+	l.Hasher.Write(p)
+	l.n += int64(len(p))
+	return len(p), nil
+}
+
+// Reset resets the hash and the count of bytes hashed.
+func (l *LimitedHasher) Reset() {
+	l.Hasher.Reset()
+	l.n = 0
+	l.exceeded = false
+}
+
+// Clone returns an independent copy of l, with the same limit and count.
+func (l *LimitedHasher) Clone() Hasher {
+	c := *l
+	c.Hasher = l.Hasher.Clone()
+	return &c
+}
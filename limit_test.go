@@ -0,0 +1,45 @@
+package blake2
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLimitedHasher(t *testing.T) {
+	h, _ := NewHasher(BLAKE2b, nil)
+	l := NewLimitedHasher(h, 10)
+	if n, err := l.Write([]byte("01234")); n != 5 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	c := l.Clone()
+	if n, err := l.Write([]byte("56789abc")); n != 5 || err != ErrLimit {
+		t.Errorf("Write past the limit = %d, %v, want 5, ErrLimit", n, err)
+	}
+	if n, err := l.Write(nil); n != 0 || err != ErrLimit {
+		t.Errorf("Write after the limit = %d, %v, want ErrLimit", n, err)
+	}
+	want, _ := NewHasher(BLAKE2b, nil)
+	want.Write([]byte("0123456789"))
+	if !bytes.Equal(l.Sum(nil), want.Sum(nil)) {
+		t.Error("digest differs from the bytes up to the limit")
+	}
+
+	// The clone keeps its count and limit.
+	if n, err := c.Write([]byte("56789")); n != 5 || err != nil {
+		t.Errorf("clone Write = %d, %v", n, err)
+	}
+	if _, err := c.Write([]byte("a")); err != ErrLimit {
+		t.Errorf("clone Write past the limit: %v, want ErrLimit", err)
+	}
+
+	l.Reset()
+	if n, err := io.Copy(l, strings.NewReader(strings.Repeat("x", 10))); n != 10 || err != nil {
+		t.Errorf("io.Copy after Reset = %d, %v", n, err)
+	}
+	l.Reset()
+	if n, err := io.Copy(l, strings.NewReader(strings.Repeat("x", 11))); n != 10 || err != ErrLimit {
+		t.Errorf("io.Copy past the limit = %d, %v, want 10, ErrLimit", n, err)
+	}
+}
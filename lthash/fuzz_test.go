@@ -0,0 +1,16 @@
+//go:build go1.18
+// +build go1.18
+
+package lthash
+
+import "testing"
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	f.Add(make([]byte, Size))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var h Hash
+		if h.UnmarshalBinary(data) == nil {
+			h.MarshalBinary()
+		}
+	})
+}
@@ -0,0 +1,132 @@
+// Package lthash implements LtHash, a homomorphic multiset hash: elements
+// can be added and removed one at a time, in any order, and equal
+// multisets get equal checksums. Replicas of a table can thus compare
+// checksums of their rows without agreeing on an order, and update them
+// as rows change without hashing the whole table again.
+//
+// Each element is hashed to 1024 16-bit lanes by the variable-length
+// BLAKE2b hash blake2b.Long, and the checksum is the lane-wise sum modulo
+// 2^16 of the hashes of the elements, as LtHash16 by Bellare and
+// Micciancio, and Lewi et al.
+package lthash
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+const (
+	// Lanes is the number of 16-bit lanes of a checksum.
+	Lanes = 1024
+	// Size is the length of a checksum in bytes.
+	Size = 2 * Lanes
+)
+
+// domain separates element hashes from other uses of blake2b.Long.
+const domain = "blake2 lthash\x00"
+
+// ErrSize is returned by UnmarshalBinary for checksums of the wrong
+// length.
+var ErrSize = errors.New("lthash: invalid checksum size")
+
+// Hash is the checksum of a multiset. The zero value is the checksum of
+// the empty multiset.
+type Hash struct {
+	lanes [Lanes]uint16
+}
+
+// New returns the checksum of the empty multiset.
+func New() *Hash {
+	return new(Hash)
+}
+
+// elementHash returns the lanes of elem, little-endian.
+func elementHash(elem []byte) []byte {
+	in := make([]byte, 0, len(domain)+len(elem))
+	in = append(in, domain...)
+	return blake2b.Long(Size, append(in, elem...))
+}
+
+// Add adds elems to the multiset. Adding an element twice counts it
+// twice.
+func (h *Hash) Add(elems ...[]byte) {
+	for _, e := range elems {
+		b := elementHash(e)
+		for i := range h.lanes {
+			h.lanes[i] += binary.LittleEndian.Uint16(b[2*i:])
+		}
+	}
+}
+
+// Remove removes elems from the multiset. Removing an element that was
+// not added gives the checksum of no multiset, until it is added back.
+func (h *Hash) Remove(elems ...[]byte) {
+	for _, e := range elems {
+		b := elementHash(e)
+		for i := range h.lanes {
+			h.lanes[i] -= binary.LittleEndian.Uint16(b[2*i:])
+		}
+	}
+}
+
+// Union adds the elements of o to h, as if each was added to h.
+func (h *Hash) Union(o *Hash) {
+	for i := range h.lanes {
+		h.lanes[i] += o.lanes[i]
+	}
+}
+
+// Difference removes the elements of o from h, as if each was removed
+// from h.
+func (h *Hash) Difference(o *Hash) {
+	for i := range h.lanes {
+		h.lanes[i] -= o.lanes[i]
+	}
+}
+
+// Reset sets h to the checksum of the empty multiset.
+func (h *Hash) Reset() {
+	*h = Hash{}
+}
+
+// Sum appends the checksum, as Size bytes of little-endian lanes, to b.
+func (h *Hash) Sum(b []byte) []byte {
+	var buf [Size]byte
+	for i, v := range h.lanes {
+		binary.LittleEndian.PutUint16(buf[2*i:], v)
+	}
+	return append(b, buf[:]...)
+}
+
+// Digest returns the 32-byte BLAKE2b digest of the checksum, a compact
+// value for replicas to exchange and compare.
+func (h *Hash) Digest() []byte {
+	d := blake2b.New(&blake2b.Config{Size: 32})
+	d.Write(h.Sum(nil))
+	return d.Sum(nil)
+}
+
+// Equal reports whether h and o are checksums of the same multiset, in
+// constant time.
+func (h *Hash) Equal(o *Hash) bool {
+	return subtle.ConstantTimeCompare(h.Sum(nil), o.Sum(nil)) == 1
+}
+
+// MarshalBinary encodes the checksum as Sum does.
+func (h *Hash) MarshalBinary() ([]byte, error) {
+	return h.Sum(nil), nil
+}
+
+// UnmarshalBinary decodes a checksum encoded by MarshalBinary.
+func (h *Hash) UnmarshalBinary(data []byte) error {
+	if len(data) != Size {
+		return ErrSize
+	}
+	for i := range h.lanes {
+		h.lanes[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package lthash
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func rows(n int) [][]byte {
+	r := make([][]byte, n)
+	for i := range r {
+		r[i] = []byte(fmt.Sprintf("row %d", i))
+	}
+	return r
+}
+
+func TestOrderIndependence(t *testing.T) {
+	r := rows(50)
+	a, b := New(), New()
+	a.Add(r...)
+	for i := len(r) - 1; i >= 0; i-- {
+		b.Add(r[i])
+	}
+	if !a.Equal(b) || !bytes.Equal(a.Digest(), b.Digest()) {
+		t.Fatal("checksums depend on the order of the elements")
+	}
+
+	b.Remove(r[7])
+	if a.Equal(b) {
+		t.Fatal("removing an element does not change the checksum")
+	}
+	b.Add(r[7])
+	if !a.Equal(b) {
+		t.Fatal("adding back a removed element does not restore the checksum")
+	}
+
+	// Multisets count duplicates.
+	b.Add(r[0])
+	if a.Equal(b) {
+		t.Fatal("duplicate element ignored")
+	}
+	b.Remove(r[0])
+
+	b.Remove(r...)
+	if !b.Equal(New()) {
+		t.Fatal("removing every element does not give the empty checksum")
+	}
+}
+
+func TestUnion(t *testing.T) {
+	r := rows(20)
+	whole, left, right := New(), New(), New()
+	whole.Add(r...)
+	left.Add(r[:8]...)
+	right.Add(r[8:]...)
+	left.Union(right)
+	if !left.Equal(whole) {
+		t.Fatal("union of partitions differs from the whole")
+	}
+	left.Difference(right)
+	part := New()
+	part.Add(r[:8]...)
+	if !left.Equal(part) {
+		t.Fatal("difference does not undo union")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	h := New()
+	h.Add(rows(3)...)
+	data, err := h.MarshalBinary()
+	if err != nil || len(data) != Size {
+		t.Fatalf("MarshalBinary: %d bytes, %v", len(data), err)
+	}
+	var g Hash
+	if err := g.UnmarshalBinary(data); err != nil || !g.Equal(h) {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if err := g.UnmarshalBinary(data[1:]); err != ErrSize {
+		t.Errorf("UnmarshalBinary of a short checksum: %v", err)
+	}
+	g.Reset()
+	if !g.Equal(New()) {
+		t.Error("Reset does not give the empty checksum")
+	}
+}
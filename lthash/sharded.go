@@ -0,0 +1,69 @@
+package lthash
+
+import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Sharded is a checksum that many goroutines can add elements to at
+// once, such as the fingerprint of events flowing through a service,
+// where a Hash behind a single mutex becomes contended. Elements are
+// hashed without holding a lock and added to one of several shards,
+// taken in turn; since the checksum does not depend on the order of the
+// elements, Sum gives the same checksum whichever shards they went to.
+type Sharded struct {
+	next   uint32
+	shards []shard
+}
+
+// shard is a checksum with its lock.
+type shard struct {
+	mu sync.Mutex
+	h  Hash
+}
+
+// NewSharded returns the checksum of the empty multiset, with n shards,
+// or runtime.GOMAXPROCS(0) if n is not positive.
+func NewSharded(n int) *Sharded {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return &Sharded{shards: make([]shard, n)}
+}
+
+// Add adds elems to the multiset, as Hash.Add does. It is safe for
+// concurrent use.
+func (s *Sharded) Add(elems ...[]byte) {
+	for _, e := range elems {
+		b := elementHash(e)
+		sh := &s.shards[atomic.AddUint32(&s.next, 1)%uint32(len(s.shards))]
+		sh.mu.Lock()
+		for i := range sh.h.lanes {
+			sh.h.lanes[i] += binary.LittleEndian.Uint16(b[2*i:])
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// Sum returns the checksum of the elements added so far, the union of
+// the shards. Elements added concurrently with Sum may be left out.
+func (s *Sharded) Sum() *Hash {
+	h := New()
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		h.Union(&sh.h)
+		sh.mu.Unlock()
+	}
+	return h
+}
+
+// Reset sets s to the checksum of the empty multiset. It must not be
+// called concurrently with Add.
+func (s *Sharded) Reset() {
+	for i := range s.shards {
+		s.shards[i].h.Reset()
+	}
+}
@@ -0,0 +1,58 @@
+package lthash
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	elems := rows(1000)
+	want := New()
+	want.Add(elems...)
+
+	s := NewSharded(4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < len(elems); i += 8 {
+				s.Add(elems[i])
+			}
+		}(g)
+	}
+	wg.Wait()
+	if !s.Sum().Equal(want) {
+		t.Error("sharded checksum differs from Hash")
+	}
+	s.Reset()
+	if !s.Sum().Equal(New()) {
+		t.Error("checksum after Reset is not empty")
+	}
+	if len(NewSharded(0).shards) == 0 {
+		t.Error("NewSharded(0) has no shards")
+	}
+}
+
+func BenchmarkAddParallel(b *testing.B) {
+	elem := []byte("event 42")
+	b.Run("Mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		h := New()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				h.Add(elem)
+				mu.Unlock()
+			}
+		})
+	})
+	b.Run("Sharded", func(b *testing.B) {
+		s := NewSharded(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Add(elem)
+			}
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache remembers the digests of files by their path, size, modification
+// time and inode, so that Build does not hash files again when their
+// metadata did not change. A cache is meant for a single tree and hash
+// function. It is safe for concurrent use.
+//
+// Like every tool relying on metadata, such as rsync and make, a cache
+// misses changes that keep the size and modification time of a file.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	Size    int64
+	ModTime time.Time
+	Inode   uint64
+	Digest  []byte
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// LoadCache reads a cache saved by Save from the file name. A missing
+// file gives an empty cache.
+func LoadCache(name string) (*Cache, error) {
+	c := NewCache()
+	b, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cache to the file name, as JSON. The file is replaced
+// atomically, so that an interrupted save keeps the previous cache.
+func (c *Cache) Save(name string) error {
+	c.mu.Lock()
+	b, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// Len returns the number of files in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// lookup returns the cached digest of the file name with the given
+// metadata. A nil cache has no digests.
+func (c *Cache) lookup(name string, info fs.FileInfo) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[name]
+	if !ok || e.Size != info.Size() || !e.ModTime.Equal(info.ModTime()) || e.Inode != inode(info) {
+		return nil, false
+	}
+	return e.Digest, true
+}
+
+func (c *Cache) store(name string, info fs.FileInfo, digest []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = cacheEntry{Size: info.Size(), ModTime: info.ModTime(), Inode: inode(info), Digest: digest}
+}
@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"bytes"
+	"hash"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// countingHash counts the files hashed.
+func countingHash(n *int) func() hash.Hash {
+	return func() hash.Hash {
+		return &counter{Hash: blake2b.New(&blake2b.Config{Size: 32}), n: n}
+	}
+}
+
+type counter struct {
+	hash.Hash
+	n *int
+}
+
+func (c *counter) Reset() {
+	*c.n++
+	c.Hash.Reset()
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	var hashed int
+	cache, err := LoadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{New: countingHash(&hashed), Cache: cache}
+	first, err := Build(os.DirFS(dir), ".", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != 3 || cache.Len() != 3 {
+		t.Fatalf("hashed %d files, cached %d", hashed, cache.Len())
+	}
+	if err := cache.Save(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the cache and change one file.
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(filepath.Join(dir, "b"), []byte("B"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "b"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	hashed = 0
+	if opts.Cache, err = LoadCache(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	second, err := Build(os.DirFS(dir), ".", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != 1 {
+		t.Errorf("hashed %d files, want 1", hashed)
+	}
+	uncached, _ := Build(os.DirFS(dir), ".", nil)
+	if !bytes.Equal(second.Digest(), uncached.Digest()) || bytes.Equal(second.Digest(), first.Digest()) {
+		t.Error("cached manifest differs from a fresh one")
+	}
+}
@@ -0,0 +1,110 @@
+package manifest
+
+import (
+	"bytes"
+	"sort"
+)
+
+// DiffOptions contains the parameters of Diff. The zero value compares
+// digests, file types and modification times, and the metadata both
+// manifests cover, without rename detection.
+type DiffOptions struct {
+	// IgnoreModTime reports files whose modification time changed but
+	// not their digest as unchanged.
+	IgnoreModTime bool
+	// DetectRenames reports a file removed from one path and added at
+	// another with the same digest as renamed.
+	DetectRenames bool
+}
+
+// Rename is a file moved from one path to another.
+type Rename struct {
+	From, To string
+}
+
+// Changes lists the differences between two manifests, each list sorted
+// by path.
+type Changes struct {
+	// Added holds the paths only in the new manifest.
+	Added []string
+	// Removed holds the paths only in the old manifest.
+	Removed []string
+	// Modified holds the paths in both whose files differ.
+	Modified []string
+	// Renamed holds the files moved, with DetectRenames.
+	Renamed []Rename
+}
+
+// Empty reports whether there are no changes.
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0 && len(c.Renamed) == 0
+}
+
+// Diff returns the changes from manifest a to manifest b. opts may be
+// nil.
+func Diff(a, b *Manifest, opts *DiffOptions) *Changes {
+	var o DiffOptions
+	if opts != nil {
+		o = *opts
+	}
+	c := new(Changes)
+	var added, removed []Entry
+	i, j := 0, 0
+	for i < len(a.Entries) || j < len(b.Entries) {
+		switch {
+		case j == len(b.Entries) || (i < len(a.Entries) && a.Entries[i].Path < b.Entries[j].Path):
+			removed = append(removed, a.Entries[i])
+			i++
+		case i == len(a.Entries) || b.Entries[j].Path < a.Entries[i].Path:
+			added = append(added, b.Entries[j])
+			j++
+		default:
+			ea, eb := a.Entries[i], b.Entries[j]
+			if !bytes.Equal(ea.Digest, eb.Digest) || ea.Size != eb.Size || ea.Mode.Type() != eb.Mode.Type() ||
+				ea.Target != eb.Target || (!o.IgnoreModTime && !ea.ModTime.Equal(eb.ModTime)) ||
+				(a.Metadata&b.Metadata&Mode != 0 && ea.Mode != eb.Mode) ||
+				(a.Metadata&b.Metadata&Owner != 0 && (ea.UID != eb.UID || ea.GID != eb.GID)) {
+				c.Modified = append(c.Modified, ea.Path)
+			}
+			i++
+			j++
+		}
+	}
+
+	if o.DetectRenames {
+		// Pair removed and added files with the same digest, in path
+		// order.
+		byDigest := make(map[string][]int)
+		for k, e := range added {
+			byDigest[string(e.Digest)] = append(byDigest[string(e.Digest)], k)
+		}
+		renamed := make([]bool, len(added))
+		kept := removed[:0]
+		for _, e := range removed {
+			if ks := byDigest[string(e.Digest)]; len(ks) > 0 {
+				byDigest[string(e.Digest)] = ks[1:]
+				renamed[ks[0]] = true
+				c.Renamed = append(c.Renamed, Rename{From: e.Path, To: added[ks[0]].Path})
+				continue
+			}
+			kept = append(kept, e)
+		}
+		removed = kept
+		kept = nil
+		for k, e := range added {
+			if !renamed[k] {
+				kept = append(kept, e)
+			}
+		}
+		added = kept
+		sort.Slice(c.Renamed, func(i, j int) bool { return c.Renamed[i].From < c.Renamed[j].From })
+	}
+
+	for _, e := range added {
+		c.Added = append(c.Added, e.Path)
+	}
+	for _, e := range removed {
+		c.Removed = append(c.Removed, e.Path)
+	}
+	return c
+}
@@ -0,0 +1,48 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := Build(testFS(), "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := testFS()
+	fsys["root/a.txt"].Data = []byte("ALPHA")
+	fsys["root/dir/b.txt"].ModTime = t0.Add(time.Hour)
+	fsys["root/moved.txt"] = fsys["root/dir/c.txt"]
+	delete(fsys, "root/dir/c.txt")
+	fsys["root/new.txt"] = fsys["other/d.txt"]
+	b, err := Build(fsys, "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		opts *DiffOptions
+		want Changes
+	}{
+		{nil, Changes{
+			Added:    []string{"moved.txt", "new.txt"},
+			Removed:  []string{"dir/c.txt"},
+			Modified: []string{"a.txt", "dir/b.txt"},
+		}},
+		{&DiffOptions{IgnoreModTime: true, DetectRenames: true}, Changes{
+			Added:    []string{"new.txt"},
+			Modified: []string{"a.txt"},
+			Renamed:  []Rename{{From: "dir/c.txt", To: "moved.txt"}},
+		}},
+	} {
+		if got := Diff(a, b, c.opts); !reflect.DeepEqual(*got, c.want) {
+			t.Errorf("options %+v: got %+v, want %+v", c.opts, *got, c.want)
+		}
+	}
+	if !Diff(a, a, nil).Empty() {
+		t.Error("manifest differs from itself")
+	}
+}
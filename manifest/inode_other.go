@@ -0,0 +1,24 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package manifest
+
+import "io/fs"
+
+// inode returns 0: inode numbers are not available on this platform.
+func inode(info fs.FileInfo) uint64 {
+	return 0
+}
+
+type fileID struct{}
+
+// getFileID reports false: hard links cannot be recognized on this
+// platform.
+func getFileID(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// owner returns -1: ownership is not available on this platform.
+func owner(info fs.FileInfo) (uid, gid int) {
+	return -1, -1
+}
@@ -0,0 +1,37 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package manifest
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// inode returns the inode number of a file on disk, or 0.
+func inode(info fs.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
+
+// fileID identifies a file on disk, whatever its links.
+type fileID struct {
+	dev, ino uint64
+}
+
+func getFileID(info fs.FileInfo) (fileID, bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+	}
+	return fileID{}, false
+}
+
+// owner returns the numeric owner and group of a file on disk, or -1.
+func owner(info fs.FileInfo) (uid, gid int) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(st.Uid), int(st.Gid)
+	}
+	return -1, -1
+}
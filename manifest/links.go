@@ -0,0 +1,62 @@
+package manifest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrReadLink is returned by Build for RecordSymlinks on file systems
+// that do not implement ReadLinkFS.
+var ErrReadLink = errors.New("manifest: file system cannot read symbolic links")
+
+// ReadLinkFS is implemented by file systems that can read symbolic links
+// without following them, such as the one returned by DirFS. Its methods
+// are those of fs.ReadLinkFS in later Go releases.
+type ReadLinkFS interface {
+	fs.FS
+	// ReadLink returns the destination of the symbolic link name.
+	ReadLink(name string) (string, error)
+	// Lstat returns information about name, without following it if
+	// it is a symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// DirFS returns a file system for the tree of files rooted at dir, like
+// os.DirFS, that also implements ReadLinkFS.
+func DirFS(dir string) fs.FS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func (d dirFS) ReadLink(name string) (string, error) {
+	path, err := d.join("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(target), nil
+}
+
+func (d dirFS) Lstat(name string) (fs.FileInfo, error) {
+	path, err := d.join("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Lstat(path)
+}
+
+func (d dirFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
@@ -0,0 +1,185 @@
+package manifest
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// linkTree creates a directory with a file, a hard link to it, and
+// symbolic links to the file, to a directory and to nothing.
+func linkTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a"), []byte("alpha"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b"), []byte("bravo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(filepath.Join(dir, "a"), filepath.Join(dir, "hard")); err != nil {
+		t.Skip(err)
+	}
+	for link, target := range map[string]string{"file": "a", "dir": "sub", "dangling": "missing"} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skip(err)
+		}
+	}
+	return dir
+}
+
+func paths(m *Manifest) []string {
+	var p []string
+	for _, e := range m.Entries {
+		p = append(p, e.Path)
+	}
+	return p
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sum(data string) []byte {
+	h := blake2b.New(&blake2b.Config{Size: 32})
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func TestSymlinks(t *testing.T) {
+	fsys := DirFS(linkTree(t))
+
+	m, err := Build(fsys, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "hard", "sub/b"}; !equalStrings(paths(m), want) {
+		t.Errorf("skipped: paths %v, want %v", paths(m), want)
+	}
+
+	m, err = Build(fsys, ".", &Options{Symlinks: FollowSymlinks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "file", "hard", "sub/b"}; !equalStrings(paths(m), want) {
+		t.Errorf("followed: paths %v, want %v", paths(m), want)
+	}
+	if e, _ := m.Lookup("file"); !e.Mode.IsRegular() || e.Size != 5 || !bytes.Equal(e.Digest, sum("alpha")) {
+		t.Errorf("followed link %+v", e)
+	}
+
+	m, err = Build(fsys, ".", &Options{Symlinks: RecordSymlinks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "dangling", "dir", "file", "hard", "sub/b"}; !equalStrings(paths(m), want) {
+		t.Errorf("recorded: paths %v, want %v", paths(m), want)
+	}
+	for link, target := range map[string]string{"file": "a", "dir": "sub", "dangling": "missing"} {
+		e, _ := m.Lookup(link)
+		if e.Mode&fs.ModeSymlink == 0 || e.Target != target || !bytes.Equal(e.Digest, sum(target)) {
+			t.Errorf("recorded link %+v", e)
+		}
+	}
+
+	// A link differs from a file holding its target path.
+	plain, err := Build(fstest.MapFS{"file": {Data: []byte("a")}}, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, _ := m.Lookup("file")
+	recorded := &Manifest{Entries: []Entry{link}}
+	if bytes.Equal(plain.Digest(), recorded.Digest()) {
+		t.Error("symbolic link and file have the same manifest digest")
+	}
+	if c := Diff(plain, recorded, &DiffOptions{IgnoreModTime: true}); !equalStrings(c.Modified, []string{"file"}) {
+		t.Errorf("Diff = %+v, want file modified", c)
+	}
+
+	// Hide the methods of MapFS that read links.
+	noLinks := struct{ fs.FS }{fstest.MapFS{"link": {Data: []byte("a"), Mode: fs.ModeSymlink}}}
+	if _, err := Build(noLinks, ".", &Options{Symlinks: RecordSymlinks}); err != ErrReadLink {
+		t.Errorf("Build without ReadLinkFS: %v, want ErrReadLink", err)
+	}
+}
+
+func TestHardlinks(t *testing.T) {
+	fsys := DirFS(linkTree(t))
+	var hashed int
+	m, err := Build(fsys, ".", &Options{New: countingHash(&hashed), Hardlinks: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != 3 {
+		t.Fatalf("paths %v", paths(m))
+	}
+	a, _ := m.Lookup("a")
+	hard, _ := m.Lookup("hard")
+	if !bytes.Equal(a.Digest, hard.Digest) || !bytes.Equal(a.Digest, sum("alpha")) {
+		t.Errorf("digests %x and %x", a.Digest, hard.Digest)
+	}
+	if _, ok := getFileID(mustStat(t, fsys, "a")); ok && hashed != 2 {
+		t.Errorf("hashed %d files, want 2", hashed)
+	}
+}
+
+func mustStat(t *testing.T, fsys fs.FS, name string) fs.FileInfo {
+	t.Helper()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestMetadata(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a": {Data: []byte("alpha"), Mode: 0o644},
+		"b": {Data: []byte("bravo"), Mode: 0o755},
+	}
+	build := func(md Metadata) *Manifest {
+		m, err := Build(fsys, ".", &Options{Metadata: md})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	plain, withMode := build(0), build(Mode)
+	fsys["b"].Mode = 0o700
+	plain2, withMode2 := build(0), build(Mode)
+
+	if !bytes.Equal(plain.Digest(), plain2.Digest()) {
+		t.Error("digest without metadata covers the mode")
+	}
+	if bytes.Equal(withMode.Digest(), withMode2.Digest()) {
+		t.Error("digest with Mode does not cover the mode")
+	}
+	if bytes.Equal(plain.Digest(), withMode.Digest()) {
+		t.Error("digests with and without metadata are equal")
+	}
+	if c := Diff(plain, plain2, nil); !c.Empty() {
+		t.Errorf("Diff without metadata = %+v", c)
+	}
+	if c := Diff(withMode, withMode2, nil); !equalStrings(c.Modified, []string{"b"}) {
+		t.Errorf("Diff with Mode = %+v, want b modified", c)
+	}
+	if e, _ := plain.Lookup("a"); e.UID != -1 || e.GID != -1 {
+		t.Errorf("owner of a MapFS file %d:%d, want -1:-1", e.UID, e.GID)
+	}
+}
@@ -0,0 +1,339 @@
+// Package manifest records the files of a directory tree with their
+// BLAKE2b digests, and compares such manifests to find what changed.
+package manifest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// ErrKey is returned by MAC for an empty key or one longer than
+// blake2b.MaxKeySize.
+var ErrKey = errors.New("manifest: MAC key must be 1 to 64 bytes")
+
+// Entry is a file of a manifest.
+type Entry struct {
+	// Path is the slash-separated path of the file, relative to the
+	// root of the tree.
+	Path string
+	// Size is the file length.
+	Size int64
+	// ModTime is the modification time of the file.
+	ModTime time.Time
+	// Mode holds the type and permission bits of the file.
+	Mode fs.FileMode
+	// UID and GID are the numeric owner and group of the file, or -1
+	// where the file system does not provide them.
+	UID, GID int
+	// Target is the destination of a symbolic link recorded with
+	// RecordSymlinks.
+	Target string
+	// Digest is the digest of the file contents, or of Target for
+	// symbolic links. It is nil for special files.
+	Digest []byte
+}
+
+// Manifest lists the files of a tree, sorted by path.
+type Manifest struct {
+	Entries []Entry
+	// Metadata is the file metadata covered by Digest, as set by
+	// Options.Metadata.
+	Metadata Metadata
+}
+
+// Metadata is a set of file attributes that manifest digests can cover,
+// besides paths and contents.
+type Metadata uint8
+
+const (
+	// Mode covers the permission bits, including setuid, setgid and
+	// sticky.
+	Mode Metadata = 1 << iota
+	// Owner covers the numeric owner and group.
+	Owner
+)
+
+// SymlinkPolicy is how Build handles symbolic links.
+type SymlinkPolicy uint8
+
+const (
+	// SkipSymlinks leaves symbolic links out of the manifest.
+	SkipSymlinks SymlinkPolicy = iota
+	// FollowSymlinks records links to regular files as the files they
+	// point to. Links to directories are not descended into, so that
+	// cycles cannot occur, and are skipped like dangling links.
+	FollowSymlinks
+	// RecordSymlinks records symbolic links themselves, with the digest
+	// of their target path. It requires a file system implementing
+	// ReadLinkFS.
+	RecordSymlinks
+)
+
+// Options contains the parameters of Build. The zero value uses the
+// defaults.
+type Options struct {
+	// New returns the hash used for file contents. If nil, 32-byte
+	// BLAKE2b is used.
+	New func() hash.Hash
+	// Cache, if not nil, provides the digests of files whose metadata
+	// did not change since they were last hashed with it, and records
+	// the others.
+	Cache *Cache
+	// Symlinks is the handling of symbolic links.
+	Symlinks SymlinkPolicy
+	// Hardlinks hashes files with several links in the tree once,
+	// recognizing them by device and inode where the file system
+	// provides them.
+	Hardlinks bool
+	// Special records devices, named pipes and sockets, without digest,
+	// instead of skipping them.
+	Special bool
+	// Metadata is the file metadata included in the manifest digest, so
+	// that it differs for trees whose files have different modes or
+	// owners.
+	Metadata Metadata
+}
+
+func (o *Options) newHash() hash.Hash {
+	if o != nil && o.New != nil {
+		return o.New()
+	}
+	return blake2b.New(&blake2b.Config{Size: 32})
+}
+
+// Build walks the tree rooted at root in fsys and returns the manifest
+// of its regular files, and of its symbolic links and special files as
+// opts requests. Use DirFS to build the manifest of a directory on disk.
+// opts may be nil.
+func Build(fsys fs.FS, root string, opts *Options) (*Manifest, error) {
+	b := newBuilder(fsys, root, opts)
+	m := &Manifest{Metadata: b.o.Metadata}
+	if err := b.walk(root, func(e Entry) { m.Entries = append(m.Entries, e) }); err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+	return m, nil
+}
+
+// builder makes the entries of the files under root.
+type builder struct {
+	fsys   fs.FS
+	root   string
+	o      Options
+	h      hash.Hash
+	linked map[fileID][]byte
+}
+
+func newBuilder(fsys fs.FS, root string, opts *Options) *builder {
+	b := &builder{fsys: fsys, root: root, h: opts.newHash()}
+	if opts != nil {
+		b.o = *opts
+	}
+	if b.o.Hardlinks {
+		b.linked = make(map[fileID][]byte)
+	}
+	return b
+}
+
+// walk passes the entries of the tree rooted at start, root or one of its
+// descendants, to add.
+func (b *builder) walk(start string, add func(Entry)) error {
+	return fs.WalkDir(b.fsys, start, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		e, ok, err := b.entry(name, d.Type(), d.Info)
+		if ok {
+			add(e)
+		}
+		return err
+	})
+}
+
+// entry returns the entry of the file name, of type typ, and reports
+// whether the options keep it. info returns the file information, not
+// following symbolic links.
+func (b *builder) entry(name string, typ fs.FileMode, info func() (fs.FileInfo, error)) (Entry, bool, error) {
+	var fi fs.FileInfo
+	var target string
+	var err error
+	switch {
+	case typ.IsRegular():
+		fi, err = info()
+	case typ&fs.ModeSymlink != 0 && b.o.Symlinks == FollowSymlinks:
+		fi, err = fs.Stat(b.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && !fi.Mode().IsRegular()) {
+			return Entry{}, false, nil
+		}
+	case typ&fs.ModeSymlink != 0 && b.o.Symlinks == RecordSymlinks:
+		rl, ok := b.fsys.(ReadLinkFS)
+		if !ok {
+			return Entry{}, false, ErrReadLink
+		}
+		if target, err = rl.ReadLink(name); err != nil {
+			return Entry{}, false, err
+		}
+		fi, err = rl.Lstat(name)
+	case typ&(fs.ModeDevice|fs.ModeNamedPipe|fs.ModeSocket) != 0 && b.o.Special:
+		fi, err = info()
+	default:
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	e := Entry{
+		Path:    relative(b.root, name),
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+		Mode:    fi.Mode(),
+		Target:  target,
+	}
+	e.UID, e.GID = owner(fi)
+	switch {
+	case e.Mode&fs.ModeSymlink != 0:
+		b.h.Reset()
+		io.WriteString(b.h, target)
+		e.Digest = b.h.Sum(nil)
+	case e.Mode.IsRegular():
+		if e.Digest, err = digestFile(b.fsys, name, fi, b.h, b.o.Cache, b.linked); err != nil {
+			return Entry{}, false, err
+		}
+	}
+	return e, true, nil
+}
+
+// digestFile returns the digest of the regular file name, from cache or
+// from the digests of the files linked if possible.
+func digestFile(fsys fs.FS, name string, info fs.FileInfo, h hash.Hash, cache *Cache, linked map[fileID][]byte) ([]byte, error) {
+	id, hasID := getFileID(info)
+	if hasID && linked != nil {
+		if digest, ok := linked[id]; ok {
+			return digest, nil
+		}
+	}
+	digest, ok := cache.lookup(name, info)
+	if !ok {
+		var err error
+		if digest, err = hashFile(fsys, name, h); err != nil {
+			return nil, err
+		}
+		cache.store(name, info, digest)
+	}
+	if hasID && linked != nil {
+		linked[id] = digest
+	}
+	return digest, nil
+}
+
+func hashFile(fsys fs.FS, name string, h hash.Hash) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h.Reset()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// relative returns name relative to root, both paths of an fs.FS.
+func relative(root, name string) string {
+	if root == "." {
+		return name
+	}
+	if name == root {
+		return path.Base(name)
+	}
+	return name[len(root)+1:]
+}
+
+// Lookup returns the entry of the file at path.
+func (m *Manifest) Lookup(path string) (Entry, bool) {
+	i := sort.Search(len(m.Entries), func(i int) bool { return m.Entries[i].Path >= path })
+	if i < len(m.Entries) && m.Entries[i].Path == path {
+		return m.Entries[i], true
+	}
+	return Entry{}, false
+}
+
+// Digest returns the 32-byte BLAKE2b digest of the paths and digests of
+// the entries, which identifies the contents of the tree. Modification
+// times are left out, so that copies of a tree have the same digest. If
+// the manifest has symbolic links or covers metadata, the digest also
+// covers the type of the entries and the metadata, and is computed with
+// a distinct personalization.
+func (m *Manifest) Digest() []byte {
+	return m.digest(nil)
+}
+
+// MAC returns the manifest digest computed as a keyed BLAKE2b MAC with
+// key, which makes a manifest tamper-evident, such as that of a firmware
+// image, without a public key infrastructure: only holders of the key can
+// produce or check it. It covers what Digest covers. It returns ErrKey if
+// key is empty or longer than blake2b.MaxKeySize.
+func (m *Manifest) MAC(key []byte) ([]byte, error) {
+	if len(key) == 0 || len(key) > blake2b.MaxKeySize {
+		return nil, ErrKey
+	}
+	return m.digest(key), nil
+}
+
+// VerifyMAC reports whether mac is the MAC of the manifest with key, in
+// time that does not depend on the contents of mac.
+func (m *Manifest) VerifyMAC(key, mac []byte) bool {
+	want, err := m.MAC(key)
+	return err == nil && subtle.ConstantTimeCompare(mac, want) == 1
+}
+
+// digest returns the manifest digest, keyed with key if not nil.
+func (m *Manifest) digest(key []byte) []byte {
+	extended := m.Metadata != 0
+	for _, e := range m.Entries {
+		extended = extended || !e.Mode.IsRegular()
+	}
+	var h hash.Hash
+	if extended {
+		h = blake2b.New(&blake2b.Config{Size: 32, Key: key, Personal: []byte("blake2 manifest")})
+		h.Write([]byte{byte(m.Metadata)})
+	} else {
+		h = blake2b.New(&blake2b.Config{Size: 32, Key: key})
+	}
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+	}
+	for _, e := range m.Entries {
+		buf.Reset()
+		putUvarint(uint64(len(e.Path)))
+		buf.WriteString(e.Path)
+		putUvarint(uint64(len(e.Digest)))
+		buf.Write(e.Digest)
+		if extended {
+			mode := e.Mode & fs.ModeType
+			if m.Metadata&Mode != 0 {
+				mode |= e.Mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+			}
+			putUvarint(uint64(mode))
+		}
+		if m.Metadata&Owner != 0 {
+			putUvarint(uint64(int64(e.UID)))
+			putUvarint(uint64(int64(e.GID)))
+		}
+		h.Write(buf.Bytes())
+	}
+	return h.Sum(nil)
+}
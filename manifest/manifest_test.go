@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+var t0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"root/a.txt":     {Data: []byte("alpha"), ModTime: t0},
+		"root/dir/b.txt": {Data: []byte("bravo"), ModTime: t0},
+		"root/dir/c.txt": {Data: []byte("charlie"), ModTime: t0},
+		"other/d.txt":    {Data: []byte("delta"), ModTime: t0},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	m, err := Build(testFS(), "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range m.Entries {
+		paths = append(paths, e.Path)
+	}
+	if want := []string{"a.txt", "dir/b.txt", "dir/c.txt"}; len(paths) != 3 || paths[0] != want[0] || paths[1] != want[1] || paths[2] != want[2] {
+		t.Fatalf("paths %v, want %v", paths, want)
+	}
+	e, ok := m.Lookup("dir/b.txt")
+	h := blake2b.New(&blake2b.Config{Size: 32})
+	h.Write([]byte("bravo"))
+	if !ok || e.Size != 5 || !e.ModTime.Equal(t0) || !bytes.Equal(e.Digest, h.Sum(nil)) {
+		t.Errorf("entry %+v", e)
+	}
+	if _, ok := m.Lookup("missing"); ok {
+		t.Error("Lookup found a missing file")
+	}
+
+	fsys := testFS()
+	fsys["root/a.txt"].ModTime = t0.Add(time.Hour)
+	touched, _ := Build(fsys, "root", nil)
+	if !bytes.Equal(touched.Digest(), m.Digest()) {
+		t.Error("modification time changes the manifest digest")
+	}
+	fsys["root/a.txt"].Data = []byte("ALPHA")
+	changed, _ := Build(fsys, "root", nil)
+	if bytes.Equal(changed.Digest(), m.Digest()) {
+		t.Error("contents do not change the manifest digest")
+	}
+}
+
+func TestMAC(t *testing.T) {
+	key := []byte("firmware signing key")
+	m, _ := Build(testFS(), "root", nil)
+	mac, err := m.MAC(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mac) != 32 || bytes.Equal(mac, m.Digest()) {
+		t.Errorf("MAC %x", mac)
+	}
+	if !m.VerifyMAC(key, mac) {
+		t.Error("VerifyMAC rejects the MAC")
+	}
+	if m.VerifyMAC([]byte("other key"), mac) {
+		t.Error("VerifyMAC accepts another key")
+	}
+
+	fsys := testFS()
+	fsys["root/dir/c.txt"].Data = []byte("CHARLIE")
+	tampered, _ := Build(fsys, "root", nil)
+	if tampered.VerifyMAC(key, mac) {
+		t.Error("VerifyMAC accepts a tampered tree")
+	}
+	withMode, _ := Build(testFS(), "root", &Options{Metadata: Mode})
+	if withMode.VerifyMAC(key, mac) {
+		t.Error("VerifyMAC ignores the metadata covered")
+	}
+
+	for _, k := range [][]byte{nil, make([]byte, blake2b.MaxKeySize+1)} {
+		if _, err := m.MAC(k); err != ErrKey {
+			t.Errorf("MAC with a %d-byte key: %v, want ErrKey", len(k), err)
+		}
+		if m.VerifyMAC(k, m.Digest()) {
+			t.Errorf("VerifyMAC with a %d-byte key accepts the digest", len(k))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"runtime"
+	"sync"
+)
+
+var (
+	// ErrNotListed is reported by VerifyPaths for paths that are not in
+	// the manifest.
+	ErrNotListed = errors.New("manifest: path not in manifest")
+	// ErrMismatch is reported by VerifyPaths for files that do not match
+	// their entry.
+	ErrMismatch = errors.New("manifest: file does not match its entry")
+)
+
+// Result is the outcome of verifying a path of a manifest.
+type Result struct {
+	Path string
+	// Err is nil if the file matches its entry. Otherwise it wraps
+	// ErrNotListed, ErrMismatch, or the error reading the file.
+	Err error
+}
+
+// VerifyPaths hashes the files at paths in fsys, the root of the tree,
+// and checks them against their entries, so that critical files of a
+// large tree can be spot-checked without hashing all of it. It hashes on
+// workers goroutines, or runtime.NumCPU() if workers is not positive, and
+// returns a result for each path, in the order of paths. Contents are
+// hashed with the default hash of Options, so manifests built with
+// Options.New cannot be verified with it; metadata is not checked.
+func (m *Manifest) VerifyPaths(fsys fs.FS, paths []string, workers int) []Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	results := make([]Result, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := (*Options)(nil).newHash()
+			for i := range next {
+				results[i] = Result{Path: paths[i], Err: m.verifyPath(fsys, paths[i], h)}
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// verifyPath checks the file at name against its entry, hashing with h.
+func (m *Manifest) verifyPath(fsys fs.FS, name string, h hash.Hash) error {
+	e, ok := m.Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotListed, name)
+	}
+	var digest []byte
+	switch {
+	case e.Mode&fs.ModeSymlink != 0 && e.Target != "":
+		rl, ok := fsys.(ReadLinkFS)
+		if !ok {
+			return ErrReadLink
+		}
+		target, err := rl.ReadLink(name)
+		if err != nil {
+			return err
+		}
+		h.Reset()
+		io.WriteString(h, target)
+		digest = h.Sum(nil)
+	case e.Digest == nil:
+		_, err := fs.Stat(fsys, name)
+		return err
+	default:
+		var err error
+		if digest, err = hashFile(fsys, name, h); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(digest, e.Digest) {
+		return fmt.Errorf("%w: %s", ErrMismatch, name)
+	}
+	return nil
+}
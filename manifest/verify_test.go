@@ -0,0 +1,35 @@
+package manifest
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestVerifyPaths(t *testing.T) {
+	fsys := testFS()
+	m, err := Build(fsys, "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := fs.Sub(fsys, "root")
+	fsys["root/dir/b.txt"].Data = []byte("brav0")
+	delete(fsys, "root/dir/c.txt")
+
+	paths := []string{"a.txt", "dir/b.txt", "dir/c.txt", "missing.txt", "a.txt"}
+	for _, workers := range []int{0, 1, 3} {
+		results := m.VerifyPaths(root, paths, workers)
+		if len(results) != len(paths) {
+			t.Fatalf("%d results for %d paths", len(results), len(paths))
+		}
+		for i, want := range []error{nil, ErrMismatch, fs.ErrNotExist, ErrNotListed, nil} {
+			r := results[i]
+			if r.Path != paths[i] || (want == nil) != (r.Err == nil) || (want != nil && !errors.Is(r.Err, want)) {
+				t.Errorf("workers %d: result %+v, want %v", workers, r, want)
+			}
+		}
+	}
+	if results := m.VerifyPaths(root, nil, 0); len(results) != 0 {
+		t.Errorf("results %v for no paths", results)
+	}
+}
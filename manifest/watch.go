@@ -0,0 +1,186 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Event reports a file whose digest changed.
+type Event struct {
+	// Path is the path of the file, relative to the root of the tree.
+	Path string
+	// Old is the previous digest, nil if the file was added.
+	Old []byte
+	// New is the current digest, nil if the file was removed.
+	New []byte
+}
+
+// Watcher keeps the manifest of a tree up to date by hashing again only
+// the files reported changed, as by a file system notification service
+// such as fsnotify:
+//
+//	for ev := range fsw.Events {
+//		rel, _ := filepath.Rel(dir, ev.Name)
+//		changes <- filepath.ToSlash(rel)
+//	}
+//
+// It is safe for concurrent use.
+type Watcher struct {
+	mu   sync.Mutex
+	fsys fs.FS
+	root string
+	opts Options
+	m    *Manifest
+}
+
+// NewWatcher builds the manifest of the tree rooted at root in fsys and
+// returns a watcher keeping it up to date. opts may be nil. Hard links
+// are only recognized within each update, as a file changed through one
+// of its paths is reported for that path only.
+func NewWatcher(fsys fs.FS, root string, opts *Options) (*Watcher, error) {
+	m, err := Build(fsys, root, opts)
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{fsys: fsys, root: root, m: m}
+	if opts != nil {
+		w.opts = *opts
+	}
+	return w, nil
+}
+
+// Manifest returns a copy of the current manifest.
+func (w *Watcher) Manifest() *Manifest {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return &Manifest{
+		Entries:  append([]Entry(nil), w.m.Entries...),
+		Metadata: w.m.Metadata,
+	}
+}
+
+// Update hashes again the files at the given slash-separated paths,
+// relative to the root of the tree, and returns the events of the files
+// whose digests changed. A directory path updates the whole subtree. Files
+// that no longer exist are removed from the manifest.
+func (w *Watcher) Update(names ...string) ([]Event, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []Event
+	for _, name := range names {
+		name = path.Clean(name)
+		if !fs.ValidPath(name) {
+			return events, &fs.PathError{Op: "update", Path: name, Err: fs.ErrInvalid}
+		}
+		ev, err := w.update(name)
+		events = append(events, ev...)
+		if err != nil {
+			return events, err
+		}
+	}
+	return events, nil
+}
+
+func (w *Watcher) update(name string) ([]Event, error) {
+	full := path.Join(w.root, name)
+	b := newBuilder(w.fsys, w.root, &w.opts)
+	var fresh []Entry
+	add := func(e Entry) { fresh = append(fresh, e) }
+	info, err := w.lstat(full)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case info.IsDir():
+		err = b.walk(full, add)
+	default:
+		var e Entry
+		var ok bool
+		e, ok, err = b.entry(full, info.Mode().Type(), func() (fs.FileInfo, error) { return info, nil })
+		if ok {
+			add(e)
+		}
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if name == "." && info != nil && !info.IsDir() {
+		// The root is a single file, whose entry path is its base name.
+		name = path.Base(w.root)
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].Path < fresh[j].Path })
+
+	// Replace the entries at or below name with fresh ones.
+	under := func(p string) bool {
+		return name == "." || p == name || strings.HasPrefix(p, name+"/")
+	}
+	var events []Event
+	old := make(map[string][]byte)
+	entries := make([]Entry, 0, len(w.m.Entries)+len(fresh))
+	for _, e := range w.m.Entries {
+		if under(e.Path) {
+			old[e.Path] = e.Digest
+		} else {
+			entries = append(entries, e)
+		}
+	}
+	for _, e := range fresh {
+		if d, ok := old[e.Path]; !ok || !bytes.Equal(d, e.Digest) {
+			events = append(events, Event{Path: e.Path, Old: d, New: e.Digest})
+		}
+		delete(old, e.Path)
+	}
+	for p, d := range old {
+		events = append(events, Event{Path: p, Old: d})
+	}
+	entries = append(entries, fresh...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	w.m.Entries = entries
+	return events, nil
+}
+
+// lstat returns the information of name, not following symbolic links
+// if the file system can tell them.
+func (w *Watcher) lstat(name string) (fs.FileInfo, error) {
+	if rl, ok := w.fsys.(ReadLinkFS); ok {
+		return rl.Lstat(name)
+	}
+	return fs.Stat(w.fsys, name)
+}
+
+// Run updates the manifest with the paths received from changes, as
+// Update, and sends the resulting events to events, until changes is
+// closed or ctx is done. It returns the first error of an update, or of
+// ctx.
+func (w *Watcher) Run(ctx context.Context, changes <-chan string, events chan<- Event) error {
+	for {
+		var name string
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			name = n
+		}
+		evs, err := w.Update(name)
+		for _, ev := range evs {
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWatcher(t *testing.T) {
+	fsys := testFS()
+	var hashed int
+	w, err := NewWatcher(fsys, "root", &Options{New: countingHash(&hashed)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != 3 {
+		t.Fatalf("hashed %d files, want 3", hashed)
+	}
+
+	fsys["root/a.txt"] = &fstest.MapFile{Data: []byte("ALPHA"), ModTime: t0}
+	fsys["root/dir/e.txt"] = &fstest.MapFile{Data: []byte("echo"), ModTime: t0}
+	delete(fsys, "root/dir/c.txt")
+	hashed = 0
+	events, err := w.Update("a.txt", "dir", "missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != 3 {
+		t.Errorf("hashed %d files, want 3", hashed)
+	}
+	want := []Event{
+		{Path: "a.txt", Old: sum("alpha"), New: sum("ALPHA")},
+		{Path: "dir/c.txt", Old: sum("charlie")},
+		{Path: "dir/e.txt", New: sum("echo")},
+	}
+	if !equalEvents(events, want) {
+		t.Errorf("events %+v, want %+v", events, want)
+	}
+
+	rebuilt, err := Build(fsys, "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := w.Manifest(); !bytes.Equal(m.Digest(), rebuilt.Digest()) || !Diff(m, rebuilt, nil).Empty() {
+		t.Errorf("manifest %v, want %v", paths(m), paths(rebuilt))
+	}
+
+	// Unchanged files give no events.
+	if events, err := w.Update("."); err != nil || len(events) != 0 {
+		t.Errorf("Update(.) = %+v, %v", events, err)
+	}
+	if _, err := w.Update("../other"); err == nil {
+		t.Error("Update accepted a path outside the tree")
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	fsys := testFS()
+	w, err := NewWatcher(fsys, "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := make(chan string)
+	events := make(chan Event, 1)
+	done := make(chan error)
+	go func() { done <- w.Run(context.Background(), changes, events) }()
+
+	fsys["root/dir/b.txt"] = &fstest.MapFile{Data: []byte("BRAVO"), ModTime: t0}
+	changes <- "dir/b.txt"
+	if ev := <-events; !equalEvents([]Event{ev}, []Event{{Path: "dir/b.txt", Old: sum("bravo"), New: sum("BRAVO")}}) {
+		t.Errorf("event %+v", ev)
+	}
+	close(changes)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := w.Run(ctx, make(chan string), events); err != context.Canceled {
+		t.Errorf("Run with canceled context: %v", err)
+	}
+}
+
+func equalEvents(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Path != b[i].Path || !bytes.Equal(a[i].Old, b[i].Old) || !bytes.Equal(a[i].New, b[i].New) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrProof is returned by VerifyConsistency for proofs that do not prove
+// consistency.
+var ErrProof = errors.New("merkle: invalid proof")
+
+// ErrUpdated is returned by RootAt and ConsistencyProof for earlier trees
+// holding a leaf that UpdateLeaf has since replaced, whose roots the
+// Builder no longer has.
+var ErrUpdated = errors.New("merkle: leaf updated since")
+
+// ConsistencyProof returns the RFC 6962 consistency proof between the
+// tree of the first m leaves and the current tree, which shows that the
+// former is a prefix of the latter. It returns ErrIndex unless
+// 0 < m <= Len(), and ErrUpdated if one of the first m leaves has been
+// replaced with UpdateLeaf.
+func (b *Builder) ConsistencyProof(m int) ([][]byte, error) {
+	n := b.Len()
+	if m <= 0 || m > n {
+		return nil, ErrIndex
+	}
+	if b.updatedBelow(m) {
+		return nil, ErrUpdated
+	}
+	return b.subproof(m, 0, n, true), nil
+}
+
+// RootAt returns the root of the tree of the first m leaves, as it was
+// when the tree had m leaves. It returns ErrIndex unless
+// 0 <= m <= Len(), and ErrUpdated if one of the first m leaves has been
+// replaced with UpdateLeaf.
+func (b *Builder) RootAt(m int) ([]byte, error) {
+	if m < 0 || m > b.Len() {
+		return nil, ErrIndex
+	}
+	if b.updatedBelow(m) {
+		return nil, ErrUpdated
+	}
+	if m == 0 {
+		return NewBuilder().Root(), nil
+	}
+	return b.subtreeHash(0, m), nil
+}
+
+// updatedBelow reports whether one of the first m leaves has been
+// replaced with UpdateLeaf.
+func (b *Builder) updatedBelow(m int) bool {
+	return b.updated != 0 && b.updated-1 < m
+}
+
+// subproof is SUBPROOF(m, D[lo:hi], complete) of RFC 6962, section
+// 2.1.2.
+func (b *Builder) subproof(m, lo, hi int, complete bool) [][]byte {
+	if m == hi-lo {
+		if complete {
+			return nil
+		}
+		return [][]byte{b.subtreeHash(lo, hi)}
+	}
+	k := splitPoint(hi - lo)
+	if m <= k {
+		return append(b.subproof(m, lo, lo+k, complete), b.subtreeHash(lo+k, hi))
+	}
+	return append(b.subproof(m-k, lo+k, hi, false), b.subtreeHash(lo, lo+k))
+}
+
+// subtreeHash returns the root of the tree of leaves lo to hi, which is
+// stored when the range is a complete, aligned subtree.
+func (b *Builder) subtreeHash(lo, hi int) []byte {
+	size := hi - lo
+	if size&(size-1) == 0 && lo%size == 0 {
+		level := 0
+		for 1<<level < size {
+			level++
+		}
+		return b.levels[level][lo>>level]
+	}
+	k := splitPoint(size)
+	return NodeHash(b.subtreeHash(lo, lo+k), b.subtreeHash(lo+k, hi))
+}
+
+// splitPoint returns the largest power of two smaller than n, n > 1.
+func splitPoint(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// VerifyConsistency checks proof, as returned by ConsistencyProof, that
+// the tree of m leaves with root oldRoot is a prefix of the tree of n
+// leaves with root newRoot, with the algorithm of RFC 9162, section
+// 2.1.4.2. It returns ErrProof if the proof fails.
+func VerifyConsistency(m, n int, oldRoot, newRoot []byte, proof [][]byte) error {
+	switch {
+	case m <= 0 || m > n:
+		return ErrProof
+	case m == n:
+		if len(proof) != 0 || !bytes.Equal(oldRoot, newRoot) {
+			return ErrProof
+		}
+		return nil
+	}
+	if m&(m-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return ErrProof
+	}
+	fn, sn := m-1, n-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(fr, oldRoot) || !bytes.Equal(sr, newRoot) {
+		return ErrProof
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestConsistencyProof(t *testing.T) {
+	var leaves [][]byte
+	b := NewBuilder()
+	for i := 0; i < 20; i++ {
+		leaves = append(leaves, []byte(fmt.Sprint("leaf ", i)))
+		b.Add(leaves[i])
+	}
+	for n := 1; n <= 20; n++ {
+		nb := NewBuilder()
+		for _, leaf := range leaves[:n] {
+			nb.Add(leaf)
+		}
+		newRoot := nb.Root()
+		for m := 1; m <= n; m++ {
+			oldRoot, err := nb.RootAt(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := rootOf(leaves[:m]); !bytes.Equal(oldRoot, want) {
+				t.Errorf("RootAt(%d) of %d leaves = %x, want %x", m, n, oldRoot, want)
+			}
+			proof, err := nb.ConsistencyProof(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyConsistency(m, n, oldRoot, newRoot, proof); err != nil {
+				t.Errorf("%d to %d leaves: %v", m, n, err)
+			}
+			if m < n {
+				bad := rootOf(append(append([][]byte(nil), leaves[:m-1]...), []byte("forged")))
+				if VerifyConsistency(m, n, bad, newRoot, proof) == nil {
+					t.Errorf("%d to %d leaves: proof verified for a forged old root", m, n)
+				}
+				if len(proof) > 0 && VerifyConsistency(m, n, oldRoot, newRoot, proof[:len(proof)-1]) == nil {
+					t.Errorf("%d to %d leaves: truncated proof verified", m, n)
+				}
+			}
+		}
+	}
+}
+
+func TestConsistencyProofRange(t *testing.T) {
+	b := NewBuilder()
+	b.Add([]byte("leaf"))
+	for _, m := range []int{0, 2} {
+		if _, err := b.ConsistencyProof(m); err != ErrIndex {
+			t.Errorf("ConsistencyProof(%d) = %v, want ErrIndex", m, err)
+		}
+	}
+}
+
+func TestConsistencyProofUpdated(t *testing.T) {
+	b := NewBuilder()
+	for i := 0; i < 8; i++ {
+		b.Add([]byte{byte(i)})
+	}
+	if err := b.UpdateLeaf(5, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	for m := 1; m <= 8; m++ {
+		_, rootErr := b.RootAt(m)
+		_, proofErr := b.ConsistencyProof(m)
+		want := error(nil)
+		if m > 5 {
+			want = ErrUpdated
+		}
+		if rootErr != want || proofErr != want {
+			t.Errorf("m = %d: RootAt = %v, ConsistencyProof = %v, want %v", m, rootErr, proofErr, want)
+		}
+	}
+	// Trees that never held leaf 5 keep verifiable proofs.
+	oldRoot, _ := b.RootAt(5)
+	proof, _ := b.ConsistencyProof(5)
+	if err := VerifyConsistency(5, 8, oldRoot, b.Root(), proof); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package merkle builds Merkle trees of BLAKE2b-256 digests in the style
+// of RFC 6962 (Certificate Transparency):
+//
+//	leaf digest = H(0x00 || data)
+//	node digest = H(0x01 || left || right)
+//
+// A tree of n leaves splits them at the largest power of two smaller than
+// n; the root of an empty tree is the digest of the empty string.
+package merkle
+
+import (
+	"errors"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Size is the length of leaf, node and root digests.
+const Size = 32
+
+// ErrIndex is returned for leaf indexes outside the tree.
+var ErrIndex = errors.New("merkle: leaf index out of range")
+
+// Builder builds a Merkle tree incrementally. It keeps every level of the
+// tree, so that adding or changing a leaf only rehashes the path from
+// that leaf to the root.
+type Builder struct {
+	// levels[0] holds the leaf digests, and each following level the
+	// digests of the level below taken in pairs. An unpaired last node
+	// is carried up unchanged, which gives the same root as the RFC 6962
+	// split rule.
+	levels [][][]byte
+	// updated is one more than the lowest index of a leaf replaced by
+	// UpdateLeaf, or 0 if no leaf has been.
+	updated int
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{levels: [][][]byte{nil}}
+}
+
+// Len returns the number of leaves.
+func (b *Builder) Len() int {
+	return len(b.levels[0])
+}
+
+// Add appends a leaf holding data.
+func (b *Builder) Add(data []byte) {
+	b.levels[0] = append(b.levels[0], LeafHash(data))
+	b.updatePath(len(b.levels[0]) - 1)
+}
+
+// UpdateLeaf replaces the data of leaf index, recomputing only the
+// digests on the path from that leaf to the root. The earlier trees that
+// held the leaf are lost: RootAt and ConsistencyProof reject them.
+func (b *Builder) UpdateLeaf(index int, data []byte) error {
+	if index < 0 || index >= b.Len() {
+		return ErrIndex
+	}
+	b.levels[0][index] = LeafHash(data)
+	b.updatePath(index)
+	if b.updated == 0 || index < b.updated-1 {
+		b.updated = index + 1
+	}
+	return nil
+}
+
+// Root returns the root digest.
+func (b *Builder) Root() []byte {
+	top := b.levels[len(b.levels)-1]
+	if len(top) == 0 {
+		h := blake2b.New(&blake2b.Config{Size: Size})
+		return h.Sum(nil)
+	}
+	return append([]byte(nil), top[0]...)
+}
+
+// updatePath recomputes the ancestors of leaf i.
+func (b *Builder) updatePath(i int) {
+	for k := 0; len(b.levels[k]) > 1; k++ {
+		level := b.levels[k]
+		var parent []byte
+		switch {
+		case i%2 == 1:
+			parent = NodeHash(level[i-1], level[i])
+		case i+1 < len(level):
+			parent = NodeHash(level[i], level[i+1])
+		default:
+			parent = level[i]
+		}
+
+		i /= 2
+		if k+1 == len(b.levels) {
+			b.levels = append(b.levels, nil)
+		}
+		if i == len(b.levels[k+1]) {
+			b.levels[k+1] = append(b.levels[k+1], parent)
+		} else {
+			b.levels[k+1][i] = parent
+		}
+	}
+}
+
+// LeafHash returns the digest of a leaf holding data.
+func LeafHash(data []byte) []byte {
+	h := blake2b.New(&blake2b.Config{Size: Size})
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// NodeHash returns the digest of an inner node with the given children.
+func NodeHash(left, right []byte) []byte {
+	h := blake2b.New(&blake2b.Config{Size: Size})
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
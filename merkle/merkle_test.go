@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// rootOf computes the root as specified by RFC 6962, section 2.1.
+func rootOf(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return NewBuilder().Root()
+	case 1:
+		return LeafHash(leaves[0])
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return NodeHash(rootOf(leaves[:k]), rootOf(leaves[k:]))
+}
+
+func TestRoot(t *testing.T) {
+	var leaves [][]byte
+	b := NewBuilder()
+	for n := 0; n <= 33; n++ {
+		if got, want := b.Root(), rootOf(leaves); !bytes.Equal(got, want) {
+			t.Errorf("%d leaves: root %x, want %x", n, got, want)
+		}
+		leaf := []byte(fmt.Sprint("leaf ", n))
+		leaves = append(leaves, leaf)
+		b.Add(leaf)
+	}
+}
+
+func TestUpdateLeaf(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8, 13} {
+		var leaves [][]byte
+		b := NewBuilder()
+		for i := 0; i < n; i++ {
+			leaves = append(leaves, []byte(fmt.Sprint("leaf ", i)))
+			b.Add(leaves[i])
+		}
+		for i := 0; i < n; i++ {
+			leaves[i] = []byte(fmt.Sprint("new leaf ", i))
+			if err := b.UpdateLeaf(i, leaves[i]); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := b.Root(), rootOf(leaves); !bytes.Equal(got, want) {
+				t.Errorf("%d leaves, updated %d: root %x, want %x", n, i, got, want)
+			}
+		}
+		if err := b.UpdateLeaf(n, nil); err != ErrIndex {
+			t.Errorf("UpdateLeaf(%d) = %v, want ErrIndex", n, err)
+		}
+	}
+}
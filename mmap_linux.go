@@ -0,0 +1,54 @@
+package blake2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"syscall"
+)
+
+// mapWindow is the length of the file mapped at a time, which bounds the
+// address space used on 32-bit systems.
+const mapWindow = 256 << 20
+
+// mapFile writes the contents of f, of size bytes, to w from memory
+// mappings of successive windows of the file, falling back to copyFile
+// if f cannot be mapped.
+func mapFile(w io.Writer, f *os.File, size int64) (n int64, err error) {
+	// Reading pages past the end of a file truncated meanwhile raises
+	// SIGBUS, which Go code turns into a panic rather than a crash. Code
+	// outside Go, such as the C backends, would crash: w must read the
+	// mapping in Go.
+	var m []byte
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			syscall.Munmap(m)
+			err = fmt.Errorf("blake2: fault reading mapped file %s: %v", f.Name(), r)
+		}
+	}()
+	for n < size {
+		k := size - n
+		if k > mapWindow {
+			k = mapWindow
+		}
+		m, err = syscall.Mmap(int(f.Fd()), n, int(k), syscall.PROT_READ, syscall.MAP_SHARED)
+		if err != nil {
+			if n == 0 {
+				return copyFile(w, f, size)
+			}
+			return n, err
+		}
+		syscall.Madvise(m, syscall.MADV_SEQUENTIAL)
+		syscall.Madvise(m, syscall.MADV_WILLNEED)
+		_, err = w.Write(m)
+		syscall.Munmap(m)
+		m = nil
+		if err != nil {
+			return n, err
+		}
+		n += k
+	}
+	return n, nil
+}
@@ -0,0 +1,117 @@
+package blake2
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestSumMapped(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{0, 1, 5000, 3<<20 + 7} {
+		data := bytes.Repeat([]byte{byte(n), 1, 2}, n/3+1)[:n]
+		name := filepath.Join(dir, "file")
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := SumMapped(name)
+		if want := blake2b.Sum512(data); err != nil || !bytes.Equal(got, want[:]) {
+			t.Errorf("%d bytes: %x, %v, want %x", n, got, err, want)
+		}
+	}
+	if _, err := SumMapped(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing file hashed")
+	}
+	if got, err := SumMapped(os.DevNull); err != nil || !bytes.Equal(got, blake2b.New(nil).Sum(nil)) {
+		t.Errorf("%s: %x, %v", os.DevNull, got, err)
+	}
+}
+
+// truncatingWriter truncates its file, then reads what is written to it,
+// or writes it to w if set.
+type truncatingWriter struct {
+	f   *os.File
+	w   io.Writer
+	sum byte
+}
+
+func (w *truncatingWriter) Write(p []byte) (int, error) {
+	w.f.Truncate(0)
+	if w.w != nil {
+		return w.w.Write(p)
+	}
+	for _, b := range p {
+		w.sum += b
+	}
+	return len(p), nil
+}
+
+func TestMapFileTruncated(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("files are only mapped on Linux")
+	}
+	name := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(name, make([]byte, 1<<20), 0o644)
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := mapFile(&truncatingWriter{f: f}, f, 1<<20); err == nil {
+		t.Error("no error reading a truncated mapping")
+	}
+}
+
+// TestSumMappedTruncated truncates the file while it is hashed, which
+// must fail rather than crash with each backend.
+func TestSumMappedTruncated(t *testing.T) {
+	defer blake2b.SetBackend(blake2b.Backend())
+	for _, backend := range blake2b.Backends() {
+		if err := blake2b.SetBackend(backend); err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Join(t.TempDir(), "file")
+		os.WriteFile(name, bytes.Repeat([]byte{1}, 1<<20), 0o644)
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := &truncatingWriter{f: f, w: blake2b.New(nil)}
+		if _, err := hashMapped(w, f, 1<<20); err == nil {
+			t.Errorf("%s: no error hashing a truncated file", backend)
+		}
+		f.Close()
+	}
+}
+
+func benchmarkFile(b *testing.B, sum func(string) ([]byte, error)) {
+	name := filepath.Join(b.TempDir(), "file")
+	data := make([]byte, 64<<20)
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sum(name); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSumMapped(b *testing.B) {
+	benchmarkFile(b, SumMapped)
+}
+
+func BenchmarkSumRead(b *testing.B) {
+	h := blake2b.New(nil)
+	benchmarkFile(b, func(name string) ([]byte, error) {
+		r := hashFile(h, name)
+		return r.Digest, r.Err
+	})
+}
@@ -0,0 +1,176 @@
+// Package mmr implements Merkle Mountain Ranges: append-only accumulators
+// of BLAKE2b-256 digests whose appends rehash only O(log n) nodes and
+// never change existing ones, for logs, blockchains and timestamping
+// services.
+//
+// A range of n leaves is a list of perfect binary trees, the mountains,
+// one for each bit set in n, from the highest to the lowest. Leaves and
+// nodes are hashed as in package merkle:
+//
+//	leaf digest = H(0x00 || data)
+//	node digest = H(0x01 || left || right)
+//
+// and the root bags the peaks of the mountains together with the number
+// of leaves, as a 64-bit little-endian integer:
+//
+//	root = H(0x02 || n || peak_1 || ... || peak_k)
+package mmr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/merkle"
+)
+
+// Size is the length of leaf, node and root digests.
+const Size = merkle.Size
+
+var (
+	// ErrIndex is returned for leaf indexes outside the range.
+	ErrIndex = errors.New("mmr: leaf index out of range")
+	// ErrProof is returned by VerifyInclusion for proofs that do not
+	// prove inclusion.
+	ErrProof = errors.New("mmr: invalid proof")
+)
+
+// MMR is a Merkle Mountain Range.
+type MMR struct {
+	// levels[k] holds the roots of the aligned perfect subtrees of 2^k
+	// leaves, in order; the last ones of some levels are peaks.
+	levels [][][]byte
+}
+
+// New returns an empty range.
+func New() *MMR {
+	return &MMR{levels: [][][]byte{nil}}
+}
+
+// Len returns the number of leaves.
+func (m *MMR) Len() int {
+	return len(m.levels[0])
+}
+
+// Append appends a leaf holding data and returns its index. It hashes one
+// node per mountain it merges.
+func (m *MMR) Append(data []byte) int {
+	i := m.Len()
+	m.levels[0] = append(m.levels[0], merkle.LeafHash(data))
+	for k, j := 0, i; j%2 == 1; k, j = k+1, j/2 {
+		if k+1 == len(m.levels) {
+			m.levels = append(m.levels, nil)
+		}
+		level := m.levels[k]
+		m.levels[k+1] = append(m.levels[k+1], merkle.NodeHash(level[j-1], level[j]))
+	}
+	return i
+}
+
+// Peaks returns the peaks of the mountains, from the highest to the
+// lowest.
+func (m *MMR) Peaks() [][]byte {
+	n := m.Len()
+	peaks := make([][]byte, 0, bits.OnesCount(uint(n)))
+	start := 0
+	for k := len(m.levels) - 1; k >= 0; k-- {
+		if n&(1<<uint(k)) != 0 {
+			peaks = append(peaks, append([]byte(nil), m.levels[k][start>>uint(k)]...))
+			start += 1 << uint(k)
+		}
+	}
+	return peaks
+}
+
+// Root returns the root digest.
+func (m *MMR) Root() []byte {
+	return Bag(m.Len(), m.Peaks())
+}
+
+// Bag returns the root of a range of n leaves with the given peaks.
+func Bag(n int, peaks [][]byte) []byte {
+	var count [8]byte
+	binary.LittleEndian.PutUint64(count[:], uint64(n))
+	h := blake2b.New(&blake2b.Config{Size: Size})
+	h.Write([]byte{2})
+	h.Write(count[:])
+	for _, p := range peaks {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// Proof is a proof that a leaf is included in a range.
+type Proof struct {
+	// Path holds the siblings of the nodes on the path from the leaf to
+	// the peak of its mountain, from the bottom up.
+	Path [][]byte
+	// Peaks holds the peaks of the other mountains, from the highest to
+	// the lowest.
+	Peaks [][]byte
+}
+
+// mountain returns the index among the peaks of the mountain holding leaf
+// index of a range of n leaves, and its height.
+func mountain(index, n int) (peak int, height uint) {
+	start := 0
+	for k := bits.Len(uint(n)) - 1; k >= 0; k-- {
+		if n&(1<<uint(k)) == 0 {
+			continue
+		}
+		if index < start+1<<uint(k) {
+			return peak, uint(k)
+		}
+		start += 1 << uint(k)
+		peak++
+	}
+	panic("mmr: leaf index out of range")
+}
+
+// InclusionProof returns the proof that leaf index is included in the
+// current range. It returns ErrIndex unless 0 <= index < Len().
+func (m *MMR) InclusionProof(index int) (Proof, error) {
+	n := m.Len()
+	if index < 0 || index >= n {
+		return Proof{}, ErrIndex
+	}
+	peak, height := mountain(index, n)
+	var p Proof
+	for k := uint(0); k < height; k++ {
+		p.Path = append(p.Path, append([]byte(nil), m.levels[k][(index>>k)^1]...))
+	}
+	peaks := m.Peaks()
+	p.Peaks = append(peaks[:peak:peak], peaks[peak+1:]...)
+	return p, nil
+}
+
+// VerifyInclusion checks proof, as returned by InclusionProof, that leaf
+// index holding data is included in the range of n leaves with the given
+// root. It returns ErrProof if the proof fails.
+func VerifyInclusion(index, n int, data, root []byte, proof Proof) error {
+	if index < 0 || index >= n {
+		return ErrProof
+	}
+	peak, height := mountain(index, n)
+	if uint(len(proof.Path)) != height || len(proof.Peaks) != bits.OnesCount(uint(n))-1 {
+		return ErrProof
+	}
+	node := merkle.LeafHash(data)
+	for k, sibling := range proof.Path {
+		if (index>>uint(k))&1 == 1 {
+			node = merkle.NodeHash(sibling, node)
+		} else {
+			node = merkle.NodeHash(node, sibling)
+		}
+	}
+	peaks := make([][]byte, 0, len(proof.Peaks)+1)
+	peaks = append(peaks, proof.Peaks[:peak]...)
+	peaks = append(peaks, node)
+	peaks = append(peaks, proof.Peaks[peak:]...)
+	if !bytes.Equal(Bag(n, peaks), root) {
+		return ErrProof
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package mmr
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jadeydi/blake2/merkle"
+)
+
+func leaf(i int) []byte {
+	return []byte(fmt.Sprintf("leaf %d", i))
+}
+
+func TestPeaks(t *testing.T) {
+	m := New()
+	b := merkle.NewBuilder()
+	for i := 0; i < 70; i++ {
+		if got := m.Append(leaf(i)); got != i {
+			t.Fatalf("Append returned %d, want %d", got, i)
+		}
+		b.Add(leaf(i))
+	}
+	// The peaks of 70 = 64 + 4 + 2 leaves are the roots of the Merkle
+	// trees of those leaves.
+	peaks := m.Peaks()
+	bounds := [][2]int{{0, 64}, {64, 68}, {68, 70}}
+	if len(peaks) != len(bounds) {
+		t.Fatalf("%d peaks, want %d", len(peaks), len(bounds))
+	}
+	for i, r := range bounds {
+		sub := merkle.NewBuilder()
+		for j := r[0]; j < r[1]; j++ {
+			sub.Add(leaf(j))
+		}
+		if !bytes.Equal(peaks[i], sub.Root()) {
+			t.Errorf("peak %d is not the root of leaves %d to %d", i, r[0], r[1])
+		}
+	}
+	if !bytes.Equal(m.Root(), Bag(70, peaks)) {
+		t.Error("Root does not bag the peaks")
+	}
+	if bytes.Equal(Bag(1, peaks), Bag(2, peaks)) {
+		t.Error("root does not depend on the leaf count")
+	}
+}
+
+func TestInclusionProof(t *testing.T) {
+	m := New()
+	for n := 1; n <= 40; n++ {
+		m.Append(leaf(n - 1))
+		root := m.Root()
+		for i := 0; i < n; i++ {
+			p, err := m.InclusionProof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyInclusion(i, n, leaf(i), root, p); err != nil {
+				t.Errorf("n=%d, leaf %d: %v", n, i, err)
+			}
+			if VerifyInclusion(i, n, leaf(i+1), root, p) != ErrProof {
+				t.Errorf("n=%d, leaf %d: proof accepted for other data", n, i)
+			}
+			if n > 1 && VerifyInclusion((i+1)%n, n, leaf(i), root, p) != ErrProof {
+				t.Errorf("n=%d, leaf %d: proof accepted at another index", n, i)
+			}
+		}
+	}
+	if _, err := m.InclusionProof(40); err != ErrIndex {
+		t.Errorf("InclusionProof(Len()) error %v, want ErrIndex", err)
+	}
+	if VerifyInclusion(40, 40, leaf(0), m.Root(), Proof{}) != ErrProof {
+		t.Error("VerifyInclusion accepted an index out of range")
+	}
+}
@@ -0,0 +1,46 @@
+package blake2
+
+// MultiHasher feeds a single input stream into several differently
+// configured hashes at once.
+type MultiHasher struct {
+	hashers []Hasher
+}
+
+// NewMultiHasher returns a MultiHasher computing a hash for each of cfgs,
+// of the variant given by its Variant field.
+func NewMultiHasher(cfgs ...*Config) (*MultiHasher, error) {
+	m := &MultiHasher{hashers: make([]Hasher, len(cfgs))}
+	for i, cfg := range cfgs {
+		h, err := newConfigHasher(cfg)
+		if err != nil {
+			return nil, err
+		}
+		m.hashers[i] = h
+	}
+	return m, nil
+}
+
+// Write writes p to every hash. It never returns an error.
+func (m *MultiHasher) Write(p []byte) (int, error) {
+	for _, h := range m.hashers {
+		h.Write(p)
+	}
+	return len(p), nil
+}
+
+// Sums returns the digests of the data written so far, in the order of
+// the configs given to NewMultiHasher.
+func (m *MultiHasher) Sums() [][]byte {
+	sums := make([][]byte, len(m.hashers))
+	for i, h := range m.hashers {
+		sums[i] = h.Sum(nil)
+	}
+	return sums
+}
+
+// Reset resets every hash to its initial state.
+func (m *MultiHasher) Reset() {
+	for _, h := range m.hashers {
+		h.Reset()
+	}
+}
@@ -0,0 +1,42 @@
+package blake2
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMultiHasher(t *testing.T) {
+	cfgs := []*Config{
+		{Variant: BLAKE2s},
+		nil,
+		{Size: 32, Key: []byte("key")},
+	}
+	m, err := NewMultiHasher(cfgs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(m, strings.NewReader("one two three")); err != nil {
+		t.Fatal(err)
+	}
+	sums := m.Sums()
+	for i, cfg := range cfgs {
+		variant := BLAKE2b
+		if cfg != nil && cfg.Variant != 0 {
+			variant = cfg.Variant
+		}
+		h, _ := NewHasher(variant, cfg)
+		h.Write([]byte("one two three"))
+		if want := h.Sum(nil); !bytes.Equal(sums[i], want) {
+			t.Errorf("hash %d: got %x, want %x", i, sums[i], want)
+		}
+	}
+	if len(sums[0]) != 32 || len(sums[1]) != 64 {
+		t.Errorf("digest sizes %d and %d, want 32 and 64", len(sums[0]), len(sums[1]))
+	}
+
+	if _, err := NewMultiHasher(&Config{Variant: BLAKE2s, Size: 64}); err == nil {
+		t.Error("NewMultiHasher accepted an invalid config")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package multipart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	u := New()
+	u.HashPart(1, strings.NewReader("part one"))
+	u.HashPart(3, strings.NewReader("part three"))
+	b, _ := u.MarshalBinary()
+	f.Add(b)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		u := New()
+		if u.UnmarshalBinary(data) != nil {
+			return
+		}
+		if b, err := u.MarshalBinary(); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("accepted encoding does not round-trip: %x", data)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+// Package multipart computes composite checksums of objects uploaded in
+// parts, as S3 multipart uploads do: each part is hashed on its own, in
+// any order and possibly concurrently, and the composite digest is the
+// hash of the part digests in part order.
+//
+// Part digests are 32-byte BLAKE2b digests of the part contents. The
+// composite digest is the 32-byte BLAKE2b digest, personalized with
+// "blake2 multipart", of the size and digest of each part:
+//
+//	composite = H(uvarint(size1) || digest1 || uvarint(size2) || ...)
+//
+// so that objects split at different offsets have different composite
+// digests.
+package multipart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/internal/wire"
+)
+
+// DigestSize is the length of part and composite digests.
+const DigestSize = 32
+
+var magic = []byte("B2MP\x01")
+
+var (
+	// ErrMissingPart is returned by Sum when the parts are not numbered
+	// from 1 without gaps. Errors wrap it with the first missing number.
+	ErrMissingPart = errors.New("multipart: missing part")
+	// ErrPartNumber is returned for part numbers below 1.
+	ErrPartNumber = errors.New("multipart: invalid part number")
+	// ErrInvalid is returned when decoding a malformed part list.
+	ErrInvalid = errors.New("multipart: invalid encoding")
+	// ErrVersion is returned when decoding a part list encoded by a newer
+	// version of the package.
+	ErrVersion = errors.New("multipart: unsupported encoding version")
+)
+
+// Part is a hashed part of an object.
+type Part struct {
+	// Number is the position of the part, from 1.
+	Number int
+	// Size is the length of the part.
+	Size int64
+	// Digest is the digest of the part contents.
+	Digest []byte
+}
+
+// Upload collects the digests of the parts of an object. The zero value
+// has no parts. It is safe for concurrent use, so that parts can be
+// hashed as they are uploaded.
+type Upload struct {
+	mu    sync.Mutex
+	parts map[int]Part
+}
+
+// New returns an upload without parts.
+func New() *Upload {
+	return &Upload{parts: make(map[int]Part)}
+}
+
+// HashPart hashes the part number read from r until EOF, records it and
+// returns it. A part hashed again replaces the previous one, as a part
+// uploaded again does.
+func (u *Upload) HashPart(number int, r io.Reader) (Part, error) {
+	if number < 1 {
+		return Part{}, ErrPartNumber
+	}
+	h := blake2b.New(&blake2b.Config{Size: DigestSize})
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return Part{}, err
+	}
+	p := Part{Number: number, Size: n, Digest: h.Sum(nil)}
+	return p, u.Add(p)
+}
+
+// Add records a part hashed elsewhere, replacing any part of the same
+// number.
+func (u *Upload) Add(p Part) error {
+	if p.Number < 1 {
+		return ErrPartNumber
+	}
+	if len(p.Digest) != DigestSize {
+		return ErrInvalid
+	}
+	p.Digest = append([]byte(nil), p.Digest...)
+	u.mu.Lock()
+	if u.parts == nil {
+		u.parts = make(map[int]Part)
+	}
+	u.parts[p.Number] = p
+	u.mu.Unlock()
+	return nil
+}
+
+// Parts returns the recorded parts, sorted by number.
+func (u *Upload) Parts() []Part {
+	u.mu.Lock()
+	parts := make([]Part, 0, len(u.parts))
+	for _, p := range u.parts {
+		parts = append(parts, p)
+	}
+	u.mu.Unlock()
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts
+}
+
+// Size returns the total size of the recorded parts.
+func (u *Upload) Size() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var n int64
+	for _, p := range u.parts {
+		n += p.Size
+	}
+	return n
+}
+
+// Sum returns the composite digest of the parts. The parts must be
+// numbered from 1 to their count.
+func (u *Upload) Sum() ([]byte, error) {
+	parts := u.Parts()
+	h := blake2b.New(&blake2b.Config{Size: DigestSize, Personal: []byte("blake2 multipart")})
+	var tmp [binary.MaxVarintLen64]byte
+	for i, p := range parts {
+		if p.Number != i+1 {
+			return nil, fmt.Errorf("%w: %d", ErrMissingPart, i+1)
+		}
+		h.Write(tmp[:binary.PutUvarint(tmp[:], uint64(p.Size))])
+		h.Write(p.Digest)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: 1", ErrMissingPart)
+	}
+	return h.Sum(nil), nil
+}
+
+// Checksum returns the composite digest in the format of S3 multipart
+// checksums: the hexadecimal digest, a dash and the number of parts.
+func (u *Upload) Checksum() (string, error) {
+	sum, err := u.Sum()
+	if err != nil {
+		return "", err
+	}
+	u.mu.Lock()
+	n := len(u.parts)
+	u.mu.Unlock()
+	return hex.EncodeToString(sum) + "-" + strconv.Itoa(n), nil
+}
+
+// MarshalBinary encodes the part list as a magic string, the number of
+// parts as a uvarint, then the number and size of each part as uvarints
+// followed by its digest, so that an interrupted upload can be resumed
+// without hashing its parts again.
+func (u *Upload) MarshalBinary() ([]byte, error) {
+	parts := u.Parts()
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(magic)
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(len(parts)))])
+	for _, p := range parts {
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(p.Number))])
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(p.Size))])
+		buf.Write(p.Digest)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a part list encoded by MarshalBinary, replacing
+// the parts of u.
+func (u *Upload) UnmarshalBinary(data []byte) error {
+	data, version, ok := wire.Header(data, string(magic))
+	if !ok {
+		return ErrInvalid
+	}
+	if version != magic[len(magic)-1] {
+		return ErrVersion
+	}
+	count, data, ok := wire.Uvarint(data)
+	if !ok || count > uint64(len(data)) {
+		return ErrInvalid
+	}
+	parts := make(map[int]Part, count)
+	var last uint64
+	for i := uint64(0); i < count; i++ {
+		var number, size uint64
+		number, data, ok = wire.Uvarint(data)
+		// Parts are encoded in increasing order, each once.
+		if !ok || number <= last || number > 1<<31-1 {
+			return ErrInvalid
+		}
+		last = number
+		size, data, ok = wire.Uvarint(data)
+		if !ok || size > 1<<63-1 || len(data) < DigestSize {
+			return ErrInvalid
+		}
+		parts[int(number)] = Part{Number: int(number), Size: int64(size), Digest: append([]byte(nil), data[:DigestSize]...)}
+		data = data[DigestSize:]
+	}
+	if len(data) != 0 {
+		return ErrInvalid
+	}
+	u.mu.Lock()
+	u.parts = parts
+	u.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,89 @@
+package multipart
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var object = []byte(strings.Repeat("0123456789", 100))
+
+// upload hashes object in parts of size bytes, concurrently and in
+// reverse order.
+func upload(t *testing.T, size int) *Upload {
+	t.Helper()
+	u := New()
+	var wg sync.WaitGroup
+	for i := (len(object) - 1) / size; i >= 0; i-- {
+		end := (i + 1) * size
+		if end > len(object) {
+			end = len(object)
+		}
+		wg.Add(1)
+		go func(number int, part []byte) {
+			defer wg.Done()
+			if _, err := u.HashPart(number, bytes.NewReader(part)); err != nil {
+				t.Error(err)
+			}
+		}(i+1, object[i*size:end])
+	}
+	wg.Wait()
+	return u
+}
+
+func TestSum(t *testing.T) {
+	u := upload(t, 300)
+	if n := len(u.Parts()); n != 4 || u.Size() != int64(len(object)) {
+		t.Fatalf("%d parts of %d bytes", n, u.Size())
+	}
+	sum, err := u.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := upload(t, 300).Sum(); !bytes.Equal(sum, again) {
+		t.Error("composite digest depends on the order parts are hashed")
+	}
+	if other, _ := upload(t, 250).Sum(); bytes.Equal(sum, other) {
+		t.Error("different part sizes give the same composite digest")
+	}
+	checksum, err := u.Checksum()
+	if err != nil || !strings.HasSuffix(checksum, "-4") || len(checksum) != 2*DigestSize+2 {
+		t.Errorf("Checksum = %q, %v", checksum, err)
+	}
+
+	var gap Upload
+	gap.Add(u.Parts()[0])
+	gap.Add(u.Parts()[2])
+	if _, err := gap.Sum(); !errors.Is(err, ErrMissingPart) || !strings.HasSuffix(err.Error(), ": 2") {
+		t.Errorf("Sum with a gap: %v", err)
+	}
+	if _, err := New().Sum(); !errors.Is(err, ErrMissingPart) {
+		t.Errorf("Sum without parts: %v", err)
+	}
+	if _, err := u.HashPart(0, bytes.NewReader(nil)); err != ErrPartNumber {
+		t.Errorf("HashPart(0): %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	u := upload(t, 300)
+	data, err := u.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resumed Upload
+	if err := resumed.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := u.Sum()
+	if got, err := resumed.Sum(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("resumed Sum = %x, %v, want %x", got, err, want)
+	}
+	for _, bad := range [][]byte{nil, data[:len(data)-1], append(data, 0), []byte("B2MP\x01\xff")} {
+		if err := resumed.UnmarshalBinary(bad); err != ErrInvalid {
+			t.Errorf("UnmarshalBinary(%x): %v", bad, err)
+		}
+	}
+}
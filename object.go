@@ -0,0 +1,52 @@
+package blake2
+
+import (
+	"io"
+	"strconv"
+)
+
+// ObjectSize is the size of object digests: 32 bytes, as SHA-256 object
+// names in git.
+const ObjectSize = 32
+
+// SumObject returns the BLAKE2b-256 digest of content framed as a git
+// object of type objType, such as "blob", "tree" or "commit": the content
+// prefixed by the header "<type> <length>\x00".
+func SumObject(objType string, content []byte) []byte {
+	h := NewObjectHasher(objType, int64(len(content)))
+	h.Write(content)
+	return h.Sum(nil)
+}
+
+// NewObjectHasher returns a BLAKE2b-256 hash with the header of an object
+// of type objType and size bytes already written, for streaming the
+// content of the object. The caller must write exactly size bytes for the
+// digest to be that of SumObject.
+func NewObjectHasher(objType string, size int64) Hasher {
+	h, _ := NewHasher(BLAKE2b, &Config{Size: ObjectSize})
+	h.Write(objectHeader(objType, size))
+	return h
+}
+
+// SumObjectReader returns the digest of the object of type objType and
+// size bytes read from r, as SumObject. It returns ErrLength if r does not
+// hold exactly size bytes.
+func SumObjectReader(objType string, r io.Reader, size int64) ([]byte, error) {
+	h := NewObjectHasher(objType, size)
+	n, err := io.Copy(h, io.LimitReader(r, size+1))
+	switch {
+	case err != nil:
+		return nil, err
+	case n != size:
+		return nil, ErrLength
+	}
+	return h.Sum(nil), nil
+}
+
+func objectHeader(objType string, size int64) []byte {
+	b := make([]byte, 0, len(objType)+22)
+	b = append(b, objType...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, size, 10)
+	return append(b, 0)
+}
@@ -0,0 +1,35 @@
+package blake2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSumObject(t *testing.T) {
+	content := []byte("hello world\n")
+	h, _ := NewHasher(BLAKE2b, &Config{Size: 32})
+	h.Write([]byte("blob 12\x00hello world\n"))
+	want := h.Sum(nil)
+	if got := SumObject("blob", content); !bytes.Equal(got, want) {
+		t.Errorf("SumObject = %x, want %x", got, want)
+	}
+	if bytes.Equal(SumObject("tree", content), want) {
+		t.Error("object type does not change the digest")
+	}
+
+	s := NewObjectHasher("blob", 12)
+	s.Write(content[:5])
+	s.Write(content[5:])
+	if got := s.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("NewObjectHasher = %x, want %x", got, want)
+	}
+	if got, err := SumObjectReader("blob", bytes.NewReader(content), 12); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("SumObjectReader = %x, %v, want %x", got, err, want)
+	}
+	for _, size := range []int64{11, 13} {
+		if _, err := SumObjectReader("blob", strings.NewReader(string(content)), size); err != ErrLength {
+			t.Errorf("SumObjectReader with size %d: %v, want ErrLength", size, err)
+		}
+	}
+}
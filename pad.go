@@ -0,0 +1,73 @@
+package blake2
+
+// padBlock holds the zero bytes of padding.
+var padBlock [1024]byte
+
+// PaddedHasher is a Hasher that pads its input to a multiple of a
+// boundary before computing digests, for systems publishing digests of
+// messages whose exact length is secret: the blocks compressed for a
+// digest then depend only on the padded length. The padding is a 0x80
+// byte followed by as few zero bytes as fill the boundary, as in
+// ISO/IEC 7816-4, so that messages padded to the same length still have
+// different digests. A digest hides the message length within a
+// boundary, not across boundaries; the boundary should exceed the range
+// of lengths to hide.
+//
+// Padded digests differ from the digests of the unpadded messages.
+type PaddedHasher struct {
+	Hasher
+	boundary, n int64
+}
+
+// NewPaddedHasher returns a PaddedHasher padding input to a multiple of
+// boundary bytes and hashing it with h. It panics if boundary is not
+// positive.
+func NewPaddedHasher(h Hasher, boundary int) *PaddedHasher {
+	if boundary <= 0 {
+		panic("blake2: padding boundary must be positive")
+	}
+	return &PaddedHasher{Hasher: h, boundary: int64(boundary)}
+}
+
+// PaddedLen returns the length of a message of n bytes padded to a
+// multiple of boundary, which is at least n+1.
+func PaddedLen(n int64, boundary int) int64 {
+	b := int64(boundary)
+	return (n/b + 1) * b
+}
+
+func (p *PaddedHasher) Write(buf []byte) (int, error) {
+	n, err := p.Hasher.Write(buf)
+	p.n += int64(n)
+	return n, err
+}
+
+// Sum appends the digest of the input written so far, padded, to buf.
+// It does not change the underlying hash state.
+func (p *PaddedHasher) Sum(buf []byte) []byte {
+	h := p.Hasher.Clone()
+	h.Write([]byte{0x80})
+	for pad := PaddedLen(p.n, int(p.boundary)) - p.n - 1; pad > 0; {
+		k := int64(len(padBlock))
+		if pad < k {
+			k = pad
+		}
+		h.Write(padBlock[:k])
+		pad -= k
+	}
+	return h.Sum(buf)
+}
+
+// Reset resets the hash and the count of bytes written.
+func (p *PaddedHasher) Reset() {
+	p.Hasher.Reset()
+	p.n = 0
+}
+
+// Clone returns an independent copy of p, with the same boundary and
+// data written so far.
+func (p *PaddedHasher) Clone() Hasher {
+	c := *p
+	c.Hasher = p.Hasher.Clone()
+	return &c
+}
@@ -0,0 +1,62 @@
+package blake2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPaddedLen(t *testing.T) {
+	for _, tt := range []struct {
+		n    int64
+		b    int
+		want int64
+	}{
+		{0, 64, 64}, {63, 64, 64}, {64, 64, 128}, {100, 64, 128}, {5, 1, 6},
+	} {
+		if got := PaddedLen(tt.n, tt.b); got != tt.want {
+			t.Errorf("PaddedLen(%d, %d) = %d, want %d", tt.n, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPaddedHasher(t *testing.T) {
+	const boundary = 1500
+	msg := bytes.Repeat([]byte("secret"), 50)
+	h, _ := NewHasher(BLAKE2b, nil)
+	p := NewPaddedHasher(h, boundary)
+	p.Write(msg[:100])
+	c := p.Clone()
+	p.Write(msg[100:])
+	got := p.Sum(nil)
+
+	want, _ := NewHasher(BLAKE2b, nil)
+	want.Write(msg)
+	want.Write([]byte{0x80})
+	want.Write(make([]byte, boundary-len(msg)-1))
+	if !bytes.Equal(got, want.Sum(nil)) {
+		t.Error("digest differs from the digest of the padded message")
+	}
+	if !bytes.Equal(p.Sum(nil), got) {
+		t.Error("Sum changed the state")
+	}
+
+	// Messages ending in the padding bytes do not collide.
+	c.Write(msg[100:])
+	c.Write([]byte{0x80})
+	if bytes.Equal(c.Sum(nil), got) {
+		t.Error("message ending in 0x80 collides")
+	}
+
+	p.Reset()
+	p.Write(msg)
+	if !bytes.Equal(p.Sum(nil), got) {
+		t.Error("digest after Reset differs")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("boundary 0 did not panic")
+		}
+	}()
+	NewPaddedHasher(h, 0)
+}
@@ -0,0 +1,168 @@
+// Package pwhash implements salted, iterated password hashing on top of
+// BLAKE2b, with hashes encoded in the PHC string format:
+//
+//	$blake2b-pw$v=1$t=<iterations>$<salt>$<hash>
+//
+// where salt and hash are unpadded base64. The construction is
+// deliberately CPU-hard only; it is not memory-hard like Argon2 and
+// should be used with a generous iteration count.
+package pwhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+const (
+	// DefaultIterations is the work factor used by Hash.
+	DefaultIterations = 1 << 16
+	// SaltSize is the length of the random salt generated by Hash.
+	SaltSize = 16
+	// HashSize is the length of the derived hash.
+	HashSize = 32
+
+	// MaxIterations is the largest work factor Verify accepts, a few
+	// seconds of hashing, so that a tampered stored hash cannot tie it
+	// up for hours.
+	MaxIterations = 1 << 24
+	// MaxSaltSize and MaxHashSize are the longest salt and hash Verify
+	// accepts.
+	MaxSaltSize = 64
+	MaxHashSize = 64
+
+	version = 1
+	id      = "blake2b-pw"
+)
+
+var (
+	// ErrMismatch is returned by Verify when the password is wrong.
+	ErrMismatch = errors.New("pwhash: password does not match")
+	// ErrInvalidHash is returned by Verify when the encoded hash
+	// cannot be parsed.
+	ErrInvalidHash = errors.New("pwhash: invalid encoded hash")
+	// ErrLimit is returned by Verify for encoded hashes whose work
+	// factor, salt or hash exceeds MaxIterations, MaxSaltSize or
+	// MaxHashSize, and by HashIterations for more than MaxIterations.
+	ErrLimit = errors.New("pwhash: encoded hash exceeds limits")
+
+	b64 = base64.RawStdEncoding
+
+	personal = []byte("blake2b-pwhash")
+)
+
+// Hash derives a hash of password with a fresh random salt and the
+// default work factor, and returns it in PHC string format.
+func Hash(password []byte) (string, error) {
+	return HashIterations(password, DefaultIterations)
+}
+
+// HashIterations is like Hash, but with the given number of iterations,
+// at most MaxIterations.
+func HashIterations(password []byte, iterations uint32) (string, error) {
+	if iterations == 0 {
+		return "", errors.New("pwhash: iterations must be positive")
+	}
+	if iterations > MaxIterations {
+		return "", ErrLimit
+	}
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := derive(password, salt, iterations, HashSize)
+	return fmt.Sprintf("$%s$v=%d$t=%d$%s$%s", id, version, iterations,
+		b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// Verify checks password against a hash produced by Hash. It returns nil
+// on success, ErrMismatch if the password is wrong, ErrInvalidHash if
+// encoded is malformed, and ErrLimit if its parameters exceed the limits
+// above, which bound the time Verify takes on a hash read from storage
+// an attacker may have tampered with.
+func Verify(encoded string, password []byte) error {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != id {
+		return ErrInvalidHash
+	}
+	if v, ok := parseParam(parts[2], "v="); !ok || v != version {
+		return ErrInvalidHash
+	}
+	iterations, ok := parseParam(parts[3], "t=")
+	if !ok || iterations == 0 {
+		return ErrInvalidHash
+	}
+	if iterations > MaxIterations ||
+		len(parts[4]) > b64.EncodedLen(MaxSaltSize) ||
+		len(parts[5]) > b64.EncodedLen(MaxHashSize) {
+		return ErrLimit
+	}
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return ErrInvalidHash
+	}
+	want, err := b64.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return ErrInvalidHash
+	}
+
+	got := derive(password, salt, uint32(iterations), len(want))
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// parseParam parses the parameter s, made of prefix and a decimal number
+// as Hash formats it, rejecting signs, leading zeros and trailing bytes,
+// so that only canonical hashes verify.
+func parseParam(s, prefix string) (uint64, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return 0, false
+	}
+	s = s[len(prefix):]
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || strconv.FormatUint(n, 10) != s {
+		return 0, false
+	}
+	return n, true
+}
+
+// derive computes the password hash:
+//
+//	x_0 = H'(64, LE32(t) || LE32(len(salt)) || salt || LE32(len(pw)) || pw)
+//	x_i = BLAKE2b-512(x_{i-1} || LE32(i)), for 0 < i < t
+//	out = H'(size, x_{t-1})
+//
+// where H' is the variable-length BLAKE2b of Argon2 and the iterated
+// BLAKE2b instances carry a package-specific personalization.
+func derive(password, salt []byte, iterations uint32, size int) []byte {
+	var buf [4]byte
+	in := make([]byte, 0, 12+len(salt)+len(password))
+	binary.LittleEndian.PutUint32(buf[:], iterations)
+	in = append(in, buf[:]...)
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(salt)))
+	in = append(in, buf[:]...)
+	in = append(in, salt...)
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(password)))
+	in = append(in, buf[:]...)
+	in = append(in, password...)
+	x := blake2b.Long(64, in)
+
+	d := blake2b.New(&blake2b.Config{Personal: personal})
+	for i := uint32(1); i < iterations; i++ {
+		d.Reset()
+		d.Write(x)
+		binary.LittleEndian.PutUint32(buf[:], i)
+		d.Write(buf[:])
+		x = d.Sum(x[:0])
+	}
+	return blake2b.Long(size, x)
+}
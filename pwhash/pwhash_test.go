@@ -0,0 +1,50 @@
+package pwhash
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashVerify(t *testing.T) {
+	h, err := HashIterations([]byte("correct horse"), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h, "$blake2b-pw$v=1$t=100$") {
+		t.Errorf("unexpected encoding %q", h)
+	}
+	if err := Verify(h, []byte("correct horse")); err != nil {
+		t.Errorf("verify failed: %v", err)
+	}
+	if err := Verify(h, []byte("battery staple")); err != ErrMismatch {
+		t.Errorf("got %v, want ErrMismatch", err)
+	}
+	for _, encoded := range []string{
+		"$blake2b-pw$v=1$t=0$AAAA$AAAA",
+		strings.Replace(h, "v=1", "v=1x", 1),
+		strings.Replace(h, "t=100", "t=100junk", 1),
+		strings.Replace(h, "t=100", "t=0100", 1),
+		strings.Replace(h, "t=100", "t=+100", 1),
+		strings.Replace(h, "t=100", "t= 100", 1),
+		strings.Replace(h, "t=100", "x=100", 1),
+	} {
+		if err := Verify(encoded, []byte("correct horse")); err != ErrInvalidHash {
+			t.Errorf("Verify(%q) = %v, want ErrInvalidHash", encoded, err)
+		}
+	}
+
+	// Stored hashes asking for too much work are rejected before any
+	// hashing.
+	for _, encoded := range []string{
+		"$blake2b-pw$v=1$t=4294967295$AAAA$AAAA",
+		"$blake2b-pw$v=1$t=1$" + strings.Repeat("A", 200) + "$AAAA",
+		"$blake2b-pw$v=1$t=1$AAAA$" + strings.Repeat("A", 200),
+	} {
+		if err := Verify(encoded, nil); err != ErrLimit {
+			t.Errorf("Verify(%.40q) = %v, want ErrLimit", encoded, err)
+		}
+	}
+	if _, err := HashIterations(nil, MaxIterations+1); err != ErrLimit {
+		t.Errorf("HashIterations above the limit: got %v, want ErrLimit", err)
+	}
+}
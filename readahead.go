@@ -0,0 +1,101 @@
+package blake2
+
+import "io"
+
+// DefaultReadAheadChunk is the chunk size of ReadAheadHashers created
+// with a chunk size of 0.
+const DefaultReadAheadChunk = 1 << 20
+
+// ReadAheadHasher is a Hasher whose ReadFrom, which io.Copy uses, reads
+// the next chunk of the source on a separate goroutine while hashing the
+// current one, so that the latency of spinning disks and network file
+// systems, such as NFS or S3-backed mounts, overlaps with hashing instead
+// of adding to it. It holds two chunks in memory during ReadFrom.
+type ReadAheadHasher struct {
+	Hasher
+	chunkSize int
+}
+
+// NewReadAheadHasher returns a ReadAheadHasher hashing with h and reading
+// chunks of chunkSize bytes, or DefaultReadAheadChunk if chunkSize is 0.
+// Larger chunks suit sources with a high latency per request. It panics
+// if chunkSize is negative.
+func NewReadAheadHasher(h Hasher, chunkSize int) *ReadAheadHasher {
+	if chunkSize < 0 {
+		panic("blake2: negative read-ahead chunk size")
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultReadAheadChunk
+	}
+	return &ReadAheadHasher{Hasher: h, chunkSize: chunkSize}
+}
+
+// readAheadChunk is a chunk read by ReadFrom, with the error that ended
+// the read.
+type readAheadChunk struct {
+	buf []byte
+	err error
+}
+
+// ReadFrom hashes the data read from src until io.EOF, which is not
+// reported, or an error, and returns the number of bytes hashed. src is
+// no longer read once it returns.
+func (r *ReadAheadHasher) ReadFrom(src io.Reader) (int64, error) {
+	free := make(chan []byte, 2)
+	free <- make([]byte, r.chunkSize)
+	free <- make([]byte, r.chunkSize)
+	filled := make(chan readAheadChunk)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	defer func() {
+		close(done)
+		<-exited
+	}()
+	go func() {
+		defer close(exited)
+		for {
+			var buf []byte
+			select {
+			case buf = <-free:
+			case <-done:
+				return
+			}
+			n, err := io.ReadFull(src, buf)
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			select {
+			case filled <- readAheadChunk{buf[:n], err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var total int64
+	for {
+		c := <-filled
+		n, err := r.Hasher.Write(c.buf)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if c.err == io.EOF {
+			return total, nil
+		}
+		if c.err != nil {
+			return total, c.err
+		}
+		free <- c.buf[:cap(c.buf)]
+	}
+}
+
+// Clone returns an independent copy of r, with the same chunk size.
+func (r *ReadAheadHasher) Clone() Hasher {
+	c := *r
+	c.Hasher = r.Hasher.Clone()
+	return &c
+}
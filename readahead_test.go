@@ -0,0 +1,94 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// slowReader delays every Read, as a network file system would.
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (s slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	return s.r.Read(p)
+}
+
+func TestReadAheadHasher(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	h, _ := NewHasher(BLAKE2b, nil)
+	h.Write(data)
+	want := h.Sum(nil)
+
+	for _, src := range []io.Reader{
+		bytes.NewReader(data),
+		iotest.HalfReader(bytes.NewReader(data)),
+		iotest.DataErrReader(bytes.NewReader(data)),
+		slowReader{bytes.NewReader(data), time.Millisecond},
+	} {
+		h, _ := NewHasher(BLAKE2b, nil)
+		r := NewReadAheadHasher(h, 4096)
+		n, err := io.Copy(r, src)
+		if n != int64(len(data)) || err != nil || !bytes.Equal(r.Sum(nil), want) {
+			t.Errorf("%T: io.Copy = %d, %v", src, n, err)
+		}
+	}
+
+	// Chunk sizes that divide the data, and the default.
+	for _, size := range []int{0, 1000, len(data)} {
+		h, _ := NewHasher(BLAKE2b, nil)
+		r := NewReadAheadHasher(h, size)
+		if n, err := r.ReadFrom(bytes.NewReader(data)); n != int64(len(data)) || err != nil || !bytes.Equal(r.Sum(nil), want) {
+			t.Errorf("chunk size %d: ReadFrom = %d, %v", size, n, err)
+		}
+	}
+
+	fail := errors.New("read failed")
+	h, _ = NewHasher(BLAKE2b, nil)
+	r := NewReadAheadHasher(h, 4096)
+	src := io.MultiReader(bytes.NewReader(data[:5000]), iotest.ErrReader(fail))
+	if n, err := r.ReadFrom(src); n != 5000 || err != fail {
+		t.Errorf("failing source: ReadFrom = %d, %v", n, err)
+	}
+
+	// A hash error stops reading.
+	l := NewLimitedHasher(h, 10000)
+	r = NewReadAheadHasher(l, 4096)
+	if n, err := r.ReadFrom(bytes.NewReader(data)); n != 10000 || err != ErrLimit {
+		t.Errorf("limited hash: ReadFrom = %d, %v", n, err)
+	}
+	if _, ok := r.Clone().(*ReadAheadHasher); !ok {
+		t.Error("Clone does not return a ReadAheadHasher")
+	}
+}
+
+func BenchmarkReadAhead(b *testing.B) {
+	data := make([]byte, 8<<20)
+	for _, readAhead := range []bool{false, true} {
+		name := "Copy"
+		if readAhead {
+			name = "ReadAhead"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				var h Hasher
+				h, _ = NewHasher(BLAKE2b, nil)
+				if readAhead {
+					h = NewReadAheadHasher(h, 1<<20)
+				}
+				src := slowReader{bytes.NewReader(data), time.Millisecond}
+				io.CopyBuffer(h, src, make([]byte, 1<<20))
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package blake2
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// RecordScanner reads records, such as lines or length-prefixed frames,
+// like bufio.Scanner, and hashes each of them, along with the whole
+// stream, as log-shipping pipelines need to check the integrity of
+// records and of the stream they belong to.
+type RecordScanner struct {
+	s      *bufio.Scanner
+	split  bufio.SplitFunc
+	record Hasher
+	stream Hasher
+	sum    []byte
+}
+
+// NewRecordScanner returns a RecordScanner reading lines from r, as
+// bufio.ScanLines splits them. Records and the stream are hashed with
+// cfg, of the variant given by its Variant field; cfg may be nil.
+func NewRecordScanner(r io.Reader, cfg *Config) (*RecordScanner, error) {
+	record, err := newConfigHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RecordScanner{
+		s:      bufio.NewScanner(r),
+		split:  bufio.ScanLines,
+		record: record,
+		stream: record.Clone(),
+	}
+	rs.s.Split(rs.splitStream)
+	return rs, nil
+}
+
+// splitStream calls the split function and hashes the bytes it consumes
+// into the stream digest, so that it covers delimiters and frame headers
+// too, but not data read ahead.
+func (rs *RecordScanner) splitStream(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := rs.split(data, atEOF)
+	if advance > 0 && advance <= len(data) {
+		rs.stream.Write(data[:advance])
+	}
+	return advance, token, err
+}
+
+// Split sets the split function, as bufio.Scanner.Split. It panics if
+// called after scanning has started.
+func (rs *RecordScanner) Split(split bufio.SplitFunc) {
+	rs.s.Split(rs.splitStream)
+	rs.split = split
+}
+
+// Buffer sets the initial buffer and the maximum record size, as
+// bufio.Scanner.Buffer.
+func (rs *RecordScanner) Buffer(buf []byte, max int) {
+	rs.s.Buffer(buf, max)
+}
+
+// Scan advances to the next record and hashes it. It returns false at the
+// end of the input or on an error, reported by Err.
+func (rs *RecordScanner) Scan() bool {
+	if !rs.s.Scan() {
+		rs.sum = nil
+		return false
+	}
+	rs.record.Reset()
+	rs.record.Write(rs.s.Bytes())
+	rs.sum = rs.record.Sum(rs.sum[:0])
+	return true
+}
+
+// Bytes returns the current record, as bufio.Scanner.Bytes.
+func (rs *RecordScanner) Bytes() []byte {
+	return rs.s.Bytes()
+}
+
+// Text returns the current record as a string.
+func (rs *RecordScanner) Text() string {
+	return rs.s.Text()
+}
+
+// Sum returns the digest of the current record. The slice is overwritten
+// by the next call to Scan.
+func (rs *RecordScanner) Sum() []byte {
+	return rs.sum
+}
+
+// StreamSum returns the digest of the stream up to the end of the current
+// record, delimiters included. At the end of the input, it is the digest
+// of the whole stream.
+func (rs *RecordScanner) StreamSum() []byte {
+	return rs.stream.Sum(nil)
+}
+
+// Err returns the first error other than io.EOF, as bufio.Scanner.Err.
+func (rs *RecordScanner) Err() error {
+	return rs.s.Err()
+}
+
+// ErrFrame is returned by ScanFrames for input ending in a partial frame.
+var ErrFrame = errors.New("blake2: truncated frame")
+
+// ScanFrames is a split function for RecordScanner and bufio.Scanner that
+// returns frames prefixed with their length, as 4-byte big-endian
+// integers, without the prefix. Frames longer than the scanner buffer
+// fail with bufio.ErrTooLong.
+func ScanFrames(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) >= 4 {
+		n := binary.BigEndian.Uint32(data)
+		if uint64(len(data)-4) >= uint64(n) {
+			return 4 + int(n), data[4 : 4+n], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return 0, nil, ErrFrame
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,87 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestRecordScanner(t *testing.T) {
+	input := "first\nsecond\r\n\nlast"
+	cfg := &Config{Variant: BLAKE2s, Size: 16}
+	rs, err := NewRecordScanner(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := func(s string) []byte {
+		h := blake2s.New(&blake2s.Config{Size: 16})
+		h.Write([]byte(s))
+		return h.Sum(nil)
+	}
+	var records []string
+	for rs.Scan() {
+		records = append(records, rs.Text())
+		if !bytes.Equal(rs.Sum(), sum(rs.Text())) {
+			t.Errorf("record %q: digest %x", rs.Text(), rs.Sum())
+		}
+		if len(records) == 1 && !bytes.Equal(rs.StreamSum(), sum("first\n")) {
+			t.Errorf("stream digest after first record %x", rs.StreamSum())
+		}
+	}
+	if err := rs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second", "", "last"}; strings.Join(records, "|") != strings.Join(want, "|") {
+		t.Errorf("records %q, want %q", records, want)
+	}
+	if !bytes.Equal(rs.StreamSum(), sum(input)) {
+		t.Errorf("stream digest %x, want %x", rs.StreamSum(), sum(input))
+	}
+	if rs.Sum() != nil {
+		t.Error("Sum after the last record is not nil")
+	}
+}
+
+func TestScanFrames(t *testing.T) {
+	var input []byte
+	frames := []string{"alpha", "", strings.Repeat("x", 1000)}
+	for _, f := range frames {
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(f)))
+		input = append(input, n[:]...)
+		input = append(input, f...)
+	}
+	rs, err := NewRecordScanner(bytes.NewReader(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Split(ScanFrames)
+	rs.Buffer(make([]byte, 16), 2048)
+	var got []string
+	for rs.Scan() {
+		got = append(got, rs.Text())
+	}
+	if err := rs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "|") != strings.Join(frames, "|") {
+		t.Errorf("frames %q", got)
+	}
+	whole, _ := NewHasher(BLAKE2b, nil)
+	whole.Write(input)
+	if !bytes.Equal(rs.StreamSum(), whole.Sum(nil)) {
+		t.Error("stream digest does not cover the frame headers")
+	}
+
+	rs, _ = NewRecordScanner(bytes.NewReader(input[:len(input)-1]), nil)
+	rs.Split(ScanFrames)
+	rs.Buffer(nil, 2048)
+	for rs.Scan() {
+	}
+	if rs.Err() != ErrFrame {
+		t.Errorf("truncated input: %v, want ErrFrame", rs.Err())
+	}
+}
@@ -0,0 +1,88 @@
+package blake2
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrAlgorithmKey is returned by the constructors of Lookup for MAC
+// algorithms called without a key, and for other algorithms called with
+// one.
+var ErrAlgorithmKey = errors.New("blake2: key does not match algorithm")
+
+// Constructor returns a new hash of a named algorithm, keyed with key for
+// MAC algorithms, which require one; other algorithms take a nil key.
+type Constructor func(key []byte) (Hasher, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+// Algorithms of both variants are registered for each digest size of
+// their Sum functions, unkeyed as "blake2b-512" and keyed as
+// "blake2b-512-mac".
+func init() {
+	for _, a := range []struct {
+		v     Variant
+		sizes []int
+	}{
+		{BLAKE2b, []int{160, 224, 256, 384, 512}},
+		{BLAKE2s, []int{128, 160, 224, 256}},
+	} {
+		for _, bits := range a.sizes {
+			name := fmt.Sprintf("%s-%d", strings.ToLower(a.v.String()), bits)
+			Register(name, sizeConstructor(a.v, bits/8, false))
+			Register(name+"-mac", sizeConstructor(a.v, bits/8, true))
+		}
+	}
+}
+
+// sizeConstructor returns the Constructor of n-byte hashes of variant v,
+// keyed if mac is set.
+func sizeConstructor(v Variant, n int, mac bool) Constructor {
+	return func(key []byte) (Hasher, error) {
+		if (len(key) > 0) != mac {
+			return nil, ErrAlgorithmKey
+		}
+		return v.NewSize(n, key)
+	}
+}
+
+// Register makes an algorithm available by name to Lookup, so that
+// protocols and configuration files can select it. Names are not case
+// sensitive. It panics if the name is already registered.
+func Register(name string, c Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.ToLower(name)
+	if _, dup := registry[name]; dup {
+		panic("blake2: Register called twice for algorithm " + name)
+	}
+	registry[name] = c
+}
+
+// Lookup returns the constructor of the algorithm registered as name, in
+// any case, such as "blake2b-512" or "blake2s-128-mac".
+func Lookup(name string) (Constructor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[strings.ToLower(name)]
+	return c, ok
+}
+
+// Names returns the names of the registered algorithms, sorted, for
+// instance to offer in a protocol negotiation.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
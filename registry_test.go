@@ -0,0 +1,71 @@
+package blake2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestRegistry(t *testing.T) {
+	names := Names()
+	if len(names) != 18 {
+		t.Errorf("%d algorithms registered: %v", len(names), names)
+	}
+	for _, name := range names {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Lookup(%q) failed", name)
+		}
+	}
+
+	c, ok := Lookup("BLAKE2s-256")
+	if !ok {
+		t.Fatal("blake2s-256 not found")
+	}
+	h, err := c(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write([]byte("abc"))
+	if want := blake2s.Sum256([]byte("abc")); !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Errorf("blake2s-256 digest %x, want %x", h.Sum(nil), want)
+	}
+	if _, err := c([]byte("key")); err != ErrAlgorithmKey {
+		t.Errorf("blake2s-256 with a key: %v, want ErrAlgorithmKey", err)
+	}
+
+	c, _ = Lookup("blake2b-256-mac")
+	if _, err := c(nil); err != ErrAlgorithmKey {
+		t.Errorf("blake2b-256-mac without a key: %v, want ErrAlgorithmKey", err)
+	}
+	mac, err := c([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac.Write([]byte("abc"))
+	want := blake2b.NewMAC(32, []byte("key"))
+	want.Write([]byte("abc"))
+	if !bytes.Equal(mac.Sum(nil), want.Sum(nil)) {
+		t.Error("blake2b-256-mac differs from NewMAC")
+	}
+	if _, ok := Lookup("md5"); ok {
+		t.Error("Lookup found md5")
+	}
+
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "test-blake2b-8")
+		registryMu.Unlock()
+	}()
+	Register("test-blake2b-8", func(key []byte) (Hasher, error) { return BLAKE2b.NewSize(1, key) })
+	if _, ok := Lookup("Test-BLAKE2b-8"); !ok {
+		t.Error("registered algorithm not found")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	Register("blake2b-512", nil)
+}
@@ -0,0 +1,105 @@
+package blake2
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Repair brings the file mirror back in line with the file primary,
+// rewriting only the leaves of leafSize bytes whose digests differ, as a
+// bit-rot repair tool does for mirrored copies. It returns the indexes of
+// the leaves rewritten, in order.
+//
+// Two copies alone cannot tell which of them rotted: primary is taken as
+// the good source. Check it first, for instance against a root of
+// SumSections recorded when it was written, and swap the arguments if
+// only the mirror matches.
+//
+// The first pass hashes primary with SumSections. The second hashes each
+// leaf of the mirror at the same position, and copies the differing ones
+// from primary, checking the data read against its digest from the first
+// pass, so that a primary changing meanwhile fails with ErrMismatch
+// instead of spreading. It then truncates or extends the mirror to the
+// size of primary and syncs it. Tree hashing is left out of
+// builds with the blake2_notree tag, where Repair panics.
+func Repair(primary, mirror string, leafSize int64) (repaired []int, err error) {
+	src, err := os.Open(primary)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(mirror, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return nil, err
+	}
+	dstInfo, err := dst.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size, dstSize := srcInfo.Size(), dstInfo.Size()
+
+	want, _, err := SumSections(src, size, leafSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	tree := blake2b.Tree{MaxDepth: 2, LeafSize: uint32(leafSize), InnerHashSize: blake2b.MaxDigestSize}
+	buf := make([]byte, leafSize)
+	for i, sum := range want {
+		off := int64(i) * leafSize
+		last := i == len(want)-1
+		if off == size {
+			break // the single empty leaf of an empty file
+		}
+		// A leaf the mirror holds in full is hashed at the same
+		// position as in primary, so that only differing leaves are
+		// rewritten.
+		if off+leafSize <= dstSize || last && size <= dstSize {
+			have, err := sumSection(dst, size, leafSize, tree, i, last)
+			if err != nil {
+				return repaired, err
+			}
+			if bytes.Equal(have, sum) {
+				continue
+			}
+		}
+		n := size - off
+		if n > leafSize {
+			n = leafSize
+		}
+		b := buf[:n]
+		if _, err := src.ReadAt(b, off); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return repaired, err
+		}
+		tree.NodeOffset, tree.IsLastNode = uint32(i), last
+		h := blake2b.New(&blake2b.Config{Size: tree.InnerHashSize, Tree: &tree})
+		h.Write(b)
+		if !bytes.Equal(h.Sum(nil), sum) {
+			return repaired, ErrMismatch
+		}
+		if _, err := dst.WriteAt(b, off); err != nil {
+			return repaired, err
+		}
+		repaired = append(repaired, i)
+	}
+	if dstSize != size {
+		if err := dst.Truncate(size); err != nil {
+			return repaired, err
+		}
+	}
+	return repaired, dst.Sync()
+}
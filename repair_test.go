@@ -0,0 +1,74 @@
+//go:build !blake2_notree
+// +build !blake2_notree
+
+package blake2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary")
+	mirror := filepath.Join(dir, "mirror")
+	data := GenerateBytes("repair", 10000)
+	if err := os.WriteFile(primary, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rotted := func(offsets ...int) []byte {
+		b := append([]byte(nil), data...)
+		for _, off := range offsets {
+			b[off] ^= 0x40
+		}
+		return b
+	}
+	for _, tt := range []struct {
+		name   string
+		mirror []byte
+		want   []int
+	}{
+		{"identical", data, nil},
+		{"one leaf", rotted(2000), []int{1}},
+		{"first and last", rotted(0, 9999), []int{0, 9}},
+		{"shorter", data[:5000], []int{4, 5, 6, 7, 8, 9}},
+		{"longer", append(append([]byte(nil), data...), "junk"...), nil},
+		{"empty", nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	} {
+		if err := os.WriteFile(mirror, tt.mirror, 0644); err != nil {
+			t.Fatal(err)
+		}
+		repaired, err := Repair(primary, mirror, 1024)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(repaired, tt.want) {
+			t.Errorf("%s: repaired %v, want %v", tt.name, repaired, tt.want)
+		}
+		if got, _ := os.ReadFile(mirror); !bytes.Equal(got, data) {
+			t.Errorf("%s: mirror differs from primary after repair", tt.name)
+		}
+	}
+
+	// An empty primary empties the mirror.
+	if err := os.WriteFile(primary, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if repaired, err := Repair(primary, mirror, 1024); err != nil || len(repaired) != 0 {
+		t.Errorf("empty primary: repaired %v, err %v", repaired, err)
+	}
+	if fi, _ := os.Stat(mirror); fi.Size() != 0 {
+		t.Errorf("empty primary: mirror has %d bytes", fi.Size())
+	}
+
+	if _, err := Repair(primary, mirror, 0); err != ErrSectionSize {
+		t.Errorf("leaf size 0: err = %v, want ErrSectionSize", err)
+	}
+	if _, err := Repair(primary, filepath.Join(dir, "missing"), 1024); !os.IsNotExist(err) {
+		t.Errorf("missing mirror: err = %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build go1.18
+// +build go1.18
+
+package resumable
+
+import "testing"
+
+func FuzzResume(f *testing.F) {
+	h := New(nil)
+	b, _ := h.MarshalBinary()
+	f.Add(b)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if h, err := Resume(data); err == nil {
+			h.Sum(nil)
+		}
+	})
+}
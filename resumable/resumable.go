@@ -0,0 +1,182 @@
+// Package resumable hashes objects streamed from cloud storage, such as
+// the bodies of S3 or GCS GetObject responses, while copying them to a
+// destination, and resumes both after interruptions instead of starting
+// over: a failed read reopens the object at the offset reached, as a
+// ranged GET does, and the hash state can be saved, so that a process
+// restarted in the middle of a multi-gigabyte download continues hashing
+// where it stopped.
+//
+// A download saved as it goes looks like:
+//
+//	h := resumable.New(nil)
+//	_, err := h.Copy(ctx, file, open)
+//	if err != nil {
+//		state, _ := h.MarshalBinary() // store it next to file
+//	}
+//
+// and, after a restart, file is truncated to h.Offset() of the Hasher
+// returned by Resume(state), and Copy is called again.
+package resumable
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// ErrState is returned by Resume for malformed states.
+var ErrState = errors.New("resumable: invalid hash state")
+
+// OpenFunc opens the object at offset, as the GetObject call of a cloud
+// SDK with a Range header of "bytes=offset-" does.
+type OpenFunc func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// checkpointer is the digest of blake2b.New.
+type checkpointer interface {
+	hash.Hash
+	SaveCheckpoint(w io.Writer) error
+}
+
+// Hasher hashes an object with BLAKE2b as Copy streams it.
+type Hasher struct {
+	// Retries is the number of times Copy reopens the object after
+	// consecutive failures that made no progress.
+	Retries int
+	// RetryDelay is the delay before the first retry, doubled for each
+	// further consecutive one.
+	RetryDelay time.Duration
+
+	d      checkpointer
+	offset int64
+}
+
+// New returns a Hasher of the digest configured by config, which may be
+// nil, with 3 retries starting after a second.
+func New(config *blake2b.Config) *Hasher {
+	return &Hasher{Retries: 3, RetryDelay: time.Second, d: blake2b.New(config)}
+}
+
+// Resume returns a Hasher in the state saved by MarshalBinary, with the
+// retry settings of New. It returns ErrState for malformed states, and
+// blake2b.ErrVersion for states saved by a newer version of the package.
+func Resume(state []byte) (*Hasher, error) {
+	if len(state) < 8 {
+		return nil, ErrState
+	}
+	offset := int64(binary.LittleEndian.Uint64(state))
+	d, err := blake2b.ResumeFromCheckpoint(bytes.NewReader(state[8:]))
+	if err == blake2b.ErrVersion {
+		return nil, err
+	}
+	if err != nil || offset < 0 {
+		return nil, ErrState
+	}
+	return &Hasher{Retries: 3, RetryDelay: time.Second, d: d, offset: offset}, nil
+}
+
+// MarshalBinary returns the offset reached and the hash state, as a
+// blake2b checkpoint, for Resume. Like the checkpoint, it contains the
+// key of keyed digests.
+func (h *Hasher) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	var offset [8]byte
+	binary.LittleEndian.PutUint64(offset[:], uint64(h.offset))
+	b.Write(offset[:])
+	if err := h.d.SaveCheckpoint(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Offset returns the number of bytes of the object hashed so far.
+func (h *Hasher) Offset() int64 {
+	return h.offset
+}
+
+// Sum appends the digest of the bytes hashed so far to b.
+func (h *Hasher) Sum(b []byte) []byte {
+	return h.d.Sum(b)
+}
+
+// Copy opens the object at the offset reached with open, and copies it to
+// dst, hashing what dst accepts, until the end of the object. Failures
+// to open or read the object are retried as set by Retries and
+// RetryDelay; errors writing to dst and the cancellation of ctx are not.
+// It returns the number of bytes copied.
+func (h *Hasher) Copy(ctx context.Context, dst io.Writer, open OpenFunc) (int64, error) {
+	var total int64
+	failures := 0
+	for {
+		r, err := open(ctx, h.offset)
+		if err == nil {
+			var n int64
+			n, err = h.copyFrom(dst, r)
+			r.Close()
+			total += n
+			if err == nil {
+				return total, nil
+			}
+			if n > 0 {
+				failures = 0
+			}
+		}
+		var werr writeError
+		if errors.As(err, &werr) {
+			return total, werr.err
+		}
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		if failures == h.Retries {
+			return total, err
+		}
+		t := time.NewTimer(h.RetryDelay << failures)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return total, ctx.Err()
+		}
+		failures++
+	}
+}
+
+// writeError is an error writing to the destination of Copy.
+type writeError struct {
+	err error
+}
+
+func (e writeError) Error() string { return e.err.Error() }
+
+// copyFrom copies r to dst until io.EOF, hashing the bytes written.
+func (h *Hasher) copyFrom(dst io.Writer, r io.Reader) (int64, error) {
+	buf := make([]byte, 32<<10)
+	var total int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			k, werr := dst.Write(buf[:n])
+			h.d.Write(buf[:k])
+			h.offset += int64(k)
+			total += int64(k)
+			if werr == nil && k < n {
+				werr = io.ErrShortWrite
+			}
+			if werr != nil {
+				return total, writeError{werr}
+			}
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package resumable
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// flakyObject serves an object whose reads fail after every failAfter
+// bytes, as interrupted downloads do.
+type flakyObject struct {
+	data      []byte
+	failAfter int
+	opens     []int64
+}
+
+var errReset = errors.New("connection reset")
+
+func (o *flakyObject) open(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	o.opens = append(o.opens, offset)
+	r := io.Reader(bytes.NewReader(o.data[offset:]))
+	if o.failAfter > 0 && int(offset)+o.failAfter < len(o.data) {
+		r = io.MultiReader(io.LimitReader(r, int64(o.failAfter)), errReader{errReset})
+	}
+	return ioutil.NopCloser(r), nil
+}
+
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
+
+func TestCopy(t *testing.T) {
+	data := make([]byte, 200000)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	want := blake2b.Sum512(data)
+
+	obj := &flakyObject{data: data, failAfter: 70000}
+	h := New(nil)
+	h.RetryDelay = 0
+	var dst bytes.Buffer
+	n, err := h.Copy(context.Background(), &dst, obj.open)
+	if n != int64(len(data)) || err != nil || !bytes.Equal(dst.Bytes(), data) {
+		t.Fatalf("Copy = %d, %v", n, err)
+	}
+	if !bytes.Equal(h.Sum(nil), want[:]) || h.Offset() != int64(len(data)) {
+		t.Errorf("digest %x, offset %d", h.Sum(nil), h.Offset())
+	}
+	if len(obj.opens) != 3 || obj.opens[1] != 70000 || obj.opens[2] != 140000 {
+		t.Errorf("opened at %v", obj.opens)
+	}
+
+	// Without progress, retries run out.
+	opens := 0
+	fail := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		opens++
+		return nil, errReset
+	}
+	h = New(nil)
+	h.RetryDelay = 0
+	if _, err := h.Copy(context.Background(), &dst, fail); err != errReset || opens != 4 {
+		t.Errorf("Copy = %v after %d opens, want errReset after 4", err, opens)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(nil).Copy(ctx, &dst, fail); err != context.Canceled {
+		t.Errorf("canceled Copy: %v", err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	data := bytes.Repeat([]byte("object data "), 10000)
+	config := &blake2b.Config{Size: 32, Key: []byte("key")}
+	want := blake2b.New(config)
+	want.Write(data)
+
+	// The first process stops after 50000 bytes.
+	obj := &flakyObject{data: data, failAfter: 50000}
+	h := New(config)
+	h.Retries = 0
+	h.RetryDelay = 0
+	var file bytes.Buffer
+	if _, err := h.Copy(context.Background(), &file, obj.open); err != errReset {
+		t.Fatalf("interrupted Copy: %v", err)
+	}
+	state, err := h.MarshalBinary()
+	if err != nil && blake2b.Backend() == "openssl" {
+		t.Skip("backend cannot save its state")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = Resume(state)
+	if err != nil || h.Offset() != 50000 {
+		t.Fatalf("Resume = offset %d, %v", h.Offset(), err)
+	}
+	file.Truncate(int(h.Offset()))
+	obj.failAfter = 0
+	if _, err := h.Copy(context.Background(), &file, obj.open); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(h.Sum(nil), want.Sum(nil)) || !bytes.Equal(file.Bytes(), data) {
+		t.Error("resumed download differs")
+	}
+
+	for _, bad := range [][]byte{nil, state[:8], state[:len(state)-1]} {
+		if _, err := Resume(bad); err != ErrState {
+			t.Errorf("Resume of %d bytes: %v, want ErrState", len(bad), err)
+		}
+	}
+}
+
+// shortWriter accepts up to n bytes.
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		k := w.n
+		w.n = 0
+		return k, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestCopyWriteError(t *testing.T) {
+	data := make([]byte, 100000)
+	obj := &flakyObject{data: data}
+	h := New(nil)
+	if n, err := h.Copy(context.Background(), &shortWriter{n: 40000}, obj.open); n != 40000 || err == nil || len(obj.opens) != 1 {
+		t.Errorf("Copy = %d, %v after %d opens", n, err, len(obj.opens))
+	}
+	want := blake2b.Sum512(data[:40000])
+	if !bytes.Equal(h.Sum(nil), want[:]) || h.Offset() != 40000 {
+		t.Error("digest does not cover the bytes written")
+	}
+}
@@ -0,0 +1,111 @@
+// Package ring places keys on nodes by consistent hashing, as distributed
+// caches do: adding or removing a node only moves the keys of that node.
+//
+// Each node is placed on a ring of 64-bit positions at several replica
+// points, the BLAKE2s-64 digests of "node:replica" labels, and each key
+// belongs to the node of the first point at or after the digest of the
+// key, wrapping around.
+package ring
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// DefaultReplicas is the number of points per node used when New is
+// given 0.
+const DefaultReplicas = 128
+
+// Ring is a consistent hashing ring. It is not safe for concurrent use
+// while being modified.
+type Ring struct {
+	replicas int
+	points   []point
+	nodes    map[string]bool
+}
+
+type point struct {
+	pos  uint64
+	node string
+}
+
+// New returns an empty ring placing each node at replicas points; more
+// points spread the keys more evenly. If replicas is 0, DefaultReplicas
+// is used.
+func New(replicas int) *Ring {
+	if replicas < 0 {
+		panic("ring: negative number of replicas")
+	}
+	if replicas == 0 {
+		replicas = DefaultReplicas
+	}
+	return &Ring{replicas: replicas, nodes: make(map[string]bool)}
+}
+
+// Add adds nodes to the ring. Nodes already present are ignored.
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		if r.nodes[node] {
+			continue
+		}
+		r.nodes[node] = true
+		for i := 0; i < r.replicas; i++ {
+			r.points = append(r.points, point{position(node + ":" + strconv.Itoa(i)), node})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		a, b := r.points[i], r.points[j]
+		return a.pos < b.pos || (a.pos == b.pos && a.node < b.node)
+	})
+}
+
+// Remove removes node from the ring, if present.
+func (r *Ring) Remove(node string) {
+	if !r.nodes[node] {
+		return
+	}
+	delete(r.nodes, node)
+	points := r.points[:0]
+	for _, p := range r.points {
+		if p.node != node {
+			points = append(points, p)
+		}
+	}
+	r.points = points
+}
+
+// Locate returns the node key belongs to, or false if the ring is empty.
+func (r *Ring) Locate(key string) (string, bool) {
+	if len(r.points) == 0 {
+		return "", false
+	}
+	pos := position(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].pos >= pos })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].node, true
+}
+
+// Nodes returns the nodes of the ring, sorted.
+func (r *Ring) Nodes() []string {
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// position returns the position of label on the ring, its BLAKE2s-64
+// digest read as a little-endian integer.
+func position(label string) uint64 {
+	var sum [8]byte
+	h := blake2s.New(&blake2s.Config{Size: uint8(len(sum))})
+	h.Write([]byte(label))
+	h.Sum(sum[:0])
+	return binary.LittleEndian.Uint64(sum[:])
+}
@@ -0,0 +1,69 @@
+package ring
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	r := New(0)
+	if _, ok := r.Locate("key"); ok {
+		t.Error("empty ring located a key")
+	}
+	r.Add("a", "b", "c", "a")
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(r.Nodes(), want) {
+		t.Errorf("Nodes() = %v, want %v", r.Nodes(), want)
+	}
+
+	counts := map[string]int{}
+	owners := map[string]string{}
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprint("key", i)
+		node, ok := r.Locate(key)
+		if !ok {
+			t.Fatal("key not located")
+		}
+		counts[node]++
+		owners[key] = node
+	}
+	for node, c := range counts {
+		if c < 500 || c > 1500 {
+			t.Errorf("node %s got %d of 3000 keys", node, c)
+		}
+	}
+
+	// Removing a node only moves its own keys.
+	r.Remove("b")
+	for key, owner := range owners {
+		node, _ := r.Locate(key)
+		if owner != "b" && node != owner {
+			t.Errorf("key %s moved from %s to %s", key, owner, node)
+		}
+		if node == "b" {
+			t.Errorf("key %s still on removed node", key)
+		}
+	}
+
+	// Adding it back restores the placement.
+	r.Add("b")
+	for key, owner := range owners {
+		if node, _ := r.Locate(key); node != owner {
+			t.Errorf("key %s on %s, want %s", key, node, owner)
+		}
+	}
+}
+
+func TestStable(t *testing.T) {
+	a, b := New(16), New(16)
+	a.Add("x", "y", "z")
+	b.Add("z", "y", "x")
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint(i)
+		na, _ := a.Locate(key)
+		nb, _ := b.Locate(key)
+		if na != nb {
+			t.Errorf("key %s placed on %s and %s depending on insertion order", key, na, nb)
+		}
+	}
+}
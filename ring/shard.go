@@ -0,0 +1,54 @@
+package ring
+
+import (
+	"encoding/binary"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// shardPersonal separates shard digests from other uses of BLAKE2b.
+var shardPersonal = []byte("blake2 shard")
+
+// Shard returns the partition of key among n partitions, numbered from 0
+// to n-1, for services that route keys to a fixed number of shards,
+// where a Ring is not needed since n does not change. Keys are spread
+// uniformly: the partition is drawn from the BLAKE2b digest of key by
+// rejection sampling, which avoids the bias of reducing a digest modulo
+// n. It panics if n is not positive.
+func Shard(key []byte, n int) int {
+	return ShardSeeded(nil, key, n)
+}
+
+// ShardSeeded is like Shard, with the digest keyed with seed, of up to
+// blake2b.MaxKeySize bytes, so that the placement differs between seeds
+// and, for a secret seed, cannot be predicted by clients choosing keys to
+// overload a partition. It panics if n is not positive or seed is too
+// long.
+func ShardSeeded(seed, key []byte, n int) int {
+	if n <= 0 {
+		panic("ring: number of partitions must be positive")
+	}
+	if len(seed) > blake2b.MaxKeySize {
+		panic(blake2b.ErrKeySize)
+	}
+	// 64-bit words are accepted below the largest multiple of n, 2^64
+	// minus rem, so that each partition is drawn from as many of them;
+	// the digest of the digest gives more words when all of a digest
+	// are rejected.
+	rem := (^uint64(0)%uint64(n) + 1) % uint64(n)
+	max := ^uint64(0) - rem
+	var sum [blake2b.MaxDigestSize]byte
+	h := blake2b.New(&blake2b.Config{Key: seed, Personal: shardPersonal})
+	h.Write(key)
+	h.Sum(sum[:0])
+	for {
+		for i := 0; i < len(sum); i += 8 {
+			if x := binary.LittleEndian.Uint64(sum[i:]); x <= max {
+				return int(x % uint64(n))
+			}
+		}
+		h.Reset()
+		h.Write(sum[:])
+		h.Sum(sum[:0])
+	}
+}
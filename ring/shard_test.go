@@ -0,0 +1,50 @@
+package ring
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestShard(t *testing.T) {
+	const n, keys = 10, 100000
+	counts := make([]int, n)
+	for i := 0; i < keys; i++ {
+		key := []byte("key" + strconv.Itoa(i))
+		s := Shard(key, n)
+		if s < 0 || s >= n || Shard(key, n) != s {
+			t.Fatalf("Shard(%q) = %d", key, s)
+		}
+		counts[s]++
+	}
+	for s, c := range counts {
+		if c < keys/n*9/10 || c > keys/n*11/10 {
+			t.Errorf("partition %d has %d keys of %d", s, c, keys)
+		}
+	}
+
+	if Shard([]byte("x"), 1) != 0 {
+		t.Error("Shard among 1 partition is not 0")
+	}
+	// On 64-bit platforms, a quarter of the words are rejected for n
+	// just above 2^62, so the draw often uses more than one word.
+	big := int(^uint(0)>>2) + 2
+	for i := 0; i < 100; i++ {
+		if s := Shard([]byte(strconv.Itoa(i)), big); s < 0 || s >= big {
+			t.Fatalf("Shard among %d partitions = %d", big, s)
+		}
+	}
+
+	same := 0
+	for i := 0; i < 1000; i++ {
+		key := []byte(strconv.Itoa(i))
+		if ShardSeeded([]byte("seed"), key, n) == Shard(key, n) {
+			same++
+		}
+		if ShardSeeded([]byte("seed"), key, n) != ShardSeeded([]byte("seed"), key, n) {
+			t.Fatal("ShardSeeded is not deterministic")
+		}
+	}
+	if same > 200 {
+		t.Errorf("%d of 1000 keys in the same partition with a seed", same)
+	}
+}
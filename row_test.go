@@ -0,0 +1,67 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestRowHasher(t *testing.T) {
+	r, err := NewRowHasher(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := blake2b.New(nil)
+	h.Write([]byte("r\x00\x00\x00\x00\x00\x00\x00\x02" +
+		"\x00\x00\x00\x00\x00\x00\x00\x02ab" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01c"))
+	if got := r.SumRow([]string{"ab", "c"}); !bytes.Equal(got, h.Sum(nil)) {
+		t.Errorf("SumRow = %x, want %x", got, h.Sum(nil))
+	}
+
+	seen := make(map[string][]string)
+	for _, row := range [][]string{
+		nil, {""}, {"", ""}, {"ab", "c"}, {"a", "bc"}, {"a", "b", "c"}, {"abc"},
+	} {
+		sum := string(r.SumRow(row))
+		if other, ok := seen[sum]; ok {
+			t.Errorf("rows %q and %q have the same digest", row, other)
+		}
+		seen[sum] = row
+	}
+
+	// Encoders quoting differently give the same digests.
+	a, _ := csv.NewReader(strings.NewReader("id,name\n1,\"Smith, J\"\n")).ReadAll()
+	b, _ := csv.NewReader(strings.NewReader("\"id\",\"name\"\r\n\"1\",\"Smith, J\"\r\n")).ReadAll()
+	for i := range a {
+		if !bytes.Equal(r.SumRow(a[i]), r.SumRow(b[i])) {
+			t.Errorf("row %d: digests differ", i)
+		}
+	}
+
+	cols := r.SumColumns(map[string]string{"id": "1", "name": "Smith"})
+	if !bytes.Equal(cols, r.SumColumns(map[string]string{"name": "Smith", "id": "1"})) {
+		t.Error("SumColumns depends on the column order")
+	}
+	for _, other := range []map[string]string{
+		{"id": "1", "name": "Smith", "x": ""},
+		{"id": "1", "nam": "eSmith"},
+		{"name": "1", "id": "Smith"},
+	} {
+		if bytes.Equal(cols, r.SumColumns(other)) {
+			t.Errorf("columns %v have the same digest", other)
+		}
+	}
+	if bytes.Equal(r.SumColumns(nil), r.SumRow(nil)) {
+		t.Error("empty rows and column sets have the same digest")
+	}
+
+	s, err := NewRowHasher(&Config{Variant: BLAKE2s, Size: 16})
+	if err != nil || len(s.SumRow([]string{"x"})) != 16 {
+		t.Errorf("BLAKE2s RowHasher: %v", err)
+	}
+}
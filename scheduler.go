@@ -0,0 +1,146 @@
+package blake2
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// schedulerChunk is the largest piece of a Write a Scheduler admits at
+// once, so that throttled hashes do not hold the budget for long.
+const schedulerChunk = 64 << 10
+
+// Scheduler shares a hashing budget between the hashes it throttles,
+// across the process: a throughput, in bytes per second, and a number of
+// Writes in progress at once, which bounds the cgo calls of the C
+// backends. Background work, such as integrity scans, can then hash at a
+// pace that leaves CPU time to latency-sensitive traffic. It is safe for
+// concurrent use.
+//
+// The throughput is enforced with a token bucket holding a tenth of a
+// second of traffic, so that a scan starting after an idle period does
+// not burst for long.
+type Scheduler struct {
+	rate float64 // bytes per second, 0 for unlimited
+	max  float64 // bucket capacity
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	slots chan struct{} // nil for unlimited
+}
+
+// NewScheduler returns a Scheduler admitting up to bytesPerSec bytes per
+// second and concurrent Writes at once. Either limit is disabled if not
+// positive.
+func NewScheduler(bytesPerSec int64, concurrent int) *Scheduler {
+	s := &Scheduler{rate: float64(bytesPerSec)}
+	if bytesPerSec > 0 {
+		s.max = s.rate / 10
+		if s.max < schedulerChunk {
+			s.max = schedulerChunk
+		}
+		s.tokens = s.max
+		s.last = time.Now()
+	}
+	if concurrent > 0 {
+		s.slots = make(chan struct{}, concurrent)
+	}
+	return s
+}
+
+// Hasher returns h throttled by s. Its Writes hash pieces of up to 64 KiB
+// as the budget allows, blocking meanwhile; if ctx is done first, they
+// return the number of bytes hashed and the error of ctx.
+func (s *Scheduler) Hasher(ctx context.Context, h Hasher) Hasher {
+	return &scheduledHasher{Hasher: h, s: s, ctx: ctx}
+}
+
+// wait blocks until n bytes, at most the bucket capacity, can be hashed,
+// or ctx is done.
+func (s *Scheduler) wait(ctx context.Context, n int) error {
+	if s.rate <= 0 {
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	now := time.Now()
+	s.tokens += now.Sub(s.last).Seconds() * s.rate
+	if s.tokens > s.max {
+		s.tokens = s.max
+	}
+	s.last = now
+	// Take the tokens now, going into debt if needed, so that waiting
+	// hashes are served in order.
+	s.tokens -= float64(n)
+	debt := -s.tokens
+	s.mu.Unlock()
+	if debt <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(time.Duration(debt / s.rate * float64(time.Second)))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		s.tokens += float64(n)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// acquire takes a Write slot, or returns the error of ctx.
+func (s *Scheduler) acquire(ctx context.Context) error {
+	if s.slots == nil {
+		return nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) release() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+// scheduledHasher is a Hasher throttled by a Scheduler.
+type scheduledHasher struct {
+	Hasher
+	s   *Scheduler
+	ctx context.Context
+}
+
+func (h *scheduledHasher) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		k := len(p)
+		if k > schedulerChunk {
+			k = schedulerChunk
+		}
+		if err := h.s.wait(h.ctx, k); err != nil {
+			return n, err
+		}
+		if err := h.s.acquire(h.ctx); err != nil {
+			return n, err
+		}
+		h.Hasher.Write(p[:k])
+		h.s.release()
+		n += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+func (h *scheduledHasher) Clone() Hasher {
+	c := *h
+	c.Hasher = h.Hasher.Clone()
+	return &c
+}
@@ -0,0 +1,88 @@
+package blake2
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRate(t *testing.T) {
+	// The bucket holds 100 KiB at 1000 KiB/s, so 300 KiB take about 0.2s.
+	s := NewScheduler(1000<<10, 0)
+	h := s.Hasher(context.Background(), mustHasher(t))
+	data := GenerateBytes("scheduler", 300<<10)
+	start := time.Now()
+	if n, err := h.Write(data); n != len(data) || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("300 KiB at 1000 KiB/s took %v", elapsed)
+	}
+	want := mustHasher(t)
+	want.Write(data)
+	if !bytes.Equal(h.Sum(nil), want.Sum(nil)) {
+		t.Error("throttled digest differs")
+	}
+	if c := h.Clone(); !bytes.Equal(c.Sum(nil), h.Sum(nil)) {
+		t.Error("Clone differs")
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler(64<<10, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	h := s.Hasher(ctx, mustHasher(t))
+	n, err := h.Write(make([]byte, 1<<20))
+	if err != context.DeadlineExceeded || n >= 1<<20 || n%schedulerChunk != 0 {
+		t.Errorf("Write = %d, %v", n, err)
+	}
+}
+
+// countingHasher records the largest number of concurrent Writes.
+type countingHasher struct {
+	Hasher
+	cur, max *int32
+}
+
+func (c countingHasher) Write(p []byte) (int, error) {
+	n := atomic.AddInt32(c.cur, 1)
+	defer atomic.AddInt32(c.cur, -1)
+	for {
+		m := atomic.LoadInt32(c.max)
+		if n <= m || atomic.CompareAndSwapInt32(c.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return c.Hasher.Write(p)
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	s := NewScheduler(0, 2)
+	var cur, max int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := s.Hasher(context.Background(), countingHasher{mustHasher(t), &cur, &max})
+			h.Write(make([]byte, 4*schedulerChunk))
+		}()
+	}
+	wg.Wait()
+	if max > 2 {
+		t.Errorf("%d concurrent Writes, limit 2", max)
+	}
+}
+
+func mustHasher(t *testing.T) Hasher {
+	h, err := NewHasher(BLAKE2b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
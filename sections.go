@@ -0,0 +1,116 @@
+package blake2
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// ErrSectionSize is returned by SumSections for section sizes that are
+// not positive or do not fit the 32-bit leaf size and offset of the BLAKE2
+// tree, and for negative sizes.
+var ErrSectionSize = errors.New("blake2: invalid section size")
+
+// SumSections hashes the first size bytes of ra in sections of sectionSize
+// bytes, the last one possibly shorter, reading and hashing up to workers
+// sections at a time, or runtime.NumCPU() if workers is not positive. It
+// returns the digests of the sections, in order, and the root that
+// combines them.
+//
+// The sections are the leaves of a two-level BLAKE2b-512 tree: each
+// section digest is a 64-byte leaf digest at its offset, and root is the
+// digest of the tree. This is the digest written by a blake2b.Tree with
+// HashLeaves and a LeafSize of sectionSize, so it can be checked against
+// a stream, and a single section against its digest. An empty input has
+// a single empty section.
+//
+// A short read is reported as io.ErrUnexpectedEOF. Tree hashing is left
+// out of builds with the blake2_notree tag, where SumSections panics.
+func SumSections(ra io.ReaderAt, size, sectionSize int64, workers int) (sections [][]byte, root []byte, err error) {
+	if size < 0 || sectionSize <= 0 || sectionSize > 1<<32-1 {
+		return nil, nil, ErrSectionSize
+	}
+	count := size / sectionSize
+	if size%sectionSize != 0 || size == 0 {
+		count++
+	}
+	if count > 1<<32 || int64(int(count)) != count {
+		return nil, nil, ErrSectionSize
+	}
+	n := int(count)
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	tree := blake2b.Tree{MaxDepth: 2, LeafSize: uint32(sectionSize), InnerHashSize: blake2b.MaxDigestSize}
+	sections = make([][]byte, n)
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sum, err := sumSection(ra, size, sectionSize, tree, i, i == n-1)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+				sections[i] = sum
+			}
+		}()
+	}
+	for i := 0; i < n && !failed(); i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	tree.NodeDepth, tree.IsLastNode = 1, true
+	h := blake2b.New(&blake2b.Config{Tree: &tree})
+	for _, sum := range sections {
+		h.Write(sum)
+	}
+	return sections, h.Sum(nil), nil
+}
+
+// sumSection returns the leaf digest of section i of ra.
+func sumSection(ra io.ReaderAt, size, sectionSize int64, tree blake2b.Tree, i int, last bool) ([]byte, error) {
+	off := int64(i) * sectionSize
+	length := size - off
+	if length > sectionSize {
+		length = sectionSize
+	}
+	tree.NodeOffset, tree.IsLastNode = uint32(i), last
+	h := blake2b.New(&blake2b.Config{Size: tree.InnerHashSize, Tree: &tree})
+	m, err := io.Copy(h, io.NewSectionReader(ra, off, length))
+	if err != nil {
+		return nil, err
+	}
+	if m < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,78 @@
+//go:build !blake2_notree
+// +build !blake2_notree
+
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestSumSections(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, size := range []int{0, 1, 1024, 4096, 10000} {
+		for _, workers := range []int{0, 1, 3} {
+			sections, root, err := SumSections(bytes.NewReader(data), int64(size), 1024, workers)
+			if err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+			want := (size + 1023) / 1024
+			if want == 0 {
+				want = 1
+			}
+			if len(sections) != want {
+				t.Errorf("size %d: %d sections, want %d", size, len(sections), want)
+			}
+
+			// The root is the digest of the tree written as a stream.
+			h := blake2b.New(&blake2b.Config{Tree: &blake2b.Tree{
+				MaxDepth: 2, LeafSize: 1024, NodeDepth: 1, InnerHashSize: 64, HashLeaves: true,
+			}})
+			h.Write(data[:size])
+			if !bytes.Equal(root, h.Sum(nil)) {
+				t.Errorf("size %d, %d workers: root does not match the streamed tree", size, workers)
+			}
+		}
+	}
+
+	// A section digest depends only on its own data and position.
+	a, _, _ := SumSections(bytes.NewReader(data), 4096, 1024, 2)
+	data[2000]++
+	b, _, _ := SumSections(bytes.NewReader(data), 4096, 1024, 2)
+	for i := range a {
+		if changed := !bytes.Equal(a[i], b[i]); changed != (i == 1) {
+			t.Errorf("section %d: changed = %v", i, changed)
+		}
+	}
+}
+
+func TestSumSectionsErrors(t *testing.T) {
+	r := strings.NewReader("short")
+	if _, _, err := SumSections(r, 100, 16, 2); err != io.ErrUnexpectedEOF {
+		t.Errorf("short reader: err = %v", err)
+	}
+	for _, c := range []struct{ size, sectionSize int64 }{{-1, 16}, {16, 0}, {16, 1 << 32}} {
+		if _, _, err := SumSections(r, c.size, c.sectionSize, 1); !errors.Is(err, ErrSectionSize) {
+			t.Errorf("SumSections(%d, %d): err = %v", c.size, c.sectionSize, err)
+		}
+	}
+}
+
+func BenchmarkSumSections(b *testing.B) {
+	data := make([]byte, 64<<20)
+	r := bytes.NewReader(data)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, _, err := SumSections(r, int64(len(data)), 1<<20, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package blake2
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// The lseek whences that find data and holes in sparse files.
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// copyFile writes the contents of f, of size bytes, to w. The holes of
+// sparse files, such as virtual machine images, are written as zeros
+// without reading them, which saves reading gigabytes of zero pages.
+// File systems that do not report holes are read in full.
+func copyFile(w io.Writer, f *os.File, size int64) (int64, error) {
+	var n int64
+	for n < size {
+		data, err := f.Seek(n, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			// Only a hole is left.
+			data = size
+		} else if err != nil {
+			if n == 0 {
+				return io.Copy(w, f)
+			}
+			return n, err
+		}
+		if data > size {
+			data = size
+		}
+		k, err := writeZeros(w, data-n)
+		n += k
+		if err != nil || n == size {
+			return n, err
+		}
+		hole, err := f.Seek(n, seekHole)
+		if err != nil {
+			return n, err
+		}
+		if hole > size {
+			hole = size
+		}
+		if _, err := f.Seek(n, io.SeekStart); err != nil {
+			return n, err
+		}
+		k, err = io.CopyN(w, f, hole-n)
+		n += k
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// zeros is written for the holes of sparse files.
+var zeros [64 << 10]byte
+
+// writeZeros writes n zero bytes to w.
+func writeZeros(w io.Writer, n int64) (int64, error) {
+	var written int64
+	for written < n {
+		k := int64(len(zeros))
+		if n-written < k {
+			k = n - written
+		}
+		m, err := w.Write(zeros[:k])
+		written += int64(m)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,57 @@
+package blake2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+func TestCopyFileSparse(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "disk.img")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Data at the start, in the middle and at the end, with holes in
+	// between; the file also ends with a hole in a second case.
+	const size = 8 << 20
+	f.Truncate(size)
+	f.WriteAt([]byte("boot sector"), 0)
+	f.WriteAt(bytes.Repeat([]byte{1}, 100000), 3<<20+5)
+	f.WriteAt([]byte("end"), size-3)
+	f.Close()
+
+	for _, size := range []int64{size, size + 1<<20} {
+		if err := os.Truncate(name, size); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := blake2b.Sum512(data)
+
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := blake2b.New(nil)
+		n, err := copyFile(h, f, size)
+		f.Close()
+		if n != size || err != nil {
+			t.Fatalf("copyFile = %d, %v, want %d", n, err, size)
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("size %d: digest %x, want %x", size, got, want)
+		}
+
+		r := hashFile(blake2b.New(nil), name)
+		if r.Err != nil || r.Size != size || !bytes.Equal(r.Digest, want[:]) {
+			t.Errorf("size %d: hashFile = %+v", size, r)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package blake2
+
+import (
+	"bufio"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+// Stats receives the activity of hashes of both variants; see
+// blake2b.Stats. Metrics implements it.
+type Stats interface {
+	// Hashed reports n bytes hashed by the named backend, and the time
+	// taken.
+	Hashed(v Variant, backend string, n int, elapsed time.Duration)
+	// Finalized reports a digest computed by the named backend.
+	Finalized(v Variant, backend string)
+}
+
+// SetStats makes hashes of both variants report their activity to s, or
+// to nothing if s is nil.
+func SetStats(s Stats) {
+	if s == nil {
+		blake2b.SetStats(nil)
+		blake2s.SetStats(nil)
+		return
+	}
+	blake2b.SetStats(variantStats{BLAKE2b, s})
+	blake2s.SetStats(variantStats{BLAKE2s, s})
+}
+
+// variantStats adapts Stats to the interface of a variant package.
+type variantStats struct {
+	v Variant
+	s Stats
+}
+
+func (vs variantStats) Hashed(backend string, n int, elapsed time.Duration) {
+	vs.s.Hashed(vs.v, backend, n, elapsed)
+}
+
+func (vs variantStats) Finalized(backend string) {
+	vs.s.Finalized(vs.v, backend)
+}
+
+// Metrics counts the bytes hashed, the time spent and the digests
+// finalized, by variant and backend. It can be published with expvar, as
+// it implements expvar.Var, or scraped by Prometheus, as it implements
+// http.Handler. It is safe for concurrent use.
+type Metrics struct {
+	mu       sync.Mutex
+	counters map[metricsKey]*metricsCounters
+}
+
+type metricsKey struct {
+	Variant Variant
+	Backend string
+}
+
+type metricsCounters struct {
+	Bytes     uint64
+	Nanos     int64
+	Finalized uint64
+}
+
+func (m *Metrics) get(v Variant, backend string) *metricsCounters {
+	if m.counters == nil {
+		m.counters = make(map[metricsKey]*metricsCounters)
+	}
+	k := metricsKey{v, backend}
+	c := m.counters[k]
+	if c == nil {
+		c = new(metricsCounters)
+		m.counters[k] = c
+	}
+	return c
+}
+
+// Hashed implements Stats.
+func (m *Metrics) Hashed(v Variant, backend string, n int, elapsed time.Duration) {
+	m.mu.Lock()
+	c := m.get(v, backend)
+	c.Bytes += uint64(n)
+	c.Nanos += int64(elapsed)
+	m.mu.Unlock()
+}
+
+// Finalized implements Stats.
+func (m *Metrics) Finalized(v Variant, backend string) {
+	m.mu.Lock()
+	m.get(v, backend).Finalized++
+	m.mu.Unlock()
+}
+
+// metricsSample is the value of the counters of a variant and backend.
+type metricsSample struct {
+	Variant   string  `json:"variant"`
+	Backend   string  `json:"backend"`
+	Bytes     uint64  `json:"bytes"`
+	Seconds   float64 `json:"seconds"`
+	Finalized uint64  `json:"finalized"`
+}
+
+// snapshot returns the counters, sorted by variant and backend.
+func (m *Metrics) snapshot() []metricsSample {
+	m.mu.Lock()
+	samples := make([]metricsSample, 0, len(m.counters))
+	for k, c := range m.counters {
+		samples = append(samples, metricsSample{
+			Variant:   k.Variant.String(),
+			Backend:   k.Backend,
+			Bytes:     c.Bytes,
+			Seconds:   time.Duration(c.Nanos).Seconds(),
+			Finalized: c.Finalized,
+		})
+	}
+	m.mu.Unlock()
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Variant != samples[j].Variant {
+			return samples[i].Variant < samples[j].Variant
+		}
+		return samples[i].Backend < samples[j].Backend
+	})
+	return samples
+}
+
+// String implements expvar.Var, as a JSON array of the counters of each
+// variant and backend.
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.snapshot())
+	return string(b)
+}
+
+// Publish sets m as the Stats of both variants and publishes it with
+// expvar under name. Like expvar.Publish, it panics if name is taken.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, m)
+	SetStats(m)
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition
+// format, as the counters blake2_hashed_bytes_total,
+// blake2_hash_seconds_total and blake2_finalized_total labeled by
+// variant and backend.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	samples := m.snapshot()
+	bw := bufio.NewWriter(w)
+	for _, metric := range []struct {
+		name, help string
+		value      func(s metricsSample) string
+	}{
+		{"blake2_hashed_bytes_total", "Bytes hashed.", func(s metricsSample) string { return fmt.Sprint(s.Bytes) }},
+		{"blake2_hash_seconds_total", "Time spent hashing.", func(s metricsSample) string { return fmt.Sprint(s.Seconds) }},
+		{"blake2_finalized_total", "Digests finalized.", func(s metricsSample) string { return fmt.Sprint(s.Finalized) }},
+	} {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s{variant=%q,backend=%q} %s\n", metric.name, s.Variant, s.Backend, metric.value(s))
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the counters in the Prometheus text exposition format,
+// so that m can be mounted as a scrape target.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
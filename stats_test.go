@@ -0,0 +1,57 @@
+package blake2
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestMetrics(t *testing.T) {
+	m := new(Metrics)
+	m.Publish("blake2_test")
+	defer SetStats(nil)
+
+	hb, _ := NewHasher(BLAKE2b, nil)
+	hb.Write(make([]byte, 100))
+	hb.Sum(nil)
+	hs, _ := NewHasher(BLAKE2s, nil)
+	hs.Write(make([]byte, 30))
+	hs.Write(make([]byte, 12))
+	hs.Sum(nil)
+	hs.Sum(nil)
+
+	var samples []metricsSample
+	if err := json.Unmarshal([]byte(expvar.Get("blake2_test").String()), &samples); err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 ||
+		samples[0].Variant != "BLAKE2b" || samples[0].Backend != blake2b.Backend() || samples[0].Bytes != 100 || samples[0].Finalized != 1 ||
+		samples[1].Variant != "BLAKE2s" || samples[1].Backend != blake2s.Backend() || samples[1].Bytes != 42 || samples[1].Finalized != 2 {
+		t.Errorf("samples %+v", samples)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE blake2_hashed_bytes_total counter\n",
+		`blake2_hashed_bytes_total{variant="BLAKE2b",backend="` + blake2b.Backend() + `"} 100` + "\n",
+		`blake2_finalized_total{variant="BLAKE2s",backend="` + blake2s.Backend() + `"} 2` + "\n",
+		`blake2_hash_seconds_total{variant="BLAKE2s",`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("exposition lacks %q:\n%s", want, body)
+		}
+	}
+
+	SetStats(nil)
+	hb.Write(make([]byte, 100))
+	if strings.Contains(m.String(), `"bytes":200`) {
+		t.Error("activity reported after SetStats(nil)")
+	}
+}
@@ -0,0 +1,278 @@
+// Package structhash computes BLAKE2b digests of Go values, for cache
+// keys and change detection: equal values have equal digests, whatever
+// the map iteration order or struct field order.
+//
+// Values are encoded with their kind and length before hashing, so that
+// for instance []string{"ab", "c"} and []string{"a", "bc"} differ:
+//
+//   - booleans, integers, floats and complex numbers as fixed-size
+//     little-endian integers, all integers widened to 64 bits;
+//   - strings and byte slices as their length and bytes;
+//   - slices and arrays as their length and elements;
+//   - maps as their length and entries sorted by encoded key;
+//   - structs as their exported fields sorted by name, each as its name
+//     and value;
+//   - pointers and interfaces as their value, or a nil marker;
+//   - values implementing encoding.BinaryMarshaler, such as time.Time, as
+//     their binary encoding.
+//
+// A struct field tag structhash:"-" leaves a field out, and
+// structhash:"name" hashes it under another name, so that fields can be
+// renamed without changing digests.
+package structhash
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Size is the length of digests.
+const Size = 32
+
+// Encoding tags, written before each value.
+const (
+	tagNil     = 'n'
+	tagBool    = 'b'
+	tagInt     = 'i'
+	tagUint    = 'u'
+	tagFloat   = 'f'
+	tagComplex = 'c'
+	tagString  = 's'
+	tagBytes   = 'y'
+	tagList    = 'l'
+	tagMap     = 'm'
+	tagStruct  = 'S'
+	tagBinary  = 'B'
+)
+
+var binaryMarshaler = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+
+// HashValue returns the 32-byte BLAKE2b digest of the encoding of v. It
+// returns an error for values holding channels, functions, unsafe
+// pointers or cycles of pointers, maps or slices.
+func HashValue(v interface{}) ([]byte, error) {
+	h := blake2b.New(&blake2b.Config{Size: Size})
+	if err := WriteValue(h, v); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// WriteValue writes the encoding of v hashed by HashValue to w, to hash
+// it with another function.
+func WriteValue(w io.Writer, v interface{}) error {
+	e := &encoder{visiting: make(map[visit]bool)}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+type encoder struct {
+	buf      bytes.Buffer
+	visiting map[visit]bool
+}
+
+// A visit is a pointer, map or slice being encoded. The type tells apart
+// a slice from a pointer to the struct or array that holds its elements.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter records that v is being encoded, or reports a cycle if it already
+// is.
+func (e *encoder) enter(v reflect.Value) (visit, error) {
+	k := visit{v.Pointer(), v.Type()}
+	if e.visiting[k] {
+		return k, errors.New("structhash: pointer cycle")
+	}
+	e.visiting[k] = true
+	return k, nil
+}
+
+func (e *encoder) tag(t byte) {
+	e.buf.WriteByte(t)
+}
+
+func (e *encoder) uint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	e.buf.Write(b[:])
+}
+
+// float writes f, with -0 as 0 so that equal floats have equal
+// encodings.
+func (e *encoder) float(f float64) {
+	if f == 0 {
+		f = 0
+	}
+	e.uint64(math.Float64bits(f))
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uint64(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.tag(tagNil)
+		return nil
+	}
+	if v.Type().Implements(binaryMarshaler) && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		b, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		e.tag(tagBinary)
+		e.bytes(b)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.tag(tagBool)
+		if v.Bool() {
+			e.buf.WriteByte(1)
+		} else {
+			e.buf.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.tag(tagInt)
+		e.uint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.tag(tagUint)
+		e.uint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.tag(tagFloat)
+		e.float(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		e.tag(tagComplex)
+		e.float(real(v.Complex()))
+		e.float(imag(v.Complex()))
+	case reflect.String:
+		e.tag(tagString)
+		e.bytes([]byte(v.String()))
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.tag(tagBytes)
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.bytes(b)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Len() > 0 {
+			k, err := e.enter(v)
+			if err != nil {
+				return err
+			}
+			defer delete(e.visiting, k)
+		}
+		e.tag(tagList)
+		e.uint64(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.tag(tagNil)
+			return nil
+		}
+		k, err := e.enter(v)
+		if err != nil {
+			return err
+		}
+		defer delete(e.visiting, k)
+		return e.encode(v.Elem())
+	case reflect.Interface:
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("structhash: cannot hash %s", v.Type())
+	}
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	if v.Len() > 0 {
+		k, err := e.enter(v)
+		if err != nil {
+			return err
+		}
+		defer delete(e.visiting, k)
+	}
+	type entry struct{ key, value []byte }
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := &encoder{visiting: e.visiting}
+		if err := k.encode(iter.Key()); err != nil {
+			return err
+		}
+		val := &encoder{visiting: e.visiting}
+		if err := val.encode(iter.Value()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{k.buf.Bytes(), val.buf.Bytes()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	e.tag(tagMap)
+	e.uint64(uint64(len(entries)))
+	for _, en := range entries {
+		e.buf.Write(en.key)
+		e.buf.Write(en.value)
+	}
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	type field struct {
+		name  string
+		index int
+	}
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("structhash"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{name, i})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	e.tag(tagStruct)
+	e.uint64(uint64(len(fields)))
+	for _, f := range fields {
+		e.bytes([]byte(f.name))
+		if err := e.encode(v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
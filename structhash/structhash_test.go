@@ -0,0 +1,129 @@
+package structhash
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name    string
+	Age     int
+	Tags    []string
+	Extra   map[string]int
+	Created time.Time
+	Cache   []byte `structhash:"-"`
+	secret  string
+}
+
+type renamed struct {
+	FullName string `structhash:"Name"`
+	Age      int
+	Tags     []string
+	Extra    map[string]int
+	Created  time.Time
+}
+
+func mustHash(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	h, err := HashValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestHashValue(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	u := user{Name: "ann", Age: 30, Tags: []string{"a", "b"}, Extra: map[string]int{"x": 1, "y": 2, "z": 3}, Created: created}
+	h := mustHash(t, u)
+
+	same := u
+	same.Cache = []byte("ignored")
+	same.secret = "ignored"
+	same.Extra = map[string]int{"z": 3, "y": 2, "x": 1}
+	if !bytes.Equal(mustHash(t, same), h) {
+		t.Error("skipped fields or map order change the digest")
+	}
+	if !bytes.Equal(mustHash(t, &u), h) {
+		t.Error("pointer and value have different digests")
+	}
+	r := renamed{FullName: "ann", Age: 30, Tags: []string{"a", "b"}, Extra: u.Extra, Created: created}
+	if !bytes.Equal(mustHash(t, r), h) {
+		t.Error("renamed field changes the digest")
+	}
+
+	for _, change := range []func(*user){
+		func(u *user) { u.Age++ },
+		func(u *user) { u.Tags = []string{"ab"} },
+		func(u *user) { u.Tags = nil },
+		func(u *user) { u.Extra["x"] = 0 },
+		func(u *user) { u.Created = u.Created.Add(time.Second) },
+	} {
+		c := u
+		c.Extra = map[string]int{"x": 1, "y": 2, "z": 3}
+		change(&c)
+		if bytes.Equal(mustHash(t, c), h) {
+			t.Errorf("changed value %+v has the same digest", c)
+		}
+	}
+}
+
+func TestFraming(t *testing.T) {
+	pairs := [][2]interface{}{
+		{[]string{"ab", "c"}, []string{"a", "bc"}},
+		{int64(1), uint64(1)},
+		{"1", 1},
+		{[]byte("x"), "x"},
+		{nil, []int{}},
+	}
+	for _, p := range pairs {
+		if bytes.Equal(mustHash(t, p[0]), mustHash(t, p[1])) {
+			t.Errorf("%#v and %#v have the same digest", p[0], p[1])
+		}
+	}
+	if !bytes.Equal(mustHash(t, int8(5)), mustHash(t, 5)) {
+		t.Error("integer width changes the digest")
+	}
+	if !bytes.Equal(mustHash(t, math.Copysign(0, -1)), mustHash(t, 0.0)) {
+		t.Error("-0 and 0 have different digests")
+	}
+	if !bytes.Equal(mustHash(t, complex(math.Copysign(0, -1), 1)), mustHash(t, complex(0, 1))) {
+		t.Error("complex -0 and 0 have different digests")
+	}
+}
+
+func TestUnhashable(t *testing.T) {
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	m := map[string]interface{}{}
+	m["self"] = m
+	l := []interface{}{nil}
+	l[0] = l
+	for _, v := range []interface{}{make(chan int), func() {}, n, m, l} {
+		if _, err := HashValue(v); err == nil {
+			t.Errorf("%T accepted", v)
+		}
+	}
+	// Shared pointers without cycles are fine.
+	shared := &node{}
+	if _, err := HashValue([]*node{shared, shared}); err != nil {
+		t.Error(err)
+	}
+	tags := []string{"a"}
+	if _, err := HashValue([][]string{tags, tags}); err != nil {
+		t.Error(err)
+	}
+	// A slice of the array that holds it is not a cycle.
+	type holder struct {
+		A [2]int
+		S []int
+	}
+	h := &holder{}
+	h.S = h.A[:]
+	if _, err := HashValue(h); err != nil {
+		t.Error(err)
+	}
+}
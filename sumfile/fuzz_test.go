@@ -0,0 +1,23 @@
+//go:build go1.18
+// +build go1.18
+
+package sumfile
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzReader(f *testing.F) {
+	f.Add("0123abcd  file.txt\nBLAKE2b-256 (other) = 00ff\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		r := NewReader(strings.NewReader(s))
+		for i := 0; i < 1000; i++ {
+			e, err := r.Next()
+			if err != nil {
+				return
+			}
+			e.NewHash()
+		}
+	})
+}
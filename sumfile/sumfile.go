@@ -0,0 +1,268 @@
+// Package sumfile reads and writes checksum list files, as produced by
+// b2sum and similar tools, and verifies the files they list.
+//
+// Two line formats are supported: the GNU format,
+//
+//	<hex digest>  <path>
+//
+// where a '*' may replace the second space to mark binary mode, and the
+// BSD tagged format,
+//
+//	BLAKE2b-256 (<path>) = <hex digest>
+//
+// Untagged digests are BLAKE2b, with the digest length given by their
+// hexadecimal encoding, as with b2sum --length.
+//
+// Lists of MACs, keyed digests, have the same formats; the key is given
+// separately to NewKeyedHash and VerifyKeyed.
+package sumfile
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jadeydi/blake2"
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+var (
+	// ErrMismatch is reported for files whose digest does not match.
+	ErrMismatch = errors.New("sumfile: digest mismatch")
+	// ErrAlgorithm is returned for unknown algorithm tags.
+	ErrAlgorithm = errors.New("sumfile: unknown algorithm")
+	// ErrKey is returned for keys too long for the algorithm of an entry.
+	ErrKey = errors.New("sumfile: key too long for algorithm")
+)
+
+// Entry is a line of a checksum list.
+type Entry struct {
+	// Algorithm is the algorithm tag, such as "BLAKE2b" or
+	// "BLAKE2s-128". It is empty for untagged lines.
+	Algorithm string
+	// Path is the file name.
+	Path string
+	// Digest is the expected digest.
+	Digest []byte
+}
+
+// NewHash returns a new hash for the algorithm of e: BLAKE2b or BLAKE2s,
+// with a digest length given by a "-<bits>" suffix, or else by the length
+// of e.Digest.
+func (e Entry) NewHash() (hash.Hash, error) {
+	return e.NewKeyedHash(nil)
+}
+
+// NewKeyedHash is like NewHash, but returns a hash keyed with key, for
+// lists of MACs rather than plain checksums. A nil or empty key gives an
+// unkeyed hash. It returns ErrKey if key is too long for the algorithm.
+func (e Entry) NewKeyedHash(key []byte) (hash.Hash, error) {
+	name := e.Algorithm
+	if name == "" {
+		name = "BLAKE2b"
+	}
+	size := len(e.Digest)
+	if i := strings.IndexByte(name, '-'); i >= 0 {
+		bits, err := strconv.Atoi(name[i+1:])
+		if err != nil || bits <= 0 || bits%8 != 0 {
+			return nil, ErrAlgorithm
+		}
+		name, size = name[:i], bits/8
+	}
+	switch {
+	case name == "BLAKE2b" && size >= 1 && size <= 64:
+		if len(key) > blake2b.MaxKeySize {
+			return nil, ErrKey
+		}
+		return blake2b.New(&blake2b.Config{Size: uint8(size), Key: key}), nil
+	case name == "BLAKE2s" && size >= 1 && size <= 32:
+		if len(key) > blake2s.MaxKeySize {
+			return nil, ErrKey
+		}
+		return blake2s.New(&blake2s.Config{Size: uint8(size), Key: key}), nil
+	}
+	return nil, ErrAlgorithm
+}
+
+// Writer writes checksum lists.
+type Writer struct {
+	w      io.Writer
+	tagged bool
+}
+
+// NewWriter returns a Writer writing GNU format lines, or BSD tagged
+// lines if tagged is set.
+func NewWriter(w io.Writer, tagged bool) *Writer {
+	return &Writer{w: w, tagged: tagged}
+}
+
+// Write writes the line for e. In the GNU format, paths holding a newline
+// or a backslash are escaped, and the line starts with a backslash.
+func (w *Writer) Write(e Entry) error {
+	var err error
+	if w.tagged {
+		alg := e.Algorithm
+		if alg == "" {
+			alg = fmt.Sprintf("BLAKE2b-%d", 8*len(e.Digest))
+		}
+		_, err = fmt.Fprintf(w.w, "%s (%s) = %x\n", alg, e.Path, e.Digest)
+	} else {
+		prefix, path := "", e.Path
+		if strings.ContainsAny(path, "\\\n") {
+			prefix = "\\"
+			path = strings.NewReplacer("\\", "\\\\", "\n", "\\n").Replace(path)
+		}
+		_, err = fmt.Fprintf(w.w, "%s%x  %s\n", prefix, e.Digest, path)
+	}
+	return err
+}
+
+// Reader reads checksum lists.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{s: bufio.NewScanner(r)}
+}
+
+// Next returns the next entry. Blank lines and lines starting with '#'
+// are skipped. At the end of the list, it returns io.EOF.
+func (r *Reader) Next() (Entry, error) {
+	for r.s.Scan() {
+		r.line++
+		line := strings.TrimSuffix(r.s.Text(), "\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		e, ok := parse(line)
+		if !ok {
+			return Entry{}, fmt.Errorf("sumfile: line %d: malformed", r.line)
+		}
+		return e, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return Entry{}, err
+	}
+	return Entry{}, io.EOF
+}
+
+func parse(line string) (Entry, bool) {
+	// BSD: ALG (path) = hex
+	if i := strings.Index(line, " ("); i > 0 && !strings.Contains(line[:i], " ") {
+		j := strings.LastIndex(line, ") = ")
+		if j > i {
+			d, err := hex.DecodeString(line[j+4:])
+			if err != nil || len(d) == 0 {
+				return Entry{}, false
+			}
+			return Entry{Algorithm: line[:i], Path: line[i+2 : j], Digest: d}, true
+		}
+	}
+
+	// GNU: [\]hex  path, or hex *path
+	escaped := line[0] == '\\'
+	if escaped {
+		line = line[1:]
+	}
+	i := strings.IndexByte(line, ' ')
+	if i <= 0 || i+2 > len(line) || (line[i+1] != ' ' && line[i+1] != '*') {
+		return Entry{}, false
+	}
+	d, err := hex.DecodeString(line[:i])
+	if err != nil {
+		return Entry{}, false
+	}
+	path := line[i+2:]
+	if escaped {
+		path = strings.NewReplacer("\\\\", "\\", "\\n", "\n").Replace(path)
+	}
+	return Entry{Path: path, Digest: d}, true
+}
+
+// Result is the outcome of verifying an entry.
+type Result struct {
+	Entry
+	// Err is nil if the file matches, ErrMismatch if it does not, or
+	// the error that prevented checking it.
+	Err error
+}
+
+// Verify reads the checksum list r and checks each listed file, opened
+// in fsys (paths must therefore be relative and slash-separated; use
+// os.DirFS to check files on disk), calling fn with the result as soon
+// as it is known. It returns the number of entries that failed, and an
+// error only if the list itself cannot be read.
+func Verify(r io.Reader, fsys fs.FS, fn func(Result)) (failed int, err error) {
+	return VerifyKeyed(r, fsys, nil, fn)
+}
+
+// VerifyKeyed is like Verify for a list of MACs computed with key, as
+// NewKeyedHash computes them.
+func VerifyKeyed(r io.Reader, fsys fs.FS, key []byte, fn func(Result)) (failed int, err error) {
+	list := NewReader(r)
+	for {
+		e, err := list.Next()
+		if err == io.EOF {
+			return failed, nil
+		}
+		if err != nil {
+			return failed, err
+		}
+		res := Result{Entry: e, Err: check(fsys, e, key)}
+		if res.Err != nil {
+			failed++
+		}
+		fn(res)
+	}
+}
+
+func check(fsys fs.FS, e Entry, key []byte) error {
+	h, err := e.NewKeyedHash(key)
+	if err != nil {
+		return err
+	}
+	if h.Size() != len(e.Digest) {
+		return ErrMismatch
+	}
+	f, err := fsys.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if !blake2.Equal(h.Sum(nil), e.Digest) {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// LoadKey returns the key given by arg, as command-line tools take it:
+// the contents of the file named arg if there is one, and otherwise arg
+// decoded from hexadecimal.
+func LoadKey(arg string) ([]byte, error) {
+	key, err := os.ReadFile(arg)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	key, err = hex.DecodeString(arg)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("sumfile: key %q is neither a file nor hexadecimal", arg)
+	}
+	return key, nil
+}
@@ -0,0 +1,152 @@
+package sumfile
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Path: "a.txt", Digest: []byte{1, 2, 3, 4}},
+		{Path: "dir/with space", Digest: []byte{5, 6}},
+		{Path: "odd\\name\nwith newline", Digest: []byte{7}},
+	}
+	for _, tagged := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, tagged)
+		for _, e := range entries {
+			if tagged && strings.Contains(e.Path, "\n") {
+				continue
+			}
+			if err := w.Write(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r := NewReader(&buf)
+		for _, want := range entries {
+			if tagged {
+				if strings.Contains(want.Path, "\n") {
+					continue
+				}
+				want.Algorithm = "BLAKE2b-" + map[int]string{4: "32", 2: "16", 1: "8"}[len(want.Digest)]
+			}
+			got, err := r.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("tagged=%v: got %+v, want %+v", tagged, got, want)
+			}
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("tagged=%v: got %v at the end, want io.EOF", tagged, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	list := "# comment\n\n0102 *binary.bin\r\nBLAKE2s (file (1)) = 0a0b\nnot a checksum line\n"
+	r := NewReader(strings.NewReader(list))
+	for _, want := range []Entry{
+		{Path: "binary.bin", Digest: []byte{1, 2}},
+		{Algorithm: "BLAKE2s", Path: "file (1)", Digest: []byte{10, 11}},
+	} {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("malformed line: got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	fsys := fstest.MapFS{
+		"good":    {Data: []byte("good data")},
+		"bad":     {Data: []byte("tampered")},
+		"short-s": {Data: []byte("blake2s data")},
+	}
+	b := blake2b.New(nil)
+	b.Write([]byte("good data"))
+	s := blake2s.New(&blake2s.Config{Size: 16})
+	s.Write([]byte("blake2s data"))
+
+	var list bytes.Buffer
+	w := NewWriter(&list, false)
+	w.Write(Entry{Path: "good", Digest: b.Sum(nil)})
+	w.Write(Entry{Path: "bad", Digest: b.Sum(nil)})
+	w.Write(Entry{Path: "missing", Digest: b.Sum(nil)})
+	NewWriter(&list, true).Write(Entry{Algorithm: "BLAKE2s-128", Path: "short-s", Digest: s.Sum(nil)})
+
+	results := make(map[string]error)
+	failed, err := Verify(&list, fsys, func(r Result) { results[r.Path] = r.Err })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 2 || results["good"] != nil || results["bad"] != ErrMismatch ||
+		results["missing"] == nil || results["short-s"] != nil {
+		t.Errorf("%d failed: %v", failed, results)
+	}
+}
+
+func TestVerifyKeyed(t *testing.T) {
+	fsys := fstest.MapFS{"data": {Data: []byte("file data")}}
+	key := []byte("manifest key")
+	e := Entry{Algorithm: "BLAKE2s-256", Path: "data"}
+	h, err := e.NewKeyedHash(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write([]byte("file data"))
+	e.Digest = h.Sum(nil)
+
+	var list bytes.Buffer
+	NewWriter(&list, true).Write(e)
+	for _, c := range []struct {
+		key    []byte
+		failed int
+	}{
+		{key, 0},
+		{[]byte("other key"), 1},
+		{nil, 1},
+	} {
+		failed, err := VerifyKeyed(bytes.NewReader(list.Bytes()), fsys, c.key, func(Result) {})
+		if err != nil || failed != c.failed {
+			t.Errorf("key %q: %d failed, %v; want %d", c.key, failed, err, c.failed)
+		}
+	}
+
+	if _, err := e.NewKeyedHash(make([]byte, 33)); err != ErrKey {
+		t.Errorf("33-byte BLAKE2s key: %v, want ErrKey", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	if key, err := LoadKey("6b6579"); err != nil || string(key) != "key" {
+		t.Errorf("hex key: %q, %v", key, err)
+	}
+	name := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(name, []byte("file key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := LoadKey(name); err != nil || string(key) != "file key" {
+		t.Errorf("key file: %q, %v", key, err)
+	}
+	if _, err := LoadKey("not hex"); err == nil {
+		t.Error("LoadKey accepted an invalid key")
+	}
+}
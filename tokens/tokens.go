@@ -0,0 +1,120 @@
+// Package tokens authenticates opaque payloads, such as session cookies
+// or API tokens, with keyed BLAKE2b, and supports rotating the key.
+//
+// A token is the ID of the key it was signed with, the payload and the
+// tag, separated by dots, the payload and the tag in unpadded URL-safe
+// base64:
+//
+//	id.base64(payload).base64(tag)
+//
+// The tag is the 32-byte BLAKE2b MAC, personalized for tokens, of
+// everything before the last dot, so that the key ID is authenticated
+// too. Payloads are not encrypted: anyone holding a token can read them.
+package tokens
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// TagSize is the length of token tags.
+const TagSize = 32
+
+var personal = []byte("blake2 tokens")
+
+var encoding = base64.RawURLEncoding
+
+var (
+	// ErrInvalid is returned by Signer.Verify for malformed tokens and
+	// tokens that do not match their tag.
+	ErrInvalid = errors.New("tokens: invalid token")
+	// ErrUnknownKey is returned by Signer.Verify for tokens signed with a
+	// key that is neither the current key nor a previous one.
+	ErrUnknownKey = errors.New("tokens: unknown key")
+	// ErrKey is returned by NewSigner for keys with an empty secret, an
+	// empty ID or one containing a dot, and for duplicate IDs.
+	ErrKey = errors.New("tokens: invalid key")
+)
+
+// Key is a signing key.
+type Key struct {
+	// ID names the key in the tokens it signs. It must not be empty or
+	// contain a dot, and should be short.
+	ID string
+	// Secret is the MAC key, up to blake2b.MaxKeySize bytes; 32 random
+	// bytes are plenty.
+	Secret []byte
+}
+
+// Signer signs tokens with its current key and verifies tokens signed
+// with its current or previous keys. It is safe for concurrent use.
+type Signer struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewSigner returns a Signer signing with current and also accepting
+// tokens signed with previous keys. To rotate keys, make a new Signer
+// with the new key as current and the old one among the previous keys,
+// and drop the old key once the tokens it signed have expired.
+func NewSigner(current Key, previous ...Key) (*Signer, error) {
+	s := &Signer{current: current.ID, keys: make(map[string][]byte, 1+len(previous))}
+	for _, k := range append([]Key{current}, previous...) {
+		if k.ID == "" || strings.Contains(k.ID, ".") || len(k.Secret) == 0 {
+			return nil, ErrKey
+		}
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, ErrKey
+		}
+		if len(k.Secret) > blake2b.MaxKeySize {
+			return nil, blake2b.ErrKeySize
+		}
+		s.keys[k.ID] = append([]byte(nil), k.Secret...)
+	}
+	return s, nil
+}
+
+// tag returns the tag of signed with key.
+func tag(key []byte, signed string) []byte {
+	h := blake2b.New(&blake2b.Config{Size: TagSize, Key: key, Personal: personal})
+	h.Write([]byte(signed))
+	return h.Sum(nil)
+}
+
+// Sign returns a token carrying payload, signed with the current key.
+func (s *Signer) Sign(payload []byte) string {
+	signed := s.current + "." + encoding.EncodeToString(payload)
+	return signed + "." + encoding.EncodeToString(tag(s.keys[s.current], signed))
+}
+
+// Verify returns the payload of token if it was signed with the current
+// key or a previous one. It returns ErrUnknownKey for tokens signed with
+// other keys, and ErrInvalid for malformed or forged tokens.
+func (s *Signer) Verify(token string) ([]byte, error) {
+	dot := strings.LastIndexByte(token, '.')
+	if dot < 0 {
+		return nil, ErrInvalid
+	}
+	signed := token[:dot]
+	i := strings.IndexByte(signed, '.')
+	if i < 0 {
+		return nil, ErrInvalid
+	}
+	key, ok := s.keys[signed[:i]]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	t, err := encoding.DecodeString(token[dot+1:])
+	if err != nil || subtle.ConstantTimeCompare(t, tag(key, signed)) != 1 {
+		return nil, ErrInvalid
+	}
+	payload, err := encoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return payload, nil
+}
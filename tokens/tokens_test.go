@@ -0,0 +1,95 @@
+package tokens
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+var (
+	k1 = Key{ID: "k1", Secret: []byte("first secret")}
+	k2 = Key{ID: "k2", Secret: []byte("second secret")}
+	k3 = Key{ID: "k3", Secret: []byte("third secret")}
+)
+
+func mustSigner(t *testing.T, current Key, previous ...Key) *Signer {
+	t.Helper()
+	s, err := NewSigner(current, previous...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSignVerify(t *testing.T) {
+	s := mustSigner(t, k1)
+	for _, payload := range [][]byte{nil, []byte("user=42"), bytes.Repeat([]byte{0xff}, 300)} {
+		token := s.Sign(payload)
+		if !strings.HasPrefix(token, "k1.") || strings.Count(token, ".") != 2 {
+			t.Errorf("token %q", token)
+		}
+		got, err := s.Verify(token)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("Verify(%q) = %q, %v, want %q", token, got, err, payload)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := mustSigner(t, k1).Sign([]byte("session"))
+	s := mustSigner(t, k2, k1)
+	if got, err := s.Verify(old); err != nil || string(got) != "session" {
+		t.Errorf("token signed with the previous key: %q, %v", got, err)
+	}
+	if token := s.Sign(nil); !strings.HasPrefix(token, "k2.") {
+		t.Errorf("token %q not signed with the current key", token)
+	}
+	if _, err := mustSigner(t, k3, k2).Verify(old); err != ErrUnknownKey {
+		t.Errorf("token signed with a dropped key: %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestForged(t *testing.T) {
+	s := mustSigner(t, k1, k2)
+	token := s.Sign([]byte("user=42"))
+	parts := strings.Split(token, ".")
+	for _, bad := range []string{
+		"",
+		"k1",
+		parts[0] + "." + parts[1],
+		parts[0] + "." + encoding.EncodeToString([]byte("user=43")) + "." + parts[2],
+		// The key ID is authenticated.
+		"k2." + parts[1] + "." + parts[2],
+		token[:len(token)-1],
+		token + "A",
+		parts[0] + ".!." + parts[2],
+	} {
+		if got, err := s.Verify(bad); err != ErrInvalid {
+			t.Errorf("Verify(%q) = %q, %v, want ErrInvalid", bad, got, err)
+		}
+	}
+	if _, err := mustSigner(t, Key{ID: "k1", Secret: []byte("other")}).Verify(token); err != ErrInvalid {
+		t.Errorf("wrong secret: %v, want ErrInvalid", err)
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		current  Key
+		previous []Key
+		err      error
+	}{
+		{"empty ID", Key{Secret: []byte("s")}, nil, ErrKey},
+		{"dotted ID", Key{ID: "a.b", Secret: []byte("s")}, nil, ErrKey},
+		{"empty secret", Key{ID: "a"}, nil, ErrKey},
+		{"duplicate ID", k1, []Key{k2, {ID: "k1", Secret: []byte("s")}}, ErrKey},
+		{"long secret", k1, []Key{{ID: "big", Secret: make([]byte, blake2b.MaxKeySize+1)}}, blake2b.ErrKeySize},
+	} {
+		if _, err := NewSigner(tt.current, tt.previous...); err != tt.err {
+			t.Errorf("%s: %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
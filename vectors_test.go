@@ -0,0 +1,85 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateVectors(t *testing.T) {
+	vs, err := GenerateVectors(&VectorMatrix{
+		Variants: []Variant{BLAKE2b, BLAKE2s},
+		Sizes:    []uint8{0, 20},
+		Keys:     [][]byte{nil, []byte("key")},
+		Inputs:   [][]byte{nil, []byte("abc")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 16 {
+		t.Fatalf("got %d vectors, want 16", len(vs))
+	}
+	// The first vectors are the unkeyed BLAKE2b-512 digests of "" and "abc".
+	if vs[0].Size != 64 || !bytes.Equal(vs[1].Input, []byte("abc")) {
+		t.Fatalf("unexpected order: %+v, %+v", vs[0], vs[1])
+	}
+	want := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1" +
+		"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	if got := hex.EncodeToString(vs[1].Digest); got != want {
+		t.Errorf("BLAKE2b-512(abc) = %s", got)
+	}
+	for _, v := range vs {
+		if ok, err := v.Verify(); !ok || err != nil {
+			t.Errorf("Verify(%+v) = %v, %v", v, ok, err)
+		}
+	}
+
+	_, err = GenerateVectors(&VectorMatrix{Variants: []Variant{BLAKE2s}, Salts: [][]byte{make([]byte, 16)}})
+	if !errors.Is(err, ErrVector) {
+		t.Errorf("16-byte BLAKE2s salt: err = %v", err)
+	}
+}
+
+func TestVectorsJSON(t *testing.T) {
+	vs, err := GenerateVectors(&VectorMatrix{
+		Variants:  []Variant{BLAKE2b, BLAKE2s},
+		Salts:     [][]byte{nil, []byte("salt")},
+		Personals: [][]byte{[]byte("app")},
+		Inputs:    [][]byte{{0, 1, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteVectors(&buf, vs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadVectors(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(vs) {
+		t.Fatalf("read %d vectors, wrote %d", len(got), len(vs))
+	}
+	for i := range got {
+		if ok, err := got[i].Verify(); !ok || err != nil || got[i].Variant != vs[i].Variant {
+			t.Errorf("vector %d: %+v does not round-trip", i, got[i])
+		}
+	}
+
+	// An entry of the reference blake2-kat.json, without size.
+	kat := `[{"hash":"blake2s","in":"00","key":"","out":` +
+		`"e34d74dbaf4ff4c6abd871cc220451d2ea2648846c7757fbaac82fe51ad64bea"}]`
+	got, err = ReadVectors(strings.NewReader(kat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := got[0].Verify(); !ok || err != nil || got[0].Size != 32 {
+		t.Errorf("reference vector: %+v, %v, %v", got[0], ok, err)
+	}
+	if _, err := ReadVectors(strings.NewReader(`[{"hash":"md5"}]`)); !errors.Is(err, ErrVector) {
+		t.Errorf("unknown hash: err = %v", err)
+	}
+}
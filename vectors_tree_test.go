@@ -0,0 +1,33 @@
+//go:build !blake2_notree
+// +build !blake2_notree
+
+package blake2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestGenerateTreeVectors(t *testing.T) {
+	tree := &VectorTree{Fanout: 2, MaxDepth: 2, LeafSize: 4096, NodeOffset: 1, InnerHashSize: 32, IsLastNode: true}
+	vs, err := GenerateVectors(&VectorMatrix{
+		Variants: []Variant{BLAKE2s},
+		Trees:    []*VectorTree{nil, tree},
+		Inputs:   [][]byte{[]byte("leaf")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := blake2s.New(&blake2s.Config{Tree: &blake2s.Tree{
+		Fanout: 2, MaxDepth: 2, LeafSize: 4096, NodeOffset: 1, InnerHashSize: 32, IsLastNode: true,
+	}})
+	h.Write([]byte("leaf"))
+	if !bytes.Equal(vs[1].Digest, h.Sum(nil)) {
+		t.Error("tree vector does not match the blake2s tree node")
+	}
+	if bytes.Equal(vs[0].Digest, vs[1].Digest) {
+		t.Error("tree parameters are ignored")
+	}
+}
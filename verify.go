@@ -0,0 +1,137 @@
+package blake2
+
+import (
+	"errors"
+	"io"
+)
+
+// Digest is an expected digest, such as a published checksum, together
+// with the parameters of the hash that computes it.
+type Digest struct {
+	// Config of the hash, of the variant given by its Variant field. If
+	// nil or if its Size is 0, the size is that of Sum.
+	Config *Config
+	// Sum is the expected digest.
+	Sum []byte
+}
+
+// newHasher returns a hash computing digests comparable to d.Sum.
+func (d Digest) newHasher() (Hasher, error) {
+	var c Config
+	if d.Config != nil {
+		c = *d.Config
+	}
+	if c.Variant == 0 {
+		c.Variant = BLAKE2b
+	}
+	if c.Size == 0 {
+		if len(d.Sum) == 0 || len(d.Sum) > 64 {
+			return nil, errors.New("blake2: invalid expected digest size")
+		}
+		c.Size = uint8(len(d.Sum))
+	}
+	return NewHasher(c.Variant, &c)
+}
+
+var (
+	// ErrMismatch is returned when data does not hash to the expected
+	// digest.
+	ErrMismatch = errors.New("blake2: digest mismatch")
+	// ErrLength is returned when data is longer or shorter than
+	// expected.
+	ErrLength = errors.New("blake2: unexpected data length")
+)
+
+// VerifyingWriter passes data through to another writer while hashing it,
+// and checks its length and digest against expected values, as when
+// saving a download.
+type VerifyingWriter struct {
+	w        io.Writer
+	h        Hasher
+	expected []byte
+	n, max   int64
+	err      error
+}
+
+// NewVerifyingWriter returns a VerifyingWriter writing to w. If
+// expectedLen is not negative, writes fail with ErrLength as soon as more
+// than expectedLen bytes arrive, and only the first expectedLen bytes
+// reach w.
+func NewVerifyingWriter(w io.Writer, expected Digest, expectedLen int64) (*VerifyingWriter, error) {
+	h, err := expected.newHasher()
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyingWriter{w: w, h: h, expected: expected.Sum, max: expectedLen}, nil
+}
+
+func (v *VerifyingWriter) Write(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	var overrun bool
+	if v.max >= 0 && int64(len(p)) > v.max-v.n {
+		p = p[:v.max-v.n]
+		overrun = true
+	}
+	n, err := v.w.Write(p)
+	v.h.Write(p[:n])
+	v.n += int64(n)
+	switch {
+	case err != nil:
+		v.err = err
+	case overrun:
+		v.err = ErrLength
+	}
+	return n, v.err
+}
+
+// Close checks the data written so far, returning ErrLength if it is
+// shorter than expected and ErrMismatch if its digest differs from the
+// expected one, or the error of a failed write. It does not close the
+// underlying writer.
+func (v *VerifyingWriter) Close() error {
+	switch {
+	case v.err != nil:
+		return v.err
+	case v.max >= 0 && v.n != v.max:
+		return ErrLength
+	case !Equal(v.h.Sum(nil), v.expected):
+		return ErrMismatch
+	}
+	return nil
+}
+
+// VerifyingReader hashes the data read from another reader and checks its
+// digest at the end of the stream, so that a streamed download can be
+// verified without buffering it.
+type VerifyingReader struct {
+	r        io.Reader
+	h        Hasher
+	expected []byte
+	err      error
+}
+
+// NewVerifyingReader returns a VerifyingReader reading from r. When r is
+// exhausted, Read returns io.EOF if the data matched expected and
+// ErrMismatch otherwise. Data read before the end is not yet verified.
+func NewVerifyingReader(r io.Reader, expected Digest) (*VerifyingReader, error) {
+	h, err := expected.newHasher()
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyingReader{r: r, h: h, expected: expected.Sum}, nil
+}
+
+func (v *VerifyingReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF && !Equal(v.h.Sum(nil), v.expected) {
+		err = ErrMismatch
+	}
+	v.err = err
+	return n, err
+}
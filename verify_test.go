@@ -0,0 +1,121 @@
+package blake2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jadeydi/blake2/blake2b"
+	"github.com/jadeydi/blake2/blake2s"
+)
+
+func TestVerifyingWriter(t *testing.T) {
+	data := []byte("the payload of a download")
+	h := blake2s.New256(nil)
+	h.Write(data)
+	expected := Digest{Config: &Config{Variant: BLAKE2s}, Sum: h.Sum(nil)}
+
+	var buf bytes.Buffer
+	w, err := NewVerifyingWriter(&buf, expected, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data[:10])
+	w.Write(data[10:])
+	if err := w.Close(); err != nil {
+		t.Errorf("Close returned %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("wrote %q, want %q", buf.Bytes(), data)
+	}
+
+	w, _ = NewVerifyingWriter(&buf, expected, -1)
+	w.Write(data[1:])
+	if err := w.Close(); err != ErrMismatch {
+		t.Errorf("Close returned %v, want %v", err, ErrMismatch)
+	}
+
+	w, _ = NewVerifyingWriter(&buf, expected, int64(len(data)))
+	w.Write(data[1:])
+	if err := w.Close(); err != ErrLength {
+		t.Errorf("short data: Close returned %v, want %v", err, ErrLength)
+	}
+}
+
+func TestVerifyingWriterOverrun(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewVerifyingWriter(&buf, Digest{Sum: make([]byte, 32)}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if n, err := w.Write([]byte("def")); n != 1 || err != ErrLength {
+		t.Errorf("Write returned %d, %v, want 1, %v", n, err, ErrLength)
+	}
+	if _, err := w.Write([]byte("g")); err != ErrLength {
+		t.Errorf("Write after overrun returned %v, want %v", err, ErrLength)
+	}
+	if buf.String() != "abcd" {
+		t.Errorf("wrote %q, want %q", buf.String(), "abcd")
+	}
+	if err := w.Close(); err != ErrLength {
+		t.Errorf("Close returned %v, want %v", err, ErrLength)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestVerifyingWriterError(t *testing.T) {
+	w, err := NewVerifyingWriter(failingWriter{}, Digest{Sum: make([]byte, 64)}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("data")); err == nil {
+		t.Error("Write did not return the underlying error")
+	}
+	if err := w.Close(); err == nil || err == ErrMismatch {
+		t.Errorf("Close returned %v, want the write error", err)
+	}
+}
+
+func TestDigestSize(t *testing.T) {
+	for _, d := range []Digest{{}, {Sum: make([]byte, 65)}, {Config: &Config{Variant: BLAKE2s}, Sum: make([]byte, 64)}} {
+		if _, err := NewVerifyingWriter(nil, d, -1); err == nil {
+			t.Errorf("digest of %d bytes accepted", len(d.Sum))
+		}
+	}
+}
+
+func TestVerifyingReader(t *testing.T) {
+	data := bytes.Repeat([]byte("streamed payload "), 1000)
+	h := blake2b.New(&blake2b.Config{Size: 32})
+	h.Write(data)
+	expected := Digest{Sum: h.Sum(nil)}
+
+	r, err := NewVerifyingReader(bytes.NewReader(data), expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Errorf("reading returned %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("read data differs")
+	}
+
+	r, _ = NewVerifyingReader(bytes.NewReader(data[1:]), expected)
+	if _, err := io.ReadAll(r); err != ErrMismatch {
+		t.Errorf("reading returned %v, want %v", err, ErrMismatch)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != ErrMismatch {
+		t.Errorf("reading after the end returned %v, want %v", err, ErrMismatch)
+	}
+}
@@ -0,0 +1,92 @@
+package blake2
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrWindow is returned by NewWindowMAC for windows that are not positive
+// and configs without a key.
+var ErrWindow = errors.New("blake2: invalid window MAC")
+
+// WindowMAC computes keyed tags over the last bytes of a stream, a
+// sliding window, so that protocols can insert authenticated sync
+// markers into long streams: a receiver that lost its place, or joined
+// late, resynchronizes at the next marker whose tag it can recompute from
+// the bytes it received, whatever came before the window.
+//
+// Each tag is the MAC of the stream offset of its emission point, as an
+// 8-byte big-endian integer, followed by the window, or the whole stream
+// if it is shorter. A tag thus only verifies at the offset it was emitted
+// at. Tags are computed from a copy of the keyed initial state, without
+// setting up the key again. A WindowMAC is not safe for concurrent use.
+type WindowMAC struct {
+	base Hasher
+	buf  []byte // ring buffer of the last len(buf) bytes
+	pos  int    // index in buf of the next byte
+	n    int64  // bytes written
+}
+
+// NewWindowMAC returns a WindowMAC over the last window bytes, computing
+// tags with a hash of the variant given by cfg.Variant configured by cfg,
+// whose Key must be set.
+func NewWindowMAC(cfg *Config, window int) (*WindowMAC, error) {
+	if window <= 0 || cfg == nil || len(cfg.Key) == 0 {
+		return nil, ErrWindow
+	}
+	h, err := newConfigHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &WindowMAC{base: h, buf: make([]byte, window)}, nil
+}
+
+// Write adds p to the stream. It never returns an error.
+func (w *WindowMAC) Write(p []byte) (int, error) {
+	n := len(p)
+	w.n += int64(n)
+	if len(p) > len(w.buf) {
+		p = p[len(p)-len(w.buf):]
+	}
+	k := copy(w.buf[w.pos:], p)
+	copy(w.buf, p[k:])
+	w.pos = (w.pos + len(p)) % len(w.buf)
+	return n, nil
+}
+
+// Tag appends the tag of the current window to b and returns the
+// resulting slice.
+func (w *WindowMAC) Tag(b []byte) []byte {
+	h := w.base.Clone()
+	var off [8]byte
+	binary.BigEndian.PutUint64(off[:], uint64(w.n))
+	h.Write(off[:])
+	if w.n < int64(len(w.buf)) {
+		h.Write(w.buf[:w.pos])
+	} else {
+		h.Write(w.buf[w.pos:])
+		h.Write(w.buf[:w.pos])
+	}
+	return h.Sum(b)
+}
+
+// Verify reports whether tag is the tag of the current window, in
+// constant time.
+func (w *WindowMAC) Verify(tag []byte) bool {
+	return Equal(w.Tag(nil), tag)
+}
+
+// Offset returns the number of bytes written, the offset of the next tag.
+func (w *WindowMAC) Offset() int64 {
+	return w.n
+}
+
+// Size returns the length of tags.
+func (w *WindowMAC) Size() int {
+	return w.base.Size()
+}
+
+// Reset empties the stream.
+func (w *WindowMAC) Reset() {
+	w.pos, w.n = 0, 0
+}
@@ -0,0 +1,76 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestWindowMAC(t *testing.T) {
+	cfg := &Config{Key: []byte("sync key"), Size: 16}
+	stream := make([]byte, 1000)
+	for i := range stream {
+		stream[i] = byte(i * 31)
+	}
+	want := func(end, window int) []byte {
+		start := end - window
+		if start < 0 {
+			start = 0
+		}
+		h, _ := NewHasher(BLAKE2b, cfg)
+		var off [8]byte
+		binary.BigEndian.PutUint64(off[:], uint64(end))
+		h.Write(off[:])
+		h.Write(stream[start:end])
+		return h.Sum(nil)
+	}
+
+	w, err := NewWindowMAC(cfg, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := 0
+	for _, n := range []int{0, 7, 93, 1, 250, 99, 150} {
+		w.Write(stream[end : end+n])
+		end += n
+		if got := w.Tag(nil); !bytes.Equal(got, want(end, 100)) || len(got) != w.Size() {
+			t.Errorf("tag at %d = %x, want %x", end, got, want(end, 100))
+		}
+	}
+	if w.Offset() != int64(end) {
+		t.Errorf("Offset = %d, want %d", w.Offset(), end)
+	}
+	tag := w.Tag(nil)
+
+	// A receiver that only has the window, at the same offset, gets the
+	// same tag; at another offset, it does not.
+	r, _ := NewWindowMAC(cfg, 100)
+	r.Write(make([]byte, end-100))
+	r.Write(stream[end-100 : end])
+	if !r.Verify(tag) {
+		t.Error("receiver does not verify the tag of the same window")
+	}
+	r.Reset()
+	r.Write(stream[end-100 : end])
+	if r.Verify(tag) {
+		t.Error("tag verified at another offset")
+	}
+
+	if _, err := NewWindowMAC(&Config{}, 100); !errors.Is(err, ErrWindow) {
+		t.Errorf("unkeyed config: err = %v", err)
+	}
+	if _, err := NewWindowMAC(cfg, 0); !errors.Is(err, ErrWindow) {
+		t.Errorf("empty window: err = %v", err)
+	}
+}
+
+func BenchmarkWindowMACTag(b *testing.B) {
+	w, _ := NewWindowMAC(&Config{Key: []byte("key")}, 4096)
+	w.Write(make([]byte, 10000))
+	buf := make([]byte, 0, 64)
+	b.SetBytes(4096)
+	for i := 0; i < b.N; i++ {
+		buf = w.Tag(buf[:0])
+	}
+}
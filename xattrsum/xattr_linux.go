@@ -0,0 +1,36 @@
+package xattrsum
+
+import (
+	"os"
+	"syscall"
+)
+
+func getxattr(name, attr string) ([]byte, error) {
+	buf := make([]byte, 256)
+	for {
+		n, err := syscall.Getxattr(name, attr, buf)
+		switch {
+		case err == syscall.ENODATA:
+			return nil, ErrNotStored
+		case err == syscall.ERANGE:
+			buf = make([]byte, 2*len(buf))
+			continue
+		case err == syscall.ENOTSUP:
+			return nil, ErrUnsupported
+		case err != nil:
+			return nil, &os.PathError{Op: "getxattr", Path: name, Err: err}
+		}
+		return buf[:n], nil
+	}
+}
+
+func setxattr(name, attr string, value []byte) error {
+	err := syscall.Setxattr(name, attr, value, 0)
+	switch {
+	case err == syscall.ENOTSUP:
+		return ErrUnsupported
+	case err != nil:
+		return &os.PathError{Op: "setxattr", Path: name, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package xattrsum
+
+func getxattr(name, attr string) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func setxattr(name, attr string, value []byte) error {
+	return ErrUnsupported
+}
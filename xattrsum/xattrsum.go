@@ -0,0 +1,184 @@
+// Package xattrsum stores the BLAKE2b digest of a file in its extended
+// attributes, together with the modification time at hashing, and uses
+// them to detect silent corruption, like cshatag: a file whose contents
+// changed while its modification time did not has been corrupted, by a
+// failing disk for instance, rather than modified.
+//
+// Extended attributes are supported on Linux; elsewhere the functions
+// return ErrUnsupported.
+package xattrsum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// Names of the extended attributes holding the hexadecimal 32-byte
+// BLAKE2b digest and the modification time, as "<seconds>.<nanoseconds>".
+const (
+	DigestAttr = "user.blake2"
+	TimeAttr   = "user.blake2.ts"
+)
+
+var (
+	// ErrNotStored is returned by Load for files without stored digest.
+	ErrNotStored = errors.New("xattrsum: no stored digest")
+	// ErrChanged is returned when a file changes while it is hashed.
+	ErrChanged = errors.New("xattrsum: file changed while hashing")
+	// ErrUnsupported is returned on platforms without extended
+	// attributes.
+	ErrUnsupported = errors.New("xattrsum: extended attributes not supported")
+)
+
+// Status is the outcome of Check.
+type Status int
+
+const (
+	// New means the file had no stored digest.
+	New Status = iota
+	// OK means the file matches its stored digest.
+	OK
+	// Outdated means the file was modified since its digest was stored.
+	Outdated
+	// Corrupt means the file contents changed but not its modification
+	// time.
+	Corrupt
+)
+
+func (s Status) String() string {
+	switch s {
+	case New:
+		return "new"
+	case OK:
+		return "ok"
+	case Outdated:
+		return "outdated"
+	case Corrupt:
+		return "corrupt"
+	}
+	return "Status(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Sum returns the 32-byte BLAKE2b digest of the file name and its
+// modification time, checked not to change while hashing.
+func Sum(name string) ([]byte, time.Time, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer f.Close()
+	before, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	h := blake2b.New(&blake2b.Config{Size: 32})
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, time.Time{}, err
+	}
+	after, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if !after.ModTime().Equal(before.ModTime()) || after.Size() != before.Size() {
+		return nil, time.Time{}, ErrChanged
+	}
+	return h.Sum(nil), before.ModTime(), nil
+}
+
+// Store hashes the file name and stores its digest and modification time
+// in its extended attributes. It returns the digest.
+func Store(name string) ([]byte, error) {
+	digest, mtime, err := Sum(name)
+	if err != nil {
+		return nil, err
+	}
+	return digest, store(name, digest, mtime)
+}
+
+func store(name string, digest []byte, mtime time.Time) error {
+	if err := setxattr(name, DigestAttr, []byte(hex.EncodeToString(digest))); err != nil {
+		return err
+	}
+	return setxattr(name, TimeAttr, []byte(formatTime(mtime)))
+}
+
+// Load returns the digest and modification time stored in the extended
+// attributes of the file name, or ErrNotStored.
+func Load(name string) ([]byte, time.Time, error) {
+	d, err := getxattr(name, DigestAttr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	ts, err := getxattr(name, TimeAttr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	digest, err := hex.DecodeString(string(d))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("xattrsum: invalid %s attribute: %v", DigestAttr, err)
+	}
+	mtime, err := parseTime(string(ts))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("xattrsum: invalid %s attribute: %v", TimeAttr, err)
+	}
+	return digest, mtime, nil
+}
+
+// Check hashes the file name and compares it with its stored digest. If
+// update is set, it stores the new digest of New and Outdated files;
+// corrupt files are never updated, so that their corruption keeps being
+// reported until resolved with Store.
+func Check(name string, update bool) (Status, error) {
+	digest, mtime, err := Sum(name)
+	if err != nil {
+		return 0, err
+	}
+	stored, storedTime, err := Load(name)
+	var status Status
+	switch {
+	case err == ErrNotStored:
+		status = New
+	case err != nil:
+		return 0, err
+	case !storedTime.Equal(mtime):
+		status = Outdated
+	case bytes.Equal(digest, stored):
+		return OK, nil
+	default:
+		return Corrupt, nil
+	}
+	if update {
+		if err := store(name, digest, mtime); err != nil {
+			return status, err
+		}
+	}
+	return status, nil
+}
+
+func formatTime(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+func parseTime(s string) (time.Time, error) {
+	parts := strings.SplitN(s, ".", 2)
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if len(parts) == 2 {
+		if nsec, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
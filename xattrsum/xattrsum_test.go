@@ -0,0 +1,132 @@
+package xattrsum
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jadeydi/blake2/blake2b"
+)
+
+// tempFile writes data to a new file, skipping the test if its file system
+// does not support user extended attributes.
+func tempFile(t *testing.T, data []byte) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setxattr(name, DigestAttr, []byte("probe")); err == ErrUnsupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestStoreLoad(t *testing.T) {
+	data := []byte("hello, world")
+	name := tempFile(t, data)
+	if _, _, err := Load(name); err != ErrNotStored {
+		t.Fatalf("Load before Store: %v, want ErrNotStored", err)
+	}
+	digest, err := Store(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := blake2b.New(&blake2b.Config{Size: 32})
+	want.Write(data)
+	if !bytes.Equal(digest, want.Sum(nil)) {
+		t.Fatalf("Store = %x, want %x", digest, want.Sum(nil))
+	}
+	stored, mtime, err := Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, digest) || !mtime.Equal(info.ModTime()) {
+		t.Fatalf("Load = %x, %v, want %x, %v", stored, mtime, digest, info.ModTime())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	name := tempFile(t, []byte("original contents"))
+	if s, err := Check(name, false); err != nil || s != New {
+		t.Fatalf("Check = %v, %v, want new", s, err)
+	}
+	if _, _, err := Load(name); err != ErrNotStored {
+		t.Fatalf("Check without update stored a digest: %v", err)
+	}
+	if s, err := Check(name, true); err != nil || s != New {
+		t.Fatalf("Check = %v, %v, want new", s, err)
+	}
+	if s, err := Check(name, true); err != nil || s != OK {
+		t.Fatalf("Check = %v, %v, want ok", s, err)
+	}
+
+	// A regular modification changes the modification time.
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte("modified contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Check(name, true); err != nil || s != Outdated {
+		t.Fatalf("Check = %v, %v, want outdated", s, err)
+	}
+	if s, err := Check(name, true); err != nil || s != OK {
+		t.Fatalf("Check = %v, %v, want ok", s, err)
+	}
+
+	// Silent corruption keeps the modification time.
+	if err := os.WriteFile(name, []byte("modified c0ntents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if s, err := Check(name, true); err != nil || s != Corrupt {
+			t.Fatalf("Check = %v, %v, want corrupt", s, err)
+		}
+	}
+	if _, err := Store(name); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Check(name, false); err != nil || s != OK {
+		t.Fatalf("Check after Store = %v, %v, want ok", s, err)
+	}
+}
+
+func TestTime(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Unix(0, 0),
+		time.Unix(1700000000, 123456789),
+		time.Unix(-5, 1),
+	} {
+		s := formatTime(tm)
+		got, err := parseTime(s)
+		if err != nil || !got.Equal(tm) {
+			t.Errorf("parseTime(%q) = %v, %v, want %v", s, got, err, tm)
+		}
+	}
+	if _, err := parseTime("x.1"); err == nil {
+		t.Error("parseTime accepted an invalid time")
+	}
+}